@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/qmd/qmd-go/internal/anel"
 	"github.com/qmd/qmd-go/internal/cli"
 )
 
@@ -18,8 +22,22 @@ func main() {
 		log.Printf("Warning: %v", err)
 	}
 
+	// Export spans to OTEL_EXPORTER_OTLP_ENDPOINT when set, otherwise keep
+	// them in-memory; either way every command gets traced.
+	shutdownTracing, err := anel.InitTracing(context.Background())
+	if err != nil {
+		log.Printf("Warning: tracing disabled: %v", err)
+	} else {
+		defer shutdownTracing(context.Background())
+	}
+
+	// Cancel the root context on SIGINT/SIGTERM so a long-running search
+	// or embed can unwind instead of being killed mid-write.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Build and run CLI
-	if err := cli.RootCmd.Execute(); err != nil {
+	if err := cli.RootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
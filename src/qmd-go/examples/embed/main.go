@@ -0,0 +1,53 @@
+// Command embed demonstrates using pkg/qmd to embed QMD in another Go
+// program: load a config, build a Client, and run a hybrid search
+// without going through the qmd CLI or Cobra.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/qmd/qmd-go/internal/config"
+	"github.com/qmd/qmd-go/internal/store"
+	"github.com/qmd/qmd-go/pkg/qmd"
+)
+
+func main() {
+	configPath := flag.String("config", "", "Path to a qmd index.yaml (defaults to qmd's built-in defaults)")
+	query := flag.String("query", "", "Query to run a hybrid search for")
+	flag.Parse()
+
+	if *query == "" {
+		log.Fatal("usage: embed -query <text> [-config path]")
+	}
+
+	cfg := config.DefaultConfig()
+	if *configPath != "" {
+		data, err := os.ReadFile(*configPath)
+		if err != nil {
+			log.Fatalf("reading config: %v", err)
+		}
+		cfg, err = config.LoadConfigFromData(data)
+		if err != nil {
+			log.Fatalf("parsing config: %v", err)
+		}
+	}
+
+	client, err := qmd.NewClient(cfg)
+	if err != nil {
+		log.Fatalf("building client: %v", err)
+	}
+
+	ctx := context.Background()
+	results, err := client.HybridSearch(ctx, *query, store.SearchOptions{Limit: 10})
+	if err != nil {
+		log.Fatalf("searching: %v", err)
+	}
+
+	for _, r := range results {
+		fmt.Printf("[%.3f] %s (%s)\n", r.Score, r.Path, r.Collection)
+	}
+}
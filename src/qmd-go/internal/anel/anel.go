@@ -8,10 +8,13 @@
 package anel
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-	"time"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ANEL protocol version
@@ -29,6 +32,11 @@ var (
 	EnvDryRun = "AGENT_DRY_RUN"
 	// EMIT_SPEC is the emit spec mode
 	EnvEmitSpec = "AGENT_EMIT_SPEC"
+	// TRACEPARENT carries a W3C Trace Context traceparent header, taking
+	// priority over AGENT_TRACE_ID when both are set and well-formed.
+	EnvTraceParent = "TRACEPARENT"
+	// TRACESTATE carries the companion W3C tracestate header.
+	EnvTraceState = "TRACESTATE"
 )
 
 // Severity levels for errors
@@ -36,9 +44,9 @@ type Severity string
 
 const (
 	SeverityDebug    Severity = "debug"
-	SeverityInfo    Severity = "info"
-	SeverityWarning Severity = "warning"
-	SeverityError   Severity = "error"
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityError    Severity = "error"
 	SeverityCritical Severity = "critical"
 )
 
@@ -51,6 +59,10 @@ const (
 	ErrorCodeInvalidInput     ErrorCode = "INVALID_INPUT"
 	ErrorCodeNotFound         ErrorCode = "NOT_FOUND"
 	ErrorCodePermissionDenied ErrorCode = "PERMISSION_DENIED"
+	// ErrorCodeTimeout marks a call that was cancelled because it
+	// crossed its deadline (or was cancelled by the caller before
+	// finishing) rather than failing outright.
+	ErrorCodeTimeout ErrorCode = "TIMEOUT"
 
 	// Search-related errors
 	ErrorCodeSearchFailed    ErrorCode = "SEARCH_FAILED"
@@ -87,6 +99,8 @@ func (e ErrorCode) ToStatus() int {
 		return 404
 	case ErrorCodePermissionDenied:
 		return 403
+	case ErrorCodeTimeout:
+		return 504
 	case ErrorCodeSearchFailed:
 		return 500
 	case ErrorCodeIndexNotReady:
@@ -142,16 +156,54 @@ func (h RecoveryHint) WithAction(action string) RecoveryHint {
 // AnelError is the ANID Error type (Agent-Native ID Error)
 // Implements RFC 7807 Problem Details with ANEL extensions
 type AnelError struct {
-	ErrorCode     ErrorCode        `json:"error_code"`
-	Status        int              `json:"status"`
-	Title         string           `json:"title"`
-	Message       string           `json:"message"`
-	Severity      Severity         `json:"severity"`
-	RecoveryHints []RecoveryHint   `json:"recovery_hints"`
-	TraceID       *string          `json:"trace_id,omitempty"`
-	Metadata      map[string]any   `json:"-"`
+	ErrorCode     ErrorCode      `json:"error_code"`
+	Status        int            `json:"status"`
+	Title         string         `json:"title"`
+	Message       string         `json:"message"`
+	Severity      Severity       `json:"severity"`
+	RecoveryHints []RecoveryHint `json:"recovery_hints"`
+	TraceID       *string        `json:"trace_id,omitempty"`
+	// SpanID is the otel span active when WithSpanContext was called, so
+	// an external collector's trace for the same TraceID can be joined
+	// down to the specific span that failed, not just the request.
+	SpanID *string `json:"span_id,omitempty"`
+	// Causes holds the individual failures a batch operation (indexing a
+	// directory, searching across collections) collapsed into this one
+	// AnelError, via Join or WithCauses, so a caller doesn't have to pick
+	// just one failure to report and discard the rest.
+	Causes   []*AnelError   `json:"causes,omitempty"`
+	Metadata map[string]any `json:"-"`
+	// cause is the original error WrapAnelError or FromError's fallback
+	// path attached, surfaced through Unwrap so errors.Is/errors.As see
+	// past the AnelError to whatever it's wrapping. Not set when an
+	// AnelError is built directly via NewAnelError.
+	cause error
 }
 
+// Sentinel AnelErrors for errors.Is comparisons against a bare ErrorCode,
+// e.g. errors.Is(err, anel.ErrNotFound). Each carries only its code; Is
+// compares by ErrorCode alone, so any AnelError in err's chain with a
+// matching code satisfies the check regardless of its Message/Title.
+var (
+	ErrUnknown             = &AnelError{ErrorCode: ErrorCodeUnknown}
+	ErrInvalidInput        = &AnelError{ErrorCode: ErrorCodeInvalidInput}
+	ErrNotFound            = &AnelError{ErrorCode: ErrorCodeNotFound}
+	ErrPermissionDenied    = &AnelError{ErrorCode: ErrorCodePermissionDenied}
+	ErrSearchFailed        = &AnelError{ErrorCode: ErrorCodeSearchFailed}
+	ErrIndexNotReady       = &AnelError{ErrorCode: ErrorCodeIndexNotReady}
+	ErrQueryParseError     = &AnelError{ErrorCode: ErrorCodeQueryParseError}
+	ErrCollectionNotFound  = &AnelError{ErrorCode: ErrorCodeCollectionNotFound}
+	ErrCollectionExists    = &AnelError{ErrorCode: ErrorCodeCollectionExists}
+	ErrCollectionCorrupted = &AnelError{ErrorCode: ErrorCodeCollectionCorrupted}
+	ErrEmbeddingFailed     = &AnelError{ErrorCode: ErrorCodeEmbeddingFailed}
+	ErrModelNotFound       = &AnelError{ErrorCode: ErrorCodeModelNotFound}
+	ErrModelLoadFailed     = &AnelError{ErrorCode: ErrorCodeModelLoadFailed}
+	ErrStorageError        = &AnelError{ErrorCode: ErrorCodeStorageError}
+	ErrBackendUnavailable  = &AnelError{ErrorCode: ErrorCodeBackendUnavailable}
+	ErrConfigError         = &AnelError{ErrorCode: ErrorCodeConfigError}
+	ErrEnvironmentError    = &AnelError{ErrorCode: ErrorCodeEnvironmentError}
+)
+
 // NewAnelError creates a new ANEL error
 func NewAnelError(errorCode ErrorCode, title, message string) *AnelError {
 	return &AnelError{
@@ -178,12 +230,51 @@ func (e *AnelError) WithTraceID(traceID string) *AnelError {
 	return e
 }
 
+// WithSpanContext sets TraceID and SpanID from ctx's active otel span,
+// when one is recording, so an agent reading this error's NDJSON can
+// jump straight to the span that failed in an external trace collector.
+// It's a no-op if ctx carries no valid span context, leaving TraceID
+// (e.g. one already set via WithTraceID from the caller's opaque
+// AGENT_TRACE_ID) untouched.
+func (e *AnelError) WithSpanContext(ctx context.Context) *AnelError {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return e
+	}
+	traceID := sc.TraceID().String()
+	spanID := sc.SpanID().String()
+	e.TraceID = &traceID
+	e.SpanID = &spanID
+	return e
+}
+
 // WithMetadata adds metadata
 func (e *AnelError) WithMetadata(key string, value any) *AnelError {
 	e.Metadata[key] = value
 	return e
 }
 
+// WithCauses appends causes (nils skipped) to e.Causes. If any cause's
+// Status is more severe than e's own, e adopts that cause's
+// ErrorCode/Status/Title/Severity -- the same "most severe wins" rule
+// Join uses -- so e still makes sense as a standalone error even though
+// it started out representing just one of several failures.
+func (e *AnelError) WithCauses(causes ...*AnelError) *AnelError {
+	for _, c := range causes {
+		if c == nil {
+			continue
+		}
+		e.Causes = append(e.Causes, c)
+		if c.Status > e.Status {
+			e.ErrorCode = c.ErrorCode
+			e.Status = c.Status
+			e.Title = c.Title
+			e.Severity = c.Severity
+		}
+	}
+	return e
+}
+
 // ToNDJSON serializes to NDJSON line
 func (e *AnelError) ToNDJSON() string {
 	data, err := json.Marshal(e)
@@ -198,44 +289,48 @@ func (e *AnelError) EmitStderr() {
 	fmt.Fprintln(os.Stderr, e.ToNDJSON())
 }
 
-// Error implements error interface
+// Error implements error interface. An AnelError with Causes (built via
+// Join or WithCauses) renders as a summary of all of them instead of its
+// own Title/Message, which describe the batch rather than any one
+// failure.
 func (e *AnelError) Error() string {
+	if len(e.Causes) > 0 {
+		parts := make([]string, len(e.Causes))
+		for i, c := range e.Causes {
+			parts[i] = fmt.Sprintf("[%s] %s", c.ErrorCode, c.Message)
+		}
+		return fmt.Sprintf("%d errors: %s", len(e.Causes), strings.Join(parts, "; "))
+	}
 	return fmt.Sprintf("[%s] %s: %s", e.ErrorCode, e.Title, e.Message)
 }
 
-// TraceContext for request correlation
-type TraceContext struct {
-	TraceID       *string        `json:"trace_id,omitempty"`
-	IdentityToken *string        `json:"identity_token,omitempty"`
-	Tags          map[string]string `json:"tags,omitempty"`
+// Unwrap returns the error AnelError was built from, if any, enabling
+// errors.Is/errors.As to see past it to the original cause.
+func (e *AnelError) Unwrap() error {
+	return e.cause
 }
 
-// NewTraceContext creates a new trace context from environment variables
-func NewTraceContext() TraceContext {
-	traceID := os.Getenv(EnvTraceID)
-	identityToken := os.Getenv(EnvIdentityToken)
-
-	ctx := TraceContext{
-		Tags: map[string]string{},
+// Is reports whether target is an *AnelError with the same ErrorCode,
+// the AnelError half of the errors.Is(err, anel.ErrNotFound)-style
+// sentinel checks; compare anel.Error.Is, which does the same for the
+// richer internal Error type.
+func (e *AnelError) Is(target error) bool {
+	t, ok := target.(*AnelError)
+	if !ok {
+		return false
 	}
-
-	if traceID != "" {
-		ctx.TraceID = &traceID
-	}
-	if identityToken != "" {
-		ctx.IdentityToken = &identityToken
-	}
-
-	return ctx
+	return e.ErrorCode == t.ErrorCode
 }
 
-// GetOrGenerateTraceID returns existing trace ID or generates a new one
-func (t *TraceContext) GetOrGenerateTraceID() string {
-	if t.TraceID != nil && *t.TraceID != "" {
-		return *t.TraceID
-	}
-	id := fmt.Sprintf("qmd-%d", time.Now().UnixNano())
-	return id
+// WrapAnelError builds an AnelError directly from a plain Go error,
+// keeping it as the Unwrap cause. It's the AnelError-producing
+// counterpart of Wrap, which builds the richer internal Error type that
+// most business logic returns instead -- use this one at a boundary that
+// needs an AnelError straight away instead of going through Error.
+func WrapAnelError(cause error, code ErrorCode, message string) *AnelError {
+	aerr := NewAnelError(code, titleForCode(code), message)
+	aerr.cause = cause
+	return aerr
 }
 
 // AnelSpec represents the ANEL specification for a command
@@ -244,7 +339,7 @@ type AnelSpec struct {
 	Command      string          `json:"command"`
 	InputSchema  json.RawMessage `json:"input_schema"`
 	OutputSchema json.RawMessage `json:"output_schema"`
-	ErrorCodes   []ErrorCode    `json:"error_codes"`
+	ErrorCodes   []ErrorCode     `json:"error_codes"`
 }
 
 // ToJSON converts spec to JSON string
@@ -289,30 +384,30 @@ func (r *NDJSONRecord) Emit() {
 
 // AnelResult represents the result of an ANEL command
 type AnelResult struct {
-	Success  bool            `json:"success"`
-	Data     json.RawMessage `json:"data,omitempty"`
-	Error    *AnelError     `json:"error,omitempty"`
-	TraceID  *string        `json:"trace_id,omitempty"`
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   *AnelError      `json:"error,omitempty"`
+	TraceID *string         `json:"trace_id,omitempty"`
 }
 
 // NewSuccessResult creates a success result
 func NewSuccessResult(data any) *AnelResult {
 	jsonData, _ := json.Marshal(data)
 	return &AnelResult{
-		Success:  true,
-		Data:     jsonData,
-		Error:    nil,
-		TraceID:  nil,
+		Success: true,
+		Data:    jsonData,
+		Error:   nil,
+		TraceID: nil,
 	}
 }
 
 // NewErrorResult creates an error result
 func NewErrorResult(err *AnelError) *AnelResult {
 	return &AnelResult{
-		Success:  false,
-		Data:     nil,
-		Error:    err,
-		TraceID:  err.TraceID,
+		Success: false,
+		Data:    nil,
+		Error:   err,
+		TraceID: err.TraceID,
 	}
 }
 
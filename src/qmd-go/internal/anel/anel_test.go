@@ -1,11 +1,14 @@
 package anel
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"testing"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // --- ErrorCode ---
@@ -19,6 +22,7 @@ func TestErrorCodeToStatus(t *testing.T) {
 		{ErrorCodeInvalidInput, 400},
 		{ErrorCodeNotFound, 404},
 		{ErrorCodePermissionDenied, 403},
+		{ErrorCodeTimeout, 504},
 		{ErrorCodeSearchFailed, 500},
 		{ErrorCodeIndexNotReady, 503},
 		{ErrorCodeQueryParseError, 400},
@@ -109,6 +113,40 @@ func TestAnelErrorWithTraceID(t *testing.T) {
 	}
 }
 
+func TestAnelErrorWithSpanContext(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	err := NewAnelError(ErrorCodeSearchFailed, "Search Failed", "index corrupted").
+		WithSpanContext(ctx)
+
+	if err.TraceID == nil || *err.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("TraceID = %v, want 4bf92f3577b34da6a3ce929d0e0e4736", err.TraceID)
+	}
+	if err.SpanID == nil || *err.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("SpanID = %v, want 00f067aa0ba902b7", err.SpanID)
+	}
+}
+
+func TestAnelErrorWithSpanContextNoopWithoutSpan(t *testing.T) {
+	err := NewAnelError(ErrorCodeSearchFailed, "Search Failed", "index corrupted").
+		WithTraceID("trace-abc-123").
+		WithSpanContext(context.Background())
+
+	if err.TraceID == nil || *err.TraceID != "trace-abc-123" {
+		t.Errorf("TraceID = %v, want trace-abc-123 to be left untouched", err.TraceID)
+	}
+	if err.SpanID != nil {
+		t.Errorf("SpanID = %v, want nil", err.SpanID)
+	}
+}
+
 func TestAnelErrorWithMetadata(t *testing.T) {
 	err := NewAnelError(ErrorCodeInvalidInput, "Bad Input", "bad query").
 		WithMetadata("field", "query").
@@ -184,56 +222,8 @@ func TestRecoveryHintJSON(t *testing.T) {
 	}
 }
 
-// --- TraceContext ---
-
-func TestNewTraceContextEmpty(t *testing.T) {
-	os.Unsetenv(EnvTraceID)
-	os.Unsetenv(EnvIdentityToken)
-
-	ctx := NewTraceContext()
-
-	if ctx.TraceID != nil {
-		t.Errorf("TraceID should be nil when env not set, got %v", ctx.TraceID)
-	}
-	if ctx.IdentityToken != nil {
-		t.Errorf("IdentityToken should be nil when env not set, got %v", ctx.IdentityToken)
-	}
-}
-
-func TestNewTraceContextFromEnv(t *testing.T) {
-	os.Setenv(EnvTraceID, "test-trace-id")
-	os.Setenv(EnvIdentityToken, "test-token")
-	defer os.Unsetenv(EnvTraceID)
-	defer os.Unsetenv(EnvIdentityToken)
-
-	ctx := NewTraceContext()
-
-	if ctx.TraceID == nil || *ctx.TraceID != "test-trace-id" {
-		t.Errorf("TraceID = %v, want test-trace-id", ctx.TraceID)
-	}
-	if ctx.IdentityToken == nil || *ctx.IdentityToken != "test-token" {
-		t.Errorf("IdentityToken = %v, want test-token", ctx.IdentityToken)
-	}
-}
-
-func TestGetOrGenerateTraceID_Existing(t *testing.T) {
-	traceID := "existing-trace"
-	ctx := TraceContext{TraceID: &traceID}
-
-	got := ctx.GetOrGenerateTraceID()
-	if got != "existing-trace" {
-		t.Errorf("GetOrGenerateTraceID() = %s, want existing-trace", got)
-	}
-}
-
-func TestGetOrGenerateTraceID_Generated(t *testing.T) {
-	ctx := TraceContext{}
-
-	got := ctx.GetOrGenerateTraceID()
-	if !strings.HasPrefix(got, "qmd-") {
-		t.Errorf("GetOrGenerateTraceID() = %s, should start with qmd-", got)
-	}
-}
+// TraceContext has its own tests in trace_test.go, alongside the W3C
+// traceparent parsing/generation it was extended with.
 
 // --- FromError ---
 
@@ -288,9 +278,14 @@ func TestIsNotFound(t *testing.T) {
 		t.Error("IsNotFound should return true for NOT_FOUND AnelError")
 	}
 
+	wrapped := fmt.Errorf("while reading: %w", Newf(ErrorCodeNotFound, "missing_file", "missing"))
+	if !IsNotFound(wrapped) {
+		t.Error("IsNotFound should return true for a wrapped NOT_FOUND Error")
+	}
+
 	plainErr := fmt.Errorf("resource not found")
-	if !IsNotFound(plainErr) {
-		t.Error("IsNotFound should return true for error containing 'not found'")
+	if IsNotFound(plainErr) {
+		t.Error("IsNotFound should return false for a plain error with no NOT_FOUND in its chain")
 	}
 
 	otherErr := fmt.Errorf("something else")
@@ -313,6 +308,141 @@ func TestIsPermissionDenied(t *testing.T) {
 	}
 }
 
+// --- Join / CollectErrors ---
+
+func TestJoin_NilErrsReturnsNil(t *testing.T) {
+	if got := Join(nil, nil); got != nil {
+		t.Errorf("Join(nil, nil) = %v, want nil", got)
+	}
+}
+
+func TestJoin_SingleErrReturnsItUnwrapped(t *testing.T) {
+	anelErr := NewAnelError(ErrorCodeNotFound, "Not Found", "missing")
+	got := Join(anelErr)
+	if got != anelErr {
+		t.Errorf("Join(single) = %v, want the same *AnelError back", got)
+	}
+}
+
+func TestJoin_PicksMostSevereStatus(t *testing.T) {
+	notFound := NewAnelError(ErrorCodeNotFound, "Not Found", "missing")     // 404
+	storage := NewAnelError(ErrorCodeStorageError, "Storage Error", "disk") // 500
+
+	joined := Join(notFound, storage)
+	if joined.ErrorCode != ErrorCodeStorageError {
+		t.Errorf("ErrorCode = %s, want %s (the higher-Status cause)", joined.ErrorCode, ErrorCodeStorageError)
+	}
+	if len(joined.Causes) != 2 {
+		t.Fatalf("len(Causes) = %d, want 2", len(joined.Causes))
+	}
+}
+
+func TestJoin_FlattensNestedJoins(t *testing.T) {
+	a := NewAnelError(ErrorCodeNotFound, "Not Found", "a")
+	b := NewAnelError(ErrorCodeInvalidInput, "Bad", "b")
+	c := NewAnelError(ErrorCodeStorageError, "Storage Error", "c")
+
+	nested := Join(Join(a, b), c)
+	flat := Join(a, b, c)
+	if len(nested.Causes) != len(flat.Causes) {
+		t.Errorf("len(nested.Causes) = %d, want %d (same as a flat Join)", len(nested.Causes), len(flat.Causes))
+	}
+}
+
+func TestJoin_ErrorRendersAllCauses(t *testing.T) {
+	a := NewAnelError(ErrorCodeNotFound, "Not Found", "missing file")
+	b := NewAnelError(ErrorCodeInvalidInput, "Bad", "bad frontmatter")
+
+	joined := Join(a, b)
+	want := "2 errors: [NOT_FOUND] missing file; [INVALID_INPUT] bad frontmatter"
+	if got := joined.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestJoin_IsNotFoundMatchesAnyCause(t *testing.T) {
+	a := NewAnelError(ErrorCodeStorageError, "Storage Error", "disk")
+	b := NewAnelError(ErrorCodeNotFound, "Not Found", "missing")
+
+	joined := Join(a, b)
+	if !IsNotFound(joined) {
+		t.Error("IsNotFound should return true if any cause matches, not just the composite's own ErrorCode")
+	}
+}
+
+func TestCollectErrors_ErrIsNilWhenNothingAdded(t *testing.T) {
+	var c CollectErrors
+	if err := c.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+}
+
+func TestCollectErrors_AddSkipsNil(t *testing.T) {
+	var c CollectErrors
+	c.Add(nil)
+	c.Add(NewAnelError(ErrorCodeNotFound, "Not Found", "missing"))
+	c.Add(nil)
+	if c.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", c.Len())
+	}
+}
+
+// --- DeadlineExceededError ---
+
+func TestDeadlineExceededError_CodeAndHints(t *testing.T) {
+	err := DeadlineExceededError(context.DeadlineExceeded)
+
+	if err.ErrorCode != ErrorCodeBackendUnavailable {
+		t.Errorf("ErrorCode = %s, want %s", err.ErrorCode, ErrorCodeBackendUnavailable)
+	}
+	if len(err.RecoveryHints) != 2 {
+		t.Fatalf("RecoveryHints count = %d, want 2", len(err.RecoveryHints))
+	}
+	if err.RecoveryHints[0].Code != "RETRY" || err.RecoveryHints[1].Code != "INCREASE_TIMEOUT" {
+		t.Errorf("RecoveryHints = %+v, want RETRY then INCREASE_TIMEOUT", err.RecoveryHints)
+	}
+}
+
+func TestDeadlineExceededError_UnwrapsToCause(t *testing.T) {
+	err := DeadlineExceededError(context.DeadlineExceeded)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("errors.Is(err, context.DeadlineExceeded) should be true through Unwrap")
+	}
+}
+
+func TestCollectErrors_ErrJoinsAccumulatedFailures(t *testing.T) {
+	var c CollectErrors
+	c.Add(NewAnelError(ErrorCodeNotFound, "Not Found", "a"))
+	c.Add(NewAnelError(ErrorCodeInvalidInput, "Bad", "b"))
+
+	err := c.Err()
+	if err == nil || len(err.Causes) != 2 {
+		t.Fatalf("Err() = %v, want a joined AnelError with 2 causes", err)
+	}
+}
+
+// --- errors.Is/errors.As on AnelError and the sentinel errors ---
+
+func TestAnelError_UnwrapAndIs(t *testing.T) {
+	cause := fmt.Errorf("disk full")
+	aerr := WrapAnelError(cause, ErrorCodeStorageError, "could not write index")
+
+	if !errors.Is(aerr, ErrStorageError) {
+		t.Error("errors.Is(aerr, ErrStorageError) should be true")
+	}
+	if errors.Is(aerr, ErrNotFound) {
+		t.Error("errors.Is(aerr, ErrNotFound) should be false")
+	}
+	if !errors.Is(aerr, cause) {
+		t.Error("errors.Is(aerr, cause) should see through to the wrapped cause")
+	}
+
+	var target *AnelError
+	if !errors.As(aerr, &target) || target != aerr {
+		t.Error("errors.As should recover the AnelError itself")
+	}
+}
+
 // --- AnelSpec ---
 
 func TestAnelSpecToJSON(t *testing.T) {
@@ -1,15 +1,31 @@
 package anel
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 )
 
-// FromError converts a standard error to AnelError
+// FromError converts a standard error to AnelError. An *AnelError
+// already in err's chain is returned as-is; failing that, a wrapped
+// *Error is unwrapped via ToAnelError so its Minor/Details/Hint survive.
+// Only when neither is present does it fall back to pattern-matching the
+// error message -- a last resort for errors that never passed through
+// anel.Wrap/Newf/WrapAnelError in the first place.
 func FromError(err error) *AnelError {
 	if err == nil {
 		return nil
 	}
 
+	var aerr *AnelError
+	if errors.As(err, &aerr) {
+		return aerr
+	}
+
+	if rerr, ok := As(err); ok {
+		return rerr.ToAnelError("")
+	}
+
 	message := err.Error()
 
 	// Try to extract error code from error message
@@ -35,7 +51,9 @@ func FromError(err error) *AnelError {
 		errorCode = ErrorCodeUnknown
 	}
 
-	return NewAnelError(errorCode, "Operation Failed", message)
+	result := NewAnelError(errorCode, "Operation Failed", message)
+	result.cause = err
+	return result
 }
 
 // FromErrorWithContext converts error with trace context
@@ -48,26 +66,128 @@ func FromErrorWithContext(err error, ctx *TraceContext) *AnelError {
 	return anelErr
 }
 
-// IsNotFound checks if error is a not found error
+// DeadlineExceededError builds the AnelError a caller whose
+// --timeout/--deadline expired mid-operation should see:
+// ErrorCodeBackendUnavailable, wrapping cause (normally
+// context.DeadlineExceeded or context.Canceled) so errors.Is(err,
+// context.DeadlineExceeded) still sees through it, with the RETRY/
+// INCREASE_TIMEOUT recovery hints a client can act on without parsing
+// the message.
+func DeadlineExceededError(cause error) *AnelError {
+	aerr := WrapAnelError(cause, ErrorCodeBackendUnavailable, fmt.Sprintf("operation did not finish before its deadline: %v", cause))
+	aerr.WithHint(NewRecoveryHint("RETRY", "Wait a few seconds and retry"))
+	aerr.WithHint(NewRecoveryHint("INCREASE_TIMEOUT", "Re-run with a longer --timeout/--deadline"))
+	return aerr
+}
+
+// IsNotFound reports whether err's chain contains an AnelError or Error
+// carrying ErrorCodeNotFound, via errors.As rather than matching err's
+// message text.
 func IsNotFound(err error) bool {
-	if aerr, ok := err.(*AnelError); ok {
-		return aerr.ErrorCode == ErrorCodeNotFound
-	}
-	return strings.Contains(err.Error(), "not found")
+	return hasErrorCode(err, ErrorCodeNotFound)
 }
 
-// IsInvalidInput checks if error is an invalid input error
+// IsInvalidInput reports whether err's chain contains an AnelError or
+// Error carrying ErrorCodeInvalidInput.
 func IsInvalidInput(err error) bool {
-	if aerr, ok := err.(*AnelError); ok {
-		return aerr.ErrorCode == ErrorCodeInvalidInput
-	}
-	return strings.Contains(err.Error(), "invalid")
+	return hasErrorCode(err, ErrorCodeInvalidInput)
 }
 
-// IsPermissionDenied checks if error is a permission denied error
+// IsPermissionDenied reports whether err's chain contains an AnelError
+// or Error carrying ErrorCodePermissionDenied.
 func IsPermissionDenied(err error) bool {
-	if aerr, ok := err.(*AnelError); ok {
-		return aerr.ErrorCode == ErrorCodePermissionDenied
+	return hasErrorCode(err, ErrorCodePermissionDenied)
+}
+
+// hasErrorCode walks err's chain looking for either error type this
+// package produces -- AnelError at the API/MCP boundary, Error in
+// business logic -- and reports whether one of them carries code.
+func hasErrorCode(err error, code ErrorCode) bool {
+	var aerr *AnelError
+	if errors.As(err, &aerr) {
+		if aerr.ErrorCode == code {
+			return true
+		}
+		for _, cause := range aerr.Causes {
+			if cause.ErrorCode == code {
+				return true
+			}
+		}
+		return false
+	}
+	var rerr *Error
+	if errors.As(err, &rerr) {
+		return rerr.Code == code
+	}
+	return false
+}
+
+// Join combines errs into a single *AnelError: nils are dropped, and any
+// *AnelError among errs that itself carries Causes (i.e. came from a
+// previous Join or WithCauses) has its Causes spliced in directly rather
+// than nested one level deeper, so Join(Join(a, b), c) flattens to the
+// same three causes as Join(a, b, c). Returns nil if every err is nil,
+// or err unchanged (converted via FromError) if exactly one is non-nil.
+// The composite's ErrorCode/Status/Title/Severity are those of whichever
+// cause has the highest Status -- the most severe -- on the theory that
+// a 5xx failure in a batch shouldn't be hidden behind a 4xx. Use
+// CollectErrors to build up errs incrementally inside a batch worker.
+func Join(errs ...error) *AnelError {
+	var causes []*AnelError
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		aerr := FromError(err)
+		if len(aerr.Causes) > 0 {
+			causes = append(causes, aerr.Causes...)
+			continue
+		}
+		causes = append(causes, aerr)
+	}
+	if len(causes) == 0 {
+		return nil
+	}
+	if len(causes) == 1 {
+		return causes[0]
+	}
+
+	worst := causes[0]
+	for _, c := range causes[1:] {
+		if c.Status > worst.Status {
+			worst = c
+		}
 	}
-	return strings.Contains(err.Error(), "permission")
+
+	joined := NewAnelError(worst.ErrorCode, worst.Title, fmt.Sprintf("%d errors", len(causes)))
+	joined.Severity = worst.Severity
+	joined.Causes = causes
+	return joined
+}
+
+// CollectErrors accumulates failures from a batch of operations -- e.g.
+// an index worker walking a directory, or a search fanning out across
+// collections -- without aborting the batch after the first one. Its
+// zero value is ready to use.
+type CollectErrors struct {
+	errs []error
+}
+
+// Add records err, a no-op if err is nil.
+func (c *CollectErrors) Add(err error) {
+	if err == nil {
+		return
+	}
+	c.errs = append(c.errs, err)
+}
+
+// Len reports how many failures have been recorded so far.
+func (c *CollectErrors) Len() int {
+	return len(c.errs)
+}
+
+// Err returns the accumulated failures as a single *AnelError via Join,
+// or nil if none were recorded.
+func (c *CollectErrors) Err() *AnelError {
+	return Join(c.errs...)
 }
@@ -0,0 +1,151 @@
+package anel
+
+import "encoding/json"
+
+// commandPaths maps each registered command to its OpenAPI path. Most
+// commands map 1:1 onto a path; "collection" is parameterized by its
+// "action" field so clients see /collection/{action} instead of a single
+// catch-all endpoint.
+var commandPaths = map[string]string{
+	"search":     "/search",
+	"vsearch":    "/vsearch",
+	"query":      "/query",
+	"hsearch":    "/hsearch",
+	"code":       "/code",
+	"get":        "/get",
+	"collection": "/collection/{action}",
+	"context":    "/context/{action}",
+	"embed":      "/embed",
+	"update":     "/update",
+	"status":     "/status",
+	"cleanup":    "/cleanup",
+	"agent":      "/agent",
+	"mcp":        "/mcp",
+	"migrate":    "/migrate",
+}
+
+// openAPICommands lists every command in a stable order so ExportOpenAPI
+// produces deterministic output.
+var openAPICommands = []string{
+	"search", "vsearch", "query", "hsearch", "code", "get", "collection", "context",
+	"embed", "update", "status", "cleanup", "agent", "mcp", "migrate",
+}
+
+// ExportOpenAPI walks every registered AnelSpec and emits an OpenAPI
+// 3.0 document describing the ANEL command surface as HTTP operations.
+// Each path's requestBody is derived from the command's InputSchema, its
+// 200 response from OutputSchema, and error responses from ErrorCodes via
+// a shared components.responses.Error. An x-anel-command extension is
+// attached to every operation so the document can be mapped back onto an
+// AnelSpec.
+func ExportOpenAPI() map[string]any {
+	paths := map[string]any{}
+
+	for _, command := range openAPICommands {
+		spec := GetSpecForCommand(command)
+		if spec == nil {
+			continue
+		}
+
+		path, ok := commandPaths[command]
+		if !ok {
+			path = "/" + command
+		}
+
+		var inputSchema any
+		_ = json.Unmarshal(spec.InputSchema, &inputSchema)
+		var outputSchema any
+		_ = json.Unmarshal(spec.OutputSchema, &outputSchema)
+
+		operation := map[string]any{
+			"operationId": command,
+			"summary":     "ANEL " + command + " command",
+			"requestBody": map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{
+						"schema": inputSchema,
+					},
+				},
+			},
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "Successful " + command + " response",
+					"content": map[string]any{
+						"application/json": map[string]any{
+							"schema": outputSchema,
+						},
+					},
+				},
+				"default": map[string]any{
+					"$ref": "#/components/responses/Error",
+				},
+			},
+			"x-anel-command": map[string]any{
+				"command":     spec.Command,
+				"version":     spec.Version,
+				"error_codes": spec.ErrorCodes,
+			},
+		}
+
+		paths[path] = map[string]any{
+			"post": operation,
+		}
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "QMD ANEL API",
+			"version": Version,
+		},
+		"paths":      paths,
+		"components": openAPIComponents(),
+	}
+}
+
+// openAPIComponents builds the shared components.responses.Error object,
+// whose schema is a oneOf over every error code registered across all
+// commands so any command's error payload validates against it.
+func openAPIComponents() map[string]any {
+	seen := map[ErrorCode]bool{}
+	var variants []any
+
+	for _, command := range openAPICommands {
+		spec := GetSpecForCommand(command)
+		if spec == nil {
+			continue
+		}
+		for _, code := range spec.ErrorCodes {
+			if seen[code] {
+				continue
+			}
+			seen[code] = true
+			variants = append(variants, map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"error_code": map[string]any{"type": "string", "enum": []string{string(code)}},
+					"status":     map[string]any{"type": "integer"},
+					"title":      map[string]any{"type": "string"},
+					"message":    map[string]any{"type": "string"},
+				},
+				"required": []string{"error_code", "status", "title", "message"},
+			})
+		}
+	}
+
+	return map[string]any{
+		"responses": map[string]any{
+			"Error": map[string]any{
+				"description": "An ANEL error response",
+				"content": map[string]any{
+					"application/json": map[string]any{
+						"schema": map[string]any{
+							"oneOf": variants,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package anel
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestExportOpenAPI_ValidDocument(t *testing.T) {
+	doc := ExportOpenAPI()
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("failed to marshal OpenAPI document: %v", err)
+	}
+
+	loader := openapi3.NewLoader()
+	spec, err := loader.LoadFromData(data)
+	if err != nil {
+		t.Fatalf("OpenAPI document failed to parse: %v", err)
+	}
+
+	if err := spec.Validate(loader.Context); err != nil {
+		t.Fatalf("OpenAPI document failed validation: %v", err)
+	}
+}
+
+func TestExportOpenAPI_OnePathPerCommand(t *testing.T) {
+	doc := ExportOpenAPI()
+	paths, ok := doc["paths"].(map[string]any)
+	if !ok {
+		t.Fatal("paths is not a map")
+	}
+
+	for _, path := range []string{"/search", "/vsearch", "/query", "/embed", "/get", "/collection/{action}"} {
+		if _, ok := paths[path]; !ok {
+			t.Errorf("expected path %q in OpenAPI document", path)
+		}
+	}
+}
+
+func TestExportOpenAPI_XAnelCommandExtension(t *testing.T) {
+	doc := ExportOpenAPI()
+	paths := doc["paths"].(map[string]any)
+
+	search := paths["/search"].(map[string]any)
+	post := search["post"].(map[string]any)
+	ext, ok := post["x-anel-command"].(map[string]any)
+	if !ok {
+		t.Fatal("expected x-anel-command extension on /search")
+	}
+	if ext["command"] != "search" {
+		t.Errorf("x-anel-command.command = %v, want search", ext["command"])
+	}
+}
@@ -0,0 +1,161 @@
+package anel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Error is a structured domain error for use in business logic, carrying
+// more than an AnelError's flat title/message: a minor sub-code for
+// disambiguating within an ErrorCode (e.g. "model not found because it
+// isn't downloaded" vs "...because the filename is wrong"), arbitrary
+// details, a remediation hint, and a wrapped cause. It converts to an
+// AnelError at the API/MCP boundary via ToAnelError.
+type Error struct {
+	Code    ErrorCode      `json:"code"`
+	Minor   string         `json:"minor,omitempty"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+	Hint    string         `json:"hint,omitempty"`
+	Cause   error          `json:"-"`
+}
+
+// Newf creates an Error with a formatted message.
+func Newf(code ErrorCode, minor, format string, args ...any) *Error {
+	return &Error{
+		Code:    code,
+		Minor:   minor,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// Wrap creates an Error that wraps cause, using cause's message as the
+// Error's message unless cause is nil.
+func Wrap(code ErrorCode, minor string, cause error) *Error {
+	e := &Error{Code: code, Minor: minor, Cause: cause}
+	if cause != nil {
+		e.Message = cause.Error()
+	}
+	return e
+}
+
+// WithDetail adds a detail key/value pair.
+func (e *Error) WithDetail(key string, value any) *Error {
+	if e.Details == nil {
+		e.Details = map[string]any{}
+	}
+	e.Details[key] = value
+	return e
+}
+
+// WithHint sets the remediation hint.
+func (e *Error) WithHint(hint string) *Error {
+	e.Hint = hint
+	return e
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	msg := e.Message
+	if e.Minor != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, e.Minor)
+	}
+	s := fmt.Sprintf("[%s] %s", e.Code, msg)
+	if e.Cause != nil {
+		s = fmt.Sprintf("%s: %s", s, e.Cause)
+	}
+	return s
+}
+
+// Unwrap returns the wrapped cause, enabling errors.Is/errors.As to see
+// through an Error to its underlying cause.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is an *Error with the same Code, and the
+// same Minor when target's Minor is non-empty. This lets callers write
+// errors.Is(err, &anel.Error{Code: anel.ErrorCodeModelNotFound}).
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	if e.Code != t.Code {
+		return false
+	}
+	if t.Minor != "" && t.Minor != e.Minor {
+		return false
+	}
+	return true
+}
+
+// Is reports whether err is, or wraps, an *Error with the given code.
+func Is(err error, code ErrorCode) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code == code
+	}
+	return false
+}
+
+// As reports whether err is, or wraps, an *Error, returning it.
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// ToAnelError converts an Error into the flatter AnelError shape used at
+// the API/MCP boundary, folding Minor/Details/Hint into Metadata and
+// RecoveryHints.
+func (e *Error) ToAnelError(traceID string) *AnelError {
+	aerr := NewAnelError(e.Code, titleForCode(e.Code), e.Message)
+	if e.Minor != "" {
+		aerr.WithMetadata("minor", e.Minor)
+	}
+	for k, v := range e.Details {
+		aerr.WithMetadata(k, v)
+	}
+	if e.Hint != "" {
+		aerr.WithHint(NewRecoveryHint(e.Minor, e.Hint))
+	}
+	if traceID != "" {
+		aerr.WithTraceID(traceID)
+	}
+	return aerr
+}
+
+// MarshalJSON renders Cause as a string so the wrapped error survives
+// serialization even though it isn't itself JSON-marshalable.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	type alias Error
+	out := struct {
+		*alias
+		Cause string `json:"cause,omitempty"`
+	}{alias: (*alias)(e)}
+	if e.Cause != nil {
+		out.Cause = e.Cause.Error()
+	}
+	return json.Marshal(out)
+}
+
+func titleForCode(code ErrorCode) string {
+	switch code {
+	case ErrorCodeEmbeddingFailed:
+		return "Embedding Failed"
+	case ErrorCodeModelNotFound:
+		return "Model Not Found"
+	case ErrorCodeModelLoadFailed:
+		return "Model Load Failed"
+	case ErrorCodeBackendUnavailable:
+		return "Backend Unavailable"
+	case ErrorCodeSearchFailed:
+		return "Search Failed"
+	case ErrorCodeQueryParseError:
+		return "Query Parse Error"
+	default:
+		return "Operation Failed"
+	}
+}
@@ -0,0 +1,85 @@
+package anel
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestError_Error(t *testing.T) {
+	err := Newf(ErrorCodeModelNotFound, "not_downloaded", "model %q is not available", "gemma")
+	want := `[MODEL_NOT_FOUND] model "gemma" is not available (not_downloaded)`
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestError_Wrap_PreservesCause(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := Wrap(ErrorCodeBackendUnavailable, "llama_server_unreachable", cause)
+
+	if !errors.Is(err, cause) {
+		t.Error("expected errors.Is to see through to the wrapped cause")
+	}
+	if err.Message != cause.Error() {
+		t.Errorf("Message = %q, want %q", err.Message, cause.Error())
+	}
+}
+
+func TestIs_MatchesCode(t *testing.T) {
+	err := Newf(ErrorCodeModelNotFound, "not_downloaded", "missing")
+	if !Is(err, ErrorCodeModelNotFound) {
+		t.Error("expected Is to match on code")
+	}
+	if Is(err, ErrorCodeStorageError) {
+		t.Error("expected Is not to match a different code")
+	}
+}
+
+func TestAs_ExtractsError(t *testing.T) {
+	inner := Newf(ErrorCodeEmbeddingFailed, "timeout", "embed request timed out")
+	wrapped := errors.Join(errors.New("context"), inner)
+
+	got, ok := As(wrapped)
+	if !ok {
+		t.Fatal("expected As to find the wrapped *Error")
+	}
+	if got.Code != ErrorCodeEmbeddingFailed || got.Minor != "timeout" {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestError_ToAnelError(t *testing.T) {
+	err := Newf(ErrorCodeEmbeddingFailed, "llama_server_unreachable", "could not reach llama-server").
+		WithDetail("url", "http://localhost:8080").
+		WithHint("start llama-server or configure a remote endpoint")
+
+	aerr := err.ToAnelError("trace-123")
+
+	if aerr.ErrorCode != ErrorCodeEmbeddingFailed {
+		t.Errorf("ErrorCode = %v, want %v", aerr.ErrorCode, ErrorCodeEmbeddingFailed)
+	}
+	if aerr.Metadata["minor"] != "llama_server_unreachable" {
+		t.Errorf("expected minor code in metadata, got %v", aerr.Metadata["minor"])
+	}
+	if aerr.Metadata["url"] != "http://localhost:8080" {
+		t.Errorf("expected url detail in metadata, got %v", aerr.Metadata["url"])
+	}
+	if len(aerr.RecoveryHints) != 1 || aerr.RecoveryHints[0].Message == "" {
+		t.Errorf("expected a recovery hint, got %v", aerr.RecoveryHints)
+	}
+	if aerr.TraceID == nil || *aerr.TraceID != "trace-123" {
+		t.Errorf("expected trace ID to be set, got %v", aerr.TraceID)
+	}
+}
+
+func TestFromError_UnwrapsRichError(t *testing.T) {
+	rich := Newf(ErrorCodeModelNotFound, "bad_filename", "model file missing")
+	aerr := FromError(rich)
+
+	if aerr.ErrorCode != ErrorCodeModelNotFound {
+		t.Errorf("ErrorCode = %v, want %v", aerr.ErrorCode, ErrorCodeModelNotFound)
+	}
+	if aerr.Metadata["minor"] != "bad_filename" {
+		t.Errorf("expected minor code preserved, got %v", aerr.Metadata["minor"])
+	}
+}
@@ -11,7 +11,13 @@ func SearchSpec() *AnelSpec {
 			"limit": {"type": "integer", "default": 20},
 			"min_score": {"type": "number", "default": 0.0},
 			"collection": {"type": "string"},
-			"all": {"type": "boolean", "default": false}
+			"all": {"type": "boolean", "default": false},
+			"no_expand": {"type": "boolean", "default": false},
+			"sort": {"type": "string", "description": "Comma-separated fields to sort by, e.g. '-score,collection,title'; a leading '-' sorts that field descending. Allowed fields: path, collection, score, lines, title, hash."},
+			"expand": {"type": "string", "enum": ["", "none", "synonyms", "llm", "all"], "description": "Query expansion strategy to use instead of the configured default."},
+			"expand_limit": {"type": "integer", "default": 0, "description": "Max expansion terms to OR into the query (0 uses the command's built-in default)."},
+			"expand_dry_run": {"type": "boolean", "default": false, "description": "Print the expanded query without executing the search."},
+			"analyze": {"type": "boolean", "default": false, "description": "Print the query's FTS5 token stream instead of searching, for debugging a collection's analyzer config."}
 		},
 		"required": ["query"]
 	}`)
@@ -31,7 +37,8 @@ func SearchSpec() *AnelSpec {
 					}
 				}
 			},
-			"total": {"type": "integer"}
+			"total": {"type": "integer"},
+			"expanded_terms": {"type": "array", "items": {"type": "string"}}
 		}
 	}`)
 
@@ -56,7 +63,8 @@ func VSearchSpec() *AnelSpec {
 			"query": {"type": "string"},
 			"limit": {"type": "integer", "default": 20},
 			"collection": {"type": "string"},
-			"all": {"type": "boolean", "default": false}
+			"all": {"type": "boolean", "default": false},
+			"sort": {"type": "string", "description": "Comma-separated fields to sort by, e.g. '-score,collection,title'; a leading '-' sorts that field descending. Allowed fields: path, collection, score, lines, title, hash."}
 		},
 		"required": ["query"]
 	}`)
@@ -102,7 +110,10 @@ func QuerySpec() *AnelSpec {
 			"query": {"type": "string"},
 			"limit": {"type": "integer", "default": 20},
 			"collection": {"type": "string"},
-			"all": {"type": "boolean", "default": false}
+			"all": {"type": "boolean", "default": false},
+			"sort": {"type": "string", "description": "Comma-separated fields to sort by, e.g. '-score,collection,title'; a leading '-' sorts that field descending. Allowed fields: path, collection, score, lines, title, hash."},
+			"fusion": {"type": "string", "enum": ["rrf", "weighted_sum", "zscore_sum", "convex", "borda"], "default": "rrf", "description": "Strategy fusing BM25 and vector result lists."},
+			"fusion_alpha": {"type": "number", "default": 0, "description": "Weight of BM25 vs. vector results for fusion=convex (0=pure vector, 1=pure BM25; 0 uses the collection's configured default)."}
 		},
 		"required": ["query"]
 	}`)
@@ -142,6 +153,142 @@ func QuerySpec() *AnelSpec {
 	}
 }
 
+// HSearchSpec returns the ANEL spec for the hsearch command
+func HSearchSpec() *AnelSpec {
+	inputSchema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"query": {"type": "string"},
+			"limit": {"type": "integer", "default": 20},
+			"collection": {"type": "string"},
+			"all": {"type": "boolean", "default": false},
+			"semantic_ratio": {"type": "number", "default": 0.5, "minimum": 0.0, "maximum": 1.0},
+			"k": {"type": "integer", "default": 60},
+			"no_expand": {"type": "boolean", "default": false},
+			"expand": {"type": "string", "enum": ["", "none", "synonyms", "llm", "all"], "description": "Query expansion strategy to use instead of the configured default."},
+			"expand_limit": {"type": "integer", "default": 0, "description": "Max expansion terms to OR into the query (0 uses the command's built-in default)."},
+			"expand_dry_run": {"type": "boolean", "default": false, "description": "Print the expanded query without executing the search."}
+		},
+		"required": ["query"]
+	}`)
+
+	outputSchema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"results": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {
+						"docid": {"type": "string"},
+						"path": {"type": "string"},
+						"score": {"type": "number"},
+						"lines": {"type": "integer"}
+					}
+				}
+			},
+			"total": {"type": "integer"},
+			"expanded_terms": {"type": "array", "items": {"type": "string"}}
+		}
+	}`)
+
+	return &AnelSpec{
+		Version:      Version,
+		Command:      "hsearch",
+		InputSchema:  inputSchema,
+		OutputSchema: outputSchema,
+		ErrorCodes: []ErrorCode{
+			ErrorCodeSearchFailed,
+			ErrorCodeIndexNotReady,
+			ErrorCodeEmbeddingFailed,
+			ErrorCodeModelNotFound,
+			ErrorCodeQueryParseError,
+		},
+	}
+}
+
+// CodeSpec returns the ANEL spec for the code command
+func CodeSpec() *AnelSpec {
+	inputSchema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"query": {"type": "string"},
+			"limit": {"type": "integer", "default": 20},
+			"collection": {"type": "string"},
+			"all": {"type": "boolean", "default": false},
+			"regex": {"type": "boolean", "default": false, "description": "Treat query as a regular expression instead of a literal substring."}
+		},
+		"required": ["query"]
+	}`)
+
+	outputSchema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"results": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {
+						"docid": {"type": "string"},
+						"path": {"type": "string"},
+						"score": {"type": "number"}
+					}
+				}
+			},
+			"total": {"type": "integer"}
+		}
+	}`)
+
+	return &AnelSpec{
+		Version:      Version,
+		Command:      "code",
+		InputSchema:  inputSchema,
+		OutputSchema: outputSchema,
+		ErrorCodes: []ErrorCode{
+			ErrorCodeSearchFailed,
+			ErrorCodeIndexNotReady,
+			ErrorCodeQueryParseError,
+		},
+	}
+}
+
+// MigrateSpec returns the ANEL spec for the migrate command
+func MigrateSpec() *AnelSpec {
+	inputSchema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"from": {"type": "string", "default": "sqlite"},
+			"to": {"type": "string", "default": "qdrant"},
+			"collection": {"type": "string"},
+			"batch_size": {"type": "integer", "default": 100},
+			"job": {"type": "string"},
+			"reembed": {"type": "boolean", "default": false, "description": "Re-embed points whose recorded model differs from the destination's configured model, instead of moving their vectors as-is."}
+		},
+		"required": ["collection"]
+	}`)
+
+	outputSchema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"moved": {"type": "integer"},
+			"reembedded": {"type": "integer"},
+			"dry_run": {"type": "boolean"}
+		}
+	}`)
+
+	return &AnelSpec{
+		Version:      Version,
+		Command:      "migrate",
+		InputSchema:  inputSchema,
+		OutputSchema: outputSchema,
+		ErrorCodes: []ErrorCode{
+			ErrorCodeInvalidInput,
+			ErrorCodeBackendUnavailable,
+			ErrorCodeStorageError,
+		},
+	}
+}
+
 // GetSpec returns the ANEL spec for the get command
 func GetSpec() *AnelSpec {
 	inputSchema := json.RawMessage(`{
@@ -394,6 +541,81 @@ func AgentSpec() *AnelSpec {
 	}
 }
 
+// ContextSpec returns the ANEL spec for the context command
+func ContextSpec() *AnelSpec {
+	inputSchema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"action": {"type": "string", "enum": ["create", "use", "show", "list", "rm", "export", "import"]},
+			"name": {"type": "string"},
+			"description": {"type": "string"},
+			"from": {"type": "string"},
+			"store": {"type": "string"},
+			"llm_url": {"type": "string"},
+			"embed_local": {"type": "string"},
+			"embed_remote": {"type": "string"}
+		}
+	}`)
+
+	outputSchema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"contexts": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"description": {"type": "string"},
+						"active": {"type": "boolean"}
+					}
+				}
+			},
+			"action": {"type": "string"}
+		}
+	}`)
+
+	return &AnelSpec{
+		Version:      Version,
+		Command:      "context",
+		InputSchema:  inputSchema,
+		OutputSchema: outputSchema,
+		ErrorCodes: []ErrorCode{
+			ErrorCodeNotFound,
+			ErrorCodeInvalidInput,
+		},
+	}
+}
+
+// McpSpec returns the ANEL spec for the mcp command
+func McpSpec() *AnelSpec {
+	inputSchema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"transport": {"type": "string", "default": "stdio", "enum": ["stdio", "sse"]},
+			"port": {"type": "integer", "default": 8080}
+		}
+	}`)
+
+	outputSchema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"transport": {"type": "string"},
+			"port": {"type": "integer"}
+		}
+	}`)
+
+	return &AnelSpec{
+		Version:      Version,
+		Command:      "mcp",
+		InputSchema:  inputSchema,
+		OutputSchema: outputSchema,
+		ErrorCodes: []ErrorCode{
+			ErrorCodeConfigError,
+		},
+	}
+}
+
 // GetSpecForCommand returns the spec for a specific command
 func GetSpecForCommand(command string) *AnelSpec {
 	switch command {
@@ -403,10 +625,16 @@ func GetSpecForCommand(command string) *AnelSpec {
 		return VSearchSpec()
 	case "query":
 		return QuerySpec()
+	case "hsearch":
+		return HSearchSpec()
+	case "code":
+		return CodeSpec()
 	case "get":
 		return GetSpec()
 	case "collection":
 		return CollectionSpec()
+	case "context":
+		return ContextSpec()
 	case "embed":
 		return EmbedSpec()
 	case "update":
@@ -417,6 +645,10 @@ func GetSpecForCommand(command string) *AnelSpec {
 		return CleanupSpec()
 	case "agent":
 		return AgentSpec()
+	case "mcp":
+		return McpSpec()
+	case "migrate":
+		return MigrateSpec()
 	default:
 		return nil
 	}
@@ -0,0 +1,306 @@
+package anel
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EnvOTLPEndpoint is the standard OTel env var naming the OTLP collector
+// qmd exports spans to. It isn't AGENT_-prefixed because it's an
+// OpenTelemetry convention, not an ANEL one.
+const EnvOTLPEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+// tracerName identifies qmd's spans in a multi-service trace.
+const tracerName = "github.com/qmd/qmd-go"
+
+// TraceContext for request correlation. It doubles as a W3C Trace
+// Context carrier: TraceID/SpanID/Sampled round-trip through the
+// traceparent header format, and TraceState carries the companion
+// tracestate header opaquely.
+type TraceContext struct {
+	TraceID       *string           `json:"trace_id,omitempty"`
+	SpanID        *string           `json:"span_id,omitempty"`
+	Sampled       bool              `json:"sampled"`
+	TraceState    *string           `json:"trace_state,omitempty"`
+	IdentityToken *string           `json:"identity_token,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+// NewTraceContext creates a new trace context from environment
+// variables. TRACEPARENT is tried first since it carries a real W3C
+// trace/span ID pair; AGENT_TRACE_ID is tried next, both as a
+// traceparent value in its own right (some agent harnesses set it to one
+// instead of a separate TRACEPARENT) and, failing that, as the legacy
+// opaque ID used when neither carries a parseable W3C header.
+// AGENT_IDENTITY_TOKEN and TRACESTATE are carried through unconditionally.
+func NewTraceContext() TraceContext {
+	ctx := TraceContext{
+		Sampled: true,
+		Tags:    map[string]string{},
+	}
+
+	if tp := os.Getenv(EnvTraceParent); tp != "" {
+		if traceID, spanID, sampled, ok := ParseTraceParent(tp); ok {
+			ctx.TraceID = &traceID
+			ctx.SpanID = &spanID
+			ctx.Sampled = sampled
+		}
+	}
+	if ctx.TraceID == nil {
+		if traceID := os.Getenv(EnvTraceID); traceID != "" {
+			if tid, sid, sampled, ok := ParseTraceParent(traceID); ok {
+				ctx.TraceID = &tid
+				ctx.SpanID = &sid
+				ctx.Sampled = sampled
+			} else {
+				ctx.TraceID = &traceID
+			}
+		}
+	}
+	if ts := os.Getenv(EnvTraceState); ts != "" {
+		ctx.TraceState = &ts
+	}
+	if identityToken := os.Getenv(EnvIdentityToken); identityToken != "" {
+		ctx.IdentityToken = &identityToken
+	}
+
+	return ctx
+}
+
+// GetOrGenerateTraceID returns the existing trace ID or mints a real
+// 16-byte W3C trace ID (32 hex characters) when none was carried in by
+// the environment.
+func (t *TraceContext) GetOrGenerateTraceID() string {
+	if t.TraceID != nil && *t.TraceID != "" {
+		return *t.TraceID
+	}
+	id := GenerateTraceID()
+	t.TraceID = &id
+	return id
+}
+
+// GenerateTraceID returns a random 16-byte W3C trace ID, hex-encoded.
+func GenerateTraceID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// GenerateSpanID returns a random 8-byte W3C span ID, hex-encoded.
+func GenerateSpanID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// ParseTraceParent parses a W3C "traceparent" header of the form
+// "version-trace_id-parent_id-trace_flags" (RFC: only version "00" is
+// understood; others are rejected rather than guessed at). It returns
+// ok=false if header is malformed.
+func ParseTraceParent(header string) (traceID, spanID string, sampled bool, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 {
+		return "", "", false, false
+	}
+	version, tid, sid, flags := parts[0], parts[1], parts[2], parts[3]
+	if version != "00" || len(tid) != 32 || len(sid) != 16 || len(flags) != 2 {
+		return "", "", false, false
+	}
+	if _, err := hex.DecodeString(tid); err != nil {
+		return "", "", false, false
+	}
+	if _, err := hex.DecodeString(sid); err != nil {
+		return "", "", false, false
+	}
+	flagsByte, err := hex.DecodeString(flags)
+	if err != nil {
+		return "", "", false, false
+	}
+	if strings.Count(tid, "0") == 32 || strings.Count(sid, "0") == 16 {
+		return "", "", false, false
+	}
+	return tid, sid, flagsByte[0]&0x01 == 1, true
+}
+
+// TraceParentHeader formats t as a W3C "traceparent" header value. It
+// returns "" if t has no TraceID, since there's nothing to propagate.
+func (t TraceContext) TraceParentHeader() string {
+	if t.TraceID == nil || *t.TraceID == "" {
+		return ""
+	}
+	spanID := "0000000000000000"
+	if t.SpanID != nil && *t.SpanID != "" {
+		spanID = *t.SpanID
+	}
+	flags := "00"
+	if t.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", *t.TraceID, spanID, flags)
+}
+
+// remoteSpanContext builds the otel trace.SpanContext a StartSpan call
+// should parent new spans under, when t carries a valid W3C trace/span
+// ID pair. ok is false when t has no usable trace ID, in which case the
+// caller should let otel mint a fresh root trace instead.
+func (t TraceContext) remoteSpanContext() (trace.SpanContext, bool) {
+	if t.TraceID == nil || *t.TraceID == "" {
+		return trace.SpanContext{}, false
+	}
+	traceID, err := trace.TraceIDFromHex(*t.TraceID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	spanID := trace.SpanID{}
+	if t.SpanID != nil && *t.SpanID != "" {
+		spanID, err = trace.SpanIDFromHex(*t.SpanID)
+		if err != nil {
+			return trace.SpanContext{}, false
+		}
+	}
+	flags := trace.TraceFlags(0)
+	if t.Sampled {
+		flags = trace.FlagsSampled
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+		Remote:     true,
+	}), true
+}
+
+// StartSpan starts a span named name, parented under t's W3C trace
+// context when it carries one (otherwise under whatever span is already
+// in ctx, or as a fresh root trace). Call sites that already have a ctx
+// carrying a span (the common case for nested calls within one process)
+// should prefer the package-level StartSpan, which doesn't risk
+// clobbering that span's parent.
+func (t TraceContext) StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		if sc, ok := t.remoteSpanContext(); ok {
+			ctx = trace.ContextWithRemoteSpanContext(ctx, sc)
+		}
+	}
+	ctx, span := Tracer().Start(ctx, name)
+	if len(attrs) > 0 {
+		span.SetAttributes(attrs...)
+	}
+	return ctx, span
+}
+
+// StartSpan starts a span named name using whatever trace context is
+// already live in ctx (a parent span, or none). It's the call used by
+// the hot paths that are always invoked from within an already-traced
+// CLI command, as opposed to TraceContext.StartSpan which also knows how
+// to seed the very first span of a process from an inbound traceparent.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := Tracer().Start(ctx, name)
+	if len(attrs) > 0 {
+		span.SetAttributes(attrs...)
+	}
+	return ctx, span
+}
+
+var (
+	tracerOnce sync.Once
+	tracer     trace.Tracer
+)
+
+// Tracer returns qmd's otel.Tracer, initializing the global
+// TracerProvider on first use if InitTracing hasn't already been called
+// (e.g. in tests or the embed example, which don't need OTLP export).
+func Tracer() trace.Tracer {
+	tracerOnce.Do(func() {
+		tracer = otel.Tracer(tracerName)
+	})
+	return tracer
+}
+
+// InitTracing installs the global otel TracerProvider: an OTLP/HTTP
+// batch exporter when EnvOTLPEndpoint is set, otherwise an in-memory
+// exporter whose recorded spans are retrievable via RecentSpans (handy
+// for --verbose output and tests; nothing is sent over the network).
+// The returned shutdown func flushes and stops the provider and should
+// be deferred by the caller.
+func InitTracing(ctx context.Context) (shutdown func(context.Context) error, err error) {
+	var exporter sdktrace.SpanExporter
+	if endpoint := os.Getenv(EnvOTLPEndpoint); endpoint != "" {
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+		if err != nil {
+			return nil, fmt.Errorf("anel: building OTLP exporter: %w", err)
+		}
+	} else {
+		mem := newMemoryExporter()
+		defaultMemoryExporterMu.Lock()
+		defaultMemoryExporter = mem
+		defaultMemoryExporterMu.Unlock()
+		exporter = mem
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(tp)
+	tracerOnce.Do(func() {
+		tracer = otel.Tracer(tracerName)
+	})
+
+	return tp.Shutdown, nil
+}
+
+// memoryExporter is a sdktrace.SpanExporter that keeps the most recent
+// spans in a ring buffer instead of sending them anywhere, used when no
+// OTLP collector is configured.
+type memoryExporter struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+const memoryExporterCapacity = 1000
+
+func newMemoryExporter() *memoryExporter {
+	return &memoryExporter{}
+}
+
+func (e *memoryExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	if overflow := len(e.spans) - memoryExporterCapacity; overflow > 0 {
+		e.spans = e.spans[overflow:]
+	}
+	return nil
+}
+
+func (e *memoryExporter) Shutdown(ctx context.Context) error { return nil }
+
+var (
+	defaultMemoryExporter   *memoryExporter
+	defaultMemoryExporterMu sync.Mutex
+)
+
+// RecentSpans returns the spans captured by the in-memory exporter
+// InitTracing installs when EnvOTLPEndpoint isn't set. It returns nil if
+// InitTracing hasn't run, or ran with an OTLP endpoint configured.
+func RecentSpans() []sdktrace.ReadOnlySpan {
+	defaultMemoryExporterMu.Lock()
+	defer defaultMemoryExporterMu.Unlock()
+	if defaultMemoryExporter == nil {
+		return nil
+	}
+	defaultMemoryExporter.mu.Lock()
+	defer defaultMemoryExporter.mu.Unlock()
+	out := make([]sdktrace.ReadOnlySpan, len(defaultMemoryExporter.spans))
+	copy(out, defaultMemoryExporter.spans)
+	return out
+}
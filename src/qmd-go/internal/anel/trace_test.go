@@ -0,0 +1,222 @@
+package anel
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewTraceContextEmpty(t *testing.T) {
+	os.Unsetenv(EnvTraceID)
+	os.Unsetenv(EnvIdentityToken)
+	os.Unsetenv(EnvTraceParent)
+	os.Unsetenv(EnvTraceState)
+
+	ctx := NewTraceContext()
+
+	if ctx.TraceID != nil {
+		t.Errorf("TraceID should be nil when env not set, got %v", ctx.TraceID)
+	}
+	if ctx.IdentityToken != nil {
+		t.Errorf("IdentityToken should be nil when env not set, got %v", ctx.IdentityToken)
+	}
+}
+
+func TestNewTraceContextFromEnv(t *testing.T) {
+	os.Setenv(EnvTraceID, "test-trace-id")
+	os.Setenv(EnvIdentityToken, "test-token")
+	defer os.Unsetenv(EnvTraceID)
+	defer os.Unsetenv(EnvIdentityToken)
+
+	ctx := NewTraceContext()
+
+	if ctx.TraceID == nil || *ctx.TraceID != "test-trace-id" {
+		t.Errorf("TraceID = %v, want test-trace-id", ctx.TraceID)
+	}
+	if ctx.IdentityToken == nil || *ctx.IdentityToken != "test-token" {
+		t.Errorf("IdentityToken = %v, want test-token", ctx.IdentityToken)
+	}
+}
+
+func TestNewTraceContextFromTraceParent(t *testing.T) {
+	os.Setenv(EnvTraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	os.Setenv(EnvTraceState, "vendor=value")
+	os.Unsetenv(EnvTraceID)
+	defer os.Unsetenv(EnvTraceParent)
+	defer os.Unsetenv(EnvTraceState)
+
+	ctx := NewTraceContext()
+
+	if ctx.TraceID == nil || *ctx.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("TraceID = %v, want 4bf92f3577b34da6a3ce929d0e0e4736", ctx.TraceID)
+	}
+	if ctx.SpanID == nil || *ctx.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("SpanID = %v, want 00f067aa0ba902b7", ctx.SpanID)
+	}
+	if !ctx.Sampled {
+		t.Error("Sampled should be true for flags 01")
+	}
+	if ctx.TraceState == nil || *ctx.TraceState != "vendor=value" {
+		t.Errorf("TraceState = %v, want vendor=value", ctx.TraceState)
+	}
+}
+
+func TestNewTraceContextParsesTraceParentFromAgentTraceID(t *testing.T) {
+	os.Unsetenv(EnvTraceParent)
+	os.Setenv(EnvTraceID, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	defer os.Unsetenv(EnvTraceID)
+
+	ctx := NewTraceContext()
+
+	if ctx.TraceID == nil || *ctx.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("TraceID = %v, want the traceparent's embedded trace ID", ctx.TraceID)
+	}
+	if ctx.SpanID == nil || *ctx.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("SpanID = %v, want the traceparent's embedded span ID", ctx.SpanID)
+	}
+}
+
+func TestNewTraceContextTraceParentTakesPriorityOverTraceID(t *testing.T) {
+	os.Setenv(EnvTraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	os.Setenv(EnvTraceID, "legacy-id")
+	defer os.Unsetenv(EnvTraceParent)
+	defer os.Unsetenv(EnvTraceID)
+
+	ctx := NewTraceContext()
+
+	if ctx.TraceID == nil || *ctx.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("TraceID = %v, want the traceparent trace ID, not the legacy one", ctx.TraceID)
+	}
+}
+
+func TestGetOrGenerateTraceID_Existing(t *testing.T) {
+	traceID := "existing-trace"
+	ctx := TraceContext{TraceID: &traceID}
+
+	got := ctx.GetOrGenerateTraceID()
+	if got != "existing-trace" {
+		t.Errorf("GetOrGenerateTraceID() = %s, want existing-trace", got)
+	}
+}
+
+func TestGetOrGenerateTraceID_Generated(t *testing.T) {
+	ctx := TraceContext{}
+
+	got := ctx.GetOrGenerateTraceID()
+	if len(got) != 32 {
+		t.Errorf("GetOrGenerateTraceID() = %s, want a 32-hex-char W3C trace ID", got)
+	}
+	if ctx.TraceID == nil || *ctx.TraceID != got {
+		t.Error("GetOrGenerateTraceID() should persist the generated ID onto the context")
+	}
+}
+
+func TestParseTraceParentValid(t *testing.T) {
+	traceID, spanID, sampled, ok := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if !ok {
+		t.Fatal("expected ok=true")
+	}
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("traceID = %s", traceID)
+	}
+	if spanID != "00f067aa0ba902b7" {
+		t.Errorf("spanID = %s", spanID)
+	}
+	if !sampled {
+		t.Error("sampled should be true")
+	}
+}
+
+func TestParseTraceParentInvalid(t *testing.T) {
+	cases := []string{
+		"",
+		"not-a-traceparent",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", // unsupported version
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01", // all-zero trace ID
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", // all-zero span ID
+		"00-tooshort-00f067aa0ba902b7-01",
+	}
+	for _, c := range cases {
+		if _, _, _, ok := ParseTraceParent(c); ok {
+			t.Errorf("ParseTraceParent(%q) should fail", c)
+		}
+	}
+}
+
+func TestTraceParentHeaderRoundTrip(t *testing.T) {
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	spanID := "00f067aa0ba902b7"
+	ctx := TraceContext{TraceID: &traceID, SpanID: &spanID, Sampled: true}
+
+	header := ctx.TraceParentHeader()
+
+	gotTrace, gotSpan, gotSampled, ok := ParseTraceParent(header)
+	if !ok {
+		t.Fatalf("ParseTraceParent(%q) failed to parse TraceParentHeader's own output", header)
+	}
+	if gotTrace != traceID || gotSpan != spanID || !gotSampled {
+		t.Errorf("round-trip = %s/%s/%v, want %s/%s/true", gotTrace, gotSpan, gotSampled, traceID, spanID)
+	}
+}
+
+func TestTraceParentHeaderEmpty(t *testing.T) {
+	ctx := TraceContext{}
+	if got := ctx.TraceParentHeader(); got != "" {
+		t.Errorf("TraceParentHeader() = %q, want empty string with no TraceID", got)
+	}
+}
+
+func TestStartSpanInheritsTraceParent(t *testing.T) {
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	spanID := "00f067aa0ba902b7"
+	ctx := TraceContext{TraceID: &traceID, SpanID: &spanID, Sampled: true}
+
+	goCtx, span := ctx.StartSpan(context.Background(), "test.span")
+	defer span.End()
+
+	sc := trace.SpanContextFromContext(goCtx)
+	if !sc.IsValid() {
+		t.Fatal("expected a valid span context")
+	}
+	if sc.TraceID().String() != traceID {
+		t.Errorf("TraceID = %s, want %s", sc.TraceID().String(), traceID)
+	}
+}
+
+func TestStartSpanWithoutTraceParentMintsRoot(t *testing.T) {
+	ctx := TraceContext{}
+
+	goCtx, span := ctx.StartSpan(context.Background(), "test.span")
+	defer span.End()
+
+	if !trace.SpanContextFromContext(goCtx).IsValid() {
+		t.Fatal("expected StartSpan to mint a valid root span even with no parent trace context")
+	}
+}
+
+func TestPackageStartSpanNestsUnderExistingSpan(t *testing.T) {
+	ctx := TraceContext{}
+	parentCtx, parentSpan := ctx.StartSpan(context.Background(), "parent")
+	defer parentSpan.End()
+
+	childCtx, childSpan := StartSpan(parentCtx, "child")
+	defer childSpan.End()
+
+	parentSC := trace.SpanContextFromContext(parentCtx)
+	childSC := trace.SpanContextFromContext(childCtx)
+	if childSC.TraceID() != parentSC.TraceID() {
+		t.Error("child span should share its parent's trace ID")
+	}
+}
+
+func TestGenerateIDsAreWellFormed(t *testing.T) {
+	if tid := GenerateTraceID(); len(tid) != 32 || strings.ContainsAny(tid, "-_ ") {
+		t.Errorf("GenerateTraceID() = %s, want 32 lowercase hex chars", tid)
+	}
+	if sid := GenerateSpanID(); len(sid) != 16 || strings.ContainsAny(sid, "-_ ") {
+		t.Errorf("GenerateSpanID() = %s, want 16 lowercase hex chars", sid)
+	}
+}
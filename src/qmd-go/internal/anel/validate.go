@@ -0,0 +1,147 @@
+package anel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// ValidationError describes a single JSON Schema validation failure,
+// pinpointing where in the document it occurred.
+type ValidationError struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
+
+func (v ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", v.Path, v.Message, v.Keyword)
+}
+
+// Validator compiles and caches the input/output JSON schemas for every
+// command registered via GetSpecForCommand, so CLI and transport layers
+// can validate payloads without recompiling a schema on every call.
+type Validator struct {
+	mu      sync.RWMutex
+	inputs  map[string]*jsonschema.Schema
+	outputs map[string]*jsonschema.Schema
+}
+
+// NewValidator compiles the input/output schema for every registered
+// command. Commands whose schema fails to compile are silently skipped
+// so one malformed spec can't break validation for the rest.
+func NewValidator() *Validator {
+	v := &Validator{
+		inputs:  map[string]*jsonschema.Schema{},
+		outputs: map[string]*jsonschema.Schema{},
+	}
+	for _, command := range openAPICommands {
+		spec := GetSpecForCommand(command)
+		if spec == nil {
+			continue
+		}
+		if s, err := compileSchema(schemaResourceName(command, "input"), spec.InputSchema); err == nil {
+			v.inputs[command] = s
+		}
+		if s, err := compileSchema(schemaResourceName(command, "output"), spec.OutputSchema); err == nil {
+			v.outputs[command] = s
+		}
+	}
+	return v
+}
+
+// schemaResourceName builds the resource URI compileSchema registers
+// command's input/output schema under. It must look like a real base
+// URI with no "#" in it -- jsonschema.Compiler.AddResource's underlying
+// resource parser panics (rather than returning an error) on a "#", so
+// the old "command#input"-style name crashed the package's init-time
+// defaultValidator before main() ever ran.
+func schemaResourceName(command, kind string) string {
+	return "https://anel.internal/schemas/" + command + "/" + kind
+}
+
+func compileSchema(name string, raw json.RawMessage) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(name, bytes.NewReader(raw)); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(name)
+}
+
+// defaultValidator is shared by the package-level ValidateInput/ValidateOutput
+// helpers, compiled once at init from the specs registered at that time.
+var defaultValidator = NewValidator()
+
+// ValidateInput validates raw JSON against the command's InputSchema using
+// the package's default, pre-compiled Validator.
+func ValidateInput(command string, raw []byte) error {
+	return defaultValidator.ValidateInput(command, raw)
+}
+
+// ValidateOutput validates raw JSON against the command's OutputSchema
+// using the package's default, pre-compiled Validator.
+func ValidateOutput(command string, raw []byte) error {
+	return defaultValidator.ValidateOutput(command, raw)
+}
+
+// ValidateInput validates raw JSON against command's compiled InputSchema.
+// Commands with no registered schema are treated as always valid.
+func (v *Validator) ValidateInput(command string, raw []byte) error {
+	v.mu.RLock()
+	schema, ok := v.inputs[command]
+	v.mu.RUnlock()
+	return validate(command, schema, ok, raw)
+}
+
+// ValidateOutput validates raw JSON against command's compiled OutputSchema.
+func (v *Validator) ValidateOutput(command string, raw []byte) error {
+	v.mu.RLock()
+	schema, ok := v.outputs[command]
+	v.mu.RUnlock()
+	return validate(command, schema, ok, raw)
+}
+
+func validate(command string, schema *jsonschema.Schema, ok bool, raw []byte) error {
+	if !ok {
+		return nil
+	}
+
+	var data any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return NewAnelError(ErrorCodeInvalidInput, "Invalid JSON", err.Error()).WithMetadata("command", command)
+	}
+
+	if err := schema.Validate(data); err != nil {
+		if ve, ok := err.(*jsonschema.ValidationError); ok {
+			return validationErrorToAnelError(command, ve)
+		}
+		return NewAnelError(ErrorCodeInvalidInput, "Schema Validation Failed", err.Error()).WithMetadata("command", command)
+	}
+
+	return nil
+}
+
+// validationErrorToAnelError walks to the most specific (leaf) cause of a
+// jsonschema.ValidationError so the resulting AnelError points at exactly
+// what failed rather than the root "doesn't validate" summary.
+func validationErrorToAnelError(command string, ve *jsonschema.ValidationError) *AnelError {
+	leaf := ve
+	for len(leaf.Causes) > 0 {
+		leaf = leaf.Causes[0]
+	}
+
+	detail := ValidationError{
+		Path:    leaf.InstanceLocation,
+		Keyword: leaf.KeywordLocation,
+		Message: leaf.Message,
+	}
+
+	anelErr := NewAnelError(ErrorCodeInvalidInput, "Schema Validation Failed", detail.Error())
+	anelErr.WithMetadata("command", command)
+	anelErr.WithMetadata("path", detail.Path)
+	anelErr.WithMetadata("keyword", detail.Keyword)
+	return anelErr
+}
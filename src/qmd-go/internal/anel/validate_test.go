@@ -0,0 +1,67 @@
+package anel
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateInput_Valid(t *testing.T) {
+	raw := []byte(`{"query": "hello", "limit": 10}`)
+	if err := ValidateInput("search", raw); err != nil {
+		t.Errorf("expected valid input, got error: %v", err)
+	}
+}
+
+func TestValidateInput_MissingRequired(t *testing.T) {
+	raw := []byte(`{"limit": 10}`)
+	err := ValidateInput("search", raw)
+	if err == nil {
+		t.Fatal("expected error for missing required 'query'")
+	}
+	anelErr, ok := err.(*AnelError)
+	if !ok {
+		t.Fatalf("expected *AnelError, got %T", err)
+	}
+	if anelErr.ErrorCode != ErrorCodeInvalidInput {
+		t.Errorf("ErrorCode = %s, want INVALID_INPUT", anelErr.ErrorCode)
+	}
+}
+
+func TestValidateInput_WrongType(t *testing.T) {
+	raw := []byte(`{"query": 123}`)
+	if err := ValidateInput("search", raw); err == nil {
+		t.Fatal("expected error for query of wrong type")
+	}
+}
+
+func TestValidateInput_CollectionActionEnum(t *testing.T) {
+	raw := []byte(`{"action": "destroy"}`)
+	if err := ValidateInput("collection", raw); err == nil {
+		t.Fatal("expected error for action not in enum")
+	}
+
+	raw = []byte(`{"action": "list"}`)
+	if err := ValidateInput("collection", raw); err != nil {
+		t.Errorf("expected valid action, got error: %v", err)
+	}
+}
+
+func TestValidateInput_UnknownCommand(t *testing.T) {
+	if err := ValidateInput("nonexistent", []byte(`{}`)); err != nil {
+		t.Errorf("unknown command should not fail validation, got: %v", err)
+	}
+}
+
+func TestValidateOutput_Valid(t *testing.T) {
+	raw := []byte(`{"results": [], "total": 0}`)
+	if err := ValidateOutput("search", raw); err != nil {
+		t.Errorf("expected valid output, got error: %v", err)
+	}
+}
+
+func TestValidationError_Error(t *testing.T) {
+	ve := ValidationError{Path: "/query", Keyword: "#/required", Message: "missing property"}
+	if !strings.Contains(ve.Error(), "missing property") {
+		t.Errorf("Error() = %q, expected to contain message", ve.Error())
+	}
+}
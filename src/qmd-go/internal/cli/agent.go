@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/qmd/qmd-go/internal/router"
+	"github.com/qmd/qmd-go/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var agentCmd = &cobra.Command{
+	Use:   "agent [query]",
+	Short: "LLM-routed search: classify intent, expand into variants, fuse and optionally rerank",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runAgent,
+}
+
+func runAgent(cmd *cobra.Command, args []string) {
+	collection, _ := cmd.Flags().GetString("collection")
+	all, _ := cmd.Flags().GetBool("all")
+	interactive, _ := cmd.Flags().GetBool("interactive")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	rerankFlag, _ := cmd.Flags().GetBool("rerank")
+	noRerankFlag, _ := cmd.Flags().GetBool("no-rerank")
+
+	var query string
+	if len(args) > 0 {
+		query = args[0]
+	}
+
+	if err := validateStrictInput("agent", map[string]any{
+		"interactive": interactive, "query": query,
+	}); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: %v\n", err)
+		return
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY-RUN] Would route query: %s\n", query)
+		fmt.Printf("[DRY-RUN] Collection: %s, SearchAll: %v, Interactive: %v\n", collection, all, interactive)
+		return
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error loading config", err, outputFormat)
+		return
+	}
+
+	s, err := store.New(cfg)
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error loading store", err, outputFormat)
+		return
+	}
+
+	r := router.New(cfg, s)
+	defer r.Close()
+
+	opts := router.Options{
+		SearchOptions: store.SearchOptions{
+			Limit:      limit,
+			Collection: collection,
+			SearchAll:  all,
+		},
+	}
+	switch {
+	case rerankFlag:
+		rerank := true
+		opts.Rerank = &rerank
+	case noRerankFlag:
+		rerank := false
+		opts.Rerank = &rerank
+	}
+
+	if interactive {
+		runAgentREPL(cmd, r, opts, verbose)
+		return
+	}
+
+	if query == "" {
+		fmt.Fprintln(cmd.OutOrStderr(), "Error: a query is required unless --interactive is set")
+		return
+	}
+
+	runAgentQuery(cmd, r, query, opts, verbose)
+}
+
+// runAgentQuery routes a single query and prints its results, plus the
+// routing Decision under --verbose.
+func runAgentQuery(cmd *cobra.Command, r *router.Router, query string, opts router.Options, verbose bool) {
+	result, err := r.Route(cmd.Context(), query, opts)
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error routing query", err, outputFormat)
+		return
+	}
+
+	if verbose {
+		printDecision(cmd, result.Decision)
+	}
+
+	if err := validateStrictOutput("agent", map[string]any{
+		"intent": string(result.Decision.Intent), "results": result.Results, "mode": "search",
+	}); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: invalid output: %v\n", err)
+		return
+	}
+
+	printResults(result.Results, outputFormat)
+}
+
+// runAgentREPL is the old `qmd agent` interactive loop, updated to run
+// every line through router.Router.Route instead of the retired
+// classifyIntent keyword heuristic. "/bm25", "/vector", "/hybrid", and
+// "/code" still force a retriever for one line by feeding it straight to
+// the matching Store method, bypassing classification entirely.
+func runAgentREPL(cmd *cobra.Command, r *router.Router, opts router.Options, verbose bool) {
+	fmt.Println("QMD Agent - Interactive Search")
+	fmt.Println("Type 'quit' or 'exit' to exit")
+	fmt.Println("Type '/bm25', '/vector', '/hybrid', or '/code' to force a retriever")
+	fmt.Println()
+
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		fmt.Print("> ")
+		line, _ := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			continue
+		}
+		if line == "quit" || line == "exit" {
+			break
+		}
+
+		if forced, rest, ok := strings.Cut(line, " "); ok {
+			if results, handled := runForcedRetriever(cmd, r, forced, rest, opts); handled {
+				printResults(results, outputFormat)
+				continue
+			}
+		}
+
+		runAgentQuery(cmd, r, line, opts, verbose)
+	}
+
+	fmt.Println("Goodbye!")
+}
+
+// runForcedRetriever handles the REPL's "/bm25 ", "/vector ", "/hybrid
+// ", and "/code " prefixes, going straight to the named Store method
+// instead of router.Router.Route. handled is false for anything else,
+// so the caller falls through to normal routing.
+func runForcedRetriever(cmd *cobra.Command, r *router.Router, prefix, query string, opts router.Options) ([]store.SearchResult, bool) {
+	s := r.Store()
+	switch prefix {
+	case "/bm25":
+		results, _ := s.BM25Search(cmd.Context(), query, opts.SearchOptions)
+		return results, true
+	case "/vector":
+		results, _ := s.VectorSearch(cmd.Context(), query, opts.SearchOptions)
+		return results, true
+	case "/hybrid":
+		results, _ := s.HybridSearch(cmd.Context(), query, opts.SearchOptions)
+		return results, true
+	case "/code":
+		results, _ := s.CodeSearch(cmd.Context(), query, opts.SearchOptions, false)
+		return results, true
+	default:
+		return nil, false
+	}
+}
+
+// printDecision prints the routing Decision's intent, variants, entities,
+// and whether reranking ran, for the --verbose flag.
+func printDecision(cmd *cobra.Command, decision router.Decision) {
+	w := cmd.OutOrStdout()
+	source := "model"
+	if decision.Cached {
+		source = "cache"
+	}
+	fmt.Fprintf(w, "[route] intent=%s (%s) variants=%v\n", decision.Intent, source, decision.Variants)
+	if len(decision.Entities) > 0 {
+		fmt.Fprintf(w, "[route] entities=%v\n", decision.Entities)
+	}
+	fmt.Fprintf(w, "[route] reranked=%v\n", decision.Reranked)
+}
+
+func init() {
+	agentCmd.Flags().StringP("collection", "c", "", "Collection name")
+	agentCmd.Flags().Bool("all", false, "Search all collections")
+	agentCmd.Flags().BoolP("interactive", "i", false, "Run in interactive REPL mode")
+	agentCmd.Flags().BoolP("verbose", "v", false, "Print the routing decision (intent, variants, entities, backend)")
+	agentCmd.Flags().Bool("rerank", false, "Force reranking the fused results with models.rerank")
+	agentCmd.Flags().Bool("no-rerank", false, "Force skipping reranking, even if router.rerank is configured")
+}
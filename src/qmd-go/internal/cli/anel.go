@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/qmd/qmd-go/internal/anel"
+	"github.com/spf13/cobra"
+)
+
+var anelCmd = &cobra.Command{
+	Use:   "anel",
+	Short: "ANEL protocol utilities",
+}
+
+var anelOpenAPICmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Export an OpenAPI document describing every ANEL command",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		doc := anel.ExportOpenAPI()
+
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal OpenAPI document: %w", err)
+		}
+
+		fmt.Println(string(data))
+		return nil
+	},
+}
+
+func init() {
+	anelCmd.AddCommand(anelOpenAPICmd)
+	RootCmd.AddCommand(anelCmd)
+}
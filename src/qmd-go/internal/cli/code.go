@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/qmd/qmd-go/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var codeCmd = &cobra.Command{
+	Use:   "code <query>",
+	Short: "Literal or regex substring search over code and identifiers, via the trigram index",
+	Args:  cobra.ExactArgs(1),
+	Run:   runCode,
+}
+
+func runCode(cmd *cobra.Command, args []string) {
+	query := args[0]
+
+	collection, _ := cmd.Flags().GetString("collection")
+	all, _ := cmd.Flags().GetBool("all")
+	regex, _ := cmd.Flags().GetBool("regex")
+
+	if dryRun {
+		fmt.Printf("[DRY-RUN] Would execute code search for query: %s\n", query)
+		fmt.Printf("[DRY-RUN] Limit: %d, Regex: %v\n", limit, regex)
+		fmt.Printf("[DRY-RUN] Collection: %s, SearchAll: %v\n", collection, all)
+		return
+	}
+
+	if err := validateStrictInput("code", map[string]any{
+		"query": query, "limit": limit, "collection": collection, "all": all, "regex": regex,
+	}); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: %v\n", err)
+		return
+	}
+
+	s, err := LoadStore()
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error loading store", err, outputFormat)
+		return
+	}
+
+	results, err := s.CodeSearch(cmd.Context(), query, store.SearchOptions{
+		Limit:      limit,
+		Collection: collection,
+		SearchAll:  all,
+	}, regex)
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error searching", err, outputFormat)
+		return
+	}
+
+	if err := validateStrictOutput("code", map[string]any{"results": results, "total": len(results)}); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: invalid output: %v\n", err)
+		return
+	}
+
+	printResults(results, outputFormat)
+}
+
+func init() {
+	codeCmd.Flags().StringP("collection", "c", "", "Collection name")
+	codeCmd.Flags().Bool("all", false, "Search all collections")
+	codeCmd.Flags().Bool("regex", false, "Treat query as a regular expression instead of a literal substring")
+}
@@ -43,22 +43,18 @@ func runCollectionAdd(cmd *cobra.Command, args []string) {
 		name = filepath.Base(path)
 	}
 
-	cfg, err := LoadConfig()
+	client, err := LoadClient()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStderr(), "Error loading config: %v\n", err)
 		return
 	}
 
-	// Add collection to config
-	cfg.Collections = append(cfg.Collections, config.CollectionConfig{
+	if err := client.AddCollection(config.CollectionConfig{
 		Name:        name,
 		Path:        path,
 		Pattern:     &mask,
 		Description: &description,
-	})
-
-	// Save config
-	if err := cfg.Save(); err != nil {
+	}); err != nil {
 		fmt.Fprintf(cmd.OutOrStderr(), "Error saving config: %v\n", err)
 		return
 	}
@@ -1,14 +1,24 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/qmd/qmd-go/internal/anel"
 	"github.com/qmd/qmd-go/internal/config"
+	"github.com/qmd/qmd-go/internal/qmdcontext"
 	"github.com/qmd/qmd-go/internal/store"
+	"github.com/qmd/qmd-go/pkg/qmd"
 	"github.com/spf13/cobra"
 )
 
@@ -27,33 +37,79 @@ var RootCmd = &cobra.Command{
 				os.Exit(0)
 			}
 		}
+
+		// Every command gets a span, parented under an inbound
+		// TRACEPARENT/AGENT_TRACE_ID when present so a caller's trace
+		// correlates with this invocation's.
+		traceCtx := anel.NewTraceContext()
+		ctx, _ := traceCtx.StartSpan(cmd.Context(), "qmd."+cmd.Name(), attribute.String("qmd.command", cmd.Name()))
+
+		ctx, cancel := contextWithDeadline(ctx)
+		cancelDeadline = cancel
+		cmd.SetContext(ctx)
+		return nil
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		cancelDeadline()
+		trace.SpanFromContext(cmd.Context()).End()
 		return nil
 	},
 }
 
 // Global options
 var (
-	configPath  string
-	outputFormat string
-	limit       int
-	ftsBackend  string
+	configPath    string
+	outputFormat  string
+	limit         int
+	ftsBackend    string
 	vectorBackend string
+	contextName   string
+	profileName   string
+	timeoutFlag   time.Duration
+	deadlineFlag  string
 )
 
+// cancelDeadline releases the context.WithTimeout/WithDeadline
+// contextWithDeadline wrapped cmd.Context() in, set by
+// RootCmd.PersistentPreRunE and called by RootCmd.PersistentPostRunE
+// once the command has returned. A package-level var is safe here only
+// because this binary runs one command per process.
+var cancelDeadline context.CancelFunc = func() {}
+
+// contextWithDeadline applies --deadline (an absolute RFC3339 timestamp)
+// or, failing that, --timeout (a duration from now) on top of ctx, so a
+// store call that runs past it fails with an
+// anel.DeadlineExceededError-shaped error instead of hanging
+// indefinitely. --deadline takes precedence when both are set, since it
+// names a specific point in time rather than a budget relative to when
+// the command happened to start. Neither flag set returns ctx unchanged.
+func contextWithDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadlineFlag != "" {
+		if t, err := time.Parse(time.RFC3339, deadlineFlag); err == nil {
+			return context.WithDeadline(ctx, t)
+		}
+	}
+	if timeoutFlag > 0 {
+		return context.WithTimeout(ctx, timeoutFlag)
+	}
+	return ctx, func() {}
+}
+
 // ANEL global options
 var (
-	emitSpec  bool
-	dryRun    bool
+	emitSpec bool
+	dryRun   bool
+	strict   bool
 )
 
 // Search options
 type SearchOptions struct {
-	Limit        int
-	MinScore     float32
-	Collection   string
-	SearchAll    bool
-	Format       string
-	FTSBackend   string
+	Limit         int
+	MinScore      float32
+	Collection    string
+	SearchAll     bool
+	Format        string
+	FTSBackend    string
 	VectorBackend string
 }
 
@@ -61,15 +117,20 @@ var searchOpts SearchOptions
 
 func init() {
 	// Global flags
-	RootCmd.PersistentFlags().StringVarP(&outputFormat, "format", "f", "cli", "Output format: cli, json, md, csv, files")
+	RootCmd.PersistentFlags().StringVarP(&outputFormat, "format", "f", "cli", "Output format: cli, json, ndjson, md, csv, files")
 	RootCmd.PersistentFlags().IntVarP(&limit, "limit", "n", 20, "Max results")
-	RootCmd.PersistentFlags().StringVar(&ftsBackend, "fts-backend", "sqlite_fts5", "BM25 backend: sqlite_fts5, lancedb")
-	RootCmd.PersistentFlags().StringVar(&vectorBackend, "vector-backend", "qmd_builtin", "Vector backend: qmd_builtin, lancedb, qdrant")
+	RootCmd.PersistentFlags().StringVar(&ftsBackend, "fts-backend", "", "BM25 backend override: sqlite_fts5, bleve, elasticsearch, meilisearch, lancedb (default: config file's bm25.backend)")
+	RootCmd.PersistentFlags().StringVar(&vectorBackend, "vector-backend", "", "Vector backend override: qmd_builtin, qdrant, milvus, lancedb (default: config file's vector.backend)")
 	RootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file path")
+	RootCmd.PersistentFlags().StringVar(&contextName, "context", "", "Context to use (overrides QMD_CONTEXT and the active context)")
+	RootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Config profile to use (overrides QMD_PROFILE and the active profile)")
+	RootCmd.PersistentFlags().DurationVar(&timeoutFlag, "timeout", 0, "Abort the command if it doesn't finish within this duration (e.g. 30s, 2m)")
+	RootCmd.PersistentFlags().StringVar(&deadlineFlag, "deadline", "", "Abort the command if it doesn't finish by this RFC3339 time (overrides --timeout)")
 
 	// ANEL flags
 	RootCmd.PersistentFlags().BoolVar(&emitSpec, "emit-spec", false, "Output JSON Schema and exit")
 	RootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Validate parameters but don't execute")
+	RootCmd.PersistentFlags().BoolVar(&strict, "strict", false, "Validate command input/output against its ANEL JSON schema")
 
 	// Check environment variables for ANEL overrides
 	if os.Getenv(anel.EnvEmitSpec) != "" {
@@ -86,15 +147,23 @@ func init() {
 	RootCmd.AddCommand(searchCmd)
 	RootCmd.AddCommand(vsearchCmd)
 	RootCmd.AddCommand(queryCmd)
+	RootCmd.AddCommand(hsearchCmd)
+	RootCmd.AddCommand(codeCmd)
 	RootCmd.AddCommand(embedCmd)
 	RootCmd.AddCommand(updateCmd)
 	RootCmd.AddCommand(statusCmd)
 	RootCmd.AddCommand(cleanupCmd)
 	RootCmd.AddCommand(mcpCmd)
+	RootCmd.AddCommand(migrateCmd)
+	RootCmd.AddCommand(agentCmd)
+	RootCmd.AddCommand(tagCmd)
 }
 
-// LoadConfig loads configuration
-func LoadConfig() (*config.Config, error) {
+// loadRawConfig loads configuration straight off disk, with neither a
+// profile nor a context overlay applied -- the form profile-management
+// commands (qmd config profile use/save/delete) need so editing and
+// saving it back doesn't bake a resolved overlay into the root config.
+func loadRawConfig() (*config.Config, error) {
 	cfgPath := configPath
 	if cfgPath == "" {
 		cfgPath = expandPath("~/.config/qmd/index.yaml")
@@ -103,15 +172,82 @@ func LoadConfig() (*config.Config, error) {
 	if _, err := os.Stat(cfgPath); os.IsNotExist(err) {
 		return config.DefaultConfig(), nil
 	}
-
 	data, err := os.ReadFile(cfgPath)
 	if err != nil {
 		return nil, err
 	}
-
 	return config.LoadConfigFromData(data)
 }
 
+// LoadConfig loads configuration, resolves the selected profile (see
+// config.Config.ResolveProfile) on top of it, and overlays the active
+// context's endpoints (store path, and embed/rerank model selection).
+func LoadConfig() (*config.Config, error) {
+	cfg, err := loadRawConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg = cfg.ResolveProfile(profileName)
+
+	applyActiveContext(cfg)
+
+	// --fts-backend/--vector-backend override whatever the config file
+	// (or context) selected, resolved later through indexer.Registry by
+	// store.Store.
+	if ftsBackend != "" {
+		cfg.BM25.Backend = config.BM25Backend(ftsBackend)
+	}
+	if vectorBackend != "" {
+		cfg.Vector.Backend = config.VectorBackend(vectorBackend)
+	}
+
+	return cfg, nil
+}
+
+// applyActiveContext overlays the active context's endpoints onto cfg.
+// --context takes precedence over QMD_CONTEXT, which takes precedence over
+// the persisted "current" context. Unset endpoint fields leave cfg
+// untouched so a context only needs to declare what it overrides.
+func applyActiveContext(cfg *config.Config) {
+	var ctx *qmdcontext.Context
+	var err error
+	if contextName != "" {
+		ctx, err = qmdcontext.Load(contextName)
+	} else {
+		ctx, err = qmdcontext.CurrentContext()
+	}
+	if err != nil || ctx == nil {
+		return
+	}
+
+	if ctx.Endpoints.Store != "" {
+		cfg.CachePath = ctx.Endpoints.Store
+	}
+	if ctx.Endpoints.LLM.BaseURL != "" {
+		os.Setenv("OPENAI_BASE_URL", ctx.Endpoints.LLM.BaseURL)
+	}
+	if ctx.Endpoints.Embed.Local != "" || ctx.Endpoints.Embed.Remote != "" {
+		embed := &config.LLMModelConfig{}
+		if ctx.Endpoints.Embed.Local != "" {
+			embed.Local = &ctx.Endpoints.Embed.Local
+		}
+		if ctx.Endpoints.Embed.Remote != "" {
+			embed.Remote = &ctx.Endpoints.Embed.Remote
+		}
+		cfg.Models.Embed = embed
+	}
+	if ctx.Endpoints.Rerank.Local != "" || ctx.Endpoints.Rerank.Remote != "" {
+		rerank := &config.LLMModelConfig{}
+		if ctx.Endpoints.Rerank.Local != "" {
+			rerank.Local = &ctx.Endpoints.Rerank.Local
+		}
+		if ctx.Endpoints.Rerank.Remote != "" {
+			rerank.Remote = &ctx.Endpoints.Rerank.Remote
+		}
+		cfg.Models.Rerank = rerank
+	}
+}
+
 // LoadStore loads the store
 func LoadStore() (*store.Store, error) {
 	cfg, err := LoadConfig()
@@ -122,6 +258,18 @@ func LoadStore() (*store.Store, error) {
 	return store.New(cfg)
 }
 
+// LoadClient loads configuration and builds a pkg/qmd.Client from it --
+// the entry point commands use to reach the reusable API surface in
+// pkg/qmd, instead of constructing a store.Store directly, for anything
+// the Client already exposes.
+func LoadClient() (*qmd.Client, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return qmd.NewClient(cfg)
+}
+
 func expandPath(path string) string {
 	if home, err := os.UserHomeDir(); err == nil {
 		if len(path) > 1 && path[:2] == "~/" {
@@ -133,23 +281,130 @@ func expandPath(path string) string {
 
 // printResults prints search results in the specified format
 func printResults(results []store.SearchResult, format string) {
+	fprintResultsHeader(os.Stdout, format)
+	for _, r := range results {
+		printResult(r, format)
+	}
+}
+
+// printResult prints a single search result in the specified format. It's
+// the unit printResults loops over, and what streamed results (--stream,
+// or --format=ndjson/files) print one at a time as they arrive rather
+// than waiting for a full slice.
+func printResult(r store.SearchResult, format string) {
+	fprintResult(os.Stdout, r, format)
+}
+
+// fprintResultsHeader writes format's header row, if it has one (only
+// "csv" does); called once before a result set, never per-result, so a
+// stream of results doesn't repeat it.
+func fprintResultsHeader(w io.Writer, format string) {
+	if format == "csv" {
+		fmt.Fprintln(w, "path,collection,score,lines,title")
+	}
+}
+
+// fprintResult writes a single search result to w in the specified
+// format -- the Writer-based core both printResult (stdout) and
+// streamSearchResults (a live *store.SearchResultStream) build on, so
+// neither has to buffer a full result set in memory before printing.
+func fprintResult(w io.Writer, r store.SearchResult, format string) {
 	switch format {
 	case "json":
-		for _, r := range results {
-			fmt.Printf(`{"path": "%s", "collection": "%s", "score": %f, "lines": %d, "title": "%s"}`+"\n",
-				r.Path, r.Collection, r.Score, r.Lines, r.Title)
-		}
+		fmt.Fprintf(w, `{"path": "%s", "collection": "%s", "score": %f, "lines": %d, "title": "%s"}`+"\n",
+			r.Path, r.Collection, r.Score, r.Lines, r.Title)
+	case "ndjson":
+		raw, _ := json.Marshal(r)
+		fmt.Fprintln(w, string(raw))
+	case "files":
+		fmt.Fprintln(w, r.Path)
 	case "csv":
-		fmt.Println("path,collection,score,lines,title")
-		for _, r := range results {
-			fmt.Printf("%s,%s,%f,%d,%s\n", r.Path, r.Collection, r.Score, r.Lines, r.Title)
-		}
+		fmt.Fprintf(w, "%s,%s,%f,%d,%s\n", r.Path, r.Collection, r.Score, r.Lines, r.Title)
 	default: // cli
-		for _, r := range results {
-			fmt.Printf("[%.3f] %s (%s)\n", r.Score, r.Path, r.Collection)
-			fmt.Printf("    Title: %s, Lines: %d\n", r.Title, r.Lines)
+		fmt.Fprintf(w, "[%.3f] %s (%s)\n", r.Score, r.Path, r.Collection)
+		fmt.Fprintf(w, "    Title: %s, Lines: %d\n", r.Title, r.Lines)
+	}
+}
+
+// streamSearchResults drains stream, writing each result to w as it
+// arrives instead of collecting the full slice first -- the path
+// runSearch/runVectorSearch/runQuery take for --format=ndjson and
+// --format=files, so e.g. `qmd search ... --format=files | head` stops
+// reading without this process ever holding the whole formatted output
+// in memory at once. stream's underlying search still ranks every
+// matching document before its first result is sent (see
+// store.streamResults), so this saves output-buffering memory on large
+// result sets; it doesn't let `head` cut the search itself short.
+// Returns stream.Err() once Results() closes.
+func streamSearchResults(w io.Writer, stream *store.SearchResultStream, format string) error {
+	fprintResultsHeader(w, format)
+	for r := range stream.Results() {
+		fprintResult(w, r, format)
+	}
+	return stream.Err()
+}
+
+// printExpansion reports which terms query expansion OR-combined into
+// the BM25 query, if any, in the given output format.
+func printExpansion(terms []string, format string) {
+	if len(terms) == 0 {
+		return
+	}
+
+	switch format {
+	case "json":
+		raw, _ := json.Marshal(map[string]any{"expanded_terms": terms})
+		fmt.Println(string(raw))
+	case "csv":
+		// No header row: this is a side channel, not part of the results table.
+	default: // cli
+		fmt.Printf("Expanded query with: %s\n", strings.Join(terms, ", "))
+	}
+}
+
+// validateStrictInput marshals input and validates it against command's
+// ANEL input schema when --strict is set, returning nil otherwise.
+func validateStrictInput(command string, input any) error {
+	if !strict {
+		return nil
+	}
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	return anel.ValidateInput(command, raw)
+}
+
+// validateStrictOutput marshals output and validates it against command's
+// ANEL output schema when --strict is set, returning nil otherwise.
+func validateStrictOutput(command string, output any) error {
+	if !strict {
+		return nil
+	}
+	raw, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+	return anel.ValidateOutput(command, raw)
+}
+
+// renderCLIError prints err to w in the given output format. A wrapped
+// anel.Error renders as its AnelError JSON under "json" (so the minor
+// code, details, and hint survive), and as a message plus hint line under
+// every other format; a plain error just prints its message.
+func renderCLIError(w io.Writer, prefix string, err error, format string) {
+	if rerr, ok := anel.As(err); ok {
+		if format == "json" {
+			fmt.Fprintln(w, rerr.ToAnelError("").ToNDJSON())
+			return
+		}
+		fmt.Fprintf(w, "%s: %s\n", prefix, rerr.Message)
+		if rerr.Hint != "" {
+			fmt.Fprintf(w, "  hint: %s\n", rerr.Hint)
 		}
+		return
 	}
+	fmt.Fprintf(w, "%s: %v\n", prefix, err)
 }
 
 func init() {
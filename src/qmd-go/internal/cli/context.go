@@ -2,7 +2,9 @@ package cli
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/qmd/qmd-go/internal/qmdcontext"
 	"github.com/spf13/cobra"
 )
 
@@ -11,66 +13,183 @@ var contextCmd = &cobra.Command{
 	Short: "Manage contexts",
 }
 
-var contextAddCmd = &cobra.Command{
-	Use:   "add [path]",
-	Short: "Add a context",
-	Args:  cobra.MaximumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		// Check for dry-run mode
-		dryRun, _ := cmd.Flags().GetBool("dry-run")
+var contextCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a context",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		description, _ := cmd.Flags().GetString("description")
+		from, _ := cmd.Flags().GetString("from")
+		store, _ := cmd.Flags().GetString("store")
+		llmURL, _ := cmd.Flags().GetString("llm-url")
+		llmAPIKeyRef, _ := cmd.Flags().GetString("llm-api-key-ref")
+		embedLocal, _ := cmd.Flags().GetString("embed-local")
+		embedRemote, _ := cmd.Flags().GetString("embed-remote")
+		rerankLocal, _ := cmd.Flags().GetString("rerank-local")
+		rerankRemote, _ := cmd.Flags().GetString("rerank-remote")
+
 		if dryRun {
-			description, _ := cmd.Flags().GetString("description")
-			path := ""
-			if len(args) > 0 {
-				path = args[0]
-			}
-			fmt.Println("[DRY-RUN] Would execute context add with:")
-			fmt.Printf("  path: %s\n", path)
-			fmt.Printf("  description: %s\n", description)
-			return
+			fmt.Println("[DRY-RUN] Would create context:")
+			fmt.Printf("  name: %s, from: %s, description: %s\n", name, from, description)
+			return nil
 		}
-		description, _ := cmd.Flags().GetString("description")
-		fmt.Printf("Context added: %s\n", description)
+
+		ctx, err := qmdcontext.Create(name, qmdcontext.CreateOptions{
+			Description:  description,
+			From:         from,
+			Store:        store,
+			LLMURL:       llmURL,
+			LLMAPIKeyRef: llmAPIKeyRef,
+			EmbedLocal:   embedLocal,
+			EmbedRemote:  embedRemote,
+			RerankLocal:  rerankLocal,
+			RerankRemote: rerankRemote,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Context %q created\n", ctx.Name)
+		return nil
+	},
+}
+
+var contextUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Set the active context",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if dryRun {
+			fmt.Printf("[DRY-RUN] Would set active context to %q\n", name)
+			return nil
+		}
+		if err := qmdcontext.Use(name); err != nil {
+			return err
+		}
+		fmt.Printf("Active context: %s\n", name)
+		return nil
+	},
+}
+
+var contextShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the active context",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, err := qmdcontext.CurrentContext()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Name:        %s\n", ctx.Name)
+		if ctx.Description != "" {
+			fmt.Printf("Description: %s\n", ctx.Description)
+		}
+		fmt.Printf("Store:       %s\n", ctx.Endpoints.Store)
+		fmt.Printf("LLM:         %s\n", ctx.Endpoints.LLM.BaseURL)
+		fmt.Printf("Embed:       local=%s remote=%s\n", ctx.Endpoints.Embed.Local, ctx.Endpoints.Embed.Remote)
+		fmt.Printf("Rerank:      local=%s remote=%s\n", ctx.Endpoints.Rerank.Local, ctx.Endpoints.Rerank.Remote)
+		return nil
 	},
 }
 
 var contextListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List contexts",
-	Run: func(cmd *cobra.Command, args []string) {
-		// Check for dry-run mode
-		dryRun, _ := cmd.Flags().GetBool("dry-run")
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if dryRun {
-			fmt.Println("[DRY-RUN] Would execute context list")
-			return
+			fmt.Println("[DRY-RUN] Would list contexts")
+			return nil
 		}
+
+		contexts, err := qmdcontext.List()
+		if err != nil {
+			return err
+		}
+
+		current, _ := qmdcontext.Current()
+
 		fmt.Println("Contexts:")
+		if len(contexts) == 0 {
+			fmt.Println("  (none configured)")
+			return nil
+		}
+		for _, c := range contexts {
+			marker := " "
+			if c.Name == current {
+				marker = "*"
+			}
+			fmt.Printf("%s %-20s %s\n", marker, c.Name, c.Description)
+		}
+		return nil
 	},
 }
 
 var contextRemoveCmd = &cobra.Command{
-	Use:   "rm <path>",
+	Use:   "rm <name>",
 	Short: "Remove a context",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		// Check for dry-run mode
-		dryRun, _ := cmd.Flags().GetBool("dry-run")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
 		if dryRun {
-			path := args[0]
-			fmt.Println("[DRY-RUN] Would execute context rm with:")
-			fmt.Printf("  path: %s\n", path)
-			return
+			fmt.Printf("[DRY-RUN] Would remove context %q\n", name)
+			return nil
+		}
+		if err := qmdcontext.Remove(name); err != nil {
+			return err
+		}
+		fmt.Printf("Context %q removed\n", name)
+		return nil
+	},
+}
+
+var contextExportCmd = &cobra.Command{
+	Use:   "export <name>",
+	Short: "Export a context as a tar bundle to stdout",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return qmdcontext.Export(args[0], os.Stdout)
+	},
+}
+
+var contextImportCmd = &cobra.Command{
+	Use:   "import <bundle.tar>",
+	Short: "Import a context from a tar bundle",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open bundle: %w", err)
 		}
-		path := args[0]
-		fmt.Printf("Context '%s' removed\n", path)
+		defer f.Close()
+
+		ctx, err := qmdcontext.Import(f)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Context %q imported\n", ctx.Name)
+		return nil
 	},
 }
 
 func init() {
-	contextCmd.AddCommand(contextAddCmd)
+	contextCmd.AddCommand(contextCreateCmd)
+	contextCmd.AddCommand(contextUseCmd)
+	contextCmd.AddCommand(contextShowCmd)
 	contextCmd.AddCommand(contextListCmd)
 	contextCmd.AddCommand(contextRemoveCmd)
+	contextCmd.AddCommand(contextExportCmd)
+	contextCmd.AddCommand(contextImportCmd)
 
-	contextAddCmd.Flags().StringP("description", "d", "", "Description")
-	contextAddCmd.MarkFlagRequired("description")
+	contextCreateCmd.Flags().StringP("description", "d", "", "Description")
+	contextCreateCmd.Flags().String("from", "", "Clone endpoints from an existing context")
+	contextCreateCmd.Flags().String("store", "", "Store path endpoint")
+	contextCreateCmd.Flags().String("llm-url", "", "LLM base URL endpoint")
+	contextCreateCmd.Flags().String("llm-api-key-ref", "", "Reference to the LLM API key (e.g. env:OPENAI_API_KEY)")
+	contextCreateCmd.Flags().String("embed-local", "", "Local embedding model")
+	contextCreateCmd.Flags().String("embed-remote", "", "Remote embedding model")
+	contextCreateCmd.Flags().String("rerank-local", "", "Local rerank model")
+	contextCreateCmd.Flags().String("rerank-remote", "", "Remote rerank model")
 }
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -12,6 +13,12 @@ var embedCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		force, _ := cmd.Flags().GetBool("force")
 		collection, _ := cmd.Flags().GetString("collection")
+		rebuildIndex, _ := cmd.Flags().GetBool("rebuild-index")
+
+		if rebuildIndex {
+			runRebuildIndex(cmd, collection)
+			return
+		}
 
 		if force {
 			fmt.Println("Force regeneration enabled")
@@ -24,15 +31,53 @@ var embedCmd = &cobra.Command{
 	},
 }
 
+// runRebuildIndex rebuilds the vector index for collection, or every
+// configured collection when collection is empty, via
+// pkg/qmd.Client.RebuildIndex.
+func runRebuildIndex(cmd *cobra.Command, collection string) {
+	client, err := LoadClient()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error loading config: %v\n", err)
+		return
+	}
+
+	var collections []string
+	if collection != "" {
+		collections = []string{collection}
+	} else {
+		for _, c := range client.Config().Collections {
+			collections = append(collections, c.Name)
+		}
+	}
+
+	for _, name := range collections {
+		if err := client.RebuildIndex(cmd.Context(), name); err != nil {
+			fmt.Fprintf(cmd.OutOrStderr(), "Error rebuilding index for %q: %v\n", name, err)
+			continue
+		}
+		fmt.Printf("Rebuilt index: %s\n", name)
+	}
+}
+
 var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update index",
 	Run: func(cmd *cobra.Command, args []string) {
 		pull, _ := cmd.Flags().GetBool("pull")
+		noAutoEmbed, _ := cmd.Flags().GetBool("no-auto-embed")
+
 		if pull {
 			fmt.Println("Pulling remote changes...")
 		}
 		fmt.Println("Updating index...")
+
+		// TODO: once update indexes documents itself, it should spin up
+		// a store.AutoEmbedder and Enqueue each inserted/changed
+		// document's chunks unless noAutoEmbed is set, instead of
+		// requiring a separate `qmd embed` pass.
+		if noAutoEmbed {
+			fmt.Println("Auto-embedding disabled; run 'qmd embed' separately")
+		}
 	},
 }
 
@@ -40,19 +85,65 @@ var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show index status",
 	Run: func(cmd *cobra.Command, args []string) {
+		backends, _ := cmd.Flags().GetBool("backends")
+		if backends {
+			runBackendStatus(cmd)
+			return
+		}
+
 		verbose, _ := cmd.Flags().GetBool("verbose")
 
 		fmt.Println("Index Status")
-		fmt.Println("=" + "="*39)
+		fmt.Println(strings.Repeat("=", 40))
 		fmt.Println("Collections: 0")
 		fmt.Println("Documents: 0")
 
 		if verbose {
 			fmt.Println("\nDetailed Statistics:")
 		}
+
+		printCollectionBackends()
 	},
 }
 
+// printCollectionBackends reports each configured collection's effective
+// BM25/vector backend (config.Config.ResolveCollection), so a
+// per-collection override in index.yaml is visible instead of silent.
+func printCollectionBackends() {
+	cfg, err := LoadConfig()
+	if err != nil || len(cfg.Collections) == 0 {
+		return
+	}
+
+	fmt.Println("\nCollection Backends:")
+	for _, col := range cfg.Collections {
+		resolved := cfg.ResolveCollection(col.Name)
+		fmt.Printf("  %-20s bm25=%-14s vector=%s\n", col.Name, resolved.BM25.Backend, resolved.Vector.Backend)
+	}
+}
+
+// runBackendStatus resolves the configured FTS and vector backends
+// through the indexer.Registry and reports each one's health, so a user
+// can tell their --fts-backend/--vector-backend choice is reachable
+// before running a search against it.
+func runBackendStatus(cmd *cobra.Command) {
+	client, err := LoadClient()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error loading store: %v\n", err)
+		return
+	}
+
+	fmt.Println("Backend Status")
+	fmt.Println(strings.Repeat("=", 40))
+	for _, status := range client.Status(cmd.Context()) {
+		if status.Healthy {
+			fmt.Printf("[ok]    %-8s %s\n", status.Kind, status.Backend)
+			continue
+		}
+		fmt.Printf("[error] %-8s %s: %s\n", status.Kind, status.Backend, status.Error)
+	}
+}
+
 var cleanupCmd = &cobra.Command{
 	Use:   "cleanup",
 	Short: "Cleanup stale entries",
@@ -71,10 +162,13 @@ var cleanupCmd = &cobra.Command{
 func init() {
 	embedCmd.Flags().BoolP("force", "f", false, "Force regeneration")
 	embedCmd.Flags().StringP("collection", "c", "", "Collection name")
+	embedCmd.Flags().Bool("rebuild-index", false, "Rebuild the vector index from scratch instead of generating embeddings (for vector.index: hnsw)")
 
 	updateCmd.Flags().Bool("pull", false, "Pull remote changes")
+	updateCmd.Flags().Bool("no-auto-embed", false, "Skip auto-embedding; fall back to a separate 'qmd embed' pass")
 
 	statusCmd.Flags().BoolP("verbose", "v", false, "Detailed output")
+	statusCmd.Flags().Bool("backends", false, "Report health of the configured FTS and vector backends")
 
 	cleanupCmd.Flags().Bool("dry-run", false, "Dry run only")
 	cleanupCmd.Flags().Uint("older-than", 30, "Remove entries older than N days")
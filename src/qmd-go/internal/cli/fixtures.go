@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/qmd/qmd-go/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// defaultTestLimit is the result limit a fixture gets when it doesn't
+// set its own, matching --limit's default for search/vsearch/query.
+const defaultTestLimit = 20
+
+var testCmd = &cobra.Command{
+	Use:   "test <path>",
+	Short: "Run query/expected-result fixtures against the indexed store",
+	Long: `test runs each fixture in path (a single JSON file, or a directory of
+them) as a search against the current store and checks its results
+against the fixture's expectations, printing a PASS/FAIL summary. It
+exits non-zero if any fixture fails, so it doubles as a regression test
+for an indexed corpus or a reranker tuning change.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTest,
+}
+
+// testFixture is one query/expectation pair loaded from a JSON fixture
+// file. Mode selects which of the store's three ranking methods to run
+// Query against; Expect is checked against whatever it returns.
+type testFixture struct {
+	Mode       string          `json:"mode"`
+	Query      string          `json:"query"`
+	Collection string          `json:"collection"`
+	Limit      int             `json:"limit"`
+	Expect     testExpectation `json:"expect"`
+
+	// name identifies the fixture in output: the file it was loaded
+	// from, plus a "#N" suffix if that file held more than one fixture.
+	name string
+}
+
+// testExpectation is a fixture's pass/fail criteria. Any subset may be
+// set; a fixture with none of them passes as long as its search runs
+// without error, which is enough for a "does this query even execute"
+// smoke test.
+type testExpectation struct {
+	ContainsDocIDs []string `json:"contains_docids"`
+	MinScore       float32  `json:"min_score"`
+	Top1Path       string   `json:"top1_path"`
+}
+
+// testResult is one fixture's outcome, in a shape that's equally useful
+// printed as a summary line or marshaled for --json.
+type testResult struct {
+	Name     string   `json:"name"`
+	Mode     string   `json:"mode"`
+	Query    string   `json:"query"`
+	Passed   bool     `json:"passed"`
+	Failures []string `json:"failures,omitempty"`
+}
+
+func runTest(cmd *cobra.Command, args []string) error {
+	fixtures, err := loadTestFixtures(args[0])
+	if err != nil {
+		return err
+	}
+	if len(fixtures) == 0 {
+		return fmt.Errorf("no fixtures found at %s", args[0])
+	}
+
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	s, err := LoadStore()
+	if err != nil {
+		return err
+	}
+
+	results := make([]testResult, len(fixtures))
+	failed := 0
+	for i, f := range fixtures {
+		results[i] = runTestFixture(cmd.Context(), s, f)
+		if !results[i].Passed {
+			failed++
+		}
+	}
+
+	if asJSON {
+		raw, err := json.Marshal(results)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(raw))
+		if failed > 0 {
+			return fmt.Errorf("%d of %d fixtures failed", failed, len(results))
+		}
+		return nil
+	}
+
+	for _, r := range results {
+		status := "PASS"
+		if !r.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s  %s (%s: %q)\n", status, r.Name, r.Mode, r.Query)
+		for _, reason := range r.Failures {
+			fmt.Fprintf(cmd.OutOrStdout(), "       %s\n", reason)
+		}
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "\n%d passed, %d failed\n", len(results)-failed, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d fixtures failed", failed, len(results))
+	}
+	return nil
+}
+
+// loadTestFixtures reads path, a single fixture file or a directory of
+// them, into a flat list. Each *.json file may hold either one fixture
+// object or an array of them; a directory's files are read in sorted
+// order so a fixture set's output is stable across runs.
+func loadTestFixtures(path string) ([]testFixture, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		files = nil
+		for _, e := range entries {
+			if !e.IsDir() && filepath.Ext(e.Name()) == ".json" {
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	var fixtures []testFixture
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var batch []testFixture
+		if err := json.Unmarshal(data, &batch); err != nil {
+			var single testFixture
+			if err := json.Unmarshal(data, &single); err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			batch = []testFixture{single}
+		}
+
+		base := filepath.Base(file)
+		for i := range batch {
+			if len(batch) > 1 {
+				batch[i].name = fmt.Sprintf("%s#%d", base, i+1)
+			} else {
+				batch[i].name = base
+			}
+		}
+		fixtures = append(fixtures, batch...)
+	}
+	return fixtures, nil
+}
+
+// runTestFixture runs f's query through the store method its Mode names
+// (hybrid is the default for an empty Mode) and checks the results
+// against f.Expect, collecting every unmet expectation rather than
+// stopping at the first so a fixture's full set of failures is visible
+// in one run.
+func runTestFixture(ctx context.Context, s *store.Store, f testFixture) testResult {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = defaultTestLimit
+	}
+	opts := store.SearchOptions{Limit: limit, Collection: f.Collection}
+
+	mode := f.Mode
+	if mode == "" {
+		mode = "hybrid"
+	}
+
+	var results []store.SearchResult
+	var err error
+	switch mode {
+	case "bm25":
+		results, err = s.BM25Search(ctx, f.Query, opts)
+	case "vector":
+		results, err = s.VectorSearch(ctx, f.Query, opts)
+	case "hybrid":
+		results, err = s.HybridSearch(ctx, f.Query, opts)
+	default:
+		return testResult{Name: f.name, Mode: mode, Query: f.Query,
+			Failures: []string{fmt.Sprintf("unknown mode %q (want bm25, vector, or hybrid)", f.Mode)}}
+	}
+	if err != nil {
+		return testResult{Name: f.name, Mode: mode, Query: f.Query,
+			Failures: []string{fmt.Sprintf("search error: %v", err)}}
+	}
+
+	var failures []string
+	if len(f.Expect.ContainsDocIDs) > 0 {
+		seen := make(map[string]bool, len(results))
+		for _, r := range results {
+			seen[r.DocID] = true
+		}
+		for _, want := range f.Expect.ContainsDocIDs {
+			if !seen[want] {
+				failures = append(failures, fmt.Sprintf("missing expected docid %q", want))
+			}
+		}
+	}
+	if f.Expect.MinScore > 0 {
+		if len(results) == 0 {
+			failures = append(failures, fmt.Sprintf("expected top score >= %.3f, got no results", f.Expect.MinScore))
+		} else if results[0].Score < f.Expect.MinScore {
+			failures = append(failures, fmt.Sprintf("expected top score >= %.3f, got %.3f", f.Expect.MinScore, results[0].Score))
+		}
+	}
+	if f.Expect.Top1Path != "" {
+		if len(results) == 0 {
+			failures = append(failures, fmt.Sprintf("expected top1 path %q, got no results", f.Expect.Top1Path))
+		} else if results[0].Path != f.Expect.Top1Path {
+			failures = append(failures, fmt.Sprintf("expected top1 path %q, got %q", f.Expect.Top1Path, results[0].Path))
+		}
+	}
+
+	return testResult{Name: f.name, Mode: mode, Query: f.Query, Passed: len(failures) == 0, Failures: failures}
+}
+
+func init() {
+	RootCmd.AddCommand(testCmd)
+	testCmd.Flags().Bool("json", false, "Emit machine-readable JSON results instead of a PASS/FAIL summary")
+}
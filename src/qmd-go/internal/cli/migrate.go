@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qmd/qmd-go/internal/anel"
+	"github.com/qmd/qmd-go/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move a collection's vectors between backends (sqlite, qdrant, milvus)",
+	Run:   runMigrate,
+}
+
+func runMigrate(cmd *cobra.Command, args []string) {
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+	collection, _ := cmd.Flags().GetString("collection")
+	batchSize, _ := cmd.Flags().GetInt("batch-size")
+	job, _ := cmd.Flags().GetString("job")
+	reembed, _ := cmd.Flags().GetBool("reembed")
+
+	if dryRun {
+		fmt.Printf("[DRY-RUN] Would migrate collection %q from %s to %s\n", collection, from, to)
+		fmt.Printf("[DRY-RUN] BatchSize: %d, Job: %s\n", batchSize, job)
+	}
+
+	if err := validateStrictInput("migrate", map[string]any{
+		"from": from, "to": to, "collection": collection, "batch_size": batchSize, "job": job, "reembed": reembed,
+	}); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: %v\n", err)
+		return
+	}
+
+	s, err := LoadStore()
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error loading store", err, outputFormat)
+		return
+	}
+
+	src, err := migrationEndpoint(s, from, collection)
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error resolving source", err, outputFormat)
+		return
+	}
+	dst, err := migrationEndpoint(s, to, collection)
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error resolving destination", err, outputFormat)
+		return
+	}
+
+	opts := store.MigrateOptions{
+		BatchSize:  batchSize,
+		DryRun:     dryRun,
+		Job:        job,
+		OnProgress: reportMigrateProgress,
+	}
+	if reembed {
+		opts.TargetModel = s.EmbedModelName()
+		opts.Embed = func(ctx context.Context, text string) ([]float32, error) {
+			vector, _, err := s.EmbedText(ctx, text)
+			return vector, err
+		}
+	}
+
+	stats, err := store.NewMigrator().Migrate(cmd.Context(), src, dst, opts)
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error migrating", err, outputFormat)
+		return
+	}
+
+	if err := validateStrictOutput("migrate", map[string]any{
+		"moved": stats.Moved, "reembedded": stats.Reembedded, "dry_run": stats.DryRun,
+	}); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: invalid output: %v\n", err)
+		return
+	}
+
+	verb := "Moved"
+	if stats.DryRun {
+		verb = "Would move"
+	}
+	if stats.Reembedded > 0 {
+		fmt.Printf("%s %d points (%d re-embedded) from %s to %s\n", verb, stats.Moved, stats.Reembedded, from, to)
+	} else {
+		fmt.Printf("%s %d points from %s to %s\n", verb, stats.Moved, from, to)
+	}
+}
+
+// migrateProgressSeq numbers the NDJSONRecord "metadata" events emitted
+// by reportMigrateProgress for one `qmd migrate` run.
+var migrateProgressSeq uint64
+
+// reportMigrateProgress emits progress as an anel.NDJSONRecord of type
+// "metadata", letting a scripted caller tail stdout for records
+// processed, failed, and an ETA instead of waiting silently for the
+// final summary line.
+func reportMigrateProgress(progress store.MigrateProgress) {
+	migrateProgressSeq++
+	anel.NewNDJSONRecord("metadata", migrateProgressSeq, map[string]any{
+		"processed":   progress.Processed,
+		"failed":      progress.Failed,
+		"eta_seconds": progress.ETA.Seconds(),
+	}).Emit()
+}
+
+// migrationEndpoint resolves a backend name ("sqlite", "qdrant", or
+// "milvus") to the store.MigrationSource/MigrationDestination for
+// collection, backed by the already-configured connections on s.
+func migrationEndpoint(s *store.Store, name, collection string) (interface {
+	store.MigrationSource
+	store.MigrationDestination
+}, error) {
+	switch name {
+	case "sqlite":
+		db, err := s.GetConnection(collection)
+		if err != nil {
+			return nil, err
+		}
+		return store.NewSQLiteVectorBackend(db, collection, 384), nil
+	case "qdrant":
+		qdrant := s.Qdrant()
+		if qdrant == nil {
+			return nil, anel.Newf(anel.ErrorCodeBackendUnavailable, "qdrant_not_configured",
+				"Qdrant backend is not available").
+				WithHint("set vector.backend to qdrant and configure its endpoint")
+		}
+		return qdrant, nil
+	case "milvus":
+		milvus := s.Milvus()
+		if milvus == nil {
+			return nil, anel.Newf(anel.ErrorCodeBackendUnavailable, "milvus_not_configured",
+				"Milvus backend is not available").
+				WithHint("set vector.backend to milvus and configure its endpoint")
+		}
+		return milvus, nil
+	case "lancedb":
+		// lancedb is a selectable indexer.Registry backend (see
+		// indexer.openLanceDBVector) but has no Go client integration yet,
+		// so there's no concrete MigrationSource/MigrationDestination for
+		// it to return here either.
+		return nil, anel.Newf(anel.ErrorCodeBackendUnavailable, "lancedb_not_configured",
+			"LanceDB backend is not available").
+			WithHint("lancedb has no Go client integration yet; use sqlite, qdrant, or milvus")
+	default:
+		return nil, anel.Newf(anel.ErrorCodeInvalidInput, "unknown_migrate_backend",
+			"unknown migration backend %q", name).
+			WithHint(`use "sqlite", "qdrant", or "milvus"`)
+	}
+}
+
+func init() {
+	migrateCmd.Flags().String("from", "sqlite", "Source backend: sqlite, qdrant, or milvus (lancedb is recognized but not yet available)")
+	migrateCmd.Flags().String("to", "qdrant", "Destination backend: sqlite, qdrant, or milvus (lancedb is recognized but not yet available)")
+	migrateCmd.Flags().StringP("collection", "c", "", "Collection to migrate")
+	migrateCmd.Flags().Int("batch-size", 100, "Points moved per batch")
+	migrateCmd.Flags().String("job", "", "Job name for checkpointing; resumes an interrupted migration sharing the same name")
+	migrateCmd.Flags().Bool("reembed", false, "Re-embed points whose recorded model differs from the configured embed model, instead of moving their vectors as-is")
+	migrateCmd.MarkFlagRequired("collection")
+}
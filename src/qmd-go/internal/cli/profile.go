@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/qmd/qmd-go/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the qmd config file",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check index.yaml against the config schema",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgPath := configPath
+		if cfgPath == "" {
+			cfgPath = expandPath("~/.config/qmd/index.yaml")
+		}
+
+		data, err := os.ReadFile(cfgPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				fmt.Printf("%s does not exist; nothing to validate\n", cfgPath)
+				return nil
+			}
+			return err
+		}
+
+		if err := config.ValidateData(data); err != nil {
+			return err
+		}
+		fmt.Printf("%s is valid\n", cfgPath)
+		return nil
+	},
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade index.yaml's schema_version and rewrite the file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgPath := configPath
+		if cfgPath == "" {
+			cfgPath = expandPath("~/.config/qmd/index.yaml")
+		}
+
+		from := 0
+		if data, err := os.ReadFile(cfgPath); err == nil {
+			var versionProbe struct {
+				SchemaVersion int `yaml:"schema_version"`
+			}
+			_ = yaml.Unmarshal(data, &versionProbe)
+			from = versionProbe.SchemaVersion
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+
+		if from >= config.CurrentSchemaVersion {
+			fmt.Printf("already at schema_version %d\n", from)
+			return nil
+		}
+
+		if dryRun {
+			fmt.Printf("[DRY-RUN] Would migrate schema_version %d -> %d\n", from, config.CurrentSchemaVersion)
+			return nil
+		}
+
+		// loadRawConfig runs Migrate as part of LoadConfigFromData; Save
+		// is what makes the upgrade stick instead of being re-applied
+		// (harmlessly, but redundantly) on every future load.
+		cfg, err := loadRawConfig()
+		if err != nil {
+			return err
+		}
+		if err := cfg.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Migrated schema_version %d -> %d\n", from, cfg.SchemaVersion)
+		return nil
+	},
+}
+
+var configProfileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named config profiles",
+}
+
+var configProfileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List config profiles",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadRawConfig()
+		if err != nil {
+			return err
+		}
+
+		profiles := cfg.ListProfiles()
+		if len(profiles) == 0 {
+			fmt.Println("(none configured)")
+			return nil
+		}
+		for _, name := range profiles {
+			marker := " "
+			if name == cfg.ActiveProfile {
+				marker = "*"
+			}
+			fmt.Printf("%s %s\n", marker, name)
+		}
+		return nil
+	},
+}
+
+var configProfileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Set the active config profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if dryRun {
+			fmt.Printf("[DRY-RUN] Would set active profile to %q\n", name)
+			return nil
+		}
+
+		cfg, err := loadRawConfig()
+		if err != nil {
+			return err
+		}
+		if err := cfg.UseProfile(name); err != nil {
+			return err
+		}
+		if err := cfg.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Active profile: %s\n", name)
+		return nil
+	},
+}
+
+var configProfileShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a config profile's overrides",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadRawConfig()
+		if err != nil {
+			return err
+		}
+
+		profile, ok := cfg.Profiles[args[0]]
+		if !ok {
+			return fmt.Errorf("no such profile %q", args[0])
+		}
+
+		fmt.Printf("Name:       %s\n", args[0])
+		fmt.Printf("BM25:       %s\n", profile.BM25.Backend)
+		fmt.Printf("Vector:     %s\n", profile.Vector.Backend)
+		if len(profile.Collections) > 0 {
+			fmt.Println("Collections:")
+			for _, col := range profile.Collections {
+				fmt.Printf("  - %s\n", col.Name)
+			}
+		}
+		return nil
+	},
+}
+
+var configProfileSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save the current config as a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if dryRun {
+			fmt.Printf("[DRY-RUN] Would save current config as profile %q\n", name)
+			return nil
+		}
+
+		cfg, err := loadRawConfig()
+		if err != nil {
+			return err
+		}
+		if err := cfg.SaveProfile(name); err != nil {
+			return err
+		}
+		if err := cfg.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Profile %q saved\n", name)
+		return nil
+	},
+}
+
+var configProfileDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a config profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if dryRun {
+			fmt.Printf("[DRY-RUN] Would delete profile %q\n", name)
+			return nil
+		}
+
+		cfg, err := loadRawConfig()
+		if err != nil {
+			return err
+		}
+		if _, ok := cfg.Profiles[name]; !ok {
+			return fmt.Errorf("no such profile %q", name)
+		}
+		delete(cfg.Profiles, name)
+		if cfg.ActiveProfile == name {
+			cfg.ActiveProfile = ""
+		}
+		if err := cfg.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Profile %q deleted\n", name)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configMigrateCmd)
+	configCmd.AddCommand(configProfileCmd)
+	configProfileCmd.AddCommand(configProfileListCmd)
+	configProfileCmd.AddCommand(configProfileUseCmd)
+	configProfileCmd.AddCommand(configProfileShowCmd)
+	configProfileCmd.AddCommand(configProfileSaveCmd)
+	configProfileCmd.AddCommand(configProfileDeleteCmd)
+}
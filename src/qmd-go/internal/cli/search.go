@@ -2,7 +2,10 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/qmd/qmd-go/internal/anel"
+	"github.com/qmd/qmd-go/internal/config"
 	"github.com/qmd/qmd-go/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -28,6 +31,13 @@ var queryCmd = &cobra.Command{
 	Run:   runQuery,
 }
 
+var hsearchCmd = &cobra.Command{
+	Use:   "hsearch <query>",
+	Short: "Hybrid search combining BM25 and vector results via Reciprocal Rank Fusion",
+	Args:  cobra.ExactArgs(1),
+	Run:   runHSearch,
+}
+
 func runSearch(cmd *cobra.Command, args []string) {
 	query := args[0]
 
@@ -41,25 +51,101 @@ func runSearch(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	collection, _ := cmd.Flags().GetString("collection")
+	all, _ := cmd.Flags().GetBool("all")
+	noExpand, _ := cmd.Flags().GetBool("no-expand")
+	sortFlag, _ := cmd.Flags().GetString("sort")
+	expandFlag, _ := cmd.Flags().GetString("expand")
+	expandLimit, _ := cmd.Flags().GetInt("expand-limit")
+	expandDryRun, _ := cmd.Flags().GetBool("expand-dry-run")
+	stream, _ := cmd.Flags().GetBool("stream")
+	analyze, _ := cmd.Flags().GetBool("analyze")
+
+	if err := validateStrictInput("search", map[string]any{
+		"query": query, "limit": limit, "collection": collection, "all": all, "no_expand": noExpand, "sort": sortFlag,
+		"expand": expandFlag, "expand_limit": expandLimit, "expand_dry_run": expandDryRun, "analyze": analyze,
+	}); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: %v\n", err)
+		return
+	}
+
+	sortSpec, err := parseSortFlag(cmd)
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: %v\n", err)
+		return
+	}
+
+	expandStrategy, err := parseExpandFlag(cmd)
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: %v\n", err)
+		return
+	}
+
 	s, err := LoadStore()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStderr(), "Error loading store: %v\n", err)
 		return
 	}
 
-	collection, _ := cmd.Flags().GetString("collection")
-	all, _ := cmd.Flags().GetBool("all")
+	if analyze {
+		tokens, err := s.AnalyzeText(cmd.Context(), collection, query)
+		if err != nil {
+			renderCLIError(cmd.OutOrStderr(), "Error analyzing query", err, outputFormat)
+			return
+		}
+		fmt.Printf("Tokens: %s\n", strings.Join(tokens, " "))
+		return
+	}
+
+	bm25Query, expandedTerms := expandBM25Query(cmd, s, query, noExpand, expandStrategy, expandLimit)
+
+	if expandDryRun {
+		printExpansion(expandedTerms, outputFormat)
+		fmt.Printf("[EXPAND-DRY-RUN] Would search for: %s\n", bm25Query)
+		return
+	}
 
-	results, err := s.BM25Search(query, store.SearchOptions{
+	if stream {
+		printExpansion(expandedTerms, outputFormat)
+		streamSearch(cmd, s, bm25Query, store.SearchOptions{
+			Limit:      limit,
+			Collection: collection,
+			SearchAll:  all,
+			SortSpec:   sortSpec,
+		})
+		return
+	}
+
+	opts := store.SearchOptions{
 		Limit:      limit,
 		Collection: collection,
 		SearchAll:  all,
-	})
+		SortSpec:   sortSpec,
+	}
+
+	if isStreamingFormat(outputFormat) {
+		printExpansion(expandedTerms, outputFormat)
+		streamOutput := s.BM25SearchStream(cmd.Context(), bm25Query, opts)
+		if err := streamSearchResults(cmd.OutOrStdout(), streamOutput, outputFormat); err != nil {
+			fmt.Fprintf(cmd.OutOrStderr(), "Error searching: %v\n", err)
+		}
+		return
+	}
+
+	results, err := s.BM25Search(cmd.Context(), bm25Query, opts)
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStderr(), "Error searching: %v\n", err)
 		return
 	}
 
+	if err := validateStrictOutput("search", map[string]any{
+		"results": results, "total": len(results), "expanded_terms": expandedTerms,
+	}); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: invalid output: %v\n", err)
+		return
+	}
+
+	printExpansion(expandedTerms, outputFormat)
 	printResults(results, outputFormat)
 }
 
@@ -76,25 +162,55 @@ func runVectorSearch(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	collection, _ := cmd.Flags().GetString("collection")
+	all, _ := cmd.Flags().GetBool("all")
+	sortFlag, _ := cmd.Flags().GetString("sort")
+
+	if err := validateStrictInput("vsearch", map[string]any{
+		"query": query, "limit": limit, "collection": collection, "all": all, "sort": sortFlag,
+	}); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: %v\n", err)
+		return
+	}
+
+	sortSpec, err := parseSortFlag(cmd)
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: %v\n", err)
+		return
+	}
+
 	s, err := LoadStore()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStderr(), "Error loading store: %v\n", err)
 		return
 	}
 
-	collection, _ := cmd.Flags().GetString("collection")
-	all, _ := cmd.Flags().GetBool("all")
-
-	results, err := s.VectorSearch(query, store.SearchOptions{
+	opts := store.SearchOptions{
 		Limit:      limit,
 		Collection: collection,
 		SearchAll:  all,
-	})
+		SortSpec:   sortSpec,
+	}
+
+	if isStreamingFormat(outputFormat) {
+		streamOutput := s.VectorSearchStream(cmd.Context(), query, opts)
+		if err := streamSearchResults(cmd.OutOrStdout(), streamOutput, outputFormat); err != nil {
+			fmt.Fprintf(cmd.OutOrStderr(), "Error searching: %v\n", err)
+		}
+		return
+	}
+
+	results, err := s.VectorSearch(cmd.Context(), query, opts)
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStderr(), "Error searching: %v\n", err)
 		return
 	}
 
+	if err := validateStrictOutput("vsearch", map[string]any{"results": results, "total": len(results)}); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: invalid output: %v\n", err)
+		return
+	}
+
 	printResults(results, outputFormat)
 }
 
@@ -111,31 +227,289 @@ func runQuery(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	collection, _ := cmd.Flags().GetString("collection")
+	all, _ := cmd.Flags().GetBool("all")
+	sortFlag, _ := cmd.Flags().GetString("sort")
+	fusionFlag, _ := cmd.Flags().GetString("fusion")
+	fusionAlpha, _ := cmd.Flags().GetFloat32("fusion-alpha")
+	var convexAlpha *float32
+	if cmd.Flags().Changed("fusion-alpha") {
+		convexAlpha = &fusionAlpha
+	}
+
+	if err := validateStrictInput("query", map[string]any{
+		"query": query, "limit": limit, "collection": collection, "all": all, "sort": sortFlag,
+		"fusion": fusionFlag, "fusion_alpha": fusionAlpha,
+	}); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: %v\n", err)
+		return
+	}
+
+	sortSpec, err := parseSortFlag(cmd)
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: %v\n", err)
+		return
+	}
+
 	s, err := LoadStore()
 	if err != nil {
-		fmt.Fprintf(cmd.OutOrStderr(), "Error loading store: %v\n", err)
+		renderCLIError(cmd.OutOrStderr(), "Error loading store", err, outputFormat)
+		return
+	}
+
+	opts := store.SearchOptions{
+		Limit:       limit,
+		Collection:  collection,
+		SearchAll:   all,
+		SortSpec:    sortSpec,
+		Fusion:      store.FusionName(fusionFlag),
+		ConvexAlpha: convexAlpha,
+	}
+
+	if isStreamingFormat(outputFormat) {
+		streamOutput := s.HybridSearchStream(cmd.Context(), query, opts)
+		if err := streamSearchResults(cmd.OutOrStdout(), streamOutput, outputFormat); err != nil {
+			renderCLIError(cmd.OutOrStderr(), "Error searching", err, outputFormat)
+		}
+		return
+	}
+
+	results, err := s.HybridSearch(cmd.Context(), query, opts)
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error searching", err, outputFormat)
 		return
 	}
 
+	if err := validateStrictOutput("query", map[string]any{"results": results, "total": len(results)}); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: invalid output: %v\n", err)
+		return
+	}
+
+	printResults(results, outputFormat)
+}
+
+func runHSearch(cmd *cobra.Command, args []string) {
+	query := args[0]
+
 	collection, _ := cmd.Flags().GetString("collection")
 	all, _ := cmd.Flags().GetBool("all")
+	semanticRatio, _ := cmd.Flags().GetFloat32("semantic-ratio")
+	rrfK, _ := cmd.Flags().GetInt("k")
+	noExpand, _ := cmd.Flags().GetBool("no-expand")
+	expandFlag, _ := cmd.Flags().GetString("expand")
+	expandLimit, _ := cmd.Flags().GetInt("expand-limit")
+	expandDryRun, _ := cmd.Flags().GetBool("expand-dry-run")
+
+	// Check for dry-run mode
+	if dryRun {
+		fmt.Printf("[DRY-RUN] Would execute hybrid RRF search for query: %s\n", query)
+		fmt.Printf("[DRY-RUN] Limit: %d\n", limit)
+		fmt.Printf("[DRY-RUN] Collection: %s, SearchAll: %v\n", collection, all)
+		fmt.Printf("[DRY-RUN] SemanticRatio: %.2f, K: %d\n", semanticRatio, rrfK)
+		return
+	}
 
-	results, err := s.HybridSearch(query, store.SearchOptions{
+	if err := validateStrictInput("hsearch", map[string]any{
+		"query": query, "limit": limit, "collection": collection, "all": all,
+		"semantic_ratio": semanticRatio, "k": rrfK, "no_expand": noExpand,
+		"expand": expandFlag, "expand_limit": expandLimit, "expand_dry_run": expandDryRun,
+	}); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: %v\n", err)
+		return
+	}
+
+	expandStrategy, err := parseExpandFlag(cmd)
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: %v\n", err)
+		return
+	}
+
+	s, err := LoadStore()
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error loading store", err, outputFormat)
+		return
+	}
+
+	bm25Query, expandedTerms := expandBM25Query(cmd, s, query, noExpand, expandStrategy, expandLimit)
+
+	if expandDryRun {
+		printExpansion(expandedTerms, outputFormat)
+		fmt.Printf("[EXPAND-DRY-RUN] Would search for: %s\n", bm25Query)
+		return
+	}
+
+	results, err := s.HSearch(cmd.Context(), bm25Query, store.SearchOptions{
 		Limit:      limit,
 		Collection: collection,
 		SearchAll:  all,
-	})
+	}, semanticRatio, rrfK)
 	if err != nil {
-		fmt.Fprintf(cmd.OutOrStderr(), "Error searching: %v\n", err)
+		renderCLIError(cmd.OutOrStderr(), "Error searching", err, outputFormat)
+		return
+	}
+
+	if err := validateStrictOutput("hsearch", map[string]any{
+		"results": results, "total": len(results), "expanded_terms": expandedTerms,
+	}); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error: invalid output: %v\n", err)
 		return
 	}
 
+	printExpansion(expandedTerms, outputFormat)
 	printResults(results, outputFormat)
 }
 
+// defaultExpansionTerms bounds how many corpus-derived terms
+// expandBM25Query OR-combines into a query, when expansion isn't
+// disabled via --no-expand.
+const defaultExpansionTerms = 5
+
+// expandBM25Query OR-combines up to limit (or defaultExpansionTerms, when
+// limit is 0) expansion terms into query, returning the combined query
+// string to search with and the terms that were added (nil when noExpand
+// is set, strategy is config.ExpansionNone, or nothing was found, in
+// which case query is returned unchanged). An empty strategy defers to
+// the configured default via s.ExpandQuery; otherwise strategy overrides
+// it via s.ExpandQueryWithStrategy.
+func expandBM25Query(cmd *cobra.Command, s *store.Store, query string, noExpand bool, strategy config.ExpansionStrategy, limit int) (string, []string) {
+	if noExpand || strategy == config.ExpansionNone {
+		return query, nil
+	}
+	if limit <= 0 {
+		limit = defaultExpansionTerms
+	}
+
+	var expanded store.ExpandedQuery
+	var err error
+	if strategy == "" {
+		expanded, err = s.ExpandQuery(cmd.Context(), query, limit)
+	} else {
+		expanded, err = s.ExpandQueryWithStrategy(cmd.Context(), query, limit, strategy)
+	}
+	if err != nil || len(expanded.Terms) == 0 {
+		return query, nil
+	}
+
+	return combineExpansionTerms(query, expanded.Terms), expanded.Terms
+}
+
+// combineExpansionTerms OR-combines terms onto query as an FTS5 query
+// string. query is parenthesized first: FTS5 binds AND tighter than OR
+// (NOT > AND > OR), so without parens "word1 word2 OR term1" means
+// "word1 AND (word2 OR term1)" -- only query's last token would get
+// loosened into the OR-set while its earlier tokens stayed mandatory.
+// Wrapping query makes it "(word1 AND word2) OR term1 OR term2" as
+// intended.
+func combineExpansionTerms(query string, terms []string) string {
+	combined := "(" + query + ")"
+	for _, term := range terms {
+		combined += " OR " + term
+	}
+	return combined
+}
+
+// parseExpandFlag parses the --expand flag into a config.ExpansionStrategy,
+// wrapped as an anel.Error naming the allowed values if it's set to
+// something other than none/synonyms/llm/all. An unset flag means "use
+// the configured default" and returns ("", nil).
+func parseExpandFlag(cmd *cobra.Command) (config.ExpansionStrategy, error) {
+	expandFlag, _ := cmd.Flags().GetString("expand")
+	if expandFlag == "" {
+		return "", nil
+	}
+	switch strategy := config.ExpansionStrategy(expandFlag); strategy {
+	case config.ExpansionNone, config.ExpansionSynonyms, config.ExpansionLLM, config.ExpansionAll:
+		return strategy, nil
+	default:
+		return "", anel.Newf(anel.ErrorCodeInvalidInput, "invalid_expand_strategy",
+			"unknown --expand strategy %q", expandFlag).
+			WithHint("allowed values: none, synonyms, llm, all")
+	}
+}
+
+// parseSortFlag parses the --sort flag (a comma-separated list such as
+// "-score,collection,title") into []store.SortKey, wrapped as an
+// anel.Error naming the allowed fields if it references one outside
+// store.SortableFields.
+func parseSortFlag(cmd *cobra.Command) ([]store.SortKey, error) {
+	sortFlag, _ := cmd.Flags().GetString("sort")
+	keys, err := store.ParseSortSpec(sortFlag)
+	if err != nil {
+		return nil, anel.Wrap(anel.ErrorCodeInvalidInput, "invalid_sort_spec", err).
+			WithDetail("sort", sortFlag).
+			WithHint("allowed fields: " + strings.Join(store.SortableFields, ", "))
+	}
+	return keys, nil
+}
+
+// isStreamingFormat reports whether format should drive a search command
+// down its *SearchStream path instead of collecting a full []SearchResult
+// first. Both ndjson and files print one line per result with nothing
+// that depends on the total count (no "Found N results:" header, no CSV
+// column alignment), so there's no reason to hold the whole formatted
+// output in memory before a piped consumer like `head` can start reading
+// it. This only avoids buffering the formatted output -- BM25Search/
+// VectorSearch/HybridSearch still rank the entire result set
+// synchronously before the first result reaches the stream, so `| head`
+// doesn't avoid paying for a full search (see search_stream.go).
+func isStreamingFormat(format string) bool {
+	return format == "ndjson" || format == "files"
+}
+
+// streamSearch opens a store.QuerySession for query and prints each
+// result as it arrives, instead of waiting for a fully ranked slice. It
+// cancels the session when the command's context is done (Ctrl-C) and
+// reports any error the session accumulated once Results() closes.
+func streamSearch(cmd *cobra.Command, s *store.Store, query string, opts store.SearchOptions) {
+	session, err := s.OpenSession(cmd.Context(), query, opts)
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error opening session: %v\n", err)
+		return
+	}
+	defer session.Close()
+
+	for result := range session.Results() {
+		printResult(result, outputFormat)
+	}
+
+	if err := session.Err(); err != nil {
+		fmt.Fprintf(cmd.OutOrStderr(), "Error streaming results: %v\n", err)
+	}
+}
+
 func init() {
-	for _, cmd := range []*cobra.Command{searchCmd, vsearchCmd, queryCmd} {
+	for _, cmd := range []*cobra.Command{searchCmd, vsearchCmd, queryCmd, hsearchCmd} {
 		cmd.Flags().StringP("collection", "c", "", "Collection name")
 		cmd.Flags().Bool("all", false, "Search all collections")
 	}
+
+	for _, cmd := range []*cobra.Command{searchCmd, vsearchCmd, queryCmd} {
+		cmd.Flags().String("sort", "", "Sort results by comma-separated fields, e.g. -score,collection,title (- prefix = descending; allowed: "+strings.Join(store.SortableFields, ", ")+")")
+	}
+
+	for _, cmd := range []*cobra.Command{searchCmd, hsearchCmd} {
+		cmd.Flags().Bool("no-expand", false, "Disable query expansion")
+		cmd.Flags().String("expand", "", "Expansion strategy: none|synonyms|llm|all (default: config-driven)")
+		cmd.Flags().Int("expand-limit", 0, fmt.Sprintf("Max expansion terms to OR into the query (default: %d)", defaultExpansionTerms))
+		cmd.Flags().Bool("expand-dry-run", false, "Print the expanded query without executing the search")
+	}
+
+	searchCmd.Flags().Bool("stream", false, "Stream results per-collection as they arrive instead of waiting for the full ranked list")
+	searchCmd.Flags().Bool("analyze", false, "Print the query's FTS5 token stream instead of searching, for debugging a collection's analyzer config")
+
+	hsearchCmd.Flags().Float32("semantic-ratio", 0.5, "Weight of vector results in fusion: 0.0 = pure BM25, 1.0 = pure vector")
+	hsearchCmd.Flags().Int("k", store.DefaultRRFK, "Reciprocal Rank Fusion constant")
+
+	queryCmd.Flags().String("fusion", string(store.FusionRRF), fmt.Sprintf("Fusion strategy combining BM25 and vector results: %s", fusionNamesHelp()))
+	queryCmd.Flags().Float32("fusion-alpha", 0, "Weight of BM25 vs. vector results for --fusion=convex (0=pure vector, 1=pure BM25; default: the collection's configured fusion_alpha, or 0.5)")
+}
+
+// fusionNamesHelp renders store.FusionNames as a "|"-joined flag help
+// string, e.g. "rrf|weighted_sum|zscore_sum|convex|borda".
+func fusionNamesHelp() string {
+	names := make([]string, len(store.FusionNames))
+	for i, n := range store.FusionNames {
+		names[i] = string(n)
+	}
+	return strings.Join(names, "|")
 }
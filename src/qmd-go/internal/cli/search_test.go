@@ -0,0 +1,19 @@
+package cli
+
+import "testing"
+
+func TestCombineExpansionTerms_ParenthesizesMultiWordQuery(t *testing.T) {
+	got := combineExpansionTerms("word1 word2", []string{"term1", "term2"})
+	want := "(word1 word2) OR term1 OR term2"
+	if got != want {
+		t.Errorf("combineExpansionTerms() = %q, want %q", got, want)
+	}
+}
+
+func TestCombineExpansionTerms_NoTerms(t *testing.T) {
+	got := combineExpansionTerms("word1 word2", nil)
+	want := "(word1 word2)"
+	if got != want {
+		t.Errorf("combineExpansionTerms() = %q, want %q", got, want)
+	}
+}
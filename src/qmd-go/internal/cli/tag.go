@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/qmd/qmd-go/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage document tags",
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add <path> <tag...>",
+	Short: "Attach tags to a document",
+	Args:  cobra.MinimumNArgs(2),
+	Run:   runTagAdd,
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:   "rm <path> <tag...>",
+	Short: "Detach tags from a document",
+	Args:  cobra.MinimumNArgs(2),
+	Run:   runTagRemove,
+}
+
+var tagListCmd = &cobra.Command{
+	Use:   "ls [path]",
+	Short: "List a collection's tags, or one document's tags when path is given",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runTagList,
+}
+
+var tagSearchCmd = &cobra.Command{
+	Use:   "search <tag-expr>",
+	Short: `Search by a boolean tag expression, e.g. "golang AND (tutorial OR reference) AND NOT deprecated"`,
+	Args:  cobra.ExactArgs(1),
+	Run:   runTagSearch,
+}
+
+var tagAutoCmd = &cobra.Command{
+	Use:   "auto <path>",
+	Short: "Suggest tags for a document from its title and content, via the configured LLM",
+	Args:  cobra.ExactArgs(1),
+	Run:   runTagAuto,
+}
+
+func runTagAdd(cmd *cobra.Command, args []string) {
+	collection, _ := cmd.Flags().GetString("collection")
+	path, tags := args[0], args[1:]
+
+	s, err := LoadStore()
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error loading store", err, outputFormat)
+		return
+	}
+
+	if err := s.AddTags(collection, path, tags); err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error adding tags", err, outputFormat)
+		return
+	}
+
+	fmt.Printf("Tagged %s: %v\n", path, tags)
+}
+
+func runTagRemove(cmd *cobra.Command, args []string) {
+	collection, _ := cmd.Flags().GetString("collection")
+	path, tags := args[0], args[1:]
+
+	s, err := LoadStore()
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error loading store", err, outputFormat)
+		return
+	}
+
+	if err := s.RemoveTags(collection, path, tags); err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error removing tags", err, outputFormat)
+		return
+	}
+
+	fmt.Printf("Untagged %s: %v\n", path, tags)
+}
+
+func runTagList(cmd *cobra.Command, args []string) {
+	collection, _ := cmd.Flags().GetString("collection")
+
+	s, err := LoadStore()
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error loading store", err, outputFormat)
+		return
+	}
+
+	if len(args) == 1 {
+		tags, err := s.DocumentTags(collection, args[0])
+		if err != nil {
+			renderCLIError(cmd.OutOrStderr(), "Error listing tags", err, outputFormat)
+			return
+		}
+		if len(tags) == 0 {
+			fmt.Println("(no tags)")
+			return
+		}
+		for _, t := range tags {
+			fmt.Println(t)
+		}
+		return
+	}
+
+	counts, err := s.ListTags(collection)
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error listing tags", err, outputFormat)
+		return
+	}
+	if len(counts) == 0 {
+		fmt.Println("(no tags)")
+		return
+	}
+	for _, tc := range counts {
+		fmt.Printf("%s (%d)\n", tc.Name, tc.Count)
+	}
+}
+
+func runTagSearch(cmd *cobra.Command, args []string) {
+	collection, _ := cmd.Flags().GetString("collection")
+	all, _ := cmd.Flags().GetBool("all")
+
+	s, err := LoadStore()
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error loading store", err, outputFormat)
+		return
+	}
+
+	results, err := s.TagSearch(store.SearchOptions{
+		Limit:      limit,
+		Collection: collection,
+		SearchAll:  all,
+		TagExpr:    args[0],
+	})
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error searching", err, outputFormat)
+		return
+	}
+
+	printResults(results, outputFormat)
+}
+
+func runTagAuto(cmd *cobra.Command, args []string) {
+	collection, _ := cmd.Flags().GetString("collection")
+	n, _ := cmd.Flags().GetInt("count")
+	path := args[0]
+
+	s, err := LoadStore()
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error loading store", err, outputFormat)
+		return
+	}
+
+	suggestions, err := s.SuggestTags(cmd.Context(), collection, path, n)
+	if err != nil {
+		renderCLIError(cmd.OutOrStderr(), "Error suggesting tags", err, outputFormat)
+		return
+	}
+	if len(suggestions) == 0 {
+		fmt.Println("(no suggestions)")
+		return
+	}
+
+	for _, suggestion := range suggestions {
+		fmt.Printf("%s (confidence %.2f)\n", suggestion.Tag, suggestion.Confidence)
+	}
+	fmt.Println("\nAccept with: qmd tag add " + path + " <tag>")
+}
+
+func init() {
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRemoveCmd)
+	tagCmd.AddCommand(tagListCmd)
+	tagCmd.AddCommand(tagSearchCmd)
+	tagCmd.AddCommand(tagAutoCmd)
+
+	tagCmd.PersistentFlags().StringP("collection", "c", "", "Collection name")
+	tagSearchCmd.Flags().Bool("all", false, "Search all collections")
+	tagAutoCmd.Flags().Int("count", 5, "Max number of tags to suggest")
+}
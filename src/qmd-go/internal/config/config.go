@@ -1,8 +1,12 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"gopkg.in/yaml.v3"
 )
@@ -16,8 +20,20 @@ const (
 type BM25Backend string
 
 const (
-	BM25BackendSqliteFTS5 BM25Backend = "sqlite_fts5"
-	BM25BackendLanceDB    BM25Backend = "lancedb"
+	BM25BackendSqliteFTS5    BM25Backend = "sqlite_fts5"
+	BM25BackendLanceDB       BM25Backend = "lancedb"
+	BM25BackendBleve         BM25Backend = "bleve"
+	BM25BackendElasticsearch BM25Backend = "elasticsearch"
+	BM25BackendMeilisearch   BM25Backend = "meilisearch"
+	// BM25BackendTrigram is a substring/regex-oriented index over
+	// trigram posting lists, selected directly by the `qmd code`
+	// command rather than through bm25.backend -- see
+	// indexer.openTrigram's doc comment.
+	BM25BackendTrigram BM25Backend = "trigram"
+	// BM25BackendMemory is a process-local, non-persistent index with no
+	// backing file or service, selected in tests that want a real
+	// indexer.Indexer without sqlite or a network dependency.
+	BM25BackendMemory BM25Backend = "memory"
 )
 
 // VectorBackend type
@@ -26,6 +42,57 @@ type VectorBackend string
 const (
 	VectorBackendQmdBuiltin VectorBackend = "qmd_builtin"
 	VectorBackendLanceDB    VectorBackend = "lancedb"
+	VectorBackendQdrant     VectorBackend = "qdrant"
+	VectorBackendMilvus     VectorBackend = "milvus"
+	// VectorBackendMemory is a process-local, non-persistent vector
+	// index, the VectorIndexer counterpart of BM25BackendMemory.
+	VectorBackendMemory VectorBackend = "memory"
+)
+
+// VectorIndex selects the in-process retrieval structure the
+// qmd_builtin vector backend searches with. It has no effect on the
+// lancedb/qdrant backends, which always use their own ANN index.
+type VectorIndex string
+
+const (
+	// VectorIndexFlat scans every row and ranks by the backend's native
+	// distance operator (sqlite-vec's vec0 extension). Exact, but scans
+	// linearly in collection size.
+	VectorIndexFlat VectorIndex = "flat"
+	// VectorIndexHNSW searches an on-disk HNSW graph instead, trading
+	// exactness for sub-linear search time on large collections.
+	VectorIndexHNSW VectorIndex = "hnsw"
+)
+
+// VectorQuantization selects how a vector's components are stored and
+// compared.
+type VectorQuantization string
+
+const (
+	// VectorQuantizationFloat32 stores each component as a full-precision
+	// float32, ranked by the backend's native distance operator.
+	VectorQuantizationFloat32 VectorQuantization = "float32"
+	// VectorQuantizationInt8 stores each component as a per-vector
+	// scale+zero-point int8, dequantizing on read.
+	VectorQuantizationInt8 VectorQuantization = "int8"
+	// VectorQuantizationBinary stores one sign bit per component, ranked
+	// by Hamming distance instead of cosine/L2.
+	VectorQuantizationBinary VectorQuantization = "binary"
+)
+
+// ExpansionStrategy selects which optional sources Store.ExpandQuery
+// layers on top of its always-on corpus-driven (PMI/embedding) expansion.
+type ExpansionStrategy string
+
+const (
+	// ExpansionNone disables expansion entirely, including corpus-driven.
+	ExpansionNone ExpansionStrategy = "none"
+	// ExpansionSynonyms adds terms from the configured synonyms file.
+	ExpansionSynonyms ExpansionStrategy = "synonyms"
+	// ExpansionLLM adds terms derived from models.query_expansion paraphrases.
+	ExpansionLLM ExpansionStrategy = "llm"
+	// ExpansionAll combines synonyms and LLM-derived terms.
+	ExpansionAll ExpansionStrategy = "all"
 )
 
 // CollectionConfig represents a collection configuration
@@ -34,17 +101,207 @@ type CollectionConfig struct {
 	Path        string  `yaml:"path"`
 	Pattern     *string `yaml:"pattern,omitempty"`
 	Description *string `yaml:"description,omitempty"`
+	// Embedders names which entries of Models.Embedders apply to this
+	// collection's documents, e.g. ["default"] for prose or ["code"] for
+	// a source-code collection. Empty means "default" only.
+	Embedders []string `yaml:"embedders,omitempty"`
+	// FusionAlpha is this collection's weight for store.FusionConvex's
+	// alpha*bm25 + (1-alpha)*vector combination, in [0,1]. Zero (the
+	// unset value) means "use store.DefaultConvexAlpha"; it only applies
+	// when SearchOptions.Fusion is convex and SearchOptions.ConvexAlpha
+	// wasn't set to override it for a single call.
+	FusionAlpha float32 `yaml:"fusion_alpha,omitempty"`
+	// Analyzer configures this collection's FTS5 tokenizer chain, e.g.
+	// {language: "ru"}. Nil means AnalyzerLanguageEnglish (the
+	// documents_fts table's long-standing "porter unicode61" default).
+	Analyzer *AnalyzerConfig `yaml:"analyzer,omitempty"`
+	// BM25 overrides Config.BM25.Backend for this collection alone, e.g.
+	// a source-code collection using BM25BackendTrigram while the rest
+	// of the config stays on sqlite_fts5. Nil means "use the root
+	// config's backend". See Config.ResolveCollection.
+	BM25 *BM25Backend `yaml:"bm25,omitempty"`
+	// Vector overrides Config.Vector's backend/model for this collection
+	// alone. Nil means "use the root config's vector settings". See
+	// Config.ResolveCollection.
+	Vector *VectorBackendConfig `yaml:"vector,omitempty"`
+	// Models overrides Config.Models for this collection alone, e.g. a
+	// collection embedded with a different model than the rest. Nil
+	// means "use the root config's models". See Config.ResolveCollection.
+	Models *ModelsConfig `yaml:"models,omitempty"`
+	// Chunking configures how this collection's documents are split into
+	// chunks before embedding. Nil means no splitting (today's behavior,
+	// one embedding per document) -- not yet read by the indexing
+	// pipeline, plumbed through config ahead of that work landing.
+	Chunking *ChunkingConfig `yaml:"chunking,omitempty"`
+}
+
+// VectorBackendConfig is CollectionConfig.Vector's override: the vector
+// backend, model, and sizing a specific collection should use instead of
+// the root Config.Vector. Qdrant/Milvus connection details aren't
+// repeated here -- a collection needing a different backend still
+// reaches it through the root config's endpoint, just a different
+// collection/index name within it.
+type VectorBackendConfig struct {
+	Backend    VectorBackend `yaml:"backend,omitempty"`
+	Model      string        `yaml:"model,omitempty"`
+	VectorSize int           `yaml:"vector_size,omitempty"`
+	Index      VectorIndex   `yaml:"index,omitempty"`
+}
+
+// ChunkingSplitter selects how a collection's documents are divided into
+// chunks before embedding.
+type ChunkingSplitter string
+
+const (
+	// ChunkingSplitterFixed splits on a fixed character/token count.
+	ChunkingSplitterFixed ChunkingSplitter = "fixed"
+	// ChunkingSplitterSentence splits on sentence boundaries, packing as
+	// many as fit under ChunkSize.
+	ChunkingSplitterSentence ChunkingSplitter = "sentence"
+	// ChunkingSplitterMarkdown splits on Markdown heading/section
+	// boundaries, for collections of structured docs.
+	ChunkingSplitterMarkdown ChunkingSplitter = "markdown"
+)
+
+// ChunkingConfig tunes how a collection's documents are split into
+// chunks before embedding. Zero values mean "use the indexer's built-in
+// default" rather than a literal zero-size chunk.
+type ChunkingConfig struct {
+	ChunkSize    int              `yaml:"chunk_size,omitempty"`
+	ChunkOverlap int              `yaml:"chunk_overlap,omitempty"`
+	Splitter     ChunkingSplitter `yaml:"splitter,omitempty"`
+}
+
+// AnalyzerLanguage selects one of store's built-in FTS5 tokenizer
+// presets for AnalyzerConfig.Language.
+type AnalyzerLanguage string
+
+const (
+	AnalyzerLanguageEnglish AnalyzerLanguage = "en"
+	AnalyzerLanguageRussian AnalyzerLanguage = "ru"
+	AnalyzerLanguageChinese AnalyzerLanguage = "zh"
+	AnalyzerLanguageGerman  AnalyzerLanguage = "de"
+	AnalyzerLanguageFrench  AnalyzerLanguage = "fr"
+	AnalyzerLanguageSpanish AnalyzerLanguage = "es"
+)
+
+// AnalyzerConfig selects a collection's FTS5 tokenizer chain. Language
+// picks one of store's built-in presets (see store.FTS5TokenizeClause);
+// Tokenizer overrides the preset's base tokenizer entirely (any FTS5
+// built-in, e.g. "trigram" or "ascii") for collections a preset doesn't
+// fit. Stemmer and Stopwords are accepted for forward compatibility
+// with a real snowball-stemming tokenizer but aren't applied yet --
+// stock FTS5 only ships the English porter stemmer, so every non-English
+// preset today is unicode61 plus diacritics removal (and Chinese is the
+// trigram tokenizer), not true stemming. See store.FTS5TokenizeClause's
+// doc comment for the honest story.
+type AnalyzerConfig struct {
+	Language  AnalyzerLanguage `yaml:"language,omitempty"`
+	Stemmer   string           `yaml:"stemmer,omitempty"`
+	Stopwords bool             `yaml:"stopwords,omitempty"`
+	Tokenizer string           `yaml:"tokenizer,omitempty"`
 }
 
 // BM25Config represents BM25 backend configuration
 type BM25Config struct {
-	Backend BM25Backend `yaml:"backend"`
+	Backend       BM25Backend          `yaml:"backend"`
+	Elasticsearch *ElasticsearchConfig `yaml:"elasticsearch,omitempty"`
+	Meilisearch   *MeilisearchConfig   `yaml:"meilisearch,omitempty"`
+	LanceDB       *LanceDBConfig       `yaml:"lancedb,omitempty"`
 }
 
 // VectorConfig represents vector backend configuration
 type VectorConfig struct {
 	Backend VectorBackend `yaml:"backend"`
+	Index   VectorIndex   `yaml:"index,omitempty"`
 	Model   string        `yaml:"model"`
+	// VectorSize is the dimensionality of the vectors backends that
+	// manage their own ANN index (Qdrant, Milvus) store, when no
+	// per-embedder Dimensions override applies. It used to live on
+	// QdrantConfig alone; Milvus needs the same number for its
+	// FloatVector field, so it moved up to be backend-agnostic.
+	VectorSize int           `yaml:"vector_size,omitempty"`
+	Qdrant     QdrantConfig  `yaml:"qdrant,omitempty"`
+	Milvus     MilvusConfig  `yaml:"milvus,omitempty"`
+	LanceDB    LanceDBConfig `yaml:"lancedb,omitempty"`
+	// Quantization selects how the qmd_builtin backend's vec0 column
+	// stores vector components; see VectorQuantization. Not yet read by
+	// VectorSearchSQLite or indexer/sqlite_vec.go, which always store
+	// float32 regardless of this setting — it's plumbed through config
+	// ahead of that scoring/storage work landing.
+	Quantization VectorQuantization `yaml:"quantization,omitempty"`
+	// HNSW tunes the qmd_builtin_hnsw index (see Index). Zero-valued
+	// fields fall back to indexer's built-in defaults.
+	HNSW HNSWConfig `yaml:"hnsw,omitempty"`
+}
+
+// HNSWConfig tunes the HNSW vector index's graph construction and
+// search, following Malkov & Yashunin's parameter names. A zero value
+// for any field means "use indexer's built-in default" rather than 0
+// itself, since 0 isn't a meaningful graph degree or search width.
+type HNSWConfig struct {
+	// M is the number of neighbors per node per layer above layer 0.
+	// Layer 0 uses 2*M. Higher M improves recall at the cost of graph
+	// size and build time.
+	M int `yaml:"m,omitempty"`
+	// EfConstruction is the candidate list size used while building the
+	// graph. Higher values produce a better-connected graph at the cost
+	// of slower indexing.
+	EfConstruction int `yaml:"ef_construction,omitempty"`
+	// EfSearch is the candidate list size used while searching, when it
+	// exceeds the requested result limit. Higher values improve recall
+	// at the cost of slower queries.
+	EfSearch int `yaml:"ef_search,omitempty"`
+	// Metric selects the distance function: "cosine" (default) or "dot".
+	// Dot product is cheaper and is the right choice for embedders that
+	// already L2-normalize their output, where it's equivalent to cosine.
+	Metric string `yaml:"metric,omitempty"`
+}
+
+// QdrantConfig configures the Qdrant vector backend.
+type QdrantConfig struct {
+	URL        string `yaml:"url,omitempty"`
+	APIKey     string `yaml:"api_key,omitempty"`
+	Collection string `yaml:"collection,omitempty"`
+}
+
+// MilvusConfig configures the Milvus vector backend.
+type MilvusConfig struct {
+	Address    string `yaml:"address,omitempty"`
+	Username   string `yaml:"username,omitempty"`
+	Password   string `yaml:"password,omitempty"`
+	Database   string `yaml:"database,omitempty"`
+	Collection string `yaml:"collection,omitempty"`
+	// MetricType is Milvus's distance metric name, e.g. "COSINE", "L2",
+	// "IP".
+	MetricType string `yaml:"metric_type,omitempty"`
+	// IndexType is Milvus's ANN index algorithm, e.g. "HNSW", "IVF_FLAT".
+	IndexType string `yaml:"index_type,omitempty"`
+	// IndexParams are algorithm-specific tuning knobs (e.g. HNSW's "M"
+	// and "efConstruction"), passed through to Milvus verbatim.
+	IndexParams map[string]string `yaml:"index_params,omitempty"`
+}
+
+// LanceDBConfig configures the LanceDB BM25/vector backend. URI is a
+// local directory path or an object-store URI (e.g. "s3://bucket/db"),
+// matching how a LanceDB dataset is addressed whether it's opened
+// in-process or through LanceDB Cloud; Table is the table name within
+// it, one per collection by convention.
+type LanceDBConfig struct {
+	URI   string `yaml:"uri,omitempty"`
+	Table string `yaml:"table,omitempty"`
+}
+
+// ElasticsearchConfig configures the Elasticsearch BM25 backend.
+type ElasticsearchConfig struct {
+	URL    string `yaml:"url,omitempty"`
+	APIKey string `yaml:"api_key,omitempty"`
+}
+
+// MeilisearchConfig configures the Meilisearch BM25 backend.
+type MeilisearchConfig struct {
+	URL    string `yaml:"url,omitempty"`
+	APIKey string `yaml:"api_key,omitempty"`
 }
 
 // LLMModelConfig represents LLM model configuration
@@ -58,17 +315,149 @@ type ModelsConfig struct {
 	Embed          *LLMModelConfig `yaml:"embed,omitempty"`
 	Rerank         *LLMModelConfig `yaml:"rerank,omitempty"`
 	QueryExpansion *LLMModelConfig `yaml:"query_expansion,omitempty"`
+	// Embedders declares named embedding pipelines, mirroring
+	// Meilisearch's named embedders: a "default" entry for prose and,
+	// say, a "code" entry tuned for source files. CollectionConfig.Embedders
+	// selects which of these apply to a given collection's documents.
+	Embedders map[string]EmbedderConfig `yaml:"embedders,omitempty"`
+}
+
+// EmbedderConfig is one named entry of ModelsConfig.Embedders: which
+// model produces the vectors, its dimensionality, and the Qdrant named
+// vector they're stored under (defaults to the embedder's name).
+type EmbedderConfig struct {
+	LLMModelConfig `yaml:",inline"`
+	Dimensions     int    `yaml:"dimensions,omitempty"`
+	VectorName     string `yaml:"vector_name,omitempty"`
+}
+
+// ExpansionConfig configures Store.ExpandQuery's pluggable strategies.
+type ExpansionConfig struct {
+	Strategy ExpansionStrategy `yaml:"strategy,omitempty"`
+	// SynonymsPath is a YAML file of term -> []synonym, consulted by the
+	// synonyms strategy. Supports a leading "~/" for the user's home dir.
+	SynonymsPath string `yaml:"synonyms_path,omitempty"`
+	// LLMMaxTerms caps how many paraphrase-derived terms the llm strategy
+	// contributes per query, independent of the caller's overall limit.
+	LLMMaxTerms int `yaml:"llm_max_terms,omitempty"`
+	// LLMCacheTTLSeconds is how long a query's LLM-generated terms are
+	// cached before being regenerated.
+	LLMCacheTTLSeconds int `yaml:"llm_cache_ttl_seconds,omitempty"`
+}
+
+// RouterConfig configures router.Router's LLM-driven intent
+// classification and query-variant fan-out, keyed off the same
+// models.query_expansion model ExpandQueryWithStrategy's llm strategy
+// uses.
+type RouterConfig struct {
+	// Variants caps how many rewritten/expanded query variants
+	// ClassifyQuery asks the model for, and router.Router.Route runs
+	// against the selected backend(s). Defaults to 3 when unset.
+	Variants int `yaml:"variants,omitempty"`
+	// CacheTTLSeconds is how long a query's classification (intent,
+	// variants, entities) is cached in the llm_cache table before being
+	// regenerated. Defaults to 24h when unset.
+	CacheTTLSeconds int `yaml:"cache_ttl_seconds,omitempty"`
+	// Rerank reranks Route's fused results with models.rerank before
+	// returning, when a rerank model is configured.
+	Rerank bool `yaml:"rerank,omitempty"`
+	// RerankTopN caps how many of the fused results are sent to
+	// models.rerank. Defaults to 20 when unset.
+	RerankTopN int `yaml:"rerank_top_n,omitempty"`
+}
+
+// AuditSinkKind selects which mcp.AuditSink(s) a server's StreamTap
+// writes NDJSON records to.
+type AuditSinkKind string
+
+const (
+	// AuditSinkStderr writes records to stderr, StreamTap's original and
+	// still-default behavior.
+	AuditSinkStderr AuditSinkKind = "stderr"
+	// AuditSinkFile writes records to AuditConfig.Path, rotating by size
+	// and/or age and gzipping rotated segments.
+	AuditSinkFile AuditSinkKind = "file"
+	// AuditSinkMulti fans records out to both stderr and the file sink,
+	// so an operator gets the file's durability without losing the
+	// stderr copy local tooling already tails.
+	AuditSinkMulti AuditSinkKind = "multi"
+)
+
+// AuditConfig configures where a Server's StreamTap writes AuditRecords.
+// It's overridden at runtime by the AGENT_AUDIT_SINK env var (see
+// mcp.NewAuditSink), so a deployment can redirect audit output without a
+// config edit.
+type AuditConfig struct {
+	Sink AuditSinkKind `yaml:"sink,omitempty"`
+	// Path is the rotated log file's path, used when Sink is file or
+	// multi. Required in that case; ignored otherwise.
+	Path string `yaml:"path,omitempty"`
+	// MaxSizeMB rotates the active file once it reaches this size.
+	// Zero disables size-based rotation.
+	MaxSizeMB int `yaml:"max_size_mb,omitempty"`
+	// MaxAgeHours rotates the active file once it's been open this long,
+	// regardless of size. Zero disables age-based rotation.
+	MaxAgeHours int `yaml:"max_age_hours,omitempty"`
+	// Retention caps how many gzipped rotated segments are kept before
+	// the oldest is deleted. Defaults to 5 when unset.
+	Retention int `yaml:"retention,omitempty"`
+}
+
+// LlamaServerConfig describes how to reach, or how to launch, the local
+// llama-server process used for embeddings and reranking. Leaving
+// BinPath empty means the router assumes a server is already running at
+// URL and never tries to supervise one itself.
+type LlamaServerConfig struct {
+	URL     string   `yaml:"url,omitempty"`
+	BinPath string   `yaml:"bin_path,omitempty"`
+	Args    []string `yaml:"args,omitempty"`
 }
 
 // Config represents the main configuration
 type Config struct {
-	BM25        BM25Config        `yaml:"bm25"`
-	Vector      VectorConfig      `yaml:"vector"`
+	BM25        BM25Config         `yaml:"bm25"`
+	Vector      VectorConfig       `yaml:"vector"`
 	Collections []CollectionConfig `yaml:"collections"`
-	Models      ModelsConfig      `yaml:"models"`
-	CachePath   string            `yaml:"cache_path"`
+	Models      ModelsConfig       `yaml:"models"`
+	CachePath   string             `yaml:"cache_path"`
+	LlamaServer *LlamaServerConfig `yaml:"llama_server,omitempty"`
+	Expansion   ExpansionConfig    `yaml:"expansion,omitempty"`
+	Router      RouterConfig       `yaml:"router,omitempty"`
+	Audit       AuditConfig        `yaml:"audit,omitempty"`
+	// Profiles holds named overlays applied on top of the config above
+	// them by ResolveProfile, e.g. a "local" entry using sqlite_fts5 +
+	// qmd_builtin alongside a "prod" entry using lancedb + a remote
+	// rerank model, in the same file. Each profile is itself a Config;
+	// its zero-valued fields inherit from the root config (Collections
+	// merge by Name instead), so a profile only needs to declare what it
+	// overrides. A profile's own Profiles/ActiveProfile fields are
+	// ignored -- profiles don't nest.
+	Profiles map[string]*Config `yaml:"profiles,omitempty"`
+	// ActiveProfile names the Profiles entry Load resolves onto the root
+	// config by default, persisted by UseProfile (followed by Save).
+	// EnvProfile, or an explicit ResolveProfile argument such as a CLI
+	// --profile flag, takes precedence over this when set.
+	ActiveProfile string `yaml:"active_profile,omitempty"`
+	// SchemaVersion records which shape of index.yaml this file was last
+	// migrated to by Migrate. Zero (the field absent) means "never
+	// migrated" -- every migrator from schema_version 0 onward runs.
+	// LoadConfigFromData always runs Migrate, but doesn't write the
+	// result back; a caller that wants the upgrade to stick (so the next
+	// load skips migrators that already ran) should follow up with Save.
+	SchemaVersion int `yaml:"schema_version,omitempty"`
 }
 
+// CurrentSchemaVersion is the schema_version Migrate brings a Config up
+// to. Bump it, and add a migrator to the migrators chain, whenever a
+// change to index.yaml's shape needs an automatic upgrade path for files
+// written against an older version.
+const CurrentSchemaVersion = 1
+
+// EnvProfile overrides which Profiles entry ResolveProfile selects,
+// taking precedence over Config.ActiveProfile but not over an explicit
+// ResolveProfile argument (e.g. a CLI --profile flag).
+const EnvProfile = "QMD_PROFILE"
+
 // DefaultConfig returns default configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -76,12 +465,39 @@ func DefaultConfig() *Config {
 			Backend: BM25BackendSqliteFTS5,
 		},
 		Vector: VectorConfig{
-			Backend: VectorBackendQmdBuiltin,
-			Model:   "embeddinggemma-300M",
+			Backend:      VectorBackendQmdBuiltin,
+			Model:        "embeddinggemma-300M",
+			VectorSize:   384,
+			Quantization: VectorQuantizationFloat32,
+			Qdrant: QdrantConfig{
+				URL:        "http://localhost:6333",
+				Collection: "qmd_documents",
+			},
+			Milvus: MilvusConfig{
+				Address:    "localhost:19530",
+				Collection: "qmd_documents",
+				MetricType: "COSINE",
+				IndexType:  "HNSW",
+				IndexParams: map[string]string{
+					"M":              "16",
+					"efConstruction": "200",
+				},
+			},
 		},
 		Collections: []CollectionConfig{},
 		Models:      ModelsConfig{},
 		CachePath:   DefaultCachePath,
+		Expansion: ExpansionConfig{
+			Strategy:           ExpansionAll,
+			SynonymsPath:       "~/.config/qmd/synonyms.yaml",
+			LLMMaxTerms:        3,
+			LLMCacheTTLSeconds: 86400,
+		},
+		Router: RouterConfig{
+			Variants:        3,
+			CacheTTLSeconds: 86400,
+			RerankTopN:      20,
+		},
 	}
 }
 
@@ -90,6 +506,13 @@ func LoadConfig() (*Config, error) {
 	return LoadConfigFromFile(expandPath(DefaultConfigPath))
 }
 
+// DefaultConfigFilePath returns the expanded on-disk path LoadConfig
+// reads, for callers (e.g. mcp.Server's config watcher) that need to
+// know where the file actually lives rather than just reading it once.
+func DefaultConfigFilePath() string {
+	return expandPath(DefaultConfigPath)
+}
+
 // LoadConfigFromFile loads configuration from a specific file
 func LoadConfigFromFile(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -100,27 +523,63 @@ func LoadConfigFromFile(path string) (*Config, error) {
 		return nil, err
 	}
 
-	return LoadConfigFromData(data)
+	cfg, err := LoadConfigFromData(data)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.ResolveProfile(""), nil
 }
 
-// LoadConfigFromData loads configuration from byte data
+// LoadConfigFromData loads configuration from byte data, validating it
+// against Schema() and running it through Migrate before returning it.
 func LoadConfigFromData(data []byte) (*Config, error) {
+	if err := ValidateData(data); err != nil {
+		return nil, err
+	}
+
 	config := &Config{
 		BM25: BM25Config{
 			Backend: BM25BackendSqliteFTS5,
 		},
 		Vector: VectorConfig{
-			Backend: VectorBackendQmdBuiltin,
-			Model:   "embeddinggemma-300M",
+			Backend:      VectorBackendQmdBuiltin,
+			Model:        "embeddinggemma-300M",
+			VectorSize:   384,
+			Quantization: VectorQuantizationFloat32,
+			Qdrant: QdrantConfig{
+				URL:        "http://localhost:6333",
+				Collection: "qmd_documents",
+			},
+			Milvus: MilvusConfig{
+				Address:    "localhost:19530",
+				Collection: "qmd_documents",
+				MetricType: "COSINE",
+				IndexType:  "HNSW",
+				IndexParams: map[string]string{
+					"M":              "16",
+					"efConstruction": "200",
+				},
+			},
 		},
 		CachePath: DefaultCachePath,
+		Expansion: ExpansionConfig{
+			Strategy:           ExpansionAll,
+			SynonymsPath:       "~/.config/qmd/synonyms.yaml",
+			LLMMaxTerms:        3,
+			LLMCacheTTLSeconds: 86400,
+		},
+		Router: RouterConfig{
+			Variants:        3,
+			CacheTTLSeconds: 86400,
+			RerankTopN:      20,
+		},
 	}
 
 	if err := yaml.Unmarshal(data, config); err != nil {
 		return nil, err
 	}
 
-	return config, nil
+	return Migrate(config)
 }
 
 // Save saves configuration to file
@@ -139,11 +598,300 @@ func (c *Config) Save() error {
 	return os.WriteFile(path, data, 0644)
 }
 
+// Embedder looks up a named entry of Models.Embedders, falling back to
+// "default" when name is empty. ok is false when neither is configured.
+func (c *Config) Embedder(name string) (EmbedderConfig, bool) {
+	if name == "" {
+		name = "default"
+	}
+	cfg, ok := c.Models.Embedders[name]
+	return cfg, ok
+}
+
 // DBPath returns database path for a collection
 func (c *Config) DBPath(collection string) string {
 	return filepath.Join(c.CachePath, collection, "index.db")
 }
 
+// DBPathFor returns collection's effective sqlite db path. When
+// collection's CollectionConfig declares a BM25 or Vector override, the
+// path is namespaced under a short fingerprint of that override so
+// switching a collection between backends doesn't silently reuse -- and
+// corrupt -- the previous backend's on-disk index; a collection with no
+// override keeps DBPath's original layout, so this feature doesn't
+// invalidate every existing index just by existing.
+func (c *Config) DBPathFor(collection string) string {
+	col, ok := c.Collection(collection)
+	if !ok || (col.BM25 == nil && col.Vector == nil) {
+		return c.DBPath(collection)
+	}
+	return filepath.Join(c.CachePath, collection, backendFingerprint(col), "index.db")
+}
+
+// backendFingerprint returns a short, stable hash of col's BM25/Vector
+// backend override, for DBPathFor to namespace a collection's on-disk
+// index by.
+func backendFingerprint(col CollectionConfig) string {
+	bm25 := ""
+	if col.BM25 != nil {
+		bm25 = string(*col.BM25)
+	}
+	vector := ""
+	if col.Vector != nil {
+		vector = string(col.Vector.Backend)
+	}
+	sum := sha256.Sum256([]byte(bm25 + "|" + vector))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+// Collection looks up a CollectionConfig by name. ok is false when no
+// collection of that name is configured.
+func (c *Config) Collection(name string) (CollectionConfig, bool) {
+	for _, col := range c.Collections {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return CollectionConfig{}, false
+}
+
+// ListProfiles returns c.Profiles' names, sorted.
+func (c *Config) ListProfiles() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UseProfile records name as c's active profile, validating it exists in
+// c.Profiles first; an empty name clears it, reverting future
+// ResolveProfile calls to the root config. It's the caller's job to
+// persist the change with Save.
+func (c *Config) UseProfile(name string) error {
+	if name != "" {
+		if _, ok := c.Profiles[name]; !ok {
+			return fmt.Errorf("config: no such profile %q", name)
+		}
+	}
+	c.ActiveProfile = name
+	return nil
+}
+
+// SaveProfile snapshots c's current root-level settings (every field but
+// Profiles and ActiveProfile) into c.Profiles[name], creating or
+// replacing that entry. It's the caller's job to persist the change
+// with Save, same as UseProfile.
+func (c *Config) SaveProfile(name string) error {
+	if name == "" {
+		return fmt.Errorf("config: profile name is required")
+	}
+	snapshot := *c
+	snapshot.Profiles = nil
+	snapshot.ActiveProfile = ""
+	if c.Profiles == nil {
+		c.Profiles = map[string]*Config{}
+	}
+	c.Profiles[name] = &snapshot
+	return nil
+}
+
+// SelectedProfileName returns which profile ResolveProfile should merge
+// onto c's root settings: explicit (e.g. a CLI --profile flag) first,
+// then EnvProfile, then c.ActiveProfile (the file's own persisted
+// selection). Empty means "no profile, root config as-is".
+func (c *Config) SelectedProfileName(explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if env := os.Getenv(EnvProfile); env != "" {
+		return env
+	}
+	return c.ActiveProfile
+}
+
+// ResolveProfile returns the effective Config once c's selected profile
+// (see SelectedProfileName) is merged on top of c -- DefaultConfig ←
+// root config (c, already produced by LoadConfigFromData) ← profile. It
+// returns c unchanged if no profile is selected, or the selected name
+// isn't one of c.Profiles.
+func (c *Config) ResolveProfile(explicit string) *Config {
+	name := c.SelectedProfileName(explicit)
+	if name == "" {
+		return c
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return c
+	}
+	return mergeProfile(c, profile)
+}
+
+// mergeProfile overlays profile's explicitly-set fields onto a copy of
+// base, leaving base's value wherever profile's is the zero value.
+// Collections are merged by Name (mergeCollections) rather than replaced
+// wholesale, so a profile can extend the root config's base set instead
+// of re-declaring it.
+func mergeProfile(base, profile *Config) *Config {
+	merged := *base
+
+	if profile.BM25.Backend != "" {
+		merged.BM25 = profile.BM25
+	}
+	if profile.Vector.Backend != "" {
+		merged.Vector = profile.Vector
+	}
+	if profile.CachePath != "" {
+		merged.CachePath = profile.CachePath
+	}
+	if profile.LlamaServer != nil {
+		merged.LlamaServer = profile.LlamaServer
+	}
+	if profile.Expansion.Strategy != "" {
+		merged.Expansion = profile.Expansion
+	}
+	if profile.Router != (RouterConfig{}) {
+		merged.Router = profile.Router
+	}
+	if profile.Audit.Sink != "" {
+		merged.Audit = profile.Audit
+	}
+
+	merged.Models = mergeModels(base.Models, profile.Models)
+	merged.Collections = mergeCollections(base.Collections, profile.Collections)
+
+	return &merged
+}
+
+// mergeModels overlays profile's explicitly-set fields onto base,
+// merging Embedders by key instead of replacing the whole map.
+func mergeModels(base, profile ModelsConfig) ModelsConfig {
+	merged := base
+	if profile.Embed != nil {
+		merged.Embed = profile.Embed
+	}
+	if profile.Rerank != nil {
+		merged.Rerank = profile.Rerank
+	}
+	if profile.QueryExpansion != nil {
+		merged.QueryExpansion = profile.QueryExpansion
+	}
+	if len(profile.Embedders) > 0 {
+		merged.Embedders = make(map[string]EmbedderConfig, len(base.Embedders)+len(profile.Embedders))
+		for k, v := range base.Embedders {
+			merged.Embedders[k] = v
+		}
+		for k, v := range profile.Embedders {
+			merged.Embedders[k] = v
+		}
+	}
+	return merged
+}
+
+// mergeCollections extends base with overlay: an overlay entry whose
+// Name matches a base entry replaces it in place, and any other overlay
+// entry is appended, so a profile can add collections or override one
+// of the root config's without re-listing the rest.
+func mergeCollections(base, overlay []CollectionConfig) []CollectionConfig {
+	if len(overlay) == 0 {
+		return base
+	}
+	merged := make([]CollectionConfig, len(base))
+	copy(merged, base)
+	byName := make(map[string]int, len(merged))
+	for i, col := range merged {
+		byName[col.Name] = i
+	}
+	for _, col := range overlay {
+		if i, ok := byName[col.Name]; ok {
+			merged[i] = col
+		} else {
+			merged = append(merged, col)
+		}
+	}
+	return merged
+}
+
+// ResolveCollection returns a Config reflecting name's effective
+// settings: the root config with whichever of its CollectionConfig's
+// BM25/Vector/Models fields are set layered on top, the same
+// override-what-you-declare semantics ResolveProfile uses for profiles.
+// Returns c unchanged if name isn't configured or declares no overrides.
+func (c *Config) ResolveCollection(name string) *Config {
+	col, ok := c.Collection(name)
+	if !ok || (col.BM25 == nil && col.Vector == nil && col.Models == nil) {
+		return c
+	}
+
+	merged := *c
+	if col.BM25 != nil {
+		merged.BM25.Backend = *col.BM25
+	}
+	if col.Vector != nil {
+		merged.Vector.Backend = col.Vector.Backend
+		if col.Vector.Model != "" {
+			merged.Vector.Model = col.Vector.Model
+		}
+		if col.Vector.VectorSize != 0 {
+			merged.Vector.VectorSize = col.Vector.VectorSize
+		}
+		if col.Vector.Index != "" {
+			merged.Vector.Index = col.Vector.Index
+		}
+	}
+	if col.Models != nil {
+		merged.Models = mergeModels(c.Models, *col.Models)
+	}
+	return &merged
+}
+
+// Validate reports whether c is sound enough to swap in for a running
+// Store: every backend enum is one this binary recognizes, and every
+// collection has the Name/Path a CollectionConfig needs to be usable.
+// It doesn't reach out to any backend (no network calls, no opening
+// files) -- it's meant to catch a malformed hand-edit before it replaces
+// a working config, not to verify the backends it names are reachable.
+func (c *Config) Validate() error {
+	switch c.BM25.Backend {
+	case BM25BackendSqliteFTS5, BM25BackendLanceDB, BM25BackendBleve,
+		BM25BackendElasticsearch, BM25BackendMeilisearch, BM25BackendTrigram, BM25BackendMemory, "":
+	default:
+		return fmt.Errorf("config: unknown bm25.backend %q", c.BM25.Backend)
+	}
+
+	switch c.Vector.Backend {
+	case VectorBackendQmdBuiltin, VectorBackendLanceDB, VectorBackendQdrant, VectorBackendMilvus, VectorBackendMemory, "":
+	default:
+		return fmt.Errorf("config: unknown vector.backend %q", c.Vector.Backend)
+	}
+
+	switch c.Audit.Sink {
+	case AuditSinkStderr, AuditSinkFile, AuditSinkMulti, "":
+	default:
+		return fmt.Errorf("config: unknown audit.sink %q", c.Audit.Sink)
+	}
+	if (c.Audit.Sink == AuditSinkFile || c.Audit.Sink == AuditSinkMulti) && c.Audit.Path == "" {
+		return fmt.Errorf("config: audit.path required for audit.sink %q", c.Audit.Sink)
+	}
+
+	seen := make(map[string]bool, len(c.Collections))
+	for _, col := range c.Collections {
+		if col.Name == "" {
+			return fmt.Errorf("config: collection with empty name")
+		}
+		if col.Path == "" {
+			return fmt.Errorf("config: collection %q has empty path", col.Name)
+		}
+		if seen[col.Name] {
+			return fmt.Errorf("config: duplicate collection name %q", col.Name)
+		}
+		seen[col.Name] = true
+	}
+
+	return nil
+}
+
 func expandPath(path string) string {
 	if home, err := os.UserHomeDir(); err == nil {
 		if len(path) > 1 && path[:2] == "~/" {
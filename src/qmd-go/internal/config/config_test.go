@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -22,8 +23,20 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.Vector.Qdrant.Collection != "qmd_documents" {
 		t.Errorf("Qdrant.Collection = %s, want qmd_documents", cfg.Vector.Qdrant.Collection)
 	}
-	if cfg.Vector.Qdrant.VectorSize != 384 {
-		t.Errorf("Qdrant.VectorSize = %d, want 384", cfg.Vector.Qdrant.VectorSize)
+	if cfg.Vector.VectorSize != 384 {
+		t.Errorf("Vector.VectorSize = %d, want 384", cfg.Vector.VectorSize)
+	}
+	if cfg.Vector.Milvus.Address != "localhost:19530" {
+		t.Errorf("Milvus.Address = %s, want localhost:19530", cfg.Vector.Milvus.Address)
+	}
+	if cfg.Vector.Milvus.Collection != "qmd_documents" {
+		t.Errorf("Milvus.Collection = %s, want qmd_documents", cfg.Vector.Milvus.Collection)
+	}
+	if cfg.Vector.Milvus.MetricType != "COSINE" {
+		t.Errorf("Milvus.MetricType = %s, want COSINE", cfg.Vector.Milvus.MetricType)
+	}
+	if cfg.Vector.Quantization != VectorQuantizationFloat32 {
+		t.Errorf("Vector.Quantization = %s, want float32", cfg.Vector.Quantization)
 	}
 	if len(cfg.Collections) != 0 {
 		t.Errorf("Collections should be empty, got %d", len(cfg.Collections))
@@ -105,8 +118,65 @@ vector:
 	if cfg.Vector.Qdrant.APIKey != "secret" {
 		t.Errorf("Qdrant.APIKey not set correctly")
 	}
-	if cfg.Vector.Qdrant.VectorSize != 768 {
-		t.Errorf("Qdrant.VectorSize = %d, want 768", cfg.Vector.Qdrant.VectorSize)
+	if cfg.Vector.VectorSize != 768 {
+		t.Errorf("Vector.VectorSize = %d, want 768", cfg.Vector.VectorSize)
+	}
+}
+
+func TestLoadConfigFromData_MilvusBackend(t *testing.T) {
+	data := []byte(`
+vector:
+  backend: milvus
+  model: embeddinggemma-300M
+  vector_size: 768
+  milvus:
+    address: milvus:19530
+    username: admin
+    password: secret
+    database: qmd
+    collection: my_docs
+    metric_type: L2
+    index_type: IVF_FLAT
+    index_params:
+      nlist: "128"
+`)
+	cfg, err := LoadConfigFromData(data)
+	if err != nil {
+		t.Fatalf("LoadConfigFromData failed: %v", err)
+	}
+
+	if cfg.Vector.Backend != VectorBackendMilvus {
+		t.Errorf("Vector.Backend = %s, want milvus", cfg.Vector.Backend)
+	}
+	if cfg.Vector.VectorSize != 768 {
+		t.Errorf("Vector.VectorSize = %d, want 768", cfg.Vector.VectorSize)
+	}
+	if cfg.Vector.Milvus.Address != "milvus:19530" {
+		t.Errorf("Milvus.Address = %s, want milvus:19530", cfg.Vector.Milvus.Address)
+	}
+	if cfg.Vector.Milvus.Database != "qmd" {
+		t.Errorf("Milvus.Database = %s, want qmd", cfg.Vector.Milvus.Database)
+	}
+	if cfg.Vector.Milvus.IndexType != "IVF_FLAT" {
+		t.Errorf("Milvus.IndexType = %s, want IVF_FLAT", cfg.Vector.Milvus.IndexType)
+	}
+	if cfg.Vector.Milvus.IndexParams["nlist"] != "128" {
+		t.Errorf("Milvus.IndexParams[nlist] = %s, want 128", cfg.Vector.Milvus.IndexParams["nlist"])
+	}
+}
+
+func TestLoadConfigFromData_Quantization(t *testing.T) {
+	data := []byte(`
+vector:
+  quantization: int8
+`)
+	cfg, err := LoadConfigFromData(data)
+	if err != nil {
+		t.Fatalf("LoadConfigFromData failed: %v", err)
+	}
+
+	if cfg.Vector.Quantization != VectorQuantizationInt8 {
+		t.Errorf("Vector.Quantization = %s, want int8", cfg.Vector.Quantization)
 	}
 }
 
@@ -153,6 +223,26 @@ func TestLoadConfigFromFile_NonExistent(t *testing.T) {
 	}
 }
 
+func TestEmbedder(t *testing.T) {
+	local := "code-embed-model"
+	cfg := DefaultConfig()
+	cfg.Models.Embedders = map[string]EmbedderConfig{
+		"code": {LLMModelConfig: LLMModelConfig{Local: &local}, VectorName: "code"},
+	}
+
+	embedder, ok := cfg.Embedder("code")
+	if !ok {
+		t.Fatal("expected \"code\" embedder to be found")
+	}
+	if embedder.Local == nil || *embedder.Local != local {
+		t.Errorf("Embedder(\"code\").Local = %v, want %s", embedder.Local, local)
+	}
+
+	if _, ok := cfg.Embedder(""); ok {
+		t.Error("expected empty name to resolve to \"default\", which isn't configured")
+	}
+}
+
 func TestDBPath(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.CachePath = "/tmp/qmd-test"
@@ -179,4 +269,361 @@ func TestBackendConstants(t *testing.T) {
 	if VectorBackendQdrant != "qdrant" {
 		t.Errorf("VectorBackendQdrant = %s", VectorBackendQdrant)
 	}
+	if VectorBackendMilvus != "milvus" {
+		t.Errorf("VectorBackendMilvus = %s", VectorBackendMilvus)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("DefaultConfig().Validate() = %v, want nil", err)
+	}
+}
+
+func TestConfig_Validate_UnknownBackend(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.BM25.Backend = "not_a_real_backend"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() with an unknown bm25.backend should return an error")
+	}
+}
+
+func TestConfig_Validate_CollectionNeedsNameAndPath(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Collections = []CollectionConfig{{Name: "", Path: "/docs"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() with an empty collection name should return an error")
+	}
+
+	cfg.Collections = []CollectionConfig{{Name: "docs", Path: ""}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() with an empty collection path should return an error")
+	}
+}
+
+func TestConfig_Validate_DuplicateCollectionName(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Collections = []CollectionConfig{
+		{Name: "docs", Path: "/a"},
+		{Name: "docs", Path: "/b"},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() with duplicate collection names should return an error")
+	}
+}
+
+func TestConfig_Validate_AuditFileNeedsPath(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Audit.Sink = AuditSinkFile
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() with audit.sink file and no path should return an error")
+	}
+
+	cfg.Audit.Path = "/var/log/qmd-audit.ndjson"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with audit.sink file and a path = %v, want nil", err)
+	}
+}
+
+func TestConfig_Validate_UnknownAuditSink(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Audit.Sink = "not_a_real_sink"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() with an unknown audit.sink should return an error")
+	}
+}
+
+func TestLoadConfigFromData_WithProfiles(t *testing.T) {
+	data := []byte(`
+bm25:
+  backend: sqlite_fts5
+vector:
+  backend: qmd_builtin
+active_profile: prod
+profiles:
+  prod:
+    vector:
+      backend: lancedb
+    models:
+      rerank:
+        remote: rerank-large
+`)
+	cfg, err := LoadConfigFromData(data)
+	if err != nil {
+		t.Fatalf("LoadConfigFromData failed: %v", err)
+	}
+
+	// Raw parse: profiles aren't merged in yet.
+	if cfg.Vector.Backend != VectorBackendQmdBuiltin {
+		t.Errorf("Vector.Backend = %s, want qmd_builtin before ResolveProfile", cfg.Vector.Backend)
+	}
+	if len(cfg.Profiles) != 1 {
+		t.Fatalf("Profiles count = %d, want 1", len(cfg.Profiles))
+	}
+
+	resolved := cfg.ResolveProfile("")
+	if resolved.Vector.Backend != VectorBackendLanceDB {
+		t.Errorf("resolved Vector.Backend = %s, want lancedb", resolved.Vector.Backend)
+	}
+	if resolved.Models.Rerank == nil || resolved.Models.Rerank.Remote == nil || *resolved.Models.Rerank.Remote != "rerank-large" {
+		t.Errorf("resolved Models.Rerank = %+v, want remote=rerank-large", resolved.Models.Rerank)
+	}
+	// Unrelated root fields fall through unchanged.
+	if resolved.BM25.Backend != BM25BackendSqliteFTS5 {
+		t.Errorf("resolved BM25.Backend = %s, want sqlite_fts5", resolved.BM25.Backend)
+	}
+}
+
+func TestResolveProfile_ExplicitOverridesActiveProfile(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ActiveProfile = "local"
+	cfg.Profiles = map[string]*Config{
+		"local": {Vector: VectorConfig{Backend: VectorBackendQmdBuiltin}},
+		"prod":  {Vector: VectorConfig{Backend: VectorBackendMilvus}},
+	}
+
+	resolved := cfg.ResolveProfile("prod")
+	if resolved.Vector.Backend != VectorBackendMilvus {
+		t.Errorf("Vector.Backend = %s, want milvus (explicit profile should win)", resolved.Vector.Backend)
+	}
+}
+
+func TestResolveProfile_UnknownNameLeavesConfigUnchanged(t *testing.T) {
+	cfg := DefaultConfig()
+	resolved := cfg.ResolveProfile("does-not-exist")
+	if resolved != cfg {
+		t.Error("ResolveProfile with an unknown name should return cfg unchanged")
+	}
+}
+
+func TestConfig_MergeCollectionsExtendsByName(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Collections = []CollectionConfig{{Name: "notes", Path: "~/notes"}}
+	cfg.Profiles = map[string]*Config{
+		"prod": {Collections: []CollectionConfig{
+			{Name: "notes", Path: "/srv/notes"},
+			{Name: "archive", Path: "/srv/archive"},
+		}},
+	}
+
+	resolved := cfg.ResolveProfile("prod")
+	if len(resolved.Collections) != 2 {
+		t.Fatalf("Collections count = %d, want 2", len(resolved.Collections))
+	}
+	notes, ok := resolved.Collection("notes")
+	if !ok || notes.Path != "/srv/notes" {
+		t.Errorf("notes collection = %+v, want profile's path to win", notes)
+	}
+	if _, ok := resolved.Collection("archive"); !ok {
+		t.Error("profile-only collection 'archive' should be added, not dropped")
+	}
+}
+
+func TestConfig_ListProfiles(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Profiles = map[string]*Config{"b": {}, "a": {}}
+
+	names := cfg.ListProfiles()
+	if len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("ListProfiles() = %v, want sorted [a b]", names)
+	}
+}
+
+func TestConfig_UseProfile_RejectsUnknownName(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.UseProfile("nope"); err == nil {
+		t.Error("UseProfile with an undeclared name should return an error")
+	}
+}
+
+func TestConfig_UseProfile_EmptyNameClearsActiveProfile(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ActiveProfile = "prod"
+	if err := cfg.UseProfile(""); err != nil {
+		t.Fatalf("UseProfile(\"\") failed: %v", err)
+	}
+	if cfg.ActiveProfile != "" {
+		t.Errorf("ActiveProfile = %q, want empty", cfg.ActiveProfile)
+	}
+}
+
+func TestConfig_SaveProfile_SnapshotsCurrentSettings(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Vector.Backend = VectorBackendMilvus
+
+	if err := cfg.SaveProfile("prod"); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+
+	saved, ok := cfg.Profiles["prod"]
+	if !ok {
+		t.Fatal("SaveProfile did not add the profile")
+	}
+	if saved.Vector.Backend != VectorBackendMilvus {
+		t.Errorf("saved profile Vector.Backend = %s, want milvus", saved.Vector.Backend)
+	}
+	if saved.Profiles != nil || saved.ActiveProfile != "" {
+		t.Error("a saved profile snapshot should not carry its own Profiles/ActiveProfile")
+	}
+}
+
+func TestResolveCollection_NoOverrideReturnsConfigUnchanged(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Collections = []CollectionConfig{{Name: "notes", Path: "~/notes"}}
+
+	resolved := cfg.ResolveCollection("notes")
+	if resolved != cfg {
+		t.Error("ResolveCollection with no per-collection override should return cfg unchanged")
+	}
+}
+
+func TestResolveCollection_BM25Override(t *testing.T) {
+	cfg := DefaultConfig()
+	trigram := BM25BackendTrigram
+	cfg.Collections = []CollectionConfig{{Name: "code", Path: "~/code", BM25: &trigram}}
+
+	resolved := cfg.ResolveCollection("code")
+	if resolved.BM25.Backend != BM25BackendTrigram {
+		t.Errorf("BM25.Backend = %s, want trigram", resolved.BM25.Backend)
+	}
+	// Unrelated root fields fall through unchanged.
+	if resolved.Vector.Backend != VectorBackendQmdBuiltin {
+		t.Errorf("Vector.Backend = %s, want qmd_builtin", resolved.Vector.Backend)
+	}
+}
+
+func TestResolveCollection_VectorOverridePartialFields(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Collections = []CollectionConfig{{
+		Name: "prod-docs",
+		Path: "~/docs",
+		Vector: &VectorBackendConfig{
+			Backend: VectorBackendMilvus,
+			Model:   "remote-embed",
+		},
+	}}
+
+	resolved := cfg.ResolveCollection("prod-docs")
+	if resolved.Vector.Backend != VectorBackendMilvus {
+		t.Errorf("Vector.Backend = %s, want milvus", resolved.Vector.Backend)
+	}
+	if resolved.Vector.Model != "remote-embed" {
+		t.Errorf("Vector.Model = %s, want remote-embed", resolved.Vector.Model)
+	}
+	// VectorSize wasn't overridden, so the root default should survive.
+	if resolved.Vector.VectorSize != 384 {
+		t.Errorf("Vector.VectorSize = %d, want 384 (root default)", resolved.Vector.VectorSize)
+	}
+}
+
+func TestDBPathFor_NoOverrideMatchesDBPath(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Collections = []CollectionConfig{{Name: "notes", Path: "~/notes"}}
+
+	if got, want := cfg.DBPathFor("notes"), cfg.DBPath("notes"); got != want {
+		t.Errorf("DBPathFor(no override) = %s, want %s (DBPath)", got, want)
+	}
+}
+
+func TestDBPathFor_OverrideNamespacesThePath(t *testing.T) {
+	cfg := DefaultConfig()
+	trigram := BM25BackendTrigram
+	cfg.Collections = []CollectionConfig{{Name: "code", Path: "~/code", BM25: &trigram}}
+
+	got := cfg.DBPathFor("code")
+	if got == cfg.DBPath("code") {
+		t.Error("DBPathFor with a BM25 override should not collide with the unoverridden path")
+	}
+}
+
+func TestValidateData_ValidConfig(t *testing.T) {
+	data := []byte(`
+bm25:
+  backend: sqlite_fts5
+collections:
+  - name: notes
+    path: ~/notes
+`)
+	if err := ValidateData(data); err != nil {
+		t.Errorf("ValidateData failed on a valid config: %v", err)
+	}
+}
+
+func TestValidateData_Empty(t *testing.T) {
+	if err := ValidateData([]byte("")); err != nil {
+		t.Errorf("ValidateData failed on an empty file: %v", err)
+	}
+}
+
+func TestValidateData_UnknownBM25Backend(t *testing.T) {
+	data := []byte(`
+bm25:
+  backend: sqlite
+`)
+	err := ValidateData(data)
+	if err == nil {
+		t.Fatal("ValidateData should reject an unrecognized bm25.backend")
+	}
+	if !strings.Contains(err.Error(), "/bm25/backend") {
+		t.Errorf("error %q should name /bm25/backend", err.Error())
+	}
+}
+
+func TestValidateData_UnknownVectorBackend(t *testing.T) {
+	data := []byte(`
+vector:
+  backend: pinecone
+`)
+	err := ValidateData(data)
+	if err == nil {
+		t.Fatal("ValidateData should reject an unrecognized vector.backend")
+	}
+	if !strings.Contains(err.Error(), "/vector/backend") {
+		t.Errorf("error %q should name /vector/backend", err.Error())
+	}
+}
+
+func TestValidateData_CollectionMissingPath(t *testing.T) {
+	data := []byte(`
+collections:
+  - name: notes
+`)
+	if err := ValidateData(data); err == nil {
+		t.Error("ValidateData should reject a collection missing path")
+	}
+}
+
+func TestMigrate_BumpsSchemaVersionToCurrent(t *testing.T) {
+	cfg := DefaultConfig()
+
+	migrated, err := Migrate(cfg)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if migrated.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", migrated.SchemaVersion, CurrentSchemaVersion)
+	}
+}
+
+func TestMigrate_IsANoOpWhenAlreadyCurrent(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SchemaVersion = CurrentSchemaVersion
+
+	migrated, err := Migrate(cfg)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if migrated.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want unchanged %d", migrated.SchemaVersion, CurrentSchemaVersion)
+	}
+}
+
+func TestLoadConfigFromData_InvalidBM25BackendRejected(t *testing.T) {
+	data := []byte(`
+bm25:
+  backend: not-a-backend
+`)
+	if _, err := LoadConfigFromData(data); err == nil {
+		t.Error("LoadConfigFromData should reject an invalid bm25.backend")
+	}
 }
@@ -0,0 +1,43 @@
+package config
+
+import "fmt"
+
+// migrator upgrades cfg in place from the schema_version before it to the
+// schema_version after it. A migrator must be idempotent-safe in the sense
+// that it only ever runs once per version step, but it need not tolerate
+// being re-applied.
+type migrator func(cfg *Config) error
+
+// migrators is indexed by the schema_version a migrator upgrades FROM, so
+// migrators[0] takes a file with schema_version 0 (or the field absent,
+// which yaml.Unmarshal leaves at its zero value) to schema_version 1.
+// Append to this slice, and bump CurrentSchemaVersion, whenever a change
+// to index.yaml's shape needs an automatic upgrade path for files written
+// against an older version.
+var migrators = []migrator{
+	// 0 -> 1: schema_version itself didn't exist before this version, so
+	// there's no prior shape to actually transform -- this step only
+	// stamps the version so every config on disk ends up migrated at
+	// least once. A future migrator that changes a field's shape (e.g.
+	// renaming a backend identifier) belongs at the next index.
+	func(cfg *Config) error {
+		return nil
+	},
+}
+
+// Migrate runs cfg through every migrator between its current
+// SchemaVersion and CurrentSchemaVersion, in order, and returns cfg with
+// SchemaVersion updated to match. It is a no-op if cfg is already current
+// or ahead (e.g. a file written by a newer qmd reread by an older one).
+func Migrate(cfg *Config) (*Config, error) {
+	for cfg.SchemaVersion < CurrentSchemaVersion {
+		if cfg.SchemaVersion < 0 || cfg.SchemaVersion >= len(migrators) {
+			return nil, fmt.Errorf("config: no migrator for schema_version %d", cfg.SchemaVersion)
+		}
+		if err := migrators[cfg.SchemaVersion](cfg); err != nil {
+			return nil, fmt.Errorf("config: migrating from schema_version %d: %w", cfg.SchemaVersion, err)
+		}
+		cfg.SchemaVersion++
+	}
+	return cfg, nil
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"sync"
+
+	"github.com/qmd/qmd-go/internal/anel"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed schema.json
+var schemaJSON []byte
+
+// Schema returns the embedded JSON Schema describing index.yaml's shape,
+// as a public asset so an editor (e.g. the VS Code YAML extension via
+// yaml-language-server's `# yaml-language-server: $schema=...` comment)
+// can offer completion and inline validation against the same rules
+// ValidateData enforces.
+func Schema() []byte {
+	return schemaJSON
+}
+
+var (
+	compiledSchemaOnce sync.Once
+	compiledSchema     *jsonschema.Schema
+	compiledSchemaErr  error
+)
+
+func compiledConfigSchema() (*jsonschema.Schema, error) {
+	compiledSchemaOnce.Do(func() {
+		compiler := jsonschema.NewCompiler()
+		if err := compiler.AddResource("config.schema.json", bytes.NewReader(schemaJSON)); err != nil {
+			compiledSchemaErr = err
+			return
+		}
+		compiledSchema, compiledSchemaErr = compiler.Compile("config.schema.json")
+	})
+	return compiledSchema, compiledSchemaErr
+}
+
+// fieldHints maps a jsonschema.ValidationError's InstanceLocation to a
+// plainer-language RecoveryHint than the schema's own message, for the
+// handful of fields a config typo most often hits. A location with no
+// entry here still gets a hint, just a more generic one.
+var fieldHints = map[string]string{
+	"/bm25/backend":   "bm25.backend must be one of: sqlite_fts5, lancedb, bleve, elasticsearch, meilisearch, trigram, memory",
+	"/vector/backend": "vector.backend must be one of: qmd_builtin, lancedb, qdrant, milvus, memory",
+	"/schema_version": "schema_version must be a non-negative integer",
+}
+
+// ValidateData checks raw (an index.yaml file's bytes, already known to
+// be well-formed YAML) against Schema(), returning an *anel.AnelError
+// with ErrorCodeConfigError and a RecoveryHint naming the offending
+// field instead of an opaque "doesn't validate" message. LoadConfigFromData
+// calls this before Migrate, so a typo like `bm25: {backend: sqlite}`
+// surfaces as a pointed error rather than silently producing a Config
+// whose backend resolves to its zero value.
+func ValidateData(raw []byte) error {
+	schema, err := compiledConfigSchema()
+	if err != nil {
+		return err
+	}
+
+	var doc any
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+	if doc == nil {
+		// An empty file validates against DefaultConfig() with nothing
+		// overridden; the schema has nothing to say about it.
+		return nil
+	}
+
+	if err := schema.Validate(doc); err != nil {
+		if ve, ok := err.(*jsonschema.ValidationError); ok {
+			return validationErrorToConfigError(ve)
+		}
+		return anel.NewAnelError(anel.ErrorCodeConfigError, "Config Validation Failed", err.Error())
+	}
+	return nil
+}
+
+// validationErrorToConfigError walks to the most specific (leaf) cause
+// of ve, the same pattern anel.validationErrorToAnelError uses for ANEL
+// command specs, so the resulting AnelError names exactly the field
+// that failed rather than the root "doesn't validate" summary.
+func validationErrorToConfigError(ve *jsonschema.ValidationError) *anel.AnelError {
+	leaf := ve
+	for len(leaf.Causes) > 0 {
+		leaf = leaf.Causes[0]
+	}
+
+	aerr := anel.NewAnelError(anel.ErrorCodeConfigError, "Config Validation Failed",
+		fmt.Sprintf("%s: %s", leaf.InstanceLocation, leaf.Message))
+
+	hint := fieldHints[leaf.InstanceLocation]
+	if hint == "" {
+		hint = fmt.Sprintf("check %s in index.yaml", leaf.InstanceLocation)
+	}
+	aerr.WithHint(anel.NewRecoveryHint("FIX_FIELD", hint))
+	return aerr
+}
@@ -4,13 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"math/rand"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/qmd/qmd-go/internal/anel"
 	"github.com/qmd/qmd-go/internal/config"
 )
 
@@ -36,76 +40,951 @@ type RerankResult struct {
 	Model    string
 }
 
+// RouterOptions controls how much time and how many retries the Router
+// spends per request and per provider before giving up or falling through.
+type RouterOptions struct {
+	// PerRequestTimeout bounds a single outbound HTTP call.
+	PerRequestTimeout time.Duration
+	// TotalDeadline bounds an entire Embed/Rerank call across all attempts
+	// and both providers. Zero means no overall deadline beyond ctx's own.
+	TotalDeadline time.Duration
+	// MaxAttempts is the max number of tries (including the first) for a
+	// single item before it's treated as a failure.
+	MaxAttempts int
+	// BackoffBase is the base delay for jittered exponential backoff
+	// between retries: attempt N waits up to BackoffBase*2^(N-1).
+	BackoffBase time.Duration
+	// RetryOn decides whether a failure is transient and worth retrying.
+	RetryOn func(err error, status int) bool
+	// FailFastLocal bounds how long the local provider gets before the
+	// router abandons it and falls through to remote, so a hung local
+	// model doesn't consume the entire TotalDeadline.
+	FailFastLocal time.Duration
+}
+
+// DefaultRouterOptions returns the Router's out-of-the-box budget: a
+// generous per-request timeout, three attempts with short backoff, and a
+// short local fail-fast window so remote always gets a turn.
+func DefaultRouterOptions() RouterOptions {
+	return RouterOptions{
+		PerRequestTimeout: 30 * time.Second,
+		TotalDeadline:     0,
+		MaxAttempts:       3,
+		BackoffBase:       200 * time.Millisecond,
+		RetryOn:           DefaultRetryOn,
+		FailFastLocal:     5 * time.Second,
+	}
+}
+
+// DefaultRetryOn retries server errors, connection resets, and per-item
+// deadline exceeded, but not client errors (4xx) or context cancellation.
+func DefaultRetryOn(err error, status int) bool {
+	if status >= 500 {
+		return true
+	}
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr interface{ Timeout() bool }
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return false
+}
+
 // Router routes LLM requests to local or remote providers
 type Router struct {
-	config          *config.Config
-	httpClient      *http.Client
-	llamaServerURL  string
+	config         *config.Config
+	httpClient     *http.Client
+	llamaServerURL string
+	opts           RouterOptions
+	supervisor     *LocalServerSupervisor
 }
 
-// New creates a new LLM router
+// New creates a new LLM router with DefaultRouterOptions.
 func New(cfg *config.Config) *Router {
+	return NewWithOptions(cfg, DefaultRouterOptions())
+}
+
+// NewWithOptions creates a new LLM router with an explicit retry/deadline
+// budget. If cfg.LlamaServer is set, the router supervises the
+// llama-server process itself, lazy-starting it on first use; otherwise
+// it assumes one is already running at the default URL.
+func NewWithOptions(cfg *config.Config, opts RouterOptions) *Router {
+	llamaServerURL := "http://localhost:8080"
+	var supervisor *LocalServerSupervisor
+	if cfg.LlamaServer != nil {
+		if cfg.LlamaServer.URL != "" {
+			llamaServerURL = cfg.LlamaServer.URL
+		}
+		supervisor = NewLocalServerSupervisor(llamaServerURL, cfg.LlamaServer.BinPath, cfg.LlamaServer.Args)
+	}
+
 	return &Router{
 		config:         cfg,
 		httpClient:     &http.Client{},
-		llamaServerURL: "http://localhost:8080",
+		llamaServerURL: llamaServerURL,
+		opts:           opts,
+		supervisor:     supervisor,
+	}
+}
+
+// Close shuts down any llama-server process this router's supervisor
+// launched. Safe to call even when no supervisor is configured.
+func (r *Router) Close() error {
+	if r.supervisor == nil {
+		return nil
 	}
+	return r.supervisor.Close()
 }
 
-// Embed generates embeddings
+// ensureLocalServer asks the supervisor (if any) to make sure
+// llama-server is up before an HTTP call is attempted.
+func (r *Router) ensureLocalServer(ctx context.Context) error {
+	if r.supervisor == nil {
+		return nil
+	}
+	return r.supervisor.EnsureRunning(ctx)
+}
+
+// withTotalDeadline derives a context bounded by opts.TotalDeadline, if set.
+func (r *Router) withTotalDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.opts.TotalDeadline <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, r.opts.TotalDeadline)
+}
+
+// Embed generates embeddings using cfg.Models.Embed, trying the local
+// provider within its FailFastLocal budget before falling through to
+// remote, all bounded by TotalDeadline.
 func (r *Router) Embed(ctx context.Context, texts []string) (*EmbeddingResult, error) {
-	// Try local first
-	if r.config.Models.Embed != nil && r.config.Models.Embed.Local != nil {
-		embeddings, err := r.localEmbed(ctx, texts)
+	return r.EmbedWithModel(ctx, r.config.Models.Embed, texts)
+}
+
+// EmbedWithModel behaves like Embed but embeds against an explicit model
+// config instead of cfg.Models.Embed, so callers that need a specific
+// named embedder (e.g. the AutoEmbedder picking a collection's
+// "code" embedder) aren't limited to the router's default model.
+func (r *Router) EmbedWithModel(ctx context.Context, model *config.LLMModelConfig, texts []string) (*EmbeddingResult, error) {
+	ctx, cancel := r.withTotalDeadline(ctx)
+	defer cancel()
+
+	var errs []error
+
+	if model != nil && model.Local != nil {
+		embeddings, err := r.withFailFastLocal(ctx, r.localEmbed, texts)
 		if err == nil {
 			return &EmbeddingResult{
 				Embeddings: embeddings,
 				Provider:   ProviderLocal,
-				Model:      *r.config.Models.Embed.Local,
+				Model:      *model.Local,
 			}, nil
 		}
+		errs = append(errs, fmt.Errorf("local embed: %w", err))
+	}
+
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+		return nil, errors.Join(errs...)
 	}
 
-	// Try remote
-	if r.config.Models.Embed != nil && r.config.Models.Embed.Remote != nil {
+	if model != nil && model.Remote != nil {
 		embeddings, err := r.remoteEmbed(ctx, texts)
 		if err == nil {
 			return &EmbeddingResult{
 				Embeddings: embeddings,
 				Provider:   ProviderRemote,
-				Model:      *r.config.Models.Embed.Remote,
+				Model:      *model.Remote,
 			}, nil
 		}
+		errs = append(errs, fmt.Errorf("remote embed: %w", err))
 	}
 
-	return nil, fmt.Errorf("no embedder available")
+	errs = append(errs, anel.Newf(anel.ErrorCodeModelNotFound, "no_embed_model_configured",
+		"no embedding model is configured").
+		WithHint("set models.embed.local or models.embed.remote in the context or config"))
+	return nil, errors.Join(errs...)
 }
 
-// Rerank reranks documents
+// Rerank reranks documents, trying local within FailFastLocal before
+// falling through to remote, all bounded by TotalDeadline.
 func (r *Router) Rerank(ctx context.Context, query string, docs []string) ([]float32, error) {
-	// Try local first
+	ctx, cancel := r.withTotalDeadline(ctx)
+	defer cancel()
+
+	var errs []error
+
 	if r.config.Models.Rerank != nil && r.config.Models.Rerank.Local != nil {
-		scores, err := r.localRerank(ctx, query, docs)
+		scores, err := r.withFailFastLocalRerank(ctx, query, docs)
 		if err == nil {
 			return scores, nil
 		}
+		errs = append(errs, fmt.Errorf("local rerank: %w", err))
+	}
+
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+		return nil, errors.Join(errs...)
 	}
 
-	// Try remote
 	if r.config.Models.Rerank != nil && r.config.Models.Rerank.Remote != nil {
 		scores, err := r.remoteRerank(ctx, query, docs)
 		if err == nil {
 			return scores, nil
 		}
+		errs = append(errs, fmt.Errorf("remote rerank: %w", err))
+	}
+
+	errs = append(errs, anel.Newf(anel.ErrorCodeModelNotFound, "no_rerank_model_configured",
+		"no rerank model is configured").
+		WithHint("set models.rerank.local or models.rerank.remote in the context or config"))
+	return nil, errors.Join(errs...)
+}
+
+// withFailFastLocal runs fn with a context bounded by FailFastLocal so a
+// hung or slow local provider doesn't eat the remote fallback's budget.
+func (r *Router) withFailFastLocal(ctx context.Context, fn func(context.Context, []string) ([][]float32, error), texts []string) ([][]float32, error) {
+	if r.opts.FailFastLocal <= 0 {
+		return fn(ctx, texts)
+	}
+	localCtx, cancel := context.WithTimeout(ctx, r.opts.FailFastLocal)
+	defer cancel()
+	return fn(localCtx, texts)
+}
+
+func (r *Router) withFailFastLocalRerank(ctx context.Context, query string, docs []string) ([]float32, error) {
+	if r.opts.FailFastLocal <= 0 {
+		return r.localRerank(ctx, query, docs)
+	}
+	localCtx, cancel := context.WithTimeout(ctx, r.opts.FailFastLocal)
+	defer cancel()
+	return r.localRerank(localCtx, query, docs)
+}
+
+// GenerateParaphrases asks cfg.Models.QueryExpansion for up to n short
+// paraphrases of query, trying the local provider within its
+// FailFastLocal budget before falling through to remote, the same way
+// Embed and Rerank do. It returns (nil, nil) rather than an error when no
+// query_expansion model is configured, since LLM-based expansion is one
+// optional strategy among several (see store.ExpandQuery).
+func (r *Router) GenerateParaphrases(ctx context.Context, query string, n int) ([]string, error) {
+	model := r.config.Models.QueryExpansion
+	if model == nil || n <= 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := r.withTotalDeadline(ctx)
+	defer cancel()
+
+	var errs []error
+
+	if model.Local != nil {
+		paraphrases, err := r.withFailFastLocalGenerate(ctx, query, n)
+		if err == nil {
+			return paraphrases, nil
+		}
+		errs = append(errs, fmt.Errorf("local expand: %w", err))
+	}
+
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+		return nil, errors.Join(errs...)
+	}
+
+	if model.Remote != nil {
+		paraphrases, err := r.remoteGenerateParaphrases(ctx, *model.Remote, query, n)
+		if err == nil {
+			return paraphrases, nil
+		}
+		errs = append(errs, fmt.Errorf("remote expand: %w", err))
+	}
+
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	return nil, anel.Wrap(anel.ErrorCodeModelLoadFailed, "query_expansion_generate_failed", errors.Join(errs...))
+}
+
+func (r *Router) withFailFastLocalGenerate(ctx context.Context, query string, n int) ([]string, error) {
+	if r.opts.FailFastLocal <= 0 {
+		return r.llamaServerGenerateParaphrases(ctx, query, n)
 	}
+	localCtx, cancel := context.WithTimeout(ctx, r.opts.FailFastLocal)
+	defer cancel()
+	return r.llamaServerGenerateParaphrases(localCtx, query, n)
+}
 
-	return nil, fmt.Errorf("no reranker available")
+// paraphrasePrompt bounds the model to a short, parseable response: one
+// paraphrase per line, nothing else.
+func paraphrasePrompt(query string, n int) string {
+	return fmt.Sprintf(
+		"Rewrite the following search query as %d alternative phrasings that preserve its meaning. "+
+			"Reply with exactly %d lines, one paraphrase per line, and nothing else.\n\nQuery: %s",
+		n, n, query)
 }
 
-// ExpandQuery expands a query using LLM
-func (r *Router) ExpandQuery(query string) []string {
-	// TODO: Implement query expansion
-	return []string{query}
+// parseParaphrases splits a model's line-based response into at most n
+// non-empty paraphrases, stripping common list markers ("- ", "1. ") and
+// dropping any line identical to query.
+func parseParaphrases(text, query string, n int) []string {
+	lines := strings.Split(text, "\n")
+	paraphrases := make([]string, 0, n)
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimLeft(line, "-*0123456789. \t")
+		if line == "" || strings.EqualFold(line, query) {
+			continue
+		}
+		paraphrases = append(paraphrases, line)
+		if len(paraphrases) == n {
+			break
+		}
+	}
+	return paraphrases
+}
+
+func (r *Router) llamaServerGenerateParaphrases(ctx context.Context, query string, n int) ([]string, error) {
+	if err := r.ensureLocalServer(ctx); err != nil {
+		return nil, err
+	}
+
+	type CompletionRequest struct {
+		Prompt   string `json:"prompt"`
+		NPredict int    `json:"n_predict"`
+	}
+	type CompletionResponse struct {
+		Content string `json:"content"`
+	}
+
+	var content string
+	err := r.withRetry(ctx, func(attemptCtx context.Context) (int, error) {
+		reqBody, _ := json.Marshal(CompletionRequest{
+			Prompt:   paraphrasePrompt(query, n),
+			NPredict: 64 * n,
+		})
+
+		req, err := http.NewRequestWithContext(attemptCtx, "POST",
+			r.llamaServerURL+"/completion", bytes.NewBuffer(reqBody))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := r.httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, anel.Newf(anel.ErrorCodeBackendUnavailable, "llama_server_completion_unavailable",
+				"llama-server completion endpoint returned status %d", resp.StatusCode).
+				WithDetail("url", r.llamaServerURL)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, err
+		}
+		var result CompletionResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			return resp.StatusCode, err
+		}
+		content = result.Content
+		return resp.StatusCode, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseParaphrases(content, query, n), nil
+}
+
+func (r *Router) remoteGenerateParaphrases(ctx context.Context, model, query string, n int) ([]string, error) {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	baseURL := os.Getenv("OPENAI_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+
+	type ChatMessage struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	type ChatRequest struct {
+		Model    string        `json:"model"`
+		Messages []ChatMessage `json:"messages"`
+	}
+	type ChatResponse struct {
+		Choices []struct {
+			Message ChatMessage `json:"message"`
+		} `json:"choices"`
+	}
+
+	var content string
+	err := r.withRetry(ctx, func(attemptCtx context.Context) (int, error) {
+		reqBody, _ := json.Marshal(ChatRequest{
+			Model: model,
+			Messages: []ChatMessage{
+				{Role: "user", Content: paraphrasePrompt(query, n)},
+			},
+		})
+
+		req, err := http.NewRequestWithContext(attemptCtx, "POST",
+			baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+
+		resp, err := r.httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, anel.Newf(anel.ErrorCodeBackendUnavailable, "remote_expand_http_error",
+				"remote query expansion returned status %d", resp.StatusCode).
+				WithDetail("url", baseURL)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, err
+		}
+		var result ChatResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			return resp.StatusCode, err
+		}
+		if len(result.Choices) == 0 {
+			return resp.StatusCode, fmt.Errorf("remote query expansion returned no choices")
+		}
+		content = result.Choices[0].Message.Content
+		return resp.StatusCode, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseParaphrases(content, query, n), nil
+}
+
+// Intent is package router's query-retrieval category, classified by
+// ClassifyQuery and named after the retrieval method it maps to:
+// IntentKeyword to store.Store.BM25Search, IntentSemantic to
+// VectorSearch, IntentHybrid to HybridSearch, and IntentCode to
+// CodeSearch.
+type Intent string
+
+const (
+	IntentKeyword  Intent = "keyword"
+	IntentSemantic Intent = "semantic"
+	IntentHybrid   Intent = "hybrid"
+	IntentCode     Intent = "code"
+)
+
+// QueryClassification is GenerateParaphrases' sibling for query
+// routing: cfg.Models.QueryExpansion's single-call answer to "how should
+// this query be searched" -- which Intent fits best, Variants rewritten
+// or expanded phrasings of it to run against that intent's backend(s),
+// and Entities (product names, identifiers, proper nouns) extracted
+// along the way.
+type QueryClassification struct {
+	Intent   Intent
+	Variants []string
+	Entities []string
+}
+
+// ClassifyQuery asks cfg.Models.QueryExpansion to classify query's
+// intent and produce up to n rewritten variants plus extracted entity
+// tokens, trying the local provider within its FailFastLocal budget
+// before falling through to remote, the same way GenerateParaphrases
+// does. It returns (nil, nil) rather than an error when no
+// query_expansion model is configured, since LLM-based routing is an
+// enhancement over package router's heuristic fallback, not a hard
+// dependency.
+func (r *Router) ClassifyQuery(ctx context.Context, query string, n int) (*QueryClassification, error) {
+	model := r.config.Models.QueryExpansion
+	if model == nil {
+		return nil, nil
+	}
+	if n <= 0 {
+		n = 3
+	}
+
+	ctx, cancel := r.withTotalDeadline(ctx)
+	defer cancel()
+
+	var errs []error
+
+	if model.Local != nil {
+		classification, err := r.withFailFastLocalClassify(ctx, query, n)
+		if err == nil {
+			return classification, nil
+		}
+		errs = append(errs, fmt.Errorf("local classify: %w", err))
+	}
+
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+		return nil, errors.Join(errs...)
+	}
+
+	if model.Remote != nil {
+		classification, err := r.remoteClassifyQuery(ctx, *model.Remote, query, n)
+		if err == nil {
+			return classification, nil
+		}
+		errs = append(errs, fmt.Errorf("remote classify: %w", err))
+	}
+
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	return nil, anel.Wrap(anel.ErrorCodeModelLoadFailed, "query_classification_failed", errors.Join(errs...))
+}
+
+func (r *Router) withFailFastLocalClassify(ctx context.Context, query string, n int) (*QueryClassification, error) {
+	if r.opts.FailFastLocal <= 0 {
+		return r.llamaServerClassifyQuery(ctx, query, n)
+	}
+	localCtx, cancel := context.WithTimeout(ctx, r.opts.FailFastLocal)
+	defer cancel()
+	return r.llamaServerClassifyQuery(localCtx, query, n)
+}
+
+// classifyPrompt bounds the model to a single parseable JSON object: the
+// intent, n rewritten/expanded query variants, and any entity tokens
+// worth extracting.
+func classifyPrompt(query string, n int) string {
+	return fmt.Sprintf(
+		"Classify the following search query's intent as exactly one of "+
+			`"keyword", "semantic", "hybrid", or "code". Propose %d rewritten `+
+			"or expanded variants of the query, and extract any named entities "+
+			"(product names, identifiers, proper nouns) it contains. Reply with "+
+			`exactly one JSON object and nothing else, shaped like `+
+			`{"intent": "hybrid", "variants": ["..."], "entities": ["..."]}.`+
+			"\n\nQuery: %s",
+		n, query)
+}
+
+// parseClassification extracts the first {...} JSON object out of text
+// (models sometimes wrap it in prose or a code fence despite the prompt)
+// and decodes it into a QueryClassification, defaulting Intent to
+// IntentHybrid on an unrecognized or missing value and capping Variants
+// at n.
+func parseClassification(text string, n int) (*QueryClassification, error) {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON object in classification response")
+	}
+
+	var raw struct {
+		Intent   string   `json:"intent"`
+		Variants []string `json:"variants"`
+		Entities []string `json:"entities"`
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("parse classification response: %w", err)
+	}
+
+	intent := Intent(strings.ToLower(strings.TrimSpace(raw.Intent)))
+	switch intent {
+	case IntentKeyword, IntentSemantic, IntentHybrid, IntentCode:
+	default:
+		intent = IntentHybrid
+	}
+
+	variants := raw.Variants
+	if len(variants) > n {
+		variants = variants[:n]
+	}
+
+	return &QueryClassification{Intent: intent, Variants: variants, Entities: raw.Entities}, nil
+}
+
+func (r *Router) llamaServerClassifyQuery(ctx context.Context, query string, n int) (*QueryClassification, error) {
+	if err := r.ensureLocalServer(ctx); err != nil {
+		return nil, err
+	}
+
+	type CompletionRequest struct {
+		Prompt   string `json:"prompt"`
+		NPredict int    `json:"n_predict"`
+	}
+	type CompletionResponse struct {
+		Content string `json:"content"`
+	}
+
+	var content string
+	err := r.withRetry(ctx, func(attemptCtx context.Context) (int, error) {
+		reqBody, _ := json.Marshal(CompletionRequest{
+			Prompt:   classifyPrompt(query, n),
+			NPredict: 128 + 64*n,
+		})
+
+		req, err := http.NewRequestWithContext(attemptCtx, "POST",
+			r.llamaServerURL+"/completion", bytes.NewBuffer(reqBody))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := r.httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, anel.Newf(anel.ErrorCodeBackendUnavailable, "llama_server_completion_unavailable",
+				"llama-server completion endpoint returned status %d", resp.StatusCode).
+				WithDetail("url", r.llamaServerURL)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, err
+		}
+		var result CompletionResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			return resp.StatusCode, err
+		}
+		content = result.Content
+		return resp.StatusCode, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseClassification(content, n)
+}
+
+func (r *Router) remoteClassifyQuery(ctx context.Context, model, query string, n int) (*QueryClassification, error) {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	baseURL := os.Getenv("OPENAI_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+
+	type ChatMessage struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	type ChatRequest struct {
+		Model    string        `json:"model"`
+		Messages []ChatMessage `json:"messages"`
+	}
+	type ChatResponse struct {
+		Choices []struct {
+			Message ChatMessage `json:"message"`
+		} `json:"choices"`
+	}
+
+	var content string
+	err := r.withRetry(ctx, func(attemptCtx context.Context) (int, error) {
+		reqBody, _ := json.Marshal(ChatRequest{
+			Model: model,
+			Messages: []ChatMessage{
+				{Role: "user", Content: classifyPrompt(query, n)},
+			},
+		})
+
+		req, err := http.NewRequestWithContext(attemptCtx, "POST",
+			baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+
+		resp, err := r.httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, anel.Newf(anel.ErrorCodeBackendUnavailable, "remote_classify_http_error",
+				"remote query classification returned status %d", resp.StatusCode).
+				WithDetail("url", baseURL)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, err
+		}
+		var result ChatResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			return resp.StatusCode, err
+		}
+		if len(result.Choices) == 0 {
+			return resp.StatusCode, fmt.Errorf("remote query classification returned no choices")
+		}
+		content = result.Choices[0].Message.Content
+		return resp.StatusCode, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseClassification(content, n)
+}
+
+// TagSuggestion is one of SuggestTags' candidates: a tag name and the
+// model's confidence in it (0-1).
+type TagSuggestion struct {
+	Tag        string
+	Confidence float32
+}
+
+// SuggestTags asks cfg.Models.QueryExpansion to propose up to n tags for
+// a document given its title and a body excerpt, trying the local
+// provider within its FailFastLocal budget before falling through to
+// remote, the same way GenerateParaphrases and ClassifyQuery do. It
+// returns (nil, nil) rather than an error when no query_expansion model
+// is configured, since tag suggestion is an enhancement a caller (store
+// package's SuggestTags) can skip, not a hard dependency.
+func (r *Router) SuggestTags(ctx context.Context, title, excerpt string, n int) ([]TagSuggestion, error) {
+	model := r.config.Models.QueryExpansion
+	if model == nil {
+		return nil, nil
+	}
+	if n <= 0 {
+		n = 5
+	}
+
+	ctx, cancel := r.withTotalDeadline(ctx)
+	defer cancel()
+
+	var errs []error
+
+	if model.Local != nil {
+		suggestions, err := r.withFailFastLocalSuggestTags(ctx, title, excerpt, n)
+		if err == nil {
+			return suggestions, nil
+		}
+		errs = append(errs, fmt.Errorf("local suggest_tags: %w", err))
+	}
+
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+		return nil, errors.Join(errs...)
+	}
+
+	if model.Remote != nil {
+		suggestions, err := r.remoteSuggestTags(ctx, *model.Remote, title, excerpt, n)
+		if err == nil {
+			return suggestions, nil
+		}
+		errs = append(errs, fmt.Errorf("remote suggest_tags: %w", err))
+	}
+
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	return nil, anel.Wrap(anel.ErrorCodeModelLoadFailed, "tag_suggestion_failed", errors.Join(errs...))
+}
+
+func (r *Router) withFailFastLocalSuggestTags(ctx context.Context, title, excerpt string, n int) ([]TagSuggestion, error) {
+	if r.opts.FailFastLocal <= 0 {
+		return r.llamaServerSuggestTags(ctx, title, excerpt, n)
+	}
+	localCtx, cancel := context.WithTimeout(ctx, r.opts.FailFastLocal)
+	defer cancel()
+	return r.llamaServerSuggestTags(localCtx, title, excerpt, n)
+}
+
+// tagSuggestPrompt bounds the model to a single parseable JSON array of
+// {tag, confidence} objects.
+func tagSuggestPrompt(title, excerpt string, n int) string {
+	return fmt.Sprintf(
+		"Suggest up to %d short, lowercase tags for the following document, "+
+			"each with a confidence score between 0 and 1 reflecting how well it "+
+			`fits. Reply with exactly one JSON array and nothing else, shaped like `+
+			`[{"tag": "golang", "confidence": 0.9}].`+
+			"\n\nTitle: %s\n\nExcerpt: %s",
+		n, title, excerpt)
+}
+
+// parseTagSuggestions extracts the first [...] JSON array out of text
+// (models sometimes wrap it in prose or a code fence despite the
+// prompt) and decodes it into TagSuggestions, dropping any entry with an
+// empty tag and capping the result at n.
+func parseTagSuggestions(text string, n int) ([]TagSuggestion, error) {
+	start := strings.Index(text, "[")
+	end := strings.LastIndex(text, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON array in tag suggestion response")
+	}
+
+	var raw []struct {
+		Tag        string  `json:"tag"`
+		Confidence float32 `json:"confidence"`
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("parse tag suggestion response: %w", err)
+	}
+
+	suggestions := make([]TagSuggestion, 0, n)
+	for _, entry := range raw {
+		tag := strings.ToLower(strings.TrimSpace(entry.Tag))
+		if tag == "" {
+			continue
+		}
+		suggestions = append(suggestions, TagSuggestion{Tag: tag, Confidence: entry.Confidence})
+		if len(suggestions) == n {
+			break
+		}
+	}
+	return suggestions, nil
+}
+
+func (r *Router) llamaServerSuggestTags(ctx context.Context, title, excerpt string, n int) ([]TagSuggestion, error) {
+	if err := r.ensureLocalServer(ctx); err != nil {
+		return nil, err
+	}
+
+	type CompletionRequest struct {
+		Prompt   string `json:"prompt"`
+		NPredict int    `json:"n_predict"`
+	}
+	type CompletionResponse struct {
+		Content string `json:"content"`
+	}
+
+	var content string
+	err := r.withRetry(ctx, func(attemptCtx context.Context) (int, error) {
+		reqBody, _ := json.Marshal(CompletionRequest{
+			Prompt:   tagSuggestPrompt(title, excerpt, n),
+			NPredict: 64 * n,
+		})
+
+		req, err := http.NewRequestWithContext(attemptCtx, "POST",
+			r.llamaServerURL+"/completion", bytes.NewBuffer(reqBody))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := r.httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, anel.Newf(anel.ErrorCodeBackendUnavailable, "llama_server_completion_unavailable",
+				"llama-server completion endpoint returned status %d", resp.StatusCode).
+				WithDetail("url", r.llamaServerURL)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, err
+		}
+		var result CompletionResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			return resp.StatusCode, err
+		}
+		content = result.Content
+		return resp.StatusCode, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTagSuggestions(content, n)
+}
+
+func (r *Router) remoteSuggestTags(ctx context.Context, model, title, excerpt string, n int) ([]TagSuggestion, error) {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	baseURL := os.Getenv("OPENAI_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+
+	type ChatMessage struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	type ChatRequest struct {
+		Model    string        `json:"model"`
+		Messages []ChatMessage `json:"messages"`
+	}
+	type ChatResponse struct {
+		Choices []struct {
+			Message ChatMessage `json:"message"`
+		} `json:"choices"`
+	}
+
+	var content string
+	err := r.withRetry(ctx, func(attemptCtx context.Context) (int, error) {
+		reqBody, _ := json.Marshal(ChatRequest{
+			Model: model,
+			Messages: []ChatMessage{
+				{Role: "user", Content: tagSuggestPrompt(title, excerpt, n)},
+			},
+		})
+
+		req, err := http.NewRequestWithContext(attemptCtx, "POST",
+			baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+
+		resp, err := r.httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, anel.Newf(anel.ErrorCodeBackendUnavailable, "remote_suggest_tags_http_error",
+				"remote tag suggestion returned status %d", resp.StatusCode).
+				WithDetail("url", baseURL)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, err
+		}
+		var result ChatResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			return resp.StatusCode, err
+		}
+		if len(result.Choices) == 0 {
+			return resp.StatusCode, fmt.Errorf("remote tag suggestion returned no choices")
+		}
+		content = result.Choices[0].Message.Content
+		return resp.StatusCode, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTagSuggestions(content, n)
 }
 
 func (r *Router) localEmbed(ctx context.Context, texts []string) ([][]float32, error) {
@@ -127,10 +1006,12 @@ func (r *Router) localEmbed(ctx context.Context, texts []string) ([][]float32, e
 	return embeddings, nil
 }
 
+// llamaServerEmbed calls the llama-server embedding API once per text,
+// retrying transient failures with jittered exponential backoff up to
+// MaxAttempts, and aborting the batch as soon as ctx is done.
 func (r *Router) llamaServerEmbed(ctx context.Context, texts []string) ([][]float32, error) {
-	// llama-server embedding API
-	type EmbedRequest struct {
-		Content string `json:"content"`
+	if err := r.ensureLocalServer(ctx); err != nil {
+		return nil, err
 	}
 
 	type EmbedResponse struct {
@@ -144,40 +1025,121 @@ func (r *Router) llamaServerEmbed(ctx context.Context, texts []string) ([][]floa
 	embeddings := make([][]float32, 0, len(texts))
 
 	for _, text := range texts {
-		reqBody, _ := json.Marshal(map[string]string{"content": text})
-		req, err := http.NewRequestWithContext(ctx, "POST",
-			r.llamaServerURL+"/embedding", bytes.NewBuffer(reqBody))
-		if err != nil {
-			continue
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("embedding batch canceled: %w", ctx.Err())
 		}
-		req.Header.Set("Content-Type", "application/json")
 
-		resp, err := r.httpClient.Do(req)
+		var embedding []float32
+		err := r.withRetry(ctx, func(attemptCtx context.Context) (int, error) {
+			reqBody, _ := json.Marshal(map[string]string{"content": text})
+			req, err := http.NewRequestWithContext(attemptCtx, "POST",
+				r.llamaServerURL+"/embedding", bytes.NewBuffer(reqBody))
+			if err != nil {
+				return 0, err
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := r.httpClient.Do(req)
+			if err != nil {
+				return 0, err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return resp.StatusCode, fmt.Errorf("llama-server returned status %d", resp.StatusCode)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return resp.StatusCode, err
+			}
+			var result APIResponse
+			if err := json.Unmarshal(body, &result); err != nil {
+				return resp.StatusCode, err
+			}
+			if len(result.Data) == 0 {
+				return resp.StatusCode, fmt.Errorf("llama-server returned no embeddings")
+			}
+			embedding = result.Data[0].Embedding
+			return resp.StatusCode, nil
+		})
 		if err != nil {
 			continue
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			continue
+		embeddings = append(embeddings, embedding)
+	}
+
+	if len(embeddings) == 0 {
+		return nil, anel.Newf(anel.ErrorCodeEmbeddingFailed, "llama_server_unreachable",
+			"could not reach llama-server at %s", r.llamaServerURL).
+			WithDetail("url", r.llamaServerURL).
+			WithHint("start llama-server or configure a remote embedding endpoint")
+	}
+
+	return embeddings, nil
+}
+
+// withRetry runs attempt up to opts.MaxAttempts times, deriving a fresh
+// PerRequestTimeout context for every try and backing off between
+// transient failures (per opts.RetryOn) with jittered exponential delay.
+func (r *Router) withRetry(ctx context.Context, attempt func(context.Context) (status int, err error)) error {
+	maxAttempts := r.opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	var lastErr error
+	for i := 0; i < maxAttempts; i++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
 
-		body, _ := io.ReadAll(resp.Body)
-		var result APIResponse
-		if err := json.Unmarshal(body, &result); err != nil {
-			continue
+		attemptCtx := ctx
+		var cancel context.CancelFunc
+		if r.opts.PerRequestTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, r.opts.PerRequestTimeout)
 		}
 
-		if len(result.Data) > 0 {
-			embeddings = append(embeddings, result.Data[0].Embedding)
+		status, err := attempt(attemptCtx)
+		if cancel != nil {
+			cancel()
 		}
-	}
+		if err == nil {
+			return nil
+		}
+		lastErr = err
 
-	if len(embeddings) == 0 {
-		return nil, fmt.Errorf("llama-server not available")
+		retryOn := r.opts.RetryOn
+		if retryOn == nil {
+			retryOn = DefaultRetryOn
+		}
+		if !retryOn(err, status) || i == maxAttempts-1 {
+			return lastErr
+		}
+
+		if err := sleepBackoff(ctx, r.opts.BackoffBase, i); err != nil {
+			return err
+		}
 	}
 
-	return embeddings, nil
+	return lastErr
+}
+
+// sleepBackoff waits BackoffBase*2^attempt plus up to 50% jitter, or
+// returns early if ctx is canceled first.
+func sleepBackoff(ctx context.Context, base time.Duration, attempt int) error {
+	if base <= 0 {
+		return nil
+	}
+	delay := base * time.Duration(1<<uint(attempt))
+	jitter := time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	select {
+	case <-time.After(delay + jitter):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (r *Router) remoteEmbed(ctx context.Context, texts []string) ([][]float32, error) {
@@ -204,41 +1166,53 @@ func (r *Router) remoteEmbed(ctx context.Context, texts []string) ([][]float32,
 		} `json:"data"`
 	}
 
-	reqBody, _ := json.Marshal(OpenAIEmbedRequest{
-		Input: texts,
-		Model: model,
-	})
+	var embeddings [][]float32
+	err := r.withRetry(ctx, func(attemptCtx context.Context) (int, error) {
+		reqBody, _ := json.Marshal(OpenAIEmbedRequest{
+			Input: texts,
+			Model: model,
+		})
 
-	req, err := http.NewRequestWithContext(ctx, "POST",
-		baseURL+"/embeddings", bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, err
-	}
+		req, err := http.NewRequestWithContext(attemptCtx, "POST",
+			baseURL+"/embeddings", bytes.NewBuffer(reqBody))
+		if err != nil {
+			return 0, err
+		}
 
-	req.Header.Set("Content-Type", "application/json")
-	if apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+apiKey)
-	}
+		req.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
 
-	resp, err := r.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+		resp, err := r.httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("remote embedding failed: %d", resp.StatusCode)
-	}
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, anel.Newf(anel.ErrorCodeEmbeddingFailed, "remote_embed_http_error",
+				"remote embedding API returned status %d", resp.StatusCode).
+				WithDetail("url", baseURL).WithDetail("status", resp.StatusCode)
+		}
 
-	body, _ := io.ReadAll(resp.Body)
-	var result OpenAIEmbedResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, err
-	}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, err
+		}
+		var result OpenAIEmbedResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			return resp.StatusCode, err
+		}
 
-	embeddings := make([][]float32, len(result.Data))
-	for i, d := range result.Data {
-		embeddings[i] = d.Embedding
+		embeddings = make([][]float32, len(result.Data))
+		for i, d := range result.Data {
+			embeddings[i] = d.Embedding
+		}
+		return resp.StatusCode, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return embeddings, nil
@@ -260,9 +1234,13 @@ func (r *Router) localRerank(ctx context.Context, query string, docs []string) (
 }
 
 func (r *Router) llamaServerRerank(ctx context.Context, query string, docs []string) ([]float32, error) {
+	if err := r.ensureLocalServer(ctx); err != nil {
+		return nil, err
+	}
+
 	// llama.cpp rerank API (if available)
 	type RerankRequest struct {
-		Query    string   `json:"query"`
+		Query     string   `json:"query"`
 		Documents []string `json:"documents"`
 	}
 
@@ -273,38 +1251,50 @@ func (r *Router) llamaServerRerank(ctx context.Context, query string, docs []str
 		} `json:"results"`
 	}
 
-	reqBody, _ := json.Marshal(RerankRequest{
-		Query:     query,
-		Documents: docs,
-	})
+	var scores []float32
+	err := r.withRetry(ctx, func(attemptCtx context.Context) (int, error) {
+		reqBody, _ := json.Marshal(RerankRequest{
+			Query:     query,
+			Documents: docs,
+		})
 
-	req, err := http.NewRequestWithContext(ctx, "POST",
-		r.llamaServerURL+"/rerank", bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
+		req, err := http.NewRequestWithContext(attemptCtx, "POST",
+			r.llamaServerURL+"/rerank", bytes.NewBuffer(reqBody))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
 
-	resp, err := r.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+		resp, err := r.httpClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("rerank not available")
-	}
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, anel.Newf(anel.ErrorCodeBackendUnavailable, "llama_server_rerank_unavailable",
+				"llama-server rerank endpoint returned status %d", resp.StatusCode).
+				WithDetail("url", r.llamaServerURL)
+		}
 
-	body, _ := io.ReadAll(resp.Body)
-	var result RerankResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, err
-	}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, err
+		}
+		var result RerankResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			return resp.StatusCode, err
+		}
 
-	// Convert to original order
-	scores := make([]float32, len(docs))
-	for _, r := range result.Results {
-		scores[r.Index] = r.Score
+		// Convert to original order
+		scores = make([]float32, len(docs))
+		for _, res := range result.Results {
+			scores[res.Index] = res.Score
+		}
+		return resp.StatusCode, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return scores, nil
@@ -315,10 +1305,10 @@ func (r *Router) remoteRerank(ctx context.Context, query string, docs []string)
 	apiKey := os.Getenv("COHERE_API_KEY")
 	if apiKey != "" {
 		type CohereRerankRequest struct {
-			Query      string   `json:"query"`
-			Documents  []string `json:"documents"`
-			Model      string   `json:"model"`
-			TopN       int      `json:"top_n"`
+			Query     string   `json:"query"`
+			Documents []string `json:"documents"`
+			Model     string   `json:"model"`
+			TopN      int      `json:"top_n"`
 		}
 
 		type CohereRerankResponse struct {
@@ -333,37 +1323,52 @@ func (r *Router) remoteRerank(ctx context.Context, query string, docs []string)
 			model = "rerank-english-v2.0"
 		}
 
-		reqBody, _ := json.Marshal(CohereRerankRequest{
-			Query:     query,
-			Documents: docs,
-			Model:     model,
-			TopN:      len(docs),
-		})
+		var scores []float32
+		err := r.withRetry(ctx, func(attemptCtx context.Context) (int, error) {
+			reqBody, _ := json.Marshal(CohereRerankRequest{
+				Query:     query,
+				Documents: docs,
+				Model:     model,
+				TopN:      len(docs),
+			})
 
-		req, err := http.NewRequestWithContext(ctx, "POST",
-			"https://api.cohere.ai/v1/rerank", bytes.NewBuffer(reqBody))
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+apiKey)
+			req, err := http.NewRequestWithContext(attemptCtx, "POST",
+				"https://api.cohere.ai/v1/rerank", bytes.NewBuffer(reqBody))
+			if err != nil {
+				return 0, err
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+apiKey)
 
-		resp, err := r.httpClient.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
+			resp, err := r.httpClient.Do(req)
+			if err != nil {
+				return 0, err
+			}
+			defer resp.Body.Close()
 
-		if resp.StatusCode == http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK {
+				return resp.StatusCode, anel.Newf(anel.ErrorCodeBackendUnavailable, "cohere_rerank_http_error",
+					"cohere rerank API returned status %d", resp.StatusCode).
+					WithDetail("status", resp.StatusCode)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return resp.StatusCode, err
+			}
 			var result CohereRerankResponse
-			if err := json.Unmarshal(body, &result); err == nil {
-				scores := make([]float32, len(docs))
-				for _, r := range result.Results {
-					scores[r.Index] = r.RelevanceScore
-				}
-				return scores, nil
+			if err := json.Unmarshal(body, &result); err != nil {
+				return resp.StatusCode, err
+			}
+
+			scores = make([]float32, len(docs))
+			for _, res := range result.Results {
+				scores[res.Index] = res.RelevanceScore
 			}
+			return resp.StatusCode, nil
+		})
+		if err == nil {
+			return scores, nil
 		}
 	}
 
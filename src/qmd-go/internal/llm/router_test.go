@@ -0,0 +1,274 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func newTestRouter(t *testing.T, serverURL string, opts RouterOptions) *Router {
+	t.Helper()
+	model := "test-embed"
+	cfg := config.DefaultConfig()
+	cfg.Models.Embed = &config.LLMModelConfig{Local: &model}
+	r := NewWithOptions(cfg, opts)
+	r.llamaServerURL = serverURL
+	return r
+}
+
+func TestLlamaServerEmbed_RetriesTransientFailures(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&attempts, 1)
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[{"embedding":[0.1,0.2]}]}`))
+	}))
+	defer srv.Close()
+
+	opts := DefaultRouterOptions()
+	opts.MaxAttempts = 3
+	opts.BackoffBase = time.Millisecond
+	r := newTestRouter(t, srv.URL, opts)
+
+	embeddings, err := r.llamaServerEmbed(context.Background(), []string{"hello"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(embeddings) != 1 {
+		t.Fatalf("expected 1 embedding, got %d", len(embeddings))
+	}
+	if atomic.LoadInt32(&attempts) != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestLlamaServerEmbed_GivesUpAfterMaxAttempts(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	opts := DefaultRouterOptions()
+	opts.MaxAttempts = 2
+	opts.BackoffBase = time.Millisecond
+	r := newTestRouter(t, srv.URL, opts)
+
+	_, err := r.llamaServerEmbed(context.Background(), []string{"hello"})
+	if err == nil {
+		t.Fatal("expected error after exhausting retries")
+	}
+	if atomic.LoadInt32(&attempts) != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestLlamaServerEmbed_PerRequestTimeoutCancelsSlowResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-time.After(200 * time.Millisecond):
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[{"embedding":[0.1]}]}`))
+	}))
+	defer srv.Close()
+
+	opts := DefaultRouterOptions()
+	opts.PerRequestTimeout = 20 * time.Millisecond
+	opts.MaxAttempts = 1
+	r := newTestRouter(t, srv.URL, opts)
+
+	_, err := r.llamaServerEmbed(context.Background(), []string{"hello"})
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+}
+
+func TestEmbed_FailFastLocalFallsThroughToRemote(t *testing.T) {
+	localSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer localSrv.Close()
+
+	remoteSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[{"embedding":[0.5]}]}`))
+	}))
+	defer remoteSrv.Close()
+	t.Setenv("OPENAI_BASE_URL", remoteSrv.URL)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	localModel := "local-embed"
+	remoteModel := "remote-embed"
+	cfg := config.DefaultConfig()
+	cfg.Models.Embed = &config.LLMModelConfig{Local: &localModel, Remote: &remoteModel}
+
+	opts := DefaultRouterOptions()
+	opts.FailFastLocal = 30 * time.Millisecond
+	opts.MaxAttempts = 1
+	r := NewWithOptions(cfg, opts)
+	r.llamaServerURL = localSrv.URL
+
+	start := time.Now()
+	result, err := r.Embed(context.Background(), []string{"hello"})
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider != ProviderRemote {
+		t.Errorf("expected remote provider fallback, got %s", result.Provider)
+	}
+	if elapsed >= time.Second {
+		t.Errorf("expected fail-fast local to abandon well under 1s, took %s", elapsed)
+	}
+}
+
+func TestEmbed_TotalDeadlineExceeded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	localModel := "local-embed"
+	cfg := config.DefaultConfig()
+	cfg.Models.Embed = &config.LLMModelConfig{Local: &localModel}
+
+	opts := DefaultRouterOptions()
+	opts.TotalDeadline = 30 * time.Millisecond
+	opts.FailFastLocal = 0
+	opts.PerRequestTimeout = 0
+	opts.MaxAttempts = 1
+	r := NewWithOptions(cfg, opts)
+	r.llamaServerURL = srv.URL
+
+	_, err := r.Embed(context.Background(), []string{"hello"})
+	if err == nil {
+		t.Fatal("expected error when total deadline is exceeded")
+	}
+	if !strings.Contains(err.Error(), "deadline exceeded") && !strings.Contains(err.Error(), "no embedder") {
+		t.Errorf("expected a deadline-related error, got: %v", err)
+	}
+}
+
+func TestParseParaphrases_StripsMarkersAndDropsEchoedQuery(t *testing.T) {
+	text := "1. alpha beta\n- gamma delta\nsearch query\n\nepsilon zeta"
+	got := parseParaphrases(text, "search query", 3)
+	want := []string{"alpha beta", "gamma delta", "epsilon zeta"}
+	if len(got) != len(want) {
+		t.Fatalf("parseParaphrases() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseParaphrases_CapsAtN(t *testing.T) {
+	got := parseParaphrases("a\nb\nc\nd", "query", 2)
+	if len(got) != 2 {
+		t.Fatalf("expected exactly 2 paraphrases, got %d: %v", len(got), got)
+	}
+}
+
+func TestGenerateParaphrases_NoModelConfiguredReturnsNilNil(t *testing.T) {
+	cfg := config.DefaultConfig()
+	r := New(cfg)
+
+	got, err := r.GenerateParaphrases(context.Background(), "search query", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != nil {
+		t.Errorf("expected nil paraphrases when no query_expansion model is configured, got %v", got)
+	}
+}
+
+func TestParseClassification_ParsesJSONWrappedInProse(t *testing.T) {
+	text := "Sure, here you go:\n```json\n" +
+		`{"intent": "KEYWORD", "variants": ["a", "b", "c"], "entities": ["Foo"]}` +
+		"\n```\nHope that helps!"
+	got, err := parseClassification(text, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Intent != IntentKeyword {
+		t.Errorf("Intent = %q, want %q", got.Intent, IntentKeyword)
+	}
+	if len(got.Variants) != 2 {
+		t.Fatalf("expected variants capped at 2, got %v", got.Variants)
+	}
+	if len(got.Entities) != 1 || got.Entities[0] != "Foo" {
+		t.Errorf("Entities = %v, want [Foo]", got.Entities)
+	}
+}
+
+func TestParseClassification_UnrecognizedIntentDefaultsToHybrid(t *testing.T) {
+	got, err := parseClassification(`{"intent": "nonsense", "variants": ["x"]}`, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Intent != IntentHybrid {
+		t.Errorf("Intent = %q, want %q", got.Intent, IntentHybrid)
+	}
+}
+
+func TestParseClassification_NoJSONObjectErrors(t *testing.T) {
+	if _, err := parseClassification("no object here", 3); err == nil {
+		t.Fatal("expected an error when the response has no JSON object")
+	}
+}
+
+func TestClassifyQuery_NoModelConfiguredReturnsNilNil(t *testing.T) {
+	cfg := config.DefaultConfig()
+	r := New(cfg)
+
+	got, err := r.ClassifyQuery(context.Background(), "search query", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != nil {
+		t.Errorf("expected nil classification when no query_expansion model is configured, got %v", got)
+	}
+}
+
+func TestDefaultRetryOn(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		want   bool
+	}{
+		{"server error", nil, 503, true},
+		{"client error", nil, 400, false},
+		{"deadline exceeded", context.DeadlineExceeded, 0, true},
+		{"canceled", context.Canceled, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultRetryOn(tt.err, tt.status); got != tt.want {
+				t.Errorf("DefaultRetryOn(%v, %d) = %v, want %v", tt.err, tt.status, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/qmd/qmd-go/internal/anel"
+)
+
+// LocalServerSupervisor lazily launches a llama-server child process
+// (--embeddings mode) the first time it's needed, health-checks it
+// before every use, and restarts it if it has exited. If BinPath is
+// empty the supervisor assumes a server is already running at URL and
+// only ever health-checks, never spawns.
+type LocalServerSupervisor struct {
+	URL        string
+	BinPath    string
+	Args       []string
+	HTTPClient *http.Client
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	exited chan struct{} // closed once cmd.Wait returns for the current cmd
+}
+
+// NewLocalServerSupervisor creates a supervisor for the llama-server at
+// url, launching it via binPath/args on first use if binPath is set.
+func NewLocalServerSupervisor(url, binPath string, args []string) *LocalServerSupervisor {
+	return &LocalServerSupervisor{
+		URL:        url,
+		BinPath:    binPath,
+		Args:       args,
+		HTTPClient: &http.Client{Timeout: 2 * time.Second},
+	}
+}
+
+// EnsureRunning health-checks the server and, if it isn't responding and
+// a BinPath is configured, (re)starts it and waits for it to become
+// healthy or for ctx to expire.
+func (s *LocalServerSupervisor) EnsureRunning(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.healthyLocked(ctx) {
+		return nil
+	}
+
+	if s.BinPath == "" {
+		return anel.Newf(anel.ErrorCodeBackendUnavailable, "llama_server_not_supervised",
+			"llama-server at %s is not responding and no bin_path is configured to launch one", s.URL).
+			WithDetail("url", s.URL)
+	}
+
+	if s.cmd != nil && !s.hasExitedLocked() {
+		// Already running under our supervision but not yet healthy; give
+		// it more time rather than spawning a duplicate.
+		return s.waitHealthy(ctx)
+	}
+
+	cmd := exec.Command(s.BinPath, s.Args...)
+	cmd.Stdout = io.Discard
+	cmd.Stderr = io.Discard
+	if err := cmd.Start(); err != nil {
+		return anel.Wrap(anel.ErrorCodeModelLoadFailed, "llama_server_spawn_failed", err).
+			WithDetail("bin_path", s.BinPath)
+	}
+	s.cmd = cmd
+
+	exited := make(chan struct{})
+	s.exited = exited
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+
+	return s.waitHealthy(ctx)
+}
+
+// hasExitedLocked reports whether s.cmd's Wait goroutine has observed it
+// exit. Must be called with s.mu held and s.cmd non-nil.
+func (s *LocalServerSupervisor) hasExitedLocked() bool {
+	select {
+	case <-s.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitHealthy polls /health until it succeeds or ctx is done.
+func (s *LocalServerSupervisor) waitHealthy(ctx context.Context) error {
+	for {
+		if s.healthyLocked(ctx) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return anel.Wrap(anel.ErrorCodeModelLoadFailed, "llama_server_health_timeout", ctx.Err()).
+				WithDetail("url", s.URL)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func (s *LocalServerSupervisor) healthyLocked(ctx context.Context) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL+"/health", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// Close terminates the supervised llama-server process, if one was
+// started by this supervisor, and waits for it to actually exit so it
+// isn't left behind as a zombie. A no-op if the server wasn't launched
+// by us (BinPath unset) or never started.
+func (s *LocalServerSupervisor) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cmd == nil || s.cmd.Process == nil {
+		return nil
+	}
+	if err := s.cmd.Process.Kill(); err != nil {
+		return err
+	}
+	<-s.exited
+	return nil
+}
@@ -0,0 +1,71 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEnsureRunning_AlreadyHealthy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	sup := NewLocalServerSupervisor(srv.URL, "", nil)
+	if err := sup.EnsureRunning(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestEnsureRunning_UnhealthyNoBinPath(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	sup := NewLocalServerSupervisor(srv.URL, "", nil)
+	if err := sup.EnsureRunning(context.Background()); err == nil {
+		t.Fatal("expected error when server is unhealthy and no bin_path is configured")
+	}
+}
+
+func TestEnsureRunning_TimesOutWaitingForHealth(t *testing.T) {
+	sup := NewLocalServerSupervisor("http://127.0.0.1:1", "/bin/sleep", []string{"5"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := sup.EnsureRunning(ctx); err == nil {
+		t.Fatal("expected error when the server never becomes healthy before ctx expires")
+	}
+	sup.Close()
+}
+
+func TestEnsureRunning_RespawnsAfterProcessExit(t *testing.T) {
+	sup := NewLocalServerSupervisor("http://127.0.0.1:1", "/bin/true", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	sup.EnsureRunning(ctx) // /bin/true exits immediately; the health check just times out
+
+	first := sup.cmd
+	<-sup.exited // wait for the Wait goroutine to observe the exit
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel2()
+	sup.EnsureRunning(ctx2)
+
+	if sup.cmd == first {
+		t.Fatal("expected EnsureRunning to spawn a new process once the previous one had exited")
+	}
+}
+
+func TestClose_NoopWithoutStartedProcess(t *testing.T) {
+	sup := NewLocalServerSupervisor("http://localhost:8080", "", nil)
+	if err := sup.Close(); err != nil {
+		t.Errorf("expected Close to be a no-op, got %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+package mcp
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+// EnvAuditSink names the environment variable that overrides a server's
+// audit.* config at runtime: "stderr", "file:/path/to/audit.ndjson", or
+// "multi:/path/to/audit.ndjson" (stderr plus the rotated file). Unset
+// falls back to config.AuditConfig, and an unset config.AuditConfig
+// falls back to AuditSinkStderr, StreamTap's original behavior.
+const EnvAuditSink = "AGENT_AUDIT_SINK"
+
+// defaultAuditRetention is how many gzipped rotated segments a
+// fileAuditSink keeps before deleting the oldest, when
+// config.AuditConfig.Retention is unset.
+const defaultAuditRetention = 5
+
+// AuditSink is a destination for AuditRecord NDJSON lines. Implementations
+// must be safe for concurrent use: handleToolsCall may be invoked
+// concurrently by the SSE transport, and every request's StreamTap shares
+// the server's sink.
+type AuditSink interface {
+	Write(AuditRecord) error
+	Close() error
+}
+
+// NewAuditSink builds the AuditSink a server's StreamTap(s) should write
+// to, preferring EnvAuditSink over cfg when both are set. A nil cfg is
+// treated the same as a zero-valued config.AuditConfig.
+func NewAuditSink(cfg *config.AuditConfig) (AuditSink, error) {
+	if raw := os.Getenv(EnvAuditSink); raw != "" {
+		return newAuditSinkFromEnv(raw)
+	}
+	if cfg == nil {
+		cfg = &config.AuditConfig{}
+	}
+	return newAuditSinkFromConfig(cfg)
+}
+
+// newAuditSinkFromEnv parses EnvAuditSink's "kind" or "kind:path" form.
+func newAuditSinkFromEnv(raw string) (AuditSink, error) {
+	kind, path, _ := strings.Cut(raw, ":")
+	return buildAuditSink(config.AuditSinkKind(kind), config.AuditConfig{Path: path})
+}
+
+func newAuditSinkFromConfig(cfg *config.AuditConfig) (AuditSink, error) {
+	return buildAuditSink(cfg.Sink, *cfg)
+}
+
+func buildAuditSink(kind config.AuditSinkKind, cfg config.AuditConfig) (AuditSink, error) {
+	switch kind {
+	case "", config.AuditSinkStderr:
+		return newStderrAuditSink(), nil
+	case config.AuditSinkFile:
+		return newFileAuditSinkFromConfig(cfg)
+	case config.AuditSinkMulti:
+		file, err := newFileAuditSinkFromConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &multiAuditSink{sinks: []AuditSink{newStderrAuditSink(), file}}, nil
+	default:
+		return nil, fmt.Errorf("mcp: unknown audit sink %q", kind)
+	}
+}
+
+func newFileAuditSinkFromConfig(cfg config.AuditConfig) (*fileAuditSink, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("mcp: audit file sink requires a path")
+	}
+	return newFileAuditSink(
+		cfg.Path,
+		int64(cfg.MaxSizeMB)*1024*1024,
+		time.Duration(cfg.MaxAgeHours)*time.Hour,
+		cfg.Retention,
+	)
+}
+
+// stderrAuditSink is StreamTap's original behavior: every record as one
+// NDJSON line on stderr.
+type stderrAuditSink struct {
+	mu sync.Mutex
+}
+
+func newStderrAuditSink() *stderrAuditSink {
+	return &stderrAuditSink{}
+}
+
+func (s *stderrAuditSink) Write(rec AuditRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = fmt.Fprintln(os.Stderr, string(data))
+	return err
+}
+
+func (s *stderrAuditSink) Close() error { return nil }
+
+// fileAuditSink appends NDJSON records to a file, rotating it once it
+// crosses maxBytes or has been open longer than maxAge (whichever comes
+// first; a zero threshold disables that trigger), gzipping the rotated
+// segment and pruning anything beyond retention.
+type fileAuditSink struct {
+	path      string
+	maxBytes  int64
+	maxAge    time.Duration
+	retention int
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+func newFileAuditSink(path string, maxBytes int64, maxAge time.Duration, retention int) (*fileAuditSink, error) {
+	if retention <= 0 {
+		retention = defaultAuditRetention
+	}
+	s := &fileAuditSink{path: path, maxBytes: maxBytes, maxAge: maxAge, retention: retention}
+	if err := s.openLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileAuditSink) openLocked() error {
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.file = f
+	s.size = info.Size()
+	s.openedAt = time.Now()
+	return nil
+}
+
+func (s *fileAuditSink) Write(rec AuditRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shouldRotateLocked() {
+		if err := s.rotateLocked(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(data)
+	s.size += int64(n)
+	return err
+}
+
+func (s *fileAuditSink) shouldRotateLocked() bool {
+	if s.maxBytes > 0 && s.size >= s.maxBytes {
+		return true
+	}
+	if s.maxAge > 0 && time.Since(s.openedAt) >= s.maxAge {
+		return true
+	}
+	return false
+}
+
+// rotateLocked closes the active file, gzips it alongside with a
+// nanosecond-timestamp suffix (so lexical and chronological order
+// agree), opens a fresh file in its place, and prunes rotated segments
+// beyond retention.
+func (s *fileAuditSink) rotateLocked() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+
+	rotated := fmt.Sprintf("%s.%d.gz", s.path, time.Now().UnixNano())
+	if err := gzipFile(s.path, rotated); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path); err != nil {
+		return err
+	}
+	if err := s.pruneLocked(); err != nil {
+		fmt.Fprintf(os.Stderr, "mcp: pruning rotated audit logs: %v\n", err)
+	}
+	return s.openLocked()
+}
+
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gw := gzip.NewWriter(out)
+	if _, err := io.Copy(gw, in); err != nil {
+		gw.Close()
+		return err
+	}
+	return gw.Close()
+}
+
+func (s *fileAuditSink) pruneLocked() error {
+	matches, err := filepath.Glob(s.path + ".*.gz")
+	if err != nil {
+		return err
+	}
+	if len(matches) <= s.retention {
+		return nil
+	}
+	sort.Strings(matches)
+	for _, old := range matches[:len(matches)-s.retention] {
+		if err := os.Remove(old); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fileAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// multiAuditSink fans a record out to every sink it wraps, joining any
+// write/close errors rather than stopping at the first one -- a rotation
+// hiccup in the file sink shouldn't suppress the stderr copy.
+type multiAuditSink struct {
+	sinks []AuditSink
+}
+
+func (m *multiAuditSink) Write(rec AuditRecord) error {
+	var errs []error
+	for _, sink := range m.sinks {
+		if err := sink.Write(rec); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *multiAuditSink) Close() error {
+	var errs []error
+	for _, sink := range m.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,192 @@
+package mcp
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func TestFileAuditSink_WritesNDJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	sink, err := newFileAuditSink(path, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("newFileAuditSink: %v", err)
+	}
+	defer sink.Close()
+
+	if err := sink.Write(AuditRecord{Type: "audit", Tool: "qmd.search", Status: "ok"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	sink.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var rec AuditRecord
+	if err := json.Unmarshal(data[:len(data)-1], &rec); err != nil {
+		t.Fatalf("Unmarshal: %v (data=%q)", err, data)
+	}
+	if rec.Tool != "qmd.search" {
+		t.Errorf("Tool = %q, want qmd.search", rec.Tool)
+	}
+}
+
+func TestFileAuditSink_RotatesOnSizeAndGzips(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	sink, err := newFileAuditSink(path, 1, 0, 3)
+	if err != nil {
+		t.Fatalf("newFileAuditSink: %v", err)
+	}
+	defer sink.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := sink.Write(AuditRecord{Type: "audit", Tool: "qmd.search", Status: "ok"}); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	matches, err := filepath.Glob(path + ".*.gz")
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	if len(matches) == 0 {
+		t.Fatal("expected at least one rotated .gz segment")
+	}
+
+	f, err := os.Open(matches[0])
+	if err != nil {
+		t.Fatalf("Open rotated segment: %v", err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	defer gr.Close()
+	scanner := bufio.NewScanner(gr)
+	if !scanner.Scan() {
+		t.Fatal("rotated segment should contain at least one NDJSON line")
+	}
+}
+
+func TestFileAuditSink_PrunesBeyondRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	sink, err := newFileAuditSink(path, 1, 0, 2)
+	if err != nil {
+		t.Fatalf("newFileAuditSink: %v", err)
+	}
+	defer sink.Close()
+
+	for i := 0; i < 10; i++ {
+		if err := sink.Write(AuditRecord{Type: "audit", Tool: "qmd.search", Status: "ok"}); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	matches, err := filepath.Glob(path + ".*.gz")
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	if len(matches) > 2 {
+		t.Errorf("got %d rotated segments, want at most 2 (retention)", len(matches))
+	}
+}
+
+func TestMultiAuditSink_FansOutToEverySink(t *testing.T) {
+	a := &recordingAuditSink{}
+	b := &recordingAuditSink{}
+	sink := &multiAuditSink{sinks: []AuditSink{a, b}}
+
+	if err := sink.Write(AuditRecord{Tool: "qmd.get"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if len(a.records) != 1 || len(b.records) != 1 {
+		t.Errorf("both sinks should have received the record, got a=%d b=%d", len(a.records), len(b.records))
+	}
+}
+
+func TestNewAuditSink_EnvOverridesConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	t.Setenv(EnvAuditSink, "file:"+path)
+
+	cfg := config.AuditConfig{Sink: config.AuditSinkStderr}
+	sink, err := NewAuditSink(&cfg)
+	if err != nil {
+		t.Fatalf("NewAuditSink: %v", err)
+	}
+	defer sink.Close()
+
+	if _, ok := sink.(*fileAuditSink); !ok {
+		t.Errorf("NewAuditSink should prefer %s over config, got %T", EnvAuditSink, sink)
+	}
+}
+
+func TestNewAuditSink_DefaultsToStderr(t *testing.T) {
+	sink, err := NewAuditSink(nil)
+	if err != nil {
+		t.Fatalf("NewAuditSink: %v", err)
+	}
+	defer sink.Close()
+
+	if _, ok := sink.(*stderrAuditSink); !ok {
+		t.Errorf("NewAuditSink(nil) = %T, want *stderrAuditSink", sink)
+	}
+}
+
+func TestStreamTap_Log_HashesLargeArgs(t *testing.T) {
+	sink := &recordingAuditSink{}
+	tap := newStreamTap(sink)
+
+	largeArgs := strings.Repeat("x", auditArgsHashThreshold+1)
+	tap.Log("qmd.multi_get", largeArgs, "ok", 5)
+
+	if len(sink.records) != 1 {
+		t.Fatalf("expected one record, got %d", len(sink.records))
+	}
+	rec := sink.records[0]
+	if rec.Args != "" {
+		t.Error("Args should be empty once argsSummary exceeds auditArgsHashThreshold")
+	}
+	if rec.ArgsHash == "" {
+		t.Error("ArgsHash should be set once argsSummary exceeds auditArgsHashThreshold")
+	}
+}
+
+func TestStreamTap_Log_KeepsSmallArgsVerbatim(t *testing.T) {
+	sink := &recordingAuditSink{}
+	tap := newStreamTap(sink)
+
+	tap.Log("qmd.get", `{"path":"a.md"}`, "ok", 1)
+
+	if len(sink.records) != 1 {
+		t.Fatalf("expected one record, got %d", len(sink.records))
+	}
+	rec := sink.records[0]
+	if rec.Args != `{"path":"a.md"}` {
+		t.Errorf("Args = %q, want the raw args summary", rec.Args)
+	}
+	if rec.ArgsHash != "" {
+		t.Error("ArgsHash should be empty for small argsSummary")
+	}
+}
+
+// recordingAuditSink is an AuditSink test double that just remembers
+// every record it was given, for asserting what Log/multiAuditSink wrote
+// without parsing files.
+type recordingAuditSink struct {
+	records []AuditRecord
+}
+
+func (r *recordingAuditSink) Write(rec AuditRecord) error {
+	r.records = append(r.records, rec)
+	return nil
+}
+
+func (r *recordingAuditSink) Close() error { return nil }
@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+// configReloadDebounce coalesces the burst of fsnotify events one save
+// often produces -- a WRITE plus a CHMOD, or an editor's rename-swap
+// REMOVE+CREATE -- into a single reload attempt.
+const configReloadDebounce = 200 * time.Millisecond
+
+// ConfigReloadRecord audits one live config reload attempt, logged via
+// StreamTap the same way AuditRecord audits a tool invocation, but as
+// its own "config-reload" record type rather than overloading "audit"
+// with fields (Tool, Args) that don't apply here.
+type ConfigReloadRecord struct {
+	Type       string `json:"type"`
+	Timestamp  int64  `json:"timestamp"`
+	Path       string `json:"path"`
+	TraceID    string `json:"trace_id"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// LogConfigReload writes a "config-reload" NDJSON audit record to stderr.
+func (t *StreamTap) LogConfigReload(path, status, errMsg string, durationMs int64) {
+	record := ConfigReloadRecord{
+		Type:       "config-reload",
+		Timestamp:  time.Now().UnixMilli(),
+		Path:       path,
+		TraceID:    t.TraceID,
+		Status:     status,
+		Error:      errMsg,
+		DurationMs: durationMs,
+	}
+	data, _ := json.Marshal(record)
+	fmt.Fprintln(os.Stderr, string(data))
+}
+
+// WatchConfig starts watching configPath for changes and hot-swaps
+// srv.config when it does, without restarting the server or dropping an
+// in-flight stdio session. It watches the file's directory rather than
+// the file itself: an editor's rename-swap save replaces the inode and
+// so fires CREATE for the original path, not WRITE, and a watch on the
+// (now-deleted) file alone would miss it. The returned watcher is the
+// caller's to Close when the server shuts down; the background reload
+// goroutine exits once it is.
+func (srv *Server) WatchConfig(configPath string) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(configPath)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	go srv.watchConfigLoop(watcher, configPath)
+	return watcher, nil
+}
+
+func (srv *Server) watchConfigLoop(watcher *fsnotify.Watcher, configPath string) {
+	target := filepath.Clean(configPath)
+	var debounce *time.Timer
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != target {
+				continue
+			}
+			if debounce == nil {
+				debounce = time.AfterFunc(configReloadDebounce, func() { srv.reloadConfig(configPath) })
+			} else {
+				debounce.Reset(configReloadDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "mcp: config watcher error: %v\n", err)
+		}
+	}
+}
+
+// reloadConfig re-reads configPath, validates it, and swaps it in for
+// srv.config only if both succeed -- an invalid edit (a syntax error
+// from a half-finished save, an unknown backend name) is logged and left
+// in place rather than taking a working server down with it.
+func (srv *Server) reloadConfig(configPath string) {
+	start := time.Now()
+
+	cfg, err := config.LoadConfigFromFile(configPath)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		srv.tap.LogConfigReload(configPath, "error", err.Error(), time.Since(start).Milliseconds())
+		return
+	}
+
+	srv.setConfig(cfg)
+	srv.tap.LogConfigReload(configPath, "ok", "", time.Since(start).Milliseconds())
+}
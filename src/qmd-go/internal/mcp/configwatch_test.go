@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReloadConfig_SwapsInValidConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "index.yaml")
+	if err := os.WriteFile(path, []byte("collections:\n  - name: docs\n    path: /docs\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	srv := newTestServer()
+	srv.reloadConfig(path)
+
+	cfg := srv.Config()
+	if len(cfg.Collections) != 1 || cfg.Collections[0].Name != "docs" {
+		t.Errorf("Config().Collections = %+v, want one collection named docs", cfg.Collections)
+	}
+}
+
+func TestReloadConfig_RejectsInvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "index.yaml")
+	if err := os.WriteFile(path, []byte("collections:\n  - name: \"\"\n    path: /docs\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	srv := newTestServer()
+	original := srv.Config()
+	srv.reloadConfig(path)
+
+	if srv.Config() != original {
+		t.Error("reloadConfig should leave the existing config in place when the new one fails Validate")
+	}
+}
+
+func TestReloadConfig_RejectsUnparsableYAML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "index.yaml")
+	if err := os.WriteFile(path, []byte(`{{{invalid yaml`), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	srv := newTestServer()
+	original := srv.Config()
+	srv.reloadConfig(path)
+
+	if srv.Config() != original {
+		t.Error("reloadConfig should leave the existing config in place when the file fails to parse")
+	}
+}
+
+func TestWatchConfig_ErrorsOnMissingDirectory(t *testing.T) {
+	srv := newTestServer()
+	_, err := srv.WatchConfig(filepath.Join(t.TempDir(), "does-not-exist", "index.yaml"))
+	if err == nil {
+		t.Error("WatchConfig should error when the config file's directory doesn't exist")
+	}
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultCallDeadline bounds a tools/call invocation when the caller's
+// params["_meta"]["deadline_ms"] doesn't set a tighter one.
+const defaultCallDeadline = 30 * time.Second
+
+// callRegistry tracks the context.CancelFunc for every in-flight
+// tools/call, keyed by its JSON-RPC request id, so the stdio reader loop
+// can cancel one in flight when it sees a matching
+// notifications/cancelled message -- modeled on the gonet.deadlineTimer
+// pattern of a per-request cancel closed either when the deadline fires
+// or an explicit cancellation arrives, whichever is first.
+type callRegistry struct {
+	mu     sync.Mutex
+	cancel map[interface{}]context.CancelFunc
+}
+
+func newCallRegistry() *callRegistry {
+	return &callRegistry{cancel: make(map[interface{}]context.CancelFunc)}
+}
+
+// register records cancel under id. A nil id (a notification, which
+// tools/call never is, but defensive regardless) is ignored since there
+// would be nothing a notifications/cancelled message could name.
+func (r *callRegistry) register(id interface{}, cancel context.CancelFunc) {
+	if id == nil {
+		return
+	}
+	r.mu.Lock()
+	r.cancel[id] = cancel
+	r.mu.Unlock()
+}
+
+// remove drops id's entry once its call has finished, so a stale
+// notifications/cancelled arriving after the response has already gone
+// out is a harmless no-op instead of cancelling a future request that
+// happens to reuse the id.
+func (r *callRegistry) remove(id interface{}) {
+	if id == nil {
+		return
+	}
+	r.mu.Lock()
+	delete(r.cancel, id)
+	r.mu.Unlock()
+}
+
+// cancelID cancels id's in-flight call, if any, and reports whether one
+// was found.
+func (r *callRegistry) cancelID(id interface{}) bool {
+	r.mu.Lock()
+	cancel, ok := r.cancel[id]
+	r.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// callContext builds the context a single tools/call handler runs
+// under. It honors a per-call deadline from params["_meta"]["deadline_ms"]
+// (the MCP convention for request-scoped metadata) when present, falling
+// back to defaultCallDeadline otherwise, and registers the returned
+// cancel func under id so handleCancelled can find it.
+func (srv *Server) callContext(id interface{}, params map[string]interface{}) (context.Context, context.CancelFunc) {
+	deadline := defaultCallDeadline
+	if meta, ok := params["_meta"].(map[string]interface{}); ok {
+		if ms, ok := meta["deadline_ms"].(float64); ok && ms > 0 {
+			deadline = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deadline)
+	srv.calls.register(id, cancel)
+	return ctx, cancel
+}
+
+// handleCancelled services a notifications/cancelled message: per the
+// MCP convention, its params carry the cancelled call's original request
+// id as "requestId". It has no response -- handleMessage returns nil for
+// it just like any other notification.
+func (srv *Server) handleCancelled(message map[string]interface{}) {
+	params, _ := message["params"].(map[string]interface{})
+	srv.calls.cancelID(params["requestId"])
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCallRegistry_CancelIDCancelsContext(t *testing.T) {
+	r := newCallRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	r.register("req-1", cancel)
+
+	if ok := r.cancelID("req-1"); !ok {
+		t.Fatal("cancelID should report true for a registered id")
+	}
+	select {
+	case <-ctx.Done():
+	default:
+		t.Error("cancelID should have cancelled the registered context")
+	}
+}
+
+func TestCallRegistry_CancelIDUnknownReturnsFalse(t *testing.T) {
+	r := newCallRegistry()
+	if ok := r.cancelID("does-not-exist"); ok {
+		t.Error("cancelID should report false for an id that was never registered")
+	}
+}
+
+func TestCallRegistry_RemoveStopsFutureCancel(t *testing.T) {
+	r := newCallRegistry()
+	_, cancel := context.WithCancel(context.Background())
+	r.register("req-1", cancel)
+	r.remove("req-1")
+
+	if ok := r.cancelID("req-1"); ok {
+		t.Error("cancelID should report false once the id has been removed")
+	}
+}
+
+func TestCallContext_UsesMetaDeadline(t *testing.T) {
+	srv := newTestServer()
+	params := map[string]interface{}{
+		"_meta": map[string]interface{}{"deadline_ms": float64(5)},
+	}
+
+	ctx, cancel := srv.callContext("req-1", params)
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("callContext should set a deadline when _meta.deadline_ms is present")
+	}
+	if d := time.Until(deadline); d > defaultCallDeadline {
+		t.Errorf("deadline %v should be much sooner than the %v default", d, defaultCallDeadline)
+	}
+}
+
+func TestCallContext_DefaultsWithoutMeta(t *testing.T) {
+	srv := newTestServer()
+	ctx, cancel := srv.callContext("req-1", map[string]interface{}{})
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("callContext should always set a deadline")
+	}
+	if d := time.Until(deadline); d <= 0 || d > defaultCallDeadline {
+		t.Errorf("deadline %v should be close to the %v default", d, defaultCallDeadline)
+	}
+}
+
+func TestHandleCancelled_CancelsRegisteredCall(t *testing.T) {
+	srv := newTestServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	srv.calls.register(float64(7), cancel)
+
+	srv.handleCancelled(map[string]interface{}{
+		"method": "notifications/cancelled",
+		"params": map[string]interface{}{"requestId": float64(7)},
+	})
+
+	select {
+	case <-ctx.Done():
+	default:
+		t.Error("notifications/cancelled should cancel the matching in-flight call")
+	}
+}
+
+func TestHandleMessage_NotificationsCancelled_NoResponse(t *testing.T) {
+	srv := newTestServer()
+	resp := srv.handleMessage(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "notifications/cancelled",
+		"params":  map[string]interface{}{"requestId": float64(1)},
+	})
+	if resp != nil {
+		t.Errorf("notifications/cancelled should produce no response, got %+v", resp)
+	}
+}
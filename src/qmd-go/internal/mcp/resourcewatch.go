@@ -0,0 +1,88 @@
+package mcp
+
+import "time"
+
+// resourcePollInterval is how often watchResourcesLoop re-checks every
+// subscribed resource's hash. Re-indexing happens out of process (the
+// `qmd index` CLI, a cron job, ...), so polling documents.hash is the
+// only way this server can learn a subscribed resource's content
+// changed -- there's no reverse-fsnotify equivalent over stdio to tell
+// it directly, the way WatchConfig can watch its own config file.
+const resourcePollInterval = 2 * time.Second
+
+// WatchResources starts a background goroutine that polls every
+// resources/subscribe'd URI for a changed hash and emits
+// notifications/resources/updated when one does. The returned func stops
+// the goroutine; call it when the server shuts down, the same way a
+// WatchConfig caller defers watcher.Close().
+func (srv *Server) WatchResources() func() {
+	stop := make(chan struct{})
+	go srv.watchResourcesLoop(stop)
+	return func() { close(stop) }
+}
+
+func (srv *Server) watchResourcesLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(resourcePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			srv.checkResourceSubscriptions()
+		}
+	}
+}
+
+// checkResourceSubscriptions re-reads the current hash of every
+// subscribed URI and notifies for any that changed since the last check
+// (or since resources/subscribe recorded its starting hash).
+func (srv *Server) checkResourceSubscriptions() {
+	srv.resourceSubsMu.Lock()
+	uris := make([]string, 0, len(srv.resourceSubs))
+	for uri := range srv.resourceSubs {
+		uris = append(uris, uri)
+	}
+	srv.resourceSubsMu.Unlock()
+
+	for _, uri := range uris {
+		collection, path, ok := parseResourceURI(uri)
+		if !ok {
+			continue
+		}
+		hash, err := srv.documentHash(collection, path)
+		if err != nil {
+			// A resource that no longer resolves (deleted, deactivated)
+			// has nothing to diff against; leave the subscription as-is
+			// rather than guessing at a deletion notification the MCP
+			// resources spec doesn't define.
+			continue
+		}
+
+		srv.resourceSubsMu.Lock()
+		last, tracked := srv.resourceSubs[uri]
+		changed := tracked && last != hash
+		if tracked {
+			srv.resourceSubs[uri] = hash
+		}
+		srv.resourceSubsMu.Unlock()
+
+		if changed {
+			srv.notifyResourceUpdated(uri)
+		}
+	}
+}
+
+// notifyResourceUpdated sends a notifications/resources/updated message
+// for uri via srv.notify, the same sink notifyProgress and notifyResult
+// use.
+func (srv *Server) notifyResourceUpdated(uri string) {
+	srv.notify(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "notifications/resources/updated",
+		"params": map[string]interface{}{
+			"uri": uri,
+		},
+	})
+}
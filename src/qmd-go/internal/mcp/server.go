@@ -2,12 +2,21 @@ package mcp
 
 import (
 	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/qmd/qmd-go/internal/anel"
 	"github.com/qmd/qmd-go/internal/config"
 	"github.com/qmd/qmd-go/internal/store"
 )
@@ -18,50 +27,143 @@ type AuditRecord struct {
 	Timestamp  int64  `json:"timestamp"`
 	Tool       string `json:"tool"`
 	TraceID    string `json:"trace_id"`
+	SessionID  string `json:"session_id,omitempty"`
 	Identity   string `json:"identity,omitempty"`
-	Args       string `json:"args"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	Args       string `json:"args,omitempty"`
+	// ArgsHash is a sha256 hex digest of Args, set instead of Args when
+	// the marshaled arguments exceed auditArgsHashThreshold -- large
+	// payloads (e.g. a multi_get batch) shouldn't bloat every audit
+	// record, and a hash is still enough to correlate records for the
+	// same call.
+	ArgsHash   string `json:"args_hash,omitempty"`
 	Status     string `json:"status"`
 	DurationMs int64  `json:"duration_ms"`
 }
 
-// StreamTap is an audit layer that logs every MCP tool invocation to stderr as NDJSON.
+// auditArgsHashThreshold is the marshaled-argument size, in bytes, above
+// which Log records a sha256 hash instead of the arguments themselves.
+const auditArgsHashThreshold = 2048
+
+// StreamTap is an audit layer that logs every MCP tool invocation as
+// NDJSON through an AuditSink (stderr by default).
 type StreamTap struct {
 	Identity string
 	TraceID  string
+	// SessionID is the caller's Mcp-Session-Id as seen by the SSE
+	// transport; empty on the stdio transport, which has no concept of
+	// a session independent of the process.
+	SessionID string
+	// RemoteAddr is the caller's address as seen by the SSE transport
+	// (http.Request.RemoteAddr); empty on the stdio transport, which has
+	// no concept of a remote peer.
+	RemoteAddr string
+
+	sink AuditSink
 }
 
-// NewStreamTap creates a StreamTap, reading identity and trace ID from environment.
+// NewStreamTap creates a StreamTap that logs to stderr, reading identity
+// and trace ID from environment. Prefer NewStreamTapWithConfig when a
+// config.Config is available, so AGENT_AUDIT_SINK/audit.* can select a
+// different sink.
 func NewStreamTap() *StreamTap {
+	return newStreamTap(newStderrAuditSink())
+}
+
+// NewStreamTapWithConfig creates a StreamTap whose sink is chosen by
+// NewAuditSink from cfg.Audit and EnvAuditSink, falling back to stderr
+// (with a warning) if building the configured sink fails -- a typo'd
+// audit file path shouldn't take the whole server down.
+func NewStreamTapWithConfig(cfg *config.Config) *StreamTap {
+	var auditCfg *config.AuditConfig
+	if cfg != nil {
+		auditCfg = &cfg.Audit
+	}
+	sink, err := NewAuditSink(auditCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mcp: audit sink disabled, falling back to stderr: %v\n", err)
+		sink = newStderrAuditSink()
+	}
+	return newStreamTap(sink)
+}
+
+func newStreamTap(sink AuditSink) *StreamTap {
 	identity := os.Getenv("AGENT_IDENTITY_TOKEN")
 	traceID := os.Getenv("AGENT_TRACE_ID")
 	if traceID == "" {
 		traceID = fmt.Sprintf("qmd-%d", time.Now().UnixNano())
 	}
-	return &StreamTap{Identity: identity, TraceID: traceID}
+	return &StreamTap{Identity: identity, TraceID: traceID, sink: sink}
 }
 
-// Log writes an NDJSON audit record to stderr.
+// Log writes an audit record to t's sink, hashing argsSummary instead of
+// recording it verbatim once it crosses auditArgsHashThreshold.
 func (t *StreamTap) Log(toolName, argsSummary, status string, durationMs int64) {
 	record := AuditRecord{
 		Type:       "audit",
 		Timestamp:  time.Now().UnixMilli(),
 		Tool:       toolName,
 		TraceID:    t.TraceID,
+		SessionID:  t.SessionID,
 		Identity:   t.Identity,
-		Args:       argsSummary,
+		RemoteAddr: t.RemoteAddr,
 		Status:     status,
 		DurationMs: durationMs,
 	}
-	data, _ := json.Marshal(record)
-	fmt.Fprintln(os.Stderr, string(data))
+	if len(argsSummary) > auditArgsHashThreshold {
+		sum := sha256.Sum256([]byte(argsSummary))
+		record.ArgsHash = hex.EncodeToString(sum[:])
+	} else {
+		record.Args = argsSummary
+	}
+
+	sink := t.sink
+	if sink == nil {
+		sink = newStderrAuditSink()
+	}
+	if err := sink.Write(record); err != nil {
+		fmt.Fprintf(os.Stderr, "mcp: writing audit record: %v\n", err)
+	}
 }
 
 // Server holds the MCP server state
 type Server struct {
 	store  *store.Store
-	config *config.Config
 	tap    *StreamTap
 	dryRun bool
+
+	// calls tracks in-flight tools/call invocations so a
+	// notifications/cancelled message can cancel one before its deadline.
+	calls *callRegistry
+
+	// configMu guards config so WatchConfig's reload goroutine can swap
+	// it out while handleMessage is reading it from another goroutine
+	// (SSE transport) or mid-request (stdio, reloading between messages).
+	configMu sync.RWMutex
+	config   *config.Config
+
+	// streaming records whether the client's initialize advertised
+	// capabilities.experimental.streaming, gating handleToolsCall's
+	// notifications/progress path for the search tools.
+	streaming atomic.Bool
+
+	// resourceSubsMu guards resourceSubs against concurrent access from
+	// handleResourcesSubscribe/handleResourcesUnsubscribe (called from
+	// handleMessage) and watchResourcesLoop's background poll.
+	resourceSubsMu sync.Mutex
+	// resourceSubs maps a subscribed qmd://collection/path URI to the
+	// document hash last observed for it, so watchResourcesLoop can tell
+	// a re-index changed the content apart from merely re-touching it.
+	resourceSubs map[string]string
+
+	// notify sends a fully-formed JSON-RPC notification (a
+	// notifications/progress or notifications/resources/updated
+	// message) to the client. Defaults to writing it framed to stdout,
+	// the stdio transport's only channel for anything outside a
+	// request/response pair; tests substitute an in-memory sink (see
+	// newTestServerWithNotify) to capture notifications without
+	// touching the real transport.
+	notify func(map[string]interface{})
 }
 
 // NewServer creates a new MCP server
@@ -70,11 +172,38 @@ func NewServer(s *store.Store, cfg *config.Config) *Server {
 	return &Server{
 		store:  s,
 		config: cfg,
-		tap:    NewStreamTap(),
+		tap:    NewStreamTapWithConfig(cfg),
 		dryRun: dryRun == "1" || dryRun == "true",
+		calls:  newCallRegistry(),
+		notify: writeNotificationToStdout,
 	}
 }
 
+// writeNotificationToStdout is the default Server.notify: it frames n
+// the same way a tools/call response is framed, since stdio has no
+// separate out-of-band channel -- notifications and responses share one
+// Content-Length-delimited stream.
+func writeNotificationToStdout(n map[string]interface{}) {
+	if err := writeFramedMessage(os.Stdout, n); err != nil {
+		fmt.Fprintf(os.Stderr, "mcp: writing notification: %v\n", err)
+	}
+}
+
+// Config returns the server's current configuration. Safe to call
+// concurrently with a WatchConfig reload swapping it out.
+func (srv *Server) Config() *config.Config {
+	srv.configMu.RLock()
+	defer srv.configMu.RUnlock()
+	return srv.config
+}
+
+// setConfig atomically replaces the server's configuration.
+func (srv *Server) setConfig(cfg *config.Config) {
+	srv.configMu.Lock()
+	srv.config = cfg
+	srv.configMu.Unlock()
+}
+
 // checkDryRun returns a dry-run message if dry-run mode is active.
 func (srv *Server) checkDryRun(toolName, argsSummary string) (string, bool, bool) {
 	if srv.dryRun {
@@ -86,7 +215,8 @@ func (srv *Server) checkDryRun(toolName, argsSummary string) (string, bool, bool
 
 // RunServer runs the MCP server (backward-compatible entry point)
 func RunServer(transport string, port int) error {
-	cfg, err := config.LoadConfig()
+	configPath := config.DefaultConfigFilePath()
+	cfg, err := config.LoadConfigFromFile(configPath)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
@@ -95,6 +225,15 @@ func RunServer(transport string, port int) error {
 		return fmt.Errorf("failed to create store: %w", err)
 	}
 	srv := NewServer(s, cfg)
+
+	if watcher, err := srv.WatchConfig(configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "mcp: config hot-reload disabled: %v\n", err)
+	} else {
+		defer watcher.Close()
+	}
+
+	defer srv.WatchResources()()
+
 	if transport == "stdio" {
 		return srv.runStdioServer()
 	}
@@ -110,59 +249,246 @@ func RunServerWithStore(transport string, port int, s *store.Store, cfg *config.
 	return srv.runSSEServer(port)
 }
 
+// runStdioServer speaks MCP over stdin/stdout: JSON-RPC 2.0 messages framed
+// by Content-Length headers, one message per frame, matching the LSP-style
+// framing the MCP stdio transport spec requires (a bare NDJSON line per
+// message is not spec-compliant and breaks clients that read the headers).
 func (srv *Server) runStdioServer() error {
 	fmt.Fprintln(os.Stderr, "Starting MCP server (stdio)")
 
-	scanner := bufio.NewScanner(os.Stdin)
-	// Increase buffer size for large messages
-	scanner.Buffer(make([]byte, 0, 1024*1024), 1024*1024)
+	reader := bufio.NewReader(os.Stdin)
+	var writeMu sync.Mutex
+	writeFrame := func(v interface{}) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := writeFramedMessage(os.Stdout, v); err != nil {
+			fmt.Fprintf(os.Stderr, "mcp: writing response: %v\n", err)
+		}
+	}
+	writeResponse := func(response map[string]interface{}) {
+		if response == nil {
+			return
+		}
+		writeFrame(response)
+	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.TrimSpace(line) == "" {
+	for {
+		payload, err := readFramedMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		var malformed *errMalformedJSONRPC
+		if errors.As(err, &malformed) {
+			// The frame itself was fine; only its JSON body wasn't, so
+			// the transport stays up and the caller gets a proper
+			// JSON-RPC error instead of the connection dying under it.
+			writeFrame(jsonrpcErrorResponse(nil, jsonrpcParseError, "Parse error"))
 			continue
 		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mcp: reading message: %v\n", err)
+			return err
+		}
 
-		var message map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &message); err != nil {
-			continue
+		switch v := payload.(type) {
+		case []interface{}:
+			if responses := srv.handleBatch(v); len(responses) > 0 {
+				writeFrame(responses)
+			}
+		case map[string]interface{}:
+			// tools/call runs in its own goroutine so the read loop stays
+			// free to deliver a notifications/cancelled message (or another
+			// request) while it's in flight -- a synchronous call here
+			// would block the whole transport on whatever deadline
+			// srv.callContext gave it. Every other method is cheap enough
+			// to run inline, and doing so keeps their responses in request
+			// order.
+			method, _ := v["method"].(string)
+			if method == "tools/call" {
+				go func(msg map[string]interface{}) {
+					writeResponse(srv.handleMessage(msg))
+				}(v)
+				continue
+			}
+			writeResponse(srv.handleMessage(v))
 		}
+	}
+}
+
+// errMalformedJSONRPC wraps a body that framed correctly (Content-Length
+// matched) but didn't decode as JSON, or decoded as neither a JSON
+// object nor a JSON array -- a per-message failure runStdioServer
+// answers with a -32700 Parse error rather than tearing down the whole
+// transport the way a framing-level error (a bad Content-Length header,
+// a truncated body) does.
+type errMalformedJSONRPC struct{ err error }
 
-		response := srv.handleMessage(message)
-		if response != nil {
-			data, err := json.Marshal(response)
+func (e *errMalformedJSONRPC) Error() string { return e.err.Error() }
+func (e *errMalformedJSONRPC) Unwrap() error { return e.err }
+
+// readFramedMessage reads one Content-Length-framed JSON-RPC payload
+// from r: a block of "Header: value" lines terminated by a blank line,
+// then exactly Content-Length bytes of JSON body. The body may be a
+// single request object or, for a JSON-RPC batch, an array of them --
+// the caller type-switches the result. Returns io.EOF once the stream is
+// exhausted between messages.
+func readFramedMessage(r *bufio.Reader) (interface{}, error) {
+	contentLength := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
 			if err != nil {
-				continue
+				return nil, fmt.Errorf("mcp: invalid Content-Length header %q: %w", value, err)
 			}
-			fmt.Println(string(data))
+			contentLength = n
 		}
 	}
+	if contentLength < 0 {
+		return nil, fmt.Errorf("mcp: message missing Content-Length header")
+	}
+
+	body := make([]byte, contentLength)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
 
-	return nil
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, &errMalformedJSONRPC{fmt.Errorf("mcp: decoding message body: %w", err)}
+	}
+	switch payload.(type) {
+	case map[string]interface{}, []interface{}:
+		return payload, nil
+	default:
+		return nil, &errMalformedJSONRPC{fmt.Errorf("mcp: message body is not a JSON object or array")}
+	}
 }
 
-func (srv *Server) runSSEServer(port int) error {
-	fmt.Fprintf(os.Stderr, "SSE transport not yet implemented, port %d\n", port)
-	return nil
+// writeFramedMessage writes v to w as a Content-Length-framed JSON-RPC
+// message, the mirror image of readFramedMessage.
+func writeFramedMessage(w io.Writer, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+// JSON-RPC 2.0's reserved error codes (https://www.jsonrpc.org/specification#error_object).
+// jsonrpcErrorResponse/handleMessage use these for transport-level
+// failures; a tool's own failure still goes through toolErrorResponse's
+// richer AnelError-shaped "data" field instead.
+const (
+	jsonrpcParseError     = -32700
+	jsonrpcInvalidRequest = -32600
+	jsonrpcMethodNotFound = -32601
+	jsonrpcInvalidParams  = -32602
+	jsonrpcInternalError  = -32603
+)
+
+// jsonrpcErrorResponse builds a bare JSON-RPC error object -- no "data"
+// field, unlike toolErrorResponse's AnelError-derived errors -- for
+// failures the dispatcher itself detects before any tool runs (an
+// unparseable body, a request naming no method, an unknown method).
+func jsonrpcErrorResponse(id interface{}, code int, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	}
 }
 
+// handleMessage dispatches a single decoded JSON-RPC message and returns
+// its response, or nil if message is a notification (no "id" key) --
+// per the JSON-RPC 2.0 spec, a server must never reply to one, even for
+// an unknown method. An id of nil is NOT the same as a missing one: a
+// request that explicitly sets "id": null still gets a reply, with that
+// same null id echoed back (handleBatch and runStdioServer's writeResponse
+// pass id, whatever its type, straight through rather than coercing it).
 func (srv *Server) handleMessage(message map[string]interface{}) map[string]interface{} {
 	method, _ := message["method"].(string)
-	id := message["id"]
+	id, hasID := message["id"]
 
+	var resp map[string]interface{}
 	switch method {
+	case "":
+		resp = jsonrpcErrorResponse(id, jsonrpcInvalidRequest, "Invalid Request")
 	case "initialize":
-		return srv.handleInitialize(id)
+		resp = srv.handleInitialize(id, message)
 	case "tools/list":
-		return srv.handleToolsList(id)
+		resp = srv.handleToolsList(id)
 	case "tools/call":
-		return srv.handleToolsCall(id, message)
+		resp = srv.handleToolsCall(id, message)
+	case "resources/list":
+		resp = srv.handleResourcesList(id)
+	case "resources/read":
+		resp = srv.handleResourcesRead(id, message)
+	case "resources/subscribe":
+		resp = srv.handleResourcesSubscribe(id, message)
+	case "resources/unsubscribe":
+		resp = srv.handleResourcesUnsubscribe(id, message)
+	case "notifications/cancelled":
+		srv.handleCancelled(message)
+		return nil
 	default:
+		resp = jsonrpcErrorResponse(id, jsonrpcMethodNotFound, "Method not found")
+	}
+
+	if !hasID {
 		return nil
 	}
+	return resp
 }
 
-func (srv *Server) handleInitialize(id interface{}) map[string]interface{} {
+// handleBatch runs every element of a JSON-RPC batch array through
+// handleMessage independently and collects the non-nil responses, in
+// the order the batch listed them. An element that isn't itself a JSON
+// object is reported as its own Invalid Request rather than failing the
+// whole batch. Per spec, a batch made up entirely of notifications gets
+// no reply at all, so an empty (nil) slice means "write nothing".
+func (srv *Server) handleBatch(elements []interface{}) []map[string]interface{} {
+	var responses []map[string]interface{}
+	for _, el := range elements {
+		msg, ok := el.(map[string]interface{})
+		if !ok {
+			responses = append(responses, jsonrpcErrorResponse(nil, jsonrpcInvalidRequest, "Invalid Request"))
+			continue
+		}
+		if resp := srv.handleMessage(msg); resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+	return responses
+}
+
+// handleInitialize answers the client's initialize request and records
+// whether it advertised capabilities.experimental.streaming, which gates
+// handleToolsCall's notifications/progress path for the search tools.
+func (srv *Server) handleInitialize(id interface{}, message map[string]interface{}) map[string]interface{} {
+	srv.streaming.Store(clientWantsStreaming(message))
+
 	return map[string]interface{}{
 		"jsonrpc": "2.0",
 		"id":      id,
@@ -171,77 +497,128 @@ func (srv *Server) handleInitialize(id interface{}) map[string]interface{} {
 			"version":         "0.1.0",
 			"protocolVersion": "2024-11-05",
 			"capabilities": map[string]interface{}{
-				"tools":     map[string]interface{}{},
-				"resources": map[string]interface{}{},
+				"tools":        map[string]interface{}{},
+				"resources":    map[string]interface{}{"subscribe": true},
+				"experimental": map[string]interface{}{"streaming": true},
 			},
 		},
 	}
 }
 
+// clientWantsStreaming reports whether an initialize message's
+// params.capabilities.experimental.streaming is set, the MCP convention
+// for opt-in experimental features a client declares support for.
+func clientWantsStreaming(message map[string]interface{}) bool {
+	params, _ := message["params"].(map[string]interface{})
+	caps, _ := params["capabilities"].(map[string]interface{})
+	experimental, _ := caps["experimental"].(map[string]interface{})
+	_, ok := experimental["streaming"]
+	return ok
+}
+
+// mcpToolAnelCommand maps an MCP tool name to the anel command whose
+// AnelSpec describes its input shape, so handleToolsList and
+// validateToolInput can both derive/reuse the spec instead of hand-rolling
+// a second copy of each schema. qmd.search is hybrid search, which lines
+// up with the anel "query" command, not "search" (BM25) -- the naming
+// diverged once the MCP tools were given their own qmd.-prefixed names.
+// Tools not listed here (query_stream, multi_get, list_collections,
+// status) have no anel command behind them and fall back to an inline
+// schema.
+var mcpToolAnelCommand = map[string]string{
+	"qmd.search":        "query",
+	"qmd.vector_search": "vsearch",
+	"qmd.bm25_search":   "search",
+	"qmd.get":           "get",
+}
+
+// toolInputSchema returns the JSON Schema for an MCP tool's arguments,
+// decoded from the anel.AnelSpec registered for its mapped command when
+// one exists, or fallback otherwise.
+func toolInputSchema(toolName string, fallback map[string]interface{}) map[string]interface{} {
+	anelCommand, ok := mcpToolAnelCommand[toolName]
+	if !ok {
+		return fallback
+	}
+	spec := anel.GetSpecForCommand(anelCommand)
+	if spec == nil {
+		return fallback
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(spec.InputSchema, &schema); err != nil {
+		return fallback
+	}
+	return schema
+}
+
 func (srv *Server) handleToolsList(id interface{}) map[string]interface{} {
-	return map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      id,
-		"result": map[string]interface{}{
-			"tools": []map[string]interface{}{
-				{
-					"name":        "search",
-					"description": "BM25 full-text search",
-					"inputSchema": map[string]interface{}{
-						"type": "object",
-						"properties": map[string]interface{}{
-							"query":      map[string]interface{}{"type": "string", "description": "Search query"},
-							"limit":      map[string]interface{}{"type": "integer", "description": "Max results"},
-							"collection": map[string]interface{}{"type": "string", "description": "Collection name"},
-						},
-						"required": []string{"query"},
-					},
-				},
-				{
-					"name":        "vsearch",
-					"description": "Vector semantic search",
-					"inputSchema": map[string]interface{}{
-						"type": "object",
-						"properties": map[string]interface{}{
-							"query":      map[string]interface{}{"type": "string", "description": "Search query"},
-							"limit":      map[string]interface{}{"type": "integer", "description": "Max results"},
-							"collection": map[string]interface{}{"type": "string", "description": "Collection name"},
-						},
-						"required": []string{"query"},
-					},
-				},
-				{
-					"name":        "query",
-					"description": "Hybrid search with reranking",
-					"inputSchema": map[string]interface{}{
-						"type": "object",
-						"properties": map[string]interface{}{
-							"query":      map[string]interface{}{"type": "string", "description": "Search query"},
-							"limit":      map[string]interface{}{"type": "integer", "description": "Max results"},
-							"collection": map[string]interface{}{"type": "string", "description": "Collection name"},
-						},
-						"required": []string{"query"},
-					},
+	tools := []map[string]interface{}{
+		{
+			"name":        "qmd.search",
+			"description": "Hybrid search (BM25 + vector) with RRF fusion and reranking",
+			"inputSchema": toolInputSchema("qmd.search", nil),
+		},
+		{
+			"name":        "qmd.vector_search",
+			"description": "Vector semantic search",
+			"inputSchema": toolInputSchema("qmd.vector_search", nil),
+		},
+		{
+			"name":        "qmd.bm25_search",
+			"description": "BM25 full-text search",
+			"inputSchema": toolInputSchema("qmd.bm25_search", nil),
+		},
+		{
+			"name":        "qmd.query_stream",
+			"description": "Hybrid search, streamed as notifications/message events per result instead of one final response",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"query":      map[string]interface{}{"type": "string", "description": "Search query"},
+					"limit":      map[string]interface{}{"type": "integer", "description": "Max results"},
+					"collection": map[string]interface{}{"type": "string", "description": "Collection name"},
 				},
-				{
-					"name":        "get",
-					"description": "Get document content",
-					"inputSchema": map[string]interface{}{
-						"type": "object",
-						"properties": map[string]interface{}{
-							"path":  map[string]interface{}{"type": "string", "description": "File path"},
-							"from":  map[string]interface{}{"type": "integer", "description": "Start line"},
-							"limit": map[string]interface{}{"type": "integer", "description": "Max lines"},
-						},
-						"required": []string{"path"},
+				"required": []string{"query"},
+			},
+		},
+		{
+			"name":        "qmd.get",
+			"description": "Get document content",
+			"inputSchema": toolInputSchema("qmd.get", nil),
+		},
+		{
+			"name":        "qmd.multi_get",
+			"description": "Get content for several paths in one call",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"paths": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "File paths to read",
 					},
+					"from":  map[string]interface{}{"type": "integer", "description": "Start line, applied to each path"},
+					"limit": map[string]interface{}{"type": "integer", "description": "Max lines, applied to each path"},
 				},
-				{
-					"name":        "status",
-					"description": "Show index status",
-				},
+				"required": []string{"paths"},
 			},
 		},
+		{
+			"name":        "qmd.list_collections",
+			"description": "List the collections configured in index.yaml",
+		},
+		{
+			"name":        "qmd.status",
+			"description": "Show index status",
+		},
+	}
+
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result": map[string]interface{}{
+			"tools": tools,
+		},
 	}
 }
 
@@ -250,91 +627,200 @@ func (srv *Server) handleToolsCall(id interface{}, message map[string]interface{
 	toolName, _ := params["name"].(string)
 	args, _ := params["arguments"].(map[string]interface{})
 
-	var content string
-	var isError bool
-
-	switch toolName {
-	case "search":
-		argsSummary, _ := json.Marshal(args)
-		if msg, _, isDry := srv.checkDryRun("search", string(argsSummary)); isDry {
-			content = msg
-		} else {
-			start := time.Now()
-			content, isError = srv.toolSearch(args)
-			status := "ok"
-			if isError {
-				status = "error"
-			}
-			srv.tap.Log("search", string(argsSummary), status, time.Since(start).Milliseconds())
-		}
-	case "vsearch":
-		argsSummary, _ := json.Marshal(args)
-		if msg, _, isDry := srv.checkDryRun("vsearch", string(argsSummary)); isDry {
-			content = msg
-		} else {
-			start := time.Now()
-			content, isError = srv.toolVSearch(args)
-			status := "ok"
-			if isError {
-				status = "error"
-			}
-			srv.tap.Log("vsearch", string(argsSummary), status, time.Since(start).Milliseconds())
-		}
-	case "query":
-		argsSummary, _ := json.Marshal(args)
-		if msg, _, isDry := srv.checkDryRun("query", string(argsSummary)); isDry {
-			content = msg
-		} else {
-			start := time.Now()
-			content, isError = srv.toolQuery(args)
-			status := "ok"
-			if isError {
-				status = "error"
-			}
-			srv.tap.Log("query", string(argsSummary), status, time.Since(start).Milliseconds())
+	if err := srv.validateToolInput(toolName, args); err != nil {
+		// Unlike a tool's own runtime failure (reported inside "result"
+		// via toolErrorResponse, per its doc comment), a request whose
+		// arguments never reach a tool is the dispatcher's own failure, so
+		// it gets a real top-level JSON-RPC error: -32603 if marshaling
+		// args itself is what failed (an internal error, not the caller's
+		// fault), -32602 if the args just didn't match the tool's schema.
+		var marshalErr *errToolArgsMarshal
+		if errors.As(err, &marshalErr) {
+			return jsonrpcErrorResponse(id, jsonrpcInternalError, "Internal error: "+err.Error())
 		}
-	case "get":
-		argsSummary, _ := json.Marshal(args)
-		if msg, _, isDry := srv.checkDryRun("get", string(argsSummary)); isDry {
-			content = msg
-		} else {
-			start := time.Now()
-			content, isError = srv.toolGet(args)
-			status := "ok"
-			if isError {
-				status = "error"
-			}
-			srv.tap.Log("get", string(argsSummary), status, time.Since(start).Milliseconds())
+		return jsonrpcErrorResponse(id, jsonrpcInvalidParams, "Invalid params: "+err.Error())
+	}
+
+	handler, ok := mcpToolHandlers[toolName]
+	if !ok {
+		return srv.toolErrorResponse(context.Background(), id, anel.Newf(anel.ErrorCodeInvalidInput, "unknown_tool", "unknown tool: %s", toolName))
+	}
+
+	argsSummary, _ := json.Marshal(args)
+	if msg, _, isDry := srv.checkDryRun(toolName, string(argsSummary)); isDry {
+		return srv.toolSuccessResponse(id, msg)
+	}
+
+	ctx, cancel := srv.callContext(id, params)
+	defer cancel()
+	defer srv.calls.remove(id)
+
+	start := time.Now()
+	content, err := srv.dispatchToolCall(ctx, toolName, params, args, handler)
+	status := "ok"
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// The handler failed because its context was cancelled or hit
+		// its deadline (not because of the failure itself), so report
+		// it as "cancelled" rather than "error" and normalize it to
+		// ErrorCodeTimeout -- the caller cares that it didn't finish in
+		// time, not which internal call noticed first.
+		status = "cancelled"
+		err = anel.Newf(anel.ErrorCodeTimeout, "call_cancelled", "tool call cancelled: %v", ctx.Err())
+	case err != nil:
+		status = "error"
+	}
+	srv.tap.Log(toolName, string(argsSummary), status, time.Since(start).Milliseconds())
+
+	if err != nil {
+		return srv.toolErrorResponse(ctx, id, err)
+	}
+	return srv.toolSuccessResponse(id, content)
+}
+
+// mcpSearchStreamers maps a search tool name to the store method that
+// streams its results over a channel, used by dispatchToolCall's
+// streaming path when the client opted into capabilities.experimental.streaming
+// and the request's _meta carries a progressToken.
+var mcpSearchStreamers = map[string]func(*store.Store, context.Context, string, store.SearchOptions) *store.SearchResultStream{
+	"qmd.search":        (*store.Store).HybridSearchStream,
+	"qmd.vector_search": (*store.Store).VectorSearchStream,
+	"qmd.bm25_search":   (*store.Store).BM25SearchStream,
+}
+
+// dispatchToolCall runs toolName's handler, taking the streaming path
+// instead when it's one of mcpSearchStreamers, the client advertised
+// streaming support at initialize, and the request supplied a
+// progressToken -- otherwise it falls back to the plain handler, same as
+// before streaming existed.
+func (srv *Server) dispatchToolCall(ctx context.Context, toolName string, params, args map[string]interface{}, handler func(*Server, context.Context, map[string]interface{}) (string, error)) (string, error) {
+	if streamer, ok := mcpSearchStreamers[toolName]; ok && srv.streaming.Load() {
+		if token, hasToken := progressToken(params); hasToken {
+			return srv.streamToolCall(ctx, streamer, token, args)
 		}
-	case "status":
-		argsSummary := "{}"
-		if msg, _, isDry := srv.checkDryRun("status", argsSummary); isDry {
-			content = msg
-		} else {
-			start := time.Now()
-			content, isError = srv.toolStatus()
-			status := "ok"
-			if isError {
-				status = "error"
-			}
-			srv.tap.Log("status", argsSummary, status, time.Since(start).Milliseconds())
+	}
+	return handler(srv, ctx, args)
+}
+
+// progressBatchSize is how many streamed search results streamToolCall
+// accumulates before emitting a notifications/progress message, so a
+// large result set doesn't turn into one notification per hit.
+const progressBatchSize = 10
+
+// streamToolCall runs a search tool's channel-based streaming variant,
+// emitting a notifications/progress message (carrying progressToken and
+// the batch of newly ranked results) every progressBatchSize results as
+// they arrive from the store. It still returns the full result set
+// formatted the same way the non-streaming handlers do, since the final
+// tools/call response carries the aggregated content regardless --
+// streaming only adds the interim notifications ahead of it.
+func (srv *Server) streamToolCall(ctx context.Context, streamer func(*store.Store, context.Context, string, store.SearchOptions) *store.SearchResultStream, token interface{}, args map[string]interface{}) (string, error) {
+	query, options := srv.parseSearchArgs(args)
+	if query == "" {
+		return "", anel.Newf(anel.ErrorCodeInvalidInput, "missing_query", "query is required")
+	}
+
+	stream := streamer(srv.store, ctx, query, options)
+	var all, batch []store.SearchResult
+	for result := range stream.Results() {
+		all = append(all, result)
+		batch = append(batch, result)
+		if len(batch) >= progressBatchSize {
+			srv.notifyProgress(token, len(all), options.Limit, batch)
+			batch = nil
 		}
-	default:
-		content = fmt.Sprintf("Unknown tool: %s", toolName)
-		isError = true
 	}
+	if len(batch) > 0 {
+		srv.notifyProgress(token, len(all), options.Limit, batch)
+	}
+
+	if err := stream.Err(); err != nil {
+		return "", err
+	}
+	return srv.formatSearchResults(all), nil
+}
 
+// notifyProgress sends a notifications/progress JSON-RPC notification
+// for an in-flight streaming tools/call, echoing the client's
+// progressToken so it can correlate updates with the request that's
+// still running, alongside the batch of results that arrived since the
+// last one. total is the caller's requested result limit and is omitted
+// from params when the call didn't specify one, since "progress out of
+// nothing" isn't meaningful.
+func (srv *Server) notifyProgress(token interface{}, progress, total int, batch []store.SearchResult) {
+	params := map[string]interface{}{
+		"progressToken": token,
+		"progress":      progress,
+		"results":       batch,
+	}
+	if total > 0 {
+		params["total"] = total
+	}
+	srv.notify(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "notifications/progress",
+		"params":  params,
+	})
+}
+
+// progressToken extracts params["_meta"]["progressToken"], the MCP
+// convention a client uses to opt a single tools/call into progress
+// notifications and correlate them back to its request.
+func progressToken(params map[string]interface{}) (interface{}, bool) {
+	meta, ok := params["_meta"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	token, ok := meta["progressToken"]
+	return token, ok
+}
+
+// toolSuccessResponse wraps a tool's text output in the standard MCP
+// tools/call result shape.
+func (srv *Server) toolSuccessResponse(id interface{}, content string) map[string]interface{} {
 	return map[string]interface{}{
 		"jsonrpc": "2.0",
 		"id":      id,
 		"result": map[string]interface{}{
 			"content": []map[string]interface{}{
-				{
-					"type": "text",
-					"text": content,
-				},
+				{"type": "text", "text": content},
 			},
-			"isError": isError,
+			"isError": false,
+		},
+	}
+}
+
+// toolErrorResponse converts err into an AnelError (unwrapping an
+// *anel.Error if present, via anel.FromError) and renders it as a JSON-RPC
+// error object whose "data" field preserves the trace ID and recovery
+// hints a client needs to act on the failure, instead of collapsing
+// everything to a plain-text message. ctx's active span (if any) fills in
+// SpanID alongside TraceID, so an external collector's trace can be
+// joined down to the span that failed; pass context.Background() from
+// call sites with no live span (e.g. a request rejected before
+// callContext ever ran).
+func (srv *Server) toolErrorResponse(ctx context.Context, id interface{}, err error) map[string]interface{} {
+	aerr := anel.FromError(err)
+	aerr.WithSpanContext(ctx)
+	if aerr.TraceID == nil {
+		aerr.WithTraceID(srv.tap.TraceID)
+	}
+
+	// Tool call errors are still reported inside "result" with
+	// isError: true, per the MCP tools/call convention -- a JSON-RPC
+	// top-level "error" is reserved for malformed requests, not tool
+	// failures a client should be able to show to an agent as a normal
+	// turn. The AnelError is attached in full via "data" so trace_id and
+	// recovery_hints survive for clients that want them.
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result": map[string]interface{}{
+			"content": []map[string]interface{}{
+				{"type": "text", "text": fmt.Sprintf("[%s] %s", aerr.ErrorCode, aerr.Message)},
+			},
+			"isError": true,
+			"data":    aerr,
 		},
 	}
 }
@@ -358,54 +844,148 @@ func (srv *Server) parseSearchArgs(args map[string]interface{}) (string, store.S
 	return query, options
 }
 
-func (srv *Server) toolSearch(args map[string]interface{}) (string, bool) {
+// mcpToolHandlers dispatches each MCP tool name to its implementation.
+// Kept as a map (rather than a switch in handleToolsCall) so adding a tool
+// only means adding one entry plus a handleToolsList schema, not touching
+// dispatch logic too.
+var mcpToolHandlers = map[string]func(*Server, context.Context, map[string]interface{}) (string, error){
+	"qmd.search":           (*Server).toolHybridSearch,
+	"qmd.vector_search":    (*Server).toolVectorSearch,
+	"qmd.bm25_search":      (*Server).toolBM25Search,
+	"qmd.query_stream":     (*Server).toolQueryStream,
+	"qmd.get":              (*Server).toolGet,
+	"qmd.multi_get":        (*Server).toolMultiGet,
+	"qmd.list_collections": (*Server).toolListCollections,
+	"qmd.status":           (*Server).toolStatus,
+}
+
+func (srv *Server) toolBM25Search(ctx context.Context, args map[string]interface{}) (string, error) {
+	query, options := srv.parseSearchArgs(args)
+	if query == "" {
+		return "", anel.Newf(anel.ErrorCodeInvalidInput, "missing_query", "query is required")
+	}
+
+	results, err := srv.store.BM25Search(ctx, query, options)
+	if err != nil {
+		return "", err
+	}
+
+	return srv.formatSearchResults(results), nil
+}
+
+func (srv *Server) toolVectorSearch(ctx context.Context, args map[string]interface{}) (string, error) {
 	query, options := srv.parseSearchArgs(args)
 	if query == "" {
-		return "Error: query is required", true
+		return "", anel.Newf(anel.ErrorCodeInvalidInput, "missing_query", "query is required")
 	}
 
-	results, err := srv.store.BM25Search(query, options)
+	results, err := srv.store.VectorSearch(ctx, query, options)
 	if err != nil {
-		return fmt.Sprintf("Error: %v", err), true
+		return "", err
 	}
 
-	return srv.formatSearchResults(results), false
+	return srv.formatSearchResults(results), nil
 }
 
-func (srv *Server) toolVSearch(args map[string]interface{}) (string, bool) {
+func (srv *Server) toolHybridSearch(ctx context.Context, args map[string]interface{}) (string, error) {
 	query, options := srv.parseSearchArgs(args)
 	if query == "" {
-		return "Error: query is required", true
+		return "", anel.Newf(anel.ErrorCodeInvalidInput, "missing_query", "query is required")
 	}
 
-	results, err := srv.store.VectorSearch(query, options)
+	results, err := srv.store.HybridSearch(ctx, query, options)
 	if err != nil {
-		return fmt.Sprintf("Error: %v", err), true
+		return "", err
 	}
 
-	return srv.formatSearchResults(results), false
+	return srv.formatSearchResults(results), nil
 }
 
-func (srv *Server) toolQuery(args map[string]interface{}) (string, bool) {
+// toolQueryStream runs a hybrid query through a store.QuerySession and
+// emits each result as a "notifications/message" JSON-RPC notification
+// as it arrives, for interactive clients that want to render hits
+// incrementally instead of waiting for the full ranked list. Its own
+// return value is just a final summary; the results themselves already
+// went out over stdout as notifications.
+func (srv *Server) toolQueryStream(ctx context.Context, args map[string]interface{}) (string, error) {
 	query, options := srv.parseSearchArgs(args)
 	if query == "" {
-		return "Error: query is required", true
+		return "", anel.Newf(anel.ErrorCodeInvalidInput, "missing_query", "query is required")
 	}
 
-	results, err := srv.store.HybridSearch(query, options)
+	session, err := srv.store.OpenSession(ctx, query, options)
 	if err != nil {
-		return fmt.Sprintf("Error: %v", err), true
+		return "", err
+	}
+	defer session.Close()
+
+	count := 0
+	for result := range session.Results() {
+		count++
+		srv.notifyResult(result)
+	}
+
+	if err := session.Err(); err != nil {
+		return "", err
 	}
+	return fmt.Sprintf("Streamed %d result(s)", count), nil
+}
 
-	return srv.formatSearchResults(results), false
+// notifyResult sends result as a JSON-RPC notification (no "id", so
+// clients don't try to correlate it with a response) via srv.notify.
+func (srv *Server) notifyResult(result store.SearchResult) {
+	srv.notify(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "notifications/message",
+		"params": map[string]interface{}{
+			"level": "info",
+			"data":  result,
+		},
+	})
 }
 
-func (srv *Server) toolGet(args map[string]interface{}) (string, bool) {
+func (srv *Server) toolGet(ctx context.Context, args map[string]interface{}) (string, error) {
 	path, _ := args["path"].(string)
 	if path == "" {
-		return "Error: path is required", true
+		return "", anel.Newf(anel.ErrorCodeInvalidInput, "missing_path", "path is required")
 	}
 
+	return readFileLines(path, args)
+}
+
+// toolMultiGet is qmd.get's batch form: it reads every path in args["paths"]
+// and returns them concatenated, each introduced by a "=== path ===" banner
+// so a client can tell the pieces apart without parsing structured output.
+// A path that fails to read gets an inline "Error: ..." line in its place
+// rather than failing the whole call, since the point of batching is to
+// still get the paths that did resolve.
+func (srv *Server) toolMultiGet(ctx context.Context, args map[string]interface{}) (string, error) {
+	rawPaths, _ := args["paths"].([]interface{})
+	if len(rawPaths) == 0 {
+		return "", anel.Newf(anel.ErrorCodeInvalidInput, "missing_paths", "paths is required")
+	}
+
+	var sb strings.Builder
+	for i, rp := range rawPaths {
+		path, _ := rp.(string)
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("=== %s ===\n", path))
+		content, err := readFileLines(path, args)
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("Error: %v\n", err))
+			continue
+		}
+		sb.WriteString(content)
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// readFileLines reads path and slices it to the from/limit line range
+// args carries, shared by qmd.get and qmd.multi_get.
+func readFileLines(path string, args map[string]interface{}) (string, error) {
 	fromLine := 0
 	if f, ok := args["from"].(float64); ok {
 		fromLine = int(f)
@@ -417,13 +997,10 @@ func (srv *Server) toolGet(args map[string]interface{}) (string, bool) {
 
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Sprintf("Error reading file: %v", err), true
+		return "", anel.Wrap(anel.ErrorCodeNotFound, "file_read_failed", err)
 	}
 
-	content := string(data)
-	lines := strings.Split(content, "\n")
-
-	// Apply from/limit
+	lines := strings.Split(string(data), "\n")
 	if fromLine > 0 && fromLine < len(lines) {
 		lines = lines[fromLine:]
 	}
@@ -431,13 +1008,29 @@ func (srv *Server) toolGet(args map[string]interface{}) (string, bool) {
 		lines = lines[:limit]
 	}
 
-	return strings.Join(lines, "\n"), false
+	return strings.Join(lines, "\n"), nil
 }
 
-func (srv *Server) toolStatus() (string, bool) {
-	stats, err := srv.store.GetStats()
+// toolListCollections reports the collections configured in index.yaml,
+// the same set qmd.search/-vector_search/-bm25_search fan out across when
+// no "collection" argument narrows them.
+func (srv *Server) toolListCollections(ctx context.Context, args map[string]interface{}) (string, error) {
+	cfg := srv.Config()
+	if len(cfg.Collections) == 0 {
+		return "No collections configured.", nil
+	}
+
+	var sb strings.Builder
+	for _, c := range cfg.Collections {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", c.Name, c.Path))
+	}
+	return sb.String(), nil
+}
+
+func (srv *Server) toolStatus(ctx context.Context, args map[string]interface{}) (string, error) {
+	stats, err := srv.store.GetStats(ctx)
 	if err != nil {
-		return fmt.Sprintf("Error: %v", err), true
+		return "", err
 	}
 
 	var sb strings.Builder
@@ -448,7 +1041,32 @@ func (srv *Server) toolStatus() (string, bool) {
 	sb.WriteString(fmt.Sprintf("Indexed:     %d\n", stats.IndexedCount))
 	sb.WriteString(fmt.Sprintf("Pending:     %d\n", stats.PendingCount))
 
-	return sb.String(), false
+	return sb.String(), nil
+}
+
+// errToolArgsMarshal wraps a failure to re-marshal a tool's already-decoded
+// arguments back to JSON for schema validation -- an internal failure on
+// qmd's side, unlike the arguments themselves not matching the schema.
+type errToolArgsMarshal struct{ err error }
+
+func (e *errToolArgsMarshal) Error() string { return e.err.Error() }
+func (e *errToolArgsMarshal) Unwrap() error { return e.err }
+
+// validateToolInput enforces the ANEL input schema for tools listed in
+// mcpToolAnelCommand, rejecting malformed arguments before dispatch rather
+// than inside each tool handler.
+func (srv *Server) validateToolInput(toolName string, args map[string]interface{}) error {
+	anelCommand, ok := mcpToolAnelCommand[toolName]
+	if !ok {
+		return nil
+	}
+
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return &errToolArgsMarshal{err}
+	}
+
+	return anel.ValidateInput(anelCommand, raw)
 }
 
 func (srv *Server) formatSearchResults(results []store.SearchResult) string {
@@ -471,3 +1089,167 @@ func (srv *Server) formatSearchResults(results []store.SearchResult) string {
 
 	return sb.String()
 }
+
+// resourceURI builds the qmd://collection/path URI resources/list and
+// resources/read both identify indexed documents by.
+func resourceURI(collection, path string) string {
+	return fmt.Sprintf("qmd://%s/%s", collection, path)
+}
+
+// parseResourceURI splits a qmd://collection/path URI back into its
+// collection and path parts.
+func parseResourceURI(uri string) (collection, path string, ok bool) {
+	rest := strings.TrimPrefix(uri, "qmd://")
+	if rest == uri {
+		return "", "", false
+	}
+	collection, path, ok = strings.Cut(rest, "/")
+	return collection, path, ok
+}
+
+// handleResourcesList enumerates every active document across every
+// configured collection as a qmd://collection/path resource, so an
+// MCP-aware client can browse the index rather than only searching it.
+func (srv *Server) handleResourcesList(id interface{}) map[string]interface{} {
+	var resources []map[string]interface{}
+
+	for _, c := range srv.Config().Collections {
+		db, err := srv.store.GetConnection(c.Name)
+		if err != nil {
+			continue
+		}
+		rows, err := db.Query(`SELECT path, title FROM documents WHERE collection = ? AND active = 1`, c.Name)
+		if err != nil {
+			continue
+		}
+		for rows.Next() {
+			var path, title string
+			if err := rows.Scan(&path, &title); err != nil {
+				continue
+			}
+			resources = append(resources, map[string]interface{}{
+				"uri":         resourceURI(c.Name, path),
+				"name":        title,
+				"description": fmt.Sprintf("%s (collection %s)", path, c.Name),
+				"mimeType":    "text/plain",
+			})
+		}
+		rows.Close()
+	}
+
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result": map[string]interface{}{
+			"resources": resources,
+		},
+	}
+}
+
+// handleResourcesRead fetches the indexed content behind a qmd://collection/path
+// URI.
+func (srv *Server) handleResourcesRead(id interface{}, message map[string]interface{}) map[string]interface{} {
+	params, _ := message["params"].(map[string]interface{})
+	uri, _ := params["uri"].(string)
+
+	collection, path, ok := parseResourceURI(uri)
+	if !ok {
+		return srv.toolErrorResponse(context.Background(), id, anel.Newf(anel.ErrorCodeInvalidInput, "invalid_resource_uri", "invalid resource uri: %s", uri))
+	}
+
+	db, err := srv.store.GetConnection(collection)
+	if err != nil {
+		return srv.toolErrorResponse(context.Background(), id, err)
+	}
+
+	var content string
+	row := db.QueryRow(`SELECT content.doc FROM documents
+		JOIN content ON content.hash = documents.hash
+		WHERE documents.collection = ? AND documents.path = ? AND documents.active = 1`, collection, path)
+	if err := row.Scan(&content); err != nil {
+		return srv.toolErrorResponse(context.Background(), id, anel.Wrap(anel.ErrorCodeNotFound, "resource_not_found", err))
+	}
+
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result": map[string]interface{}{
+			"contents": []map[string]interface{}{
+				{
+					"uri":      uri,
+					"mimeType": "text/plain",
+					"text":     content,
+				},
+			},
+		},
+	}
+}
+
+// documentHash looks up the hash documents.path currently resolves to,
+// the same value resources/read's content join keys on -- watchResourcesLoop
+// diffs this to tell a re-index that changed the content apart from one
+// that merely re-touched it.
+func (srv *Server) documentHash(collection, path string) (string, error) {
+	db, err := srv.store.GetConnection(collection)
+	if err != nil {
+		return "", err
+	}
+	var hash string
+	row := db.QueryRow(`SELECT hash FROM documents WHERE collection = ? AND path = ? AND active = 1`, collection, path)
+	if err := row.Scan(&hash); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// handleResourcesSubscribe records uri as subscribed, so a later
+// watchResourcesLoop poll that finds its hash changed emits a
+// notifications/resources/updated for it. Per the MCP spec the result is
+// an empty object; there's no payload to return beyond confirming the
+// subscription.
+func (srv *Server) handleResourcesSubscribe(id interface{}, message map[string]interface{}) map[string]interface{} {
+	params, _ := message["params"].(map[string]interface{})
+	uri, _ := params["uri"].(string)
+
+	collection, path, ok := parseResourceURI(uri)
+	if !ok {
+		return srv.toolErrorResponse(context.Background(), id, anel.Newf(anel.ErrorCodeInvalidInput, "invalid_resource_uri", "invalid resource uri: %s", uri))
+	}
+
+	hash, err := srv.documentHash(collection, path)
+	if err != nil {
+		return srv.toolErrorResponse(context.Background(), id, anel.Wrap(anel.ErrorCodeNotFound, "resource_not_found", err))
+	}
+
+	srv.resourceSubsMu.Lock()
+	if srv.resourceSubs == nil {
+		srv.resourceSubs = make(map[string]string)
+	}
+	srv.resourceSubs[uri] = hash
+	srv.resourceSubsMu.Unlock()
+
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result":  map[string]interface{}{},
+	}
+}
+
+// handleResourcesUnsubscribe drops uri from the subscribed set. Unlike
+// subscribe, unsubscribing from a URI that was never subscribed (or that
+// no longer resolves to a document) is not an error -- the end state the
+// caller wants is the same either way.
+func (srv *Server) handleResourcesUnsubscribe(id interface{}, message map[string]interface{}) map[string]interface{} {
+	params, _ := message["params"].(map[string]interface{})
+	uri, _ := params["uri"].(string)
+
+	srv.resourceSubsMu.Lock()
+	delete(srv.resourceSubs, uri)
+	srv.resourceSubsMu.Unlock()
+
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result":  map[string]interface{}{},
+	}
+}
@@ -1,12 +1,19 @@
 package mcp
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/qmd/qmd-go/internal/anel"
 	"github.com/qmd/qmd-go/internal/config"
+	"github.com/qmd/qmd-go/internal/store"
 )
 
 // newTestServer creates a Server with nil store and default config.
@@ -16,6 +23,21 @@ func newTestServer() *Server {
 	return NewServer(nil, cfg)
 }
 
+// newTestServerWithNotify creates a Server whose notify sink appends to
+// an in-memory slice instead of writing to stdout, so tests can assert
+// on notifications/progress and notifications/resources/updated
+// messages without going through the real stdio transport. The returned
+// slice is appended to in place; read it after the call(s) under test
+// complete.
+func newTestServerWithNotify() (*Server, *[]map[string]interface{}) {
+	srv := newTestServer()
+	var sent []map[string]interface{}
+	srv.notify = func(n map[string]interface{}) {
+		sent = append(sent, n)
+	}
+	return srv, &sent
+}
+
 // --- handleMessage dispatching ---
 
 func TestHandleMessage_Initialize(t *testing.T) {
@@ -63,7 +85,7 @@ func TestHandleMessage_ToolsCall(t *testing.T) {
 		"id":      float64(3),
 		"method":  "tools/call",
 		"params": map[string]interface{}{
-			"name":      "get",
+			"name":      "qmd.get",
 			"arguments": map[string]interface{}{"path": tmpFile},
 		},
 	}
@@ -82,8 +104,153 @@ func TestHandleMessage_UnknownMethod(t *testing.T) {
 		"method":  "nonexistent/method",
 	}
 	resp := srv.handleMessage(msg)
-	if resp != nil {
-		t.Errorf("expected nil response for unknown method, got %v", resp)
+	assertJSONRPC(t, resp, float64(99))
+	assertJSONRPCErrorCode(t, resp, jsonrpcMethodNotFound)
+}
+
+func TestHandleMessage_NotificationGetsNoResponse(t *testing.T) {
+	srv := newTestServer()
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "nonexistent/method",
+	}
+	if resp := srv.handleMessage(msg); resp != nil {
+		t.Errorf("expected no response for a notification (no id), got %v", resp)
+	}
+}
+
+func TestHandleMessage_NoMethodIsInvalidRequest(t *testing.T) {
+	srv := newTestServer()
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      "req-1",
+	}
+	resp := srv.handleMessage(msg)
+	assertJSONRPC(t, resp, "req-1")
+	assertJSONRPCErrorCode(t, resp, jsonrpcInvalidRequest)
+}
+
+func TestHandleMessage_PreservesNullID(t *testing.T) {
+	srv := newTestServer()
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      nil,
+		"method":  "tools/list",
+	}
+	resp := srv.handleMessage(msg)
+	if resp == nil {
+		t.Fatal("a request with an explicit null id is still a request, not a notification")
+	}
+	if id, ok := resp["id"]; !ok || id != nil {
+		t.Errorf("id = %v, want null preserved verbatim", resp["id"])
+	}
+}
+
+func TestHandleMessage_PreservesStringID(t *testing.T) {
+	srv := newTestServer()
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      "abc-123",
+		"method":  "tools/list",
+	}
+	resp := srv.handleMessage(msg)
+	assertJSONRPC(t, resp, "abc-123")
+}
+
+func TestHandleBatch_MixOfRequestsAndNotifications(t *testing.T) {
+	srv := newTestServer()
+	batch := []interface{}{
+		map[string]interface{}{"jsonrpc": "2.0", "id": float64(1), "method": "tools/list"},
+		map[string]interface{}{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": map[string]interface{}{}},
+		map[string]interface{}{"jsonrpc": "2.0", "id": float64(2), "method": "nonexistent/method"},
+	}
+	responses := srv.handleBatch(batch)
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 responses (notification dropped), got %d: %v", len(responses), responses)
+	}
+	assertJSONRPC(t, responses[0], float64(1))
+	assertJSONRPC(t, responses[1], float64(2))
+	assertJSONRPCErrorCode(t, responses[1], jsonrpcMethodNotFound)
+}
+
+func TestHandleBatch_AllNotificationsYieldsNoResponses(t *testing.T) {
+	srv := newTestServer()
+	batch := []interface{}{
+		map[string]interface{}{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": map[string]interface{}{}},
+	}
+	if responses := srv.handleBatch(batch); len(responses) != 0 {
+		t.Errorf("expected no responses for an all-notification batch, got %v", responses)
+	}
+}
+
+func TestHandleBatch_NonObjectElementIsInvalidRequest(t *testing.T) {
+	srv := newTestServer()
+	batch := []interface{}{"not-an-object"}
+	responses := srv.handleBatch(batch)
+	if len(responses) != 1 {
+		t.Fatalf("expected 1 response, got %d", len(responses))
+	}
+	assertJSONRPCErrorCode(t, responses[0], jsonrpcInvalidRequest)
+}
+
+func TestHandleMessage_ResourcesSubscribe(t *testing.T) {
+	srv := newTestServer()
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      float64(30),
+		"method":  "resources/subscribe",
+		"params":  map[string]interface{}{"uri": "not-a-qmd-uri"},
+	}
+	resp := srv.handleMessage(msg)
+	if resp == nil {
+		t.Fatal("expected non-nil response for resources/subscribe")
+	}
+	assertJSONRPC(t, resp, float64(30))
+	if _, ok := resp["error"]; !ok {
+		t.Error("expected an error response for an invalid resource uri")
+	}
+}
+
+func TestHandleMessage_ResourcesUnsubscribe(t *testing.T) {
+	srv := newTestServer()
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      float64(31),
+		"method":  "resources/unsubscribe",
+		"params":  map[string]interface{}{"uri": "qmd://notes/never-subscribed.md"},
+	}
+	resp := srv.handleMessage(msg)
+	if resp == nil {
+		t.Fatal("expected non-nil response for resources/unsubscribe")
+	}
+	assertJSONRPC(t, resp, float64(31))
+	if _, ok := resp["error"]; ok {
+		t.Errorf("unsubscribing from a never-subscribed uri should not error, got %v", resp["error"])
+	}
+}
+
+func TestHandleResourcesSubscribe_InvalidURI(t *testing.T) {
+	srv := newTestServer()
+	resp := srv.handleResourcesSubscribe(float64(1), map[string]interface{}{
+		"params": map[string]interface{}{"uri": "http://not-qmd-scheme"},
+	})
+	if _, ok := resp["error"]; !ok {
+		t.Error("expected an error for a uri outside the qmd:// scheme")
+	}
+}
+
+func TestHandleResourcesUnsubscribe_RemovesSubscription(t *testing.T) {
+	srv := newTestServer()
+	srv.resourceSubs = map[string]string{"qmd://notes/a.md": "somehash"}
+
+	resp := srv.handleResourcesUnsubscribe(float64(1), map[string]interface{}{
+		"params": map[string]interface{}{"uri": "qmd://notes/a.md"},
+	})
+	if _, ok := resp["error"]; ok {
+		t.Fatalf("unexpected error: %v", resp["error"])
+	}
+	if _, tracked := srv.resourceSubs["qmd://notes/a.md"]; tracked {
+		t.Error("expected resources/unsubscribe to drop the subscription")
 	}
 }
 
@@ -91,7 +258,7 @@ func TestHandleMessage_UnknownMethod(t *testing.T) {
 
 func TestHandleInitialize(t *testing.T) {
 	srv := newTestServer()
-	resp := srv.handleInitialize(float64(10))
+	resp := srv.handleInitialize(float64(10), map[string]interface{}{})
 
 	assertJSONRPC(t, resp, float64(10))
 
@@ -140,7 +307,10 @@ func TestHandleToolsList(t *testing.T) {
 		t.Fatal("tools is not []map[string]interface{}")
 	}
 
-	expectedTools := []string{"search", "vsearch", "query", "get", "status"}
+	expectedTools := []string{
+		"qmd.search", "qmd.vector_search", "qmd.bm25_search", "qmd.query_stream",
+		"qmd.get", "qmd.multi_get", "qmd.list_collections", "qmd.status",
+	}
 	if len(tools) != len(expectedTools) {
 		t.Fatalf("expected %d tools, got %d", len(expectedTools), len(tools))
 	}
@@ -158,8 +328,9 @@ func TestHandleToolsList_InputSchemas(t *testing.T) {
 	result := resp["result"].(map[string]interface{})
 	tools := result["tools"].([]map[string]interface{})
 
-	// search, vsearch, query, get should have inputSchema
-	toolsWithSchema := []string{"search", "vsearch", "query", "get"}
+	// qmd.search, qmd.vector_search, qmd.bm25_search, qmd.get should have
+	// an inputSchema derived from their mapped anel spec.
+	toolsWithSchema := []string{"qmd.search", "qmd.vector_search", "qmd.bm25_search", "qmd.get"}
 	for i, name := range toolsWithSchema {
 		schema, ok := tools[i]["inputSchema"].(map[string]interface{})
 		if !ok {
@@ -178,9 +349,12 @@ func TestHandleToolsList_InputSchemas(t *testing.T) {
 		}
 	}
 
-	// status tool (index 4) has no inputSchema
-	if _, ok := tools[4]["inputSchema"]; ok {
-		t.Error("status tool should not have inputSchema")
+	// qmd.list_collections and qmd.status (the last two tools) have no
+	// inputSchema.
+	for _, i := range []int{6, 7} {
+		if _, ok := tools[i]["inputSchema"]; ok {
+			t.Errorf("tool %q should not have inputSchema", tools[i]["name"])
+		}
 	}
 }
 
@@ -200,7 +374,7 @@ func TestToolsCall_Get_ValidFile(t *testing.T) {
 		"id":      float64(30),
 		"method":  "tools/call",
 		"params": map[string]interface{}{
-			"name":      "get",
+			"name":      "qmd.get",
 			"arguments": map[string]interface{}{"path": tmpFile},
 		},
 	}
@@ -223,7 +397,7 @@ func TestToolsCall_Get_MissingPath(t *testing.T) {
 		"id":      float64(31),
 		"method":  "tools/call",
 		"params": map[string]interface{}{
-			"name":      "get",
+			"name":      "qmd.get",
 			"arguments": map[string]interface{}{},
 		},
 	}
@@ -246,7 +420,7 @@ func TestToolsCall_Get_NonexistentFile(t *testing.T) {
 		"id":      float64(32),
 		"method":  "tools/call",
 		"params": map[string]interface{}{
-			"name":      "get",
+			"name":      "qmd.get",
 			"arguments": map[string]interface{}{"path": "/nonexistent/file.txt"},
 		},
 	}
@@ -257,7 +431,7 @@ func TestToolsCall_Get_NonexistentFile(t *testing.T) {
 	if !isError {
 		t.Error("expected isError=true for nonexistent file")
 	}
-	if !strings.Contains(text, "Error reading file") {
+	if !strings.Contains(text, "no such file") {
 		t.Errorf("expected file read error, got: %s", text)
 	}
 }
@@ -276,7 +450,7 @@ func TestToolsCall_Get_WithFromAndLimit(t *testing.T) {
 		"id":      float64(33),
 		"method":  "tools/call",
 		"params": map[string]interface{}{
-			"name": "get",
+			"name": "qmd.get",
 			"arguments": map[string]interface{}{
 				"path":  tmpFile,
 				"from":  float64(1),
@@ -318,9 +492,46 @@ func TestToolsCall_UnknownTool(t *testing.T) {
 	if !isError {
 		t.Error("expected isError=true for unknown tool")
 	}
-	if !strings.Contains(text, "Unknown tool") {
-		t.Errorf("expected 'Unknown tool' error, got: %s", text)
+	if !strings.Contains(text, "unknown tool") {
+		t.Errorf("expected 'unknown tool' error, got: %s", text)
+	}
+}
+
+// --- handleToolsCall: schema validation ---
+
+func TestToolsCall_SchemaViolation_IsInvalidParams(t *testing.T) {
+	srv := newTestServer()
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      float64(41),
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      "qmd.bm25_search",
+			"arguments": map[string]interface{}{}, // "query" is required
+		},
+	}
+	resp := srv.handleMessage(msg)
+	assertJSONRPC(t, resp, float64(41))
+	assertJSONRPCErrorCode(t, resp, jsonrpcInvalidParams)
+}
+
+func TestToolsCall_UnmarshalableArgs_IsInternalError(t *testing.T) {
+	srv := newTestServer()
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      float64(42),
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name": "qmd.bm25_search",
+			// A channel can't be marshaled back to JSON, so
+			// validateToolInput fails before it ever gets to check the
+			// schema -- that's qmd's own failure, not the caller's.
+			"arguments": map[string]interface{}{"query": "x", "bad": make(chan int)},
+		},
 	}
+	resp := srv.handleMessage(msg)
+	assertJSONRPC(t, resp, float64(42))
+	assertJSONRPCErrorCode(t, resp, jsonrpcInternalError)
 }
 
 // --- JSON-RPC format validation ---
@@ -350,7 +561,7 @@ func TestJSONRPC_Format_ToolsCall(t *testing.T) {
 		"id":      float64(101),
 		"method":  "tools/call",
 		"params": map[string]interface{}{
-			"name":      "get",
+			"name":      "qmd.get",
 			"arguments": map[string]interface{}{},
 		},
 	}
@@ -393,6 +604,28 @@ func assertJSONRPC(t *testing.T, resp map[string]interface{}, expectedID interfa
 	if resp["id"] != expectedID {
 		t.Errorf("id = %v, want %v", resp["id"], expectedID)
 	}
+	_, hasResult := resp["result"]
+	_, hasError := resp["error"]
+	if hasResult == hasError {
+		t.Errorf("response should have exactly one of result/error, got result=%v error=%v", hasResult, hasError)
+	}
+}
+
+// assertJSONRPCErrorCode checks resp is a JSON-RPC error response whose
+// error.code matches wantCode (one of the jsonrpc* constants).
+func assertJSONRPCErrorCode(t *testing.T, resp map[string]interface{}, wantCode int) {
+	t.Helper()
+	errObj, ok := resp["error"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("response has no error object: %v", resp)
+	}
+	code, ok := errObj["code"].(int)
+	if !ok {
+		t.Fatalf("error.code is not an int: %v", errObj["code"])
+	}
+	if code != wantCode {
+		t.Errorf("error.code = %d, want %d", code, wantCode)
+	}
 }
 
 // extractToolResult pulls the text and isError from a tools/call response.
@@ -413,3 +646,358 @@ func extractToolResult(t *testing.T, resp map[string]interface{}) (string, bool)
 	isError, _ := result["isError"].(bool)
 	return text, isError
 }
+
+// --- qmd.multi_get / qmd.list_collections ---
+
+func TestToolsCall_MultiGet_ReadsEachPath(t *testing.T) {
+	srv := newTestServer()
+	tmpDir := t.TempDir()
+	fileA := filepath.Join(tmpDir, "a.txt")
+	fileB := filepath.Join(tmpDir, "b.txt")
+	if err := os.WriteFile(fileA, []byte("hello a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fileB, []byte("hello b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      float64(50),
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      "qmd.multi_get",
+			"arguments": map[string]interface{}{"paths": []interface{}{fileA, fileB}},
+		},
+	}
+	resp := srv.handleMessage(msg)
+	assertJSONRPC(t, resp, float64(50))
+
+	text, isError := extractToolResult(t, resp)
+	if isError {
+		t.Errorf("expected no error, got: %s", text)
+	}
+	if !strings.Contains(text, "hello a") || !strings.Contains(text, "hello b") {
+		t.Errorf("expected both files' contents, got: %s", text)
+	}
+}
+
+func TestToolsCall_MultiGet_MissingPaths(t *testing.T) {
+	srv := newTestServer()
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      float64(51),
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      "qmd.multi_get",
+			"arguments": map[string]interface{}{},
+		},
+	}
+	resp := srv.handleMessage(msg)
+	text, isError := extractToolResult(t, resp)
+	if !isError {
+		t.Error("expected isError=true for missing paths")
+	}
+	if !strings.Contains(text, "paths is required") {
+		t.Errorf("expected 'paths is required' error, got: %s", text)
+	}
+}
+
+func TestToolsCall_ListCollections(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Collections = []config.CollectionConfig{{Name: "docs", Path: "./docs"}}
+	srv := NewServer(nil, cfg)
+
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      float64(52),
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      "qmd.list_collections",
+			"arguments": map[string]interface{}{},
+		},
+	}
+	resp := srv.handleMessage(msg)
+	text, isError := extractToolResult(t, resp)
+	if isError {
+		t.Errorf("expected no error, got: %s", text)
+	}
+	if !strings.Contains(text, "docs") {
+		t.Errorf("expected collection name in output, got: %s", text)
+	}
+}
+
+// --- error responses preserve trace_id/recovery_hints ---
+
+func TestToolErrorResponse_PreservesTraceIDInData(t *testing.T) {
+	srv := newTestServer()
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      float64(60),
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      "qmd.get",
+			"arguments": map[string]interface{}{},
+		},
+	}
+	resp := srv.handleMessage(msg)
+	result := resp["result"].(map[string]interface{})
+	data, ok := result["data"].(*anel.AnelError)
+	if !ok {
+		t.Fatal("result.data is not *anel.AnelError")
+	}
+	if data.TraceID == nil || *data.TraceID == "" {
+		t.Error("expected a non-empty trace_id")
+	}
+}
+
+// --- Content-Length framing ---
+
+func TestWriteReadFramedMessage_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	original := map[string]interface{}{"jsonrpc": "2.0", "id": float64(1), "method": "initialize"}
+	if err := writeFramedMessage(&buf, original); err != nil {
+		t.Fatalf("writeFramedMessage: %v", err)
+	}
+
+	payload, err := readFramedMessage(bufio.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("readFramedMessage: %v", err)
+	}
+	got, ok := payload.(map[string]interface{})
+	if !ok {
+		t.Fatalf("payload is not a map: %v", payload)
+	}
+	if got["method"] != "initialize" {
+		t.Errorf("method = %v, want initialize", got["method"])
+	}
+	if got["id"] != float64(1) {
+		t.Errorf("id = %v, want 1", got["id"])
+	}
+}
+
+func TestReadFramedMessage_MultipleMessagesInStream(t *testing.T) {
+	var buf bytes.Buffer
+	_ = writeFramedMessage(&buf, map[string]interface{}{"method": "initialize"})
+	_ = writeFramedMessage(&buf, map[string]interface{}{"method": "tools/list"})
+
+	r := bufio.NewReader(&buf)
+	firstPayload, err := readFramedMessage(r)
+	if err != nil {
+		t.Fatalf("first readFramedMessage: %v", err)
+	}
+	first := firstPayload.(map[string]interface{})
+	if first["method"] != "initialize" {
+		t.Errorf("first method = %v, want initialize", first["method"])
+	}
+
+	secondPayload, err := readFramedMessage(r)
+	if err != nil {
+		t.Fatalf("second readFramedMessage: %v", err)
+	}
+	second := secondPayload.(map[string]interface{})
+	if second["method"] != "tools/list" {
+		t.Errorf("second method = %v, want tools/list", second["method"])
+	}
+
+	if _, err := readFramedMessage(r); err == nil {
+		t.Error("expected io.EOF at end of stream")
+	}
+}
+
+func TestReadFramedMessage_BatchArray(t *testing.T) {
+	var buf bytes.Buffer
+	batch := []interface{}{
+		map[string]interface{}{"jsonrpc": "2.0", "id": float64(1), "method": "tools/list"},
+		map[string]interface{}{"jsonrpc": "2.0", "id": float64(2), "method": "tools/list"},
+	}
+	if err := writeFramedMessage(&buf, batch); err != nil {
+		t.Fatalf("writeFramedMessage: %v", err)
+	}
+
+	payload, err := readFramedMessage(bufio.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("readFramedMessage: %v", err)
+	}
+	elements, ok := payload.([]interface{})
+	if !ok {
+		t.Fatalf("payload is not a batch array: %v", payload)
+	}
+	if len(elements) != 2 {
+		t.Errorf("len(elements) = %d, want 2", len(elements))
+	}
+}
+
+func TestReadFramedMessage_MalformedJSONIsRecoverable(t *testing.T) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n%s", len("{not json"), "{not json")
+
+	_, err := readFramedMessage(bufio.NewReader(&buf))
+	if err == nil {
+		t.Fatal("expected an error for malformed JSON")
+	}
+	var malformed *errMalformedJSONRPC
+	if !errors.As(err, &malformed) {
+		t.Errorf("expected an *errMalformedJSONRPC, got %T: %v", err, err)
+	}
+}
+
+// --- streaming negotiation ---
+
+func TestHandleInitialize_AdvertisesStreamingCapability(t *testing.T) {
+	srv := newTestServer()
+	resp := srv.handleInitialize(float64(1), map[string]interface{}{})
+
+	caps := resp["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	experimental, ok := caps["experimental"].(map[string]interface{})
+	if !ok {
+		t.Fatal("capabilities missing 'experimental'")
+	}
+	if experimental["streaming"] != true {
+		t.Errorf("experimental.streaming = %v, want true", experimental["streaming"])
+	}
+}
+
+func TestHandleInitialize_RecordsClientStreamingOptIn(t *testing.T) {
+	srv := newTestServer()
+	if srv.streaming.Load() {
+		t.Fatal("streaming should default to false before initialize")
+	}
+
+	srv.handleInitialize(float64(1), map[string]interface{}{
+		"params": map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"experimental": map[string]interface{}{"streaming": true},
+			},
+		},
+	})
+	if !srv.streaming.Load() {
+		t.Error("expected streaming to be recorded after a client opts in")
+	}
+}
+
+func TestClientWantsStreaming_AbsentCapability(t *testing.T) {
+	if clientWantsStreaming(map[string]interface{}{}) {
+		t.Error("expected no streaming opt-in without params.capabilities.experimental.streaming")
+	}
+}
+
+func TestProgressToken_PresentAndAbsent(t *testing.T) {
+	params := map[string]interface{}{
+		"_meta": map[string]interface{}{"progressToken": "tok-1"},
+	}
+	token, ok := progressToken(params)
+	if !ok || token != "tok-1" {
+		t.Errorf("progressToken(%v) = %v, %v; want tok-1, true", params, token, ok)
+	}
+
+	if _, ok := progressToken(map[string]interface{}{}); ok {
+		t.Error("expected no progressToken without _meta")
+	}
+}
+
+func TestDispatchToolCall_FallsBackWithoutProgressToken(t *testing.T) {
+	srv := newTestServer()
+	srv.streaming.Store(true)
+
+	called := false
+	handler := func(_ *Server, _ context.Context, _ map[string]interface{}) (string, error) {
+		called = true
+		return "fallback", nil
+	}
+
+	content, err := srv.dispatchToolCall(context.Background(), "qmd.search", map[string]interface{}{}, map[string]interface{}{"query": "x"}, handler)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !called {
+		t.Error("expected the plain handler to run when no progressToken is present")
+	}
+	if content != "fallback" {
+		t.Errorf("content = %q, want fallback", content)
+	}
+}
+
+func TestNotifyProgress_IncludesTotalWhenLimitSet(t *testing.T) {
+	srv, sent := newTestServerWithNotify()
+
+	srv.notifyProgress("tok-1", 3, 10, []store.SearchResult{{Path: "a.md"}})
+
+	if len(*sent) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(*sent))
+	}
+	params := (*sent)[0]["params"].(map[string]interface{})
+	if params["progressToken"] != "tok-1" || params["progress"] != 3 || params["total"] != 10 {
+		t.Errorf("unexpected params: %v", params)
+	}
+}
+
+func TestNotifyProgress_OmitsTotalWithoutLimit(t *testing.T) {
+	srv, sent := newTestServerWithNotify()
+
+	srv.notifyProgress("tok-1", 3, 0, []store.SearchResult{{Path: "a.md"}})
+
+	params := (*sent)[0]["params"].(map[string]interface{})
+	if _, ok := params["total"]; ok {
+		t.Errorf("expected no total field when no limit was requested, got %v", params["total"])
+	}
+}
+
+// TestStreamToolCall_EmptyResultsSendsNoProgressButReturnsContent exercises
+// streamToolCall end to end against a real (collection-less) Store, using
+// an in-memory notify sink in place of the stdio transport: zero results
+// means zero progressBatchSize batches ever fill, so no
+// notifications/progress should fire, but the final tools/call content
+// must still reflect the (empty) result set.
+func TestStreamToolCall_EmptyResultsSendsNoProgressButReturnsContent(t *testing.T) {
+	srv, sent := newTestServerWithNotify()
+	s, err := store.New(&config.Config{})
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	srv.store = s
+
+	content, err := srv.streamToolCall(context.Background(), (*store.Store).BM25SearchStream, "tok-1", map[string]interface{}{"query": "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "No results found." {
+		t.Errorf("content = %q, want %q", content, "No results found.")
+	}
+	if len(*sent) != 0 {
+		t.Errorf("expected no notifications for an empty result set, got %d", len(*sent))
+	}
+}
+
+func TestDispatchToolCall_StreamsWhenProgressTokenPresent(t *testing.T) {
+	srv, sent := newTestServerWithNotify()
+	srv.streaming.Store(true)
+	s, err := store.New(&config.Config{})
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	srv.store = s
+
+	called := false
+	handler := func(_ *Server, _ context.Context, _ map[string]interface{}) (string, error) {
+		called = true
+		return "fallback", nil
+	}
+	params := map[string]interface{}{
+		"_meta": map[string]interface{}{"progressToken": "tok-2"},
+	}
+
+	content, err := srv.dispatchToolCall(context.Background(), "qmd.search", params, map[string]interface{}{"query": "x"}, handler)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Error("expected the streaming path, not the plain handler, when a progressToken is present")
+	}
+	if content != "No results found." {
+		t.Errorf("content = %q, want %q", content, "No results found.")
+	}
+	if len(*sent) != 0 {
+		t.Errorf("expected no notifications for an empty result set, got %d", len(*sent))
+	}
+}
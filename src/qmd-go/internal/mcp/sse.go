@@ -0,0 +1,242 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qmd/qmd-go/internal/anel"
+)
+
+// sseHeartbeatInterval is how often an idle SSE connection gets a
+// comment-only ping, so proxies and load balancers that kill connections
+// after a period of silence (typically 30-60s) don't mistake a quiet
+// client for a dead one.
+const sseHeartbeatInterval = 20 * time.Second
+
+// sseSession is one long-lived SSE connection: handleSSEMessage looks a
+// session up by its Mcp-Session-Id to deliver a tools/call response (and
+// any notifications/message events, e.g. from qmd.query_stream) over the
+// matching stream instead of the HTTP response that carried the request.
+type sseSession struct {
+	id       string
+	identity string
+	out      chan []byte
+}
+
+// sseTransport holds the session table and CORS policy for the HTTP+SSE
+// transport. It's kept separate from Server so runStdioServer's path
+// never pays for it, and so tests can exercise handleSSE/handleSSEMessage
+// against an httptest.Server without going through RunServer.
+type sseTransport struct {
+	srv *Server
+
+	mu       sync.Mutex
+	sessions map[string]*sseSession
+}
+
+func newSSETransport(srv *Server) *sseTransport {
+	return &sseTransport{srv: srv, sessions: make(map[string]*sseSession)}
+}
+
+// runSSEServer implements the MCP 2024-11-05 HTTP+SSE transport: clients
+// open a GET /sse stream to receive responses/notifications and POST
+// JSON-RPC requests to /message, correlated by an Mcp-Session-Id header.
+// A client that POSTs without ever opening /sse still gets a usable
+// reply -- handleSSEMessage falls back to answering inline -- but loses
+// out on server-initiated notifications like qmd.query_stream's.
+func (srv *Server) runSSEServer(port int) error {
+	transport := newSSETransport(srv)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", transport.handleSSE)
+	mux.HandleFunc("/message", transport.handleSSEMessage)
+
+	addr := fmt.Sprintf(":%d", port)
+	fmt.Fprintf(os.Stderr, "Starting MCP server (sse) on %s\n", addr)
+	return http.ListenAndServe(addr, withCORS(mux))
+}
+
+// withCORS lets a browser-based agent connect to the SSE transport from
+// a different origin: it answers preflight OPTIONS requests directly and
+// adds the Access-Control-* headers every other response needs.
+func withCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Mcp-Session-Id")
+		w.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleSSE opens the event stream half of the transport. It assigns a
+// fresh session ID unless the client already has one (e.g. it's
+// reconnecting after a dropped connection and wants its in-flight
+// responses), registers the session, and then blocks writing
+// "event: message" frames for whatever handleSSEMessage enqueues plus a
+// periodic heartbeat, until the client disconnects.
+func (t *sseTransport) handleSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	sessionID := r.Header.Get("Mcp-Session-Id")
+	if sessionID == "" {
+		sessionID = anel.GenerateSpanID()
+	}
+	session := t.registerSession(sessionID, bearerIdentity(r))
+	defer t.removeSession(sessionID)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Mcp-Session-Id", sessionID)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprintf(w, ": ping\n\n")
+			flusher.Flush()
+		case frame := <-session.out:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", frame)
+			flusher.Flush()
+		}
+	}
+}
+
+// handleSSEMessage is the POST half of the transport: it decodes one
+// JSON-RPC request, runs it through the same handleMessage the stdio
+// transport uses, and audits it with a StreamTap carrying the caller's
+// remote address and bearer-token identity instead of the
+// AGENT_IDENTITY_TOKEN env var the stdio path reads. If the caller named
+// a live SSE session, the reply is delivered there (so notifications and
+// the response interleave correctly); otherwise it's written directly as
+// the HTTP response.
+func (t *sseTransport) handleSSEMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var message map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		http.Error(w, fmt.Sprintf("invalid JSON-RPC request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	identity := bearerIdentity(r)
+	sessionID := r.Header.Get("Mcp-Session-Id")
+	session, hasSession := t.session(sessionID)
+
+	requestSrv := &Server{
+		store:  t.srv.store,
+		config: t.srv.Config(),
+		dryRun: t.srv.dryRun,
+		calls:  newCallRegistry(),
+		tap: &StreamTap{
+			Identity:   identity,
+			TraceID:    fmt.Sprintf("qmd-sse-%s", anel.GenerateSpanID()),
+			SessionID:  sessionID,
+			RemoteAddr: r.RemoteAddr,
+			sink:       t.srv.tap.sink,
+		},
+		notify: func(n map[string]interface{}) {
+			if !hasSession {
+				// Same trade-off handleSSE's doc comment already calls
+				// out: a caller that never opened /sse has no stream to
+				// deliver a notification on, so it's dropped rather than
+				// blocking the request or falling back to stdout (which
+				// isn't this transport's channel at all).
+				return
+			}
+			frame, err := json.Marshal(n)
+			if err != nil {
+				return
+			}
+			session.out <- frame
+		},
+	}
+	requestSrv.streaming.Store(t.srv.streaming.Load())
+
+	response := requestSrv.handleMessage(message)
+	if method, _ := message["method"].(string); method == "initialize" {
+		t.srv.streaming.Store(requestSrv.streaming.Load())
+	}
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	frame, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("encoding response: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if session, ok := t.session(sessionID); ok {
+		session.out <- frame
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(frame)
+}
+
+func (t *sseTransport) registerSession(id, identity string) *sseSession {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if session, ok := t.sessions[id]; ok {
+		return session
+	}
+	session := &sseSession{id: id, identity: identity, out: make(chan []byte, 16)}
+	t.sessions[id] = session
+	return session
+}
+
+func (t *sseTransport) removeSession(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.sessions, id)
+}
+
+func (t *sseTransport) session(id string) (*sseSession, bool) {
+	if id == "" {
+		return nil, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	session, ok := t.sessions[id]
+	return session, ok
+}
+
+// bearerIdentity extracts the token from an "Authorization: Bearer ..."
+// header, the SSE transport's equivalent of the stdio path's
+// AGENT_IDENTITY_TOKEN env var. Returns "" if the header is absent or
+// isn't a bearer token.
+func bearerIdentity(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if !strings.HasPrefix(auth, prefix) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(auth, prefix))
+}
@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestTransport() *sseTransport {
+	return newSSETransport(newTestServer())
+}
+
+func TestHandleSSEMessage_NoSessionRespondsInline(t *testing.T) {
+	transport := newTestTransport()
+	srv := httptest.NewServer(http.HandlerFunc(transport.handleSSEMessage))
+	defer srv.Close()
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      float64(1),
+		"method":  "initialize",
+	})
+	resp, err := http.Post(srv.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var decoded map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	assertJSONRPC(t, decoded, float64(1))
+}
+
+func TestHandleSSEMessage_WithSessionRoutesToSSEChannel(t *testing.T) {
+	transport := newTestTransport()
+	session := transport.registerSession("test-session", "")
+
+	srv := httptest.NewServer(http.HandlerFunc(transport.handleSSEMessage))
+	defer srv.Close()
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      float64(2),
+		"method":  "initialize",
+	})
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, bytes.NewReader(body))
+	req.Header.Set("Mcp-Session-Id", "test-session")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", resp.StatusCode)
+	}
+
+	select {
+	case frame := <-session.out:
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(frame, &decoded); err != nil {
+			t.Fatalf("decoding session frame: %v", err)
+		}
+		assertJSONRPC(t, decoded, float64(2))
+	default:
+		t.Fatal("expected a response queued on the session channel")
+	}
+}
+
+func TestHandleSSEMessage_RejectsNonPost(t *testing.T) {
+	transport := newTestTransport()
+	srv := httptest.NewServer(http.HandlerFunc(transport.handleSSEMessage))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", resp.StatusCode)
+	}
+}
+
+func TestBearerIdentity(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com", nil)
+	if got := bearerIdentity(req); got != "" {
+		t.Errorf("bearerIdentity() with no header = %q, want empty", got)
+	}
+
+	req.Header.Set("Authorization", "Bearer abc123")
+	if got := bearerIdentity(req); got != "abc123" {
+		t.Errorf("bearerIdentity() = %q, want abc123", got)
+	}
+}
+
+func TestWithCORS_PreflightHandledDirectly(t *testing.T) {
+	handler := withCORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("inner handler should not run for OPTIONS")
+	}))
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodOptions, srv.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("OPTIONS: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", resp.StatusCode)
+	}
+	if resp.Header.Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", resp.Header.Get("Access-Control-Allow-Origin"))
+	}
+}
@@ -0,0 +1,64 @@
+// Package otel wraps qmd's search/index entry points in OTel spans with a
+// consistent attribute set. anel.StartSpan/anel.TraceContext already give
+// qmd its tracer and W3C trace-context propagation; this package only adds
+// the fixed set of span names (query.parse, bm25.search, vector.search,
+// rerank, hybrid.merge) and attributes (collection, k, backend, latency,
+// hit count) those entry points share, so each call site doesn't
+// reimplement the same bookkeeping.
+package otel
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/qmd/qmd-go/internal/anel"
+)
+
+// Entry-point span names, matching the stages qmd's retrieval pipeline
+// passes a query through: intent/variant parsing, the two retrieval
+// backends, reranking, and RRF fusion across variants/backends.
+const (
+	SpanQueryParse   = "query.parse"
+	SpanBM25Search   = "bm25.search"
+	SpanVectorSearch = "vector.search"
+	SpanRerank       = "rerank"
+	SpanHybridMerge  = "hybrid.merge"
+)
+
+// Attrs describes the span attributes common to qmd's entry points: the
+// collection searched (empty for a cross-collection call), the requested
+// result count k, and which backend served it (empty where there's only
+// one, e.g. rerank or hybrid.merge).
+type Attrs struct {
+	Collection string
+	K          int
+	Backend    string
+}
+
+// Wrap runs fn inside a span named name (one of the Span* constants
+// above), tagging it with attrs plus the latency and hit count fn
+// reports once it returns -- neither is known until the call completes,
+// so they're recorded on the span rather than passed in as attrs. A
+// non-nil error fn returns is recorded on the span and also returned
+// to the caller unchanged.
+func Wrap(ctx context.Context, name string, attrs Attrs, fn func(ctx context.Context) (hits int, err error)) error {
+	ctx, span := anel.StartSpan(ctx, name,
+		attribute.String("qmd.collection", attrs.Collection),
+		attribute.Int("qmd.k", attrs.K),
+		attribute.String("qmd.backend", attrs.Backend),
+	)
+	defer span.End()
+
+	start := time.Now()
+	hits, err := fn(ctx)
+	span.SetAttributes(
+		attribute.Int64("qmd.latency_ms", time.Since(start).Milliseconds()),
+		attribute.Int("qmd.hit_count", hits),
+	)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
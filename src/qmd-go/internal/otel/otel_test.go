@@ -0,0 +1,44 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWrapReturnsFnResult(t *testing.T) {
+	err := Wrap(context.Background(), SpanBM25Search, Attrs{Collection: "docs", K: 10}, func(ctx context.Context) (int, error) {
+		return 3, nil
+	})
+
+	if err != nil {
+		t.Errorf("Wrap() err = %v, want nil", err)
+	}
+}
+
+func TestWrapPropagatesFnError(t *testing.T) {
+	wantErr := errors.New("backend unavailable")
+
+	err := Wrap(context.Background(), SpanVectorSearch, Attrs{Backend: "qdrant"}, func(ctx context.Context) (int, error) {
+		return 0, wantErr
+	})
+
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Wrap() err = %v, want %v", err, wantErr)
+	}
+}
+
+func TestWrapPassesContextThrough(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	var sawValue string
+
+	Wrap(ctx, SpanRerank, Attrs{}, func(ctx context.Context) (int, error) {
+		sawValue, _ = ctx.Value(key{}).(string)
+		return 0, nil
+	})
+
+	if sawValue != "value" {
+		t.Errorf("fn's ctx did not carry the caller's value, got %q", sawValue)
+	}
+}
@@ -0,0 +1,327 @@
+// Package qmdcontext implements Docker CLI-style "contexts": named bundles
+// of endpoint configuration (store path, LLM base URL, embed/rerank model
+// selection) that can be created, switched between, and exported/imported
+// as a unit, independent of the flat on-disk config.Config.
+package qmdcontext
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// EnvContext overrides the active context selection, taking precedence
+// over the persisted "current" pointer.
+const EnvContext = "QMD_CONTEXT"
+
+// DefaultContextName is used when no context has ever been created or selected.
+const DefaultContextName = "default"
+
+// LLMEndpoint describes how to reach the LLM provider for a context.
+type LLMEndpoint struct {
+	BaseURL  string `json:"base_url,omitempty"`
+	APIKeyRef string `json:"api_key_ref,omitempty"`
+}
+
+// ModelEndpoint describes local/remote model selection for embed or rerank.
+type ModelEndpoint struct {
+	Local  string `json:"local,omitempty"`
+	Remote string `json:"remote,omitempty"`
+}
+
+// Endpoints bundles every endpoint a context can override.
+type Endpoints struct {
+	Store  string        `json:"store,omitempty"`
+	LLM    LLMEndpoint   `json:"llm,omitempty"`
+	Embed  ModelEndpoint `json:"embed,omitempty"`
+	Rerank ModelEndpoint `json:"rerank,omitempty"`
+}
+
+// Context is a named, persisted bundle of endpoint configuration.
+type Context struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Endpoints   Endpoints         `json:"endpoints"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// CreateOptions configures a new context; zero values leave the
+// corresponding endpoint unset (or inherited from From).
+type CreateOptions struct {
+	Description string
+	From        string
+	Store       string
+	LLMURL      string
+	LLMAPIKeyRef string
+	EmbedLocal  string
+	EmbedRemote string
+	RerankLocal string
+	RerankRemote string
+}
+
+// rootDir returns ~/.qmd/contexts, creating it if necessary.
+func rootDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve home directory: %w", err)
+	}
+	dir := filepath.Join(home, ".qmd", "contexts")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func metaPath(name string) (string, error) {
+	dir, err := rootDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name, "meta.json"), nil
+}
+
+func currentPath() (string, error) {
+	dir, err := rootDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "current"), nil
+}
+
+// Create persists a new context named `name`. When opts.From names an
+// existing context, its endpoints are cloned as defaults before opts'
+// explicit fields are applied over them.
+func Create(name string, opts CreateOptions) (*Context, error) {
+	if name == "" {
+		return nil, fmt.Errorf("context name is required")
+	}
+
+	ctx := &Context{Name: name, Description: opts.Description, Metadata: map[string]string{}}
+
+	if opts.From != "" {
+		base, err := Load(opts.From)
+		if err != nil {
+			return nil, fmt.Errorf("failed to clone context %q: %w", opts.From, err)
+		}
+		ctx.Endpoints = base.Endpoints
+		if ctx.Description == "" {
+			ctx.Description = base.Description
+		}
+	}
+
+	if opts.Store != "" {
+		ctx.Endpoints.Store = opts.Store
+	}
+	if opts.LLMURL != "" {
+		ctx.Endpoints.LLM.BaseURL = opts.LLMURL
+	}
+	if opts.LLMAPIKeyRef != "" {
+		ctx.Endpoints.LLM.APIKeyRef = opts.LLMAPIKeyRef
+	}
+	if opts.EmbedLocal != "" {
+		ctx.Endpoints.Embed.Local = opts.EmbedLocal
+	}
+	if opts.EmbedRemote != "" {
+		ctx.Endpoints.Embed.Remote = opts.EmbedRemote
+	}
+	if opts.RerankLocal != "" {
+		ctx.Endpoints.Rerank.Local = opts.RerankLocal
+	}
+	if opts.RerankRemote != "" {
+		ctx.Endpoints.Rerank.Remote = opts.RerankRemote
+	}
+
+	if err := Save(ctx); err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// Save writes a context's meta.json, creating its directory if needed.
+func Save(ctx *Context) error {
+	path, err := metaPath(ctx.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ctx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Load reads a single context by name.
+func Load(name string) (*Context, error) {
+	path, err := metaPath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("context %q not found", name)
+		}
+		return nil, err
+	}
+	var ctx Context
+	if err := json.Unmarshal(data, &ctx); err != nil {
+		return nil, fmt.Errorf("context %q has corrupt meta.json: %w", name, err)
+	}
+	return &ctx, nil
+}
+
+// List returns every persisted context, sorted by name.
+func List() ([]*Context, error) {
+	dir, err := rootDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var contexts []*Context
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		ctx, err := Load(entry.Name())
+		if err != nil {
+			continue
+		}
+		contexts = append(contexts, ctx)
+	}
+	return contexts, nil
+}
+
+// Remove deletes a context's directory. Removing the active context clears
+// the current-context pointer.
+func Remove(name string) error {
+	dir, err := rootDir()
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(dir, name)); err != nil {
+		return err
+	}
+
+	if current, _ := Current(); current == name {
+		path, err := currentPath()
+		if err == nil {
+			os.Remove(path)
+		}
+	}
+	return nil
+}
+
+// Use persists `name` as the active context. It does not validate that the
+// context exists so `context use` can target a context created moments
+// later in a script, matching Docker CLI semantics.
+func Use(name string) error {
+	path, err := currentPath()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(name), 0644)
+}
+
+// Current returns the active context name: QMD_CONTEXT if set, otherwise
+// the persisted "current" pointer, otherwise DefaultContextName.
+func Current() (string, error) {
+	if env := os.Getenv(EnvContext); env != "" {
+		return env, nil
+	}
+
+	path, err := currentPath()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultContextName, nil
+		}
+		return "", err
+	}
+	return string(data), nil
+}
+
+// CurrentContext resolves and loads the active context, falling back to an
+// empty default context (no endpoint overrides) if none has been created.
+func CurrentContext() (*Context, error) {
+	name, err := Current()
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := Load(name)
+	if err != nil {
+		if name == DefaultContextName {
+			return &Context{Name: DefaultContextName}, nil
+		}
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// Export writes a context as a tar bundle containing its meta.json.
+func Export(name string, w io.Writer) error {
+	ctx, err := Load(name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ctx, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	if err := tw.WriteHeader(&tar.Header{
+		Name: "meta.json",
+		Mode: 0644,
+		Size: int64(len(data)),
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// Import reads a tar bundle produced by Export and persists it as a new
+// context, returning the imported Context.
+func Import(r io.Reader) (*Context, error) {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("bundle does not contain meta.json")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Name != "meta.json" {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		var ctx Context
+		if err := json.Unmarshal(data, &ctx); err != nil {
+			return nil, fmt.Errorf("bundle meta.json is invalid: %w", err)
+		}
+		if err := Save(&ctx); err != nil {
+			return nil, err
+		}
+		return &ctx, nil
+	}
+}
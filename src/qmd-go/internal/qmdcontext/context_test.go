@@ -0,0 +1,181 @@
+package qmdcontext
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// withHome points os.UserHomeDir at a temp directory for the duration of a test.
+func withHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	return home
+}
+
+func TestCreate_PersistsMeta(t *testing.T) {
+	home := withHome(t)
+
+	ctx, err := Create("staging", CreateOptions{
+		Description: "staging endpoints",
+		Store:       "/data/staging",
+		LLMURL:      "http://llm.staging:8080",
+		EmbedLocal:  "embeddinggemma-300M",
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if ctx.Endpoints.Store != "/data/staging" {
+		t.Errorf("Store = %q, want /data/staging", ctx.Endpoints.Store)
+	}
+
+	metaFile := filepath.Join(home, ".qmd", "contexts", "staging", "meta.json")
+	if _, err := os.Stat(metaFile); err != nil {
+		t.Fatalf("expected meta.json at %s: %v", metaFile, err)
+	}
+
+	loaded, err := Load("staging")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Endpoints.LLM.BaseURL != "http://llm.staging:8080" {
+		t.Errorf("LLM.BaseURL = %q, want http://llm.staging:8080", loaded.Endpoints.LLM.BaseURL)
+	}
+}
+
+func TestCreate_CloneFrom(t *testing.T) {
+	withHome(t)
+
+	if _, err := Create("base", CreateOptions{Store: "/data/base", EmbedLocal: "model-a"}); err != nil {
+		t.Fatalf("Create(base) failed: %v", err)
+	}
+
+	clone, err := Create("clone", CreateOptions{From: "base", EmbedRemote: "model-b-remote"})
+	if err != nil {
+		t.Fatalf("Create(clone) failed: %v", err)
+	}
+	if clone.Endpoints.Store != "/data/base" {
+		t.Errorf("cloned Store = %q, want /data/base", clone.Endpoints.Store)
+	}
+	if clone.Endpoints.Embed.Local != "model-a" {
+		t.Errorf("cloned Embed.Local = %q, want model-a", clone.Endpoints.Embed.Local)
+	}
+	if clone.Endpoints.Embed.Remote != "model-b-remote" {
+		t.Errorf("clone override Embed.Remote = %q, want model-b-remote", clone.Endpoints.Embed.Remote)
+	}
+}
+
+func TestUseAndCurrent_Switch(t *testing.T) {
+	withHome(t)
+
+	if _, err := Create("a", CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Create("b", CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if name, _ := Current(); name != DefaultContextName {
+		t.Errorf("Current() before any use = %q, want %q", name, DefaultContextName)
+	}
+
+	if err := Use("a"); err != nil {
+		t.Fatalf("Use(a) failed: %v", err)
+	}
+	if name, _ := Current(); name != "a" {
+		t.Errorf("Current() = %q, want a", name)
+	}
+
+	if err := Use("b"); err != nil {
+		t.Fatalf("Use(b) failed: %v", err)
+	}
+	if name, _ := Current(); name != "b" {
+		t.Errorf("Current() = %q, want b", name)
+	}
+}
+
+func TestCurrent_EnvOverride(t *testing.T) {
+	withHome(t)
+
+	if err := Use("a"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvContext, "env-context")
+
+	if name, _ := Current(); name != "env-context" {
+		t.Errorf("Current() with QMD_CONTEXT set = %q, want env-context", name)
+	}
+}
+
+func TestList(t *testing.T) {
+	withHome(t)
+
+	Create("one", CreateOptions{})
+	Create("two", CreateOptions{})
+
+	contexts, err := List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(contexts) != 2 {
+		t.Fatalf("List() returned %d contexts, want 2", len(contexts))
+	}
+}
+
+func TestRemove(t *testing.T) {
+	withHome(t)
+
+	Create("gone", CreateOptions{})
+	Use("gone")
+
+	if err := Remove("gone"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := Load("gone"); err == nil {
+		t.Error("expected Load to fail after Remove")
+	}
+	if name, _ := Current(); name != DefaultContextName {
+		t.Errorf("Current() after removing active context = %q, want %q", name, DefaultContextName)
+	}
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	withHome(t)
+
+	original, err := Create("roundtrip", CreateOptions{
+		Description: "round trip test",
+		Store:       "/data/roundtrip",
+		LLMURL:      "http://llm:9000",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Export("roundtrip", &buf); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	Remove("roundtrip")
+
+	imported, err := Import(&buf)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if imported.Name != original.Name {
+		t.Errorf("imported Name = %q, want %q", imported.Name, original.Name)
+	}
+	if imported.Endpoints.Store != original.Endpoints.Store {
+		t.Errorf("imported Store = %q, want %q", imported.Endpoints.Store, original.Endpoints.Store)
+	}
+
+	reloaded, err := Load("roundtrip")
+	if err != nil {
+		t.Fatalf("expected imported context to be persisted: %v", err)
+	}
+	if reloaded.Endpoints.LLM.BaseURL != "http://llm:9000" {
+		t.Errorf("reloaded LLM.BaseURL = %q, want http://llm:9000", reloaded.Endpoints.LLM.BaseURL)
+	}
+}
@@ -0,0 +1,419 @@
+// Package router implements LLM-driven query routing: classifying an
+// incoming query's intent, expanding it into several rewritten variants,
+// running the variants against whichever backend(s) the intent selects,
+// and fusing the results -- replacing the cli package's old hardcoded
+// classifyIntent keyword-list heuristic with a model call, cached in a
+// sqlite llm_cache table the same way store.ExpandQuery caches LLM
+// paraphrases, only durable across process restarts instead of
+// in-memory.
+package router
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/qmd/qmd-go/internal/config"
+	"github.com/qmd/qmd-go/internal/llm"
+	"github.com/qmd/qmd-go/internal/otel"
+	"github.com/qmd/qmd-go/internal/store"
+)
+
+// Decision is one query's routing outcome: the Intent llm.Router.ClassifyQuery
+// (or classifyHeuristic, when no query_expansion model is configured)
+// picked, the Variants it ran, Entities extracted alongside the intent,
+// and whether the decision was served from the llm_cache table instead
+// of a fresh model call. This is exactly what the `agent` command's
+// --verbose output reports, so users can see which backend fired and
+// why.
+type Decision struct {
+	Intent   llm.Intent
+	Variants []string
+	Entities []string
+	Cached   bool
+	// Reranked reports whether Route's Rerank step actually ran (a
+	// rerank model must be configured as well as requested).
+	Reranked bool
+}
+
+// Result is Route's return value: the fused (and optionally reranked)
+// results plus the Decision that produced them.
+type Result struct {
+	Decision Decision
+	Results  []store.SearchResult
+}
+
+// Router classifies and routes queries across store.Store's retrieval
+// methods. It shares its llm.Router with store (via store.Store.LLMRouter)
+// rather than constructing its own, so routing doesn't start a second
+// llama-server supervisor when config.LlamaServerConfig is set.
+type Router struct {
+	store  *store.Store
+	llm    *llm.Router
+	config *config.Config
+
+	cacheOnce sync.Once
+	cacheDB   *sql.DB
+	cacheErr  error
+}
+
+// Store returns the router's underlying store.Store, for callers (like
+// the `agent` command's REPL) that need to bypass routing and call a
+// specific retriever directly.
+func (r *Router) Store() *store.Store {
+	return r.store
+}
+
+// Close releases the router cache database, if Route ever opened one.
+// Safe to call even when it never did.
+func (r *Router) Close() error {
+	if r.cacheDB == nil {
+		return nil
+	}
+	return r.cacheDB.Close()
+}
+
+// New builds a Router over an already-constructed store.Store, reusing
+// its llm.Router.
+func New(cfg *config.Config, s *store.Store) *Router {
+	return &Router{store: s, llm: s.LLMRouter(), config: cfg}
+}
+
+// Options configures a Route call. Rerank overrides
+// config.RouterConfig.Rerank for this call when non-nil; a nil Rerank
+// defers to the configured default.
+type Options struct {
+	store.SearchOptions
+	Rerank *bool
+}
+
+// Route classifies query (via the cached or freshly generated
+// Decision), runs each of its Variants against the Intent's backend(s),
+// fuses the per-variant result lists with Reciprocal Rank Fusion, and --
+// when enabled -- reranks the top RerankTopN with config.Models.Rerank.
+func (r *Router) Route(ctx context.Context, query string, opts Options) (*Result, error) {
+	decision, err := r.classify(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := decision.Variants
+	if len(variants) == 0 {
+		variants = []string{query}
+	}
+
+	lists := make([]store.FusionList, 0, len(variants))
+	for i, variant := range variants {
+		results, err := r.retrieve(ctx, decision.Intent, variant, opts.SearchOptions)
+		if err != nil {
+			continue
+		}
+		lists = append(lists, store.FusionList{
+			Source:  fmt.Sprintf("variant%d", i),
+			Results: results,
+		})
+	}
+
+	strategy, err := store.ResolveFusionStrategy(store.FusionRRF)
+	if err != nil {
+		return nil, err
+	}
+	fused := strategy.Fuse(lists, store.FusionOptions{K: store.DefaultRRFK})
+
+	if opts.Limit > 0 && len(fused) > opts.Limit {
+		fused = fused[:opts.Limit]
+	}
+
+	rerank := r.config.Router.Rerank
+	if opts.Rerank != nil {
+		rerank = *opts.Rerank
+	}
+	if rerank {
+		if reranked, err := r.rerank(ctx, query, fused); err == nil {
+			fused = reranked
+			decision.Reranked = true
+		}
+	}
+
+	store.ApplySort(fused, opts.SortSpec)
+	return &Result{Decision: *decision, Results: fused}, nil
+}
+
+// retrieve runs query against the backend(s) intent selects: BM25Search
+// for IntentKeyword, VectorSearch for IntentSemantic, CodeSearch for
+// IntentCode, and HybridSearch (BM25 fused with vector) for IntentHybrid
+// or anything unrecognized.
+func (r *Router) retrieve(ctx context.Context, intent llm.Intent, query string, opts store.SearchOptions) ([]store.SearchResult, error) {
+	switch intent {
+	case llm.IntentKeyword:
+		return r.store.BM25Search(ctx, query, opts)
+	case llm.IntentSemantic:
+		return r.store.VectorSearch(ctx, query, opts)
+	case llm.IntentCode:
+		return r.store.CodeSearch(ctx, query, opts, false)
+	default:
+		return r.store.HybridSearch(ctx, query, opts)
+	}
+}
+
+// rerank scores results against query with config.Models.Rerank,
+// bounded to config.Router.RerankTopN candidates (defaulting to 20),
+// and reorders that prefix by score descending, leaving any results
+// beyond the window in their fused order.
+func (r *Router) rerank(ctx context.Context, query string, results []store.SearchResult) ([]store.SearchResult, error) {
+	var reranked []store.SearchResult
+	err := otel.Wrap(ctx, otel.SpanRerank, otel.Attrs{K: r.config.Router.RerankTopN}, func(ctx context.Context) (int, error) {
+		var err error
+		reranked, err = r.rerankImpl(ctx, query, results)
+		return len(reranked), err
+	})
+	return reranked, err
+}
+
+// rerankImpl is rerank's body, split out so rerank can wrap it in a
+// single otel.Wrap span tagged with the reranked result count.
+func (r *Router) rerankImpl(ctx context.Context, query string, results []store.SearchResult) ([]store.SearchResult, error) {
+	topN := r.config.Router.RerankTopN
+	if topN <= 0 {
+		topN = 20
+	}
+	if len(results) == 0 {
+		return results, nil
+	}
+	if topN > len(results) {
+		topN = len(results)
+	}
+
+	window := results[:topN]
+	docs := make([]string, len(window))
+	for i, result := range window {
+		docs[i] = result.Title + "\n" + result.Path
+	}
+
+	scores, err := r.llm.Rerank(ctx, query, docs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range window {
+		if i < len(scores) {
+			window[i].Score = scores[i]
+		}
+	}
+
+	reranked := append([]store.SearchResult{}, window...)
+	store.ApplySort(reranked, []store.SortKey{{Field: "score", Desc: true}})
+	return append(reranked, results[topN:]...), nil
+}
+
+// classify resolves query's Decision, consulting the llm_cache table
+// before calling r.llm.ClassifyQuery, and falling back to a keyword
+// heuristic when no query_expansion model is configured or the model
+// call fails.
+func (r *Router) classify(ctx context.Context, query string) (*Decision, error) {
+	var decision *Decision
+	err := otel.Wrap(ctx, otel.SpanQueryParse, otel.Attrs{}, func(ctx context.Context) (int, error) {
+		var err error
+		decision, err = r.classifyImpl(ctx, query)
+		if decision != nil {
+			return len(decision.Variants), err
+		}
+		return 0, err
+	})
+	return decision, err
+}
+
+// classifyImpl is classify's body, split out so classify can wrap it
+// in a single otel.Wrap span tagged with the variant count it resolves.
+func (r *Router) classifyImpl(ctx context.Context, query string) (*Decision, error) {
+	model := ""
+	if m := r.config.Models.QueryExpansion; m != nil {
+		if m.Local != nil {
+			model = *m.Local
+		} else if m.Remote != nil {
+			model = *m.Remote
+		}
+	}
+
+	variants := r.config.Router.Variants
+	if variants <= 0 {
+		variants = 3
+	}
+	key := cacheKey(model, fmt.Sprintf("classify:v%d", variants), query)
+
+	if model != "" {
+		if cached, ok := r.cacheGet(key); ok {
+			cached.Cached = true
+			return &cached, nil
+		}
+	}
+
+	classification, err := r.llm.ClassifyQuery(ctx, query, variants)
+	if err != nil || classification == nil {
+		return classifyHeuristic(query), nil
+	}
+
+	decision := Decision{
+		Intent:   classification.Intent,
+		Variants: classification.Variants,
+		Entities: classification.Entities,
+	}
+	if model != "" {
+		r.cachePut(key, decision)
+	}
+	return &decision, nil
+}
+
+// classifyHeuristic is the no-query_expansion-model fallback: the same
+// keyword-list classification the old cli.classifyIntent used, kept
+// simple since it only runs when there's no model configured to do
+// better.
+func classifyHeuristic(query string) *Decision {
+	lower := strings.ToLower(query)
+
+	nlPatterns := []string{"explain", "describe", "what is", "how does", "why", "meaning"}
+	for _, p := range nlPatterns {
+		if strings.Contains(lower, p) {
+			return &Decision{Intent: llm.IntentSemantic, Variants: []string{query}}
+		}
+	}
+
+	techPatterns := []string{"error", "exception", "api", "function", "class", "method"}
+	for _, p := range techPatterns {
+		if strings.Contains(lower, p) {
+			return &Decision{Intent: llm.IntentKeyword, Variants: []string{query}}
+		}
+	}
+
+	return &Decision{Intent: llm.IntentHybrid, Variants: []string{query}}
+}
+
+// cacheKey hashes model+prompt+query with sha256 into the llm_cache
+// table's primary key, so the same query against a different model (or
+// the classification prompt changing) misses rather than returning a
+// stale decision.
+func cacheKey(model, prompt, query string) string {
+	sum := sha256.Sum256([]byte(model + "\x00" + prompt + "\x00" + query))
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheGet returns the cached Decision for key, if present and not yet
+// expired.
+func (r *Router) cacheGet(key string) (Decision, bool) {
+	db := r.db()
+	if db == nil {
+		return Decision{}, false
+	}
+
+	var response string
+	var expiresAt string
+	err := db.QueryRow(`SELECT response, expires_at FROM llm_cache WHERE key = ?`, key).
+		Scan(&response, &expiresAt)
+	if err != nil {
+		return Decision{}, false
+	}
+
+	expires, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil || time.Now().After(expires) {
+		return Decision{}, false
+	}
+
+	var decision Decision
+	if err := json.Unmarshal([]byte(response), &decision); err != nil {
+		return Decision{}, false
+	}
+	return decision, true
+}
+
+// cachePut stores decision under key with config.Router.CacheTTLSeconds
+// (default 24h), replacing any existing entry for key.
+func (r *Router) cachePut(key string, decision Decision) {
+	db := r.db()
+	if db == nil {
+		return
+	}
+
+	ttl := time.Duration(r.config.Router.CacheTTLSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	response, err := json.Marshal(decision)
+	if err != nil {
+		return
+	}
+
+	db.Exec(`
+		INSERT INTO llm_cache(key, model, query, response, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET
+			response = excluded.response,
+			created_at = excluded.created_at,
+			expires_at = excluded.expires_at
+	`, key, "", "", string(response), time.Now().Format(time.RFC3339), time.Now().Add(ttl).Format(time.RFC3339))
+}
+
+// db lazily opens (and schema-initializes) the router cache database at
+// config.CachePath/router_cache.db, a standalone sqlite file rather than
+// living inside a collection's own index.db, since llm_cache entries are
+// keyed by query+model, not scoped to any one collection.
+func (r *Router) db() *sql.DB {
+	r.cacheOnce.Do(func() {
+		cachePath := r.config.CachePath
+		if cachePath == "" {
+			cachePath = config.DefaultCachePath
+		}
+		dir := expandHome(cachePath)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			r.cacheErr = err
+			return
+		}
+
+		db, err := sql.Open("sqlite3", filepath.Join(dir, "router_cache.db"))
+		if err != nil {
+			r.cacheErr = err
+			return
+		}
+
+		_, err = db.Exec(`
+			CREATE TABLE IF NOT EXISTS llm_cache (
+				key TEXT PRIMARY KEY,
+				model TEXT NOT NULL,
+				query TEXT NOT NULL,
+				response TEXT NOT NULL,
+				created_at TEXT NOT NULL,
+				expires_at TEXT NOT NULL
+			);
+		`)
+		if err != nil {
+			db.Close()
+			r.cacheErr = err
+			return
+		}
+
+		r.cacheDB = db
+	})
+
+	if r.cacheErr != nil {
+		return nil
+	}
+	return r.cacheDB
+}
+
+// expandHome expands a leading "~/" in path to the user's home
+// directory, leaving path unchanged if that can't be determined.
+func expandHome(path string) string {
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(path, "~/") {
+		return filepath.Join(home, strings.TrimPrefix(path, "~/"))
+	}
+	return path
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/qmd/qmd-go/internal/llm"
+)
+
+func TestClassifyHeuristic_NaturalLanguagePatternsAreSemantic(t *testing.T) {
+	got := classifyHeuristic("explain how retries work")
+	if got.Intent != llm.IntentSemantic {
+		t.Errorf("Intent = %q, want %q", got.Intent, llm.IntentSemantic)
+	}
+}
+
+func TestClassifyHeuristic_TechnicalPatternsAreKeyword(t *testing.T) {
+	got := classifyHeuristic("NullPointerException in UserService.authenticate")
+	if got.Intent != llm.IntentKeyword {
+		t.Errorf("Intent = %q, want %q", got.Intent, llm.IntentKeyword)
+	}
+}
+
+func TestClassifyHeuristic_UnmatchedQueryDefaultsToHybrid(t *testing.T) {
+	got := classifyHeuristic("quarterly revenue numbers")
+	if got.Intent != llm.IntentHybrid {
+		t.Errorf("Intent = %q, want %q", got.Intent, llm.IntentHybrid)
+	}
+}
+
+func TestCacheKey_DeterministicAndSensitiveToInputs(t *testing.T) {
+	a := cacheKey("model-a", "classify:v3", "how does retry work")
+	b := cacheKey("model-a", "classify:v3", "how does retry work")
+	if a != b {
+		t.Errorf("cacheKey should be deterministic, got %q and %q", a, b)
+	}
+
+	if c := cacheKey("model-b", "classify:v3", "how does retry work"); c == a {
+		t.Error("cacheKey should change when model changes")
+	}
+	if c := cacheKey("model-a", "classify:v5", "how does retry work"); c == a {
+		t.Error("cacheKey should change when prompt changes")
+	}
+	if c := cacheKey("model-a", "classify:v3", "something else"); c == a {
+		t.Error("cacheKey should change when query changes")
+	}
+}
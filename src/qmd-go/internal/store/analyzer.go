@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+// FTS5TokenizeClause resolves an AnalyzerConfig to the string passed as
+// documents_fts's tokenize= argument.
+//
+// Honest limitation: stock SQLite FTS5 ships exactly one stemmer
+// (Porter, English-only) and no snowball support, so
+// AnalyzerConfig.Stemmer/Stopwords aren't applied here -- doing real
+// per-language stemming would mean registering a custom C tokenizer,
+// which isn't reachable from the stdlib-only mattn/go-sqlite3 build
+// this repo uses. What this function does instead, per language, is
+// the best fit among FTS5's built-ins:
+//
+//   - en: "porter unicode61", same as the long-standing default.
+//   - ru, de, fr, es: "unicode61 remove_diacritics 2", so accented and
+//     unaccented forms of a word still match each other even without
+//     stemming.
+//   - zh: "trigram", FTS5's built-in case-insensitive trigram
+//     tokenizer, since CJK text has no whitespace word boundaries for
+//     unicode61 to split on.
+//
+// cfg.Tokenizer, if set, overrides the preset's base tokenizer
+// entirely (e.g. "ascii", or "trigram" for a non-CJK substring-heavy
+// collection). A nil cfg or empty cfg.Language defaults to English.
+func FTS5TokenizeClause(cfg *config.AnalyzerConfig) string {
+	if cfg == nil {
+		cfg = &config.AnalyzerConfig{}
+	}
+	if cfg.Tokenizer != "" {
+		return cfg.Tokenizer
+	}
+
+	switch cfg.Language {
+	case config.AnalyzerLanguageChinese:
+		return "trigram"
+	case config.AnalyzerLanguageRussian, config.AnalyzerLanguageGerman,
+		config.AnalyzerLanguageFrench, config.AnalyzerLanguageSpanish:
+		return "unicode61 remove_diacritics 2"
+	default:
+		return "porter unicode61"
+	}
+}
+
+// AnalyzeText runs text through tokenizeClause (the same value
+// FTS5TokenizeClause produces for a collection) and returns the
+// resulting token stream in order, for the search command's --analyze
+// flag. It works by building a throwaway temp.* fts5 table sharing
+// tokenizeClause, inserting text as its only row, then reading the
+// tokens back out of fts5vocab's 'instance' mode (term, doc, col,
+// offset) ordered by offset -- the same trick `sqlite3 .fts5vocab`
+// debugging sessions use, just without a real table behind it.
+func AnalyzeText(ctx context.Context, db *sql.DB, tokenizeClause, text string) ([]string, error) {
+	const tmpTable = "temp.qmd_analyze_fts"
+	const tmpVocab = "temp.qmd_analyze_vocab"
+
+	cleanup := func() {
+		db.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS %s`, tmpVocab))
+		db.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS %s`, tmpTable))
+	}
+	cleanup()
+	defer cleanup()
+
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE VIRTUAL TABLE %s USING fts5(body, tokenize=%q)`, tmpTable, tokenizeClause,
+	)); err != nil {
+		return nil, fmt.Errorf("create analyzer preview table: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(
+		`INSERT INTO %s(rowid, body) VALUES (1, ?)`, tmpTable,
+	), text); err != nil {
+		return nil, fmt.Errorf("insert analyzer preview text: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE VIRTUAL TABLE %s USING fts5vocab(qmd_analyze_fts, 'instance')`, tmpVocab,
+	)); err != nil {
+		return nil, fmt.Errorf("create analyzer preview vocab: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT term FROM %s WHERE doc = 1 ORDER BY col, offset`, tmpVocab,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("query analyzer preview tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []string
+	for rows.Next() {
+		var term string
+		if err := rows.Scan(&term); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, term)
+	}
+	return tokens, rows.Err()
+}
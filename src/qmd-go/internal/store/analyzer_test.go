@@ -0,0 +1,42 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func TestFTS5TokenizeClause_LanguagePresets(t *testing.T) {
+	cases := []struct {
+		language config.AnalyzerLanguage
+		want     string
+	}{
+		{config.AnalyzerLanguageEnglish, "porter unicode61"},
+		{"", "porter unicode61"},
+		{config.AnalyzerLanguageRussian, "unicode61 remove_diacritics 2"},
+		{config.AnalyzerLanguageGerman, "unicode61 remove_diacritics 2"},
+		{config.AnalyzerLanguageFrench, "unicode61 remove_diacritics 2"},
+		{config.AnalyzerLanguageSpanish, "unicode61 remove_diacritics 2"},
+		{config.AnalyzerLanguageChinese, "trigram"},
+	}
+
+	for _, c := range cases {
+		got := FTS5TokenizeClause(&config.AnalyzerConfig{Language: c.language})
+		if got != c.want {
+			t.Errorf("FTS5TokenizeClause(language=%q) = %q, want %q", c.language, got, c.want)
+		}
+	}
+}
+
+func TestFTS5TokenizeClause_NilDefaultsToEnglish(t *testing.T) {
+	if got := FTS5TokenizeClause(nil); got != "porter unicode61" {
+		t.Errorf("FTS5TokenizeClause(nil) = %q, want %q", got, "porter unicode61")
+	}
+}
+
+func TestFTS5TokenizeClause_TokenizerOverridesLanguage(t *testing.T) {
+	got := FTS5TokenizeClause(&config.AnalyzerConfig{Language: config.AnalyzerLanguageChinese, Tokenizer: "ascii"})
+	if got != "ascii" {
+		t.Errorf("FTS5TokenizeClause with Tokenizer override = %q, want %q", got, "ascii")
+	}
+}
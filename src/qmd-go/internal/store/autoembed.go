@@ -0,0 +1,197 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// DocumentChunk is one unit of work for the AutoEmbedder: a chunk of a
+// document's content, tagged with which named embedder
+// (config.ModelsConfig.Embedders key) should produce its vector.
+type DocumentChunk struct {
+	Collection string
+	Hash       string
+	Seq        int
+	Path       string
+	Title      string
+	Text       string
+	// Embedder names the config.ModelsConfig.Embedders entry to use;
+	// empty means "default".
+	Embedder string
+}
+
+// AutoEmbedder is a bounded worker pool that embeds enqueued document
+// chunks and writes the resulting vectors to SQLite (and to Qdrant,
+// under the chunk's named vector, if configured) as soon as they're
+// ready -- so BM25 and vector indexes never drift the way they can with
+// today's separate `qmd embed` pass.
+//
+// It is the embedding half of Meilisearch-style auto-embedding on
+// ingestion. The other half -- update's document-insert loop calling
+// Enqueue for every new or changed document -- isn't implemented in
+// this tree yet (`qmd update` is still a placeholder), so this wires
+// the embedding side ready for that loop to call into once it exists.
+type AutoEmbedder struct {
+	store   *Store
+	queue   chan DocumentChunk
+	wg      sync.WaitGroup
+	closeCh chan struct{}
+	once    sync.Once
+}
+
+// NewAutoEmbedder starts workers goroutines draining a queue of
+// capacity queueSize (workers*4 when queueSize<=0). Enqueue blocks once
+// the queue is full, applying natural backpressure to the caller feeding
+// it chunks.
+func NewAutoEmbedder(s *Store, workers, queueSize int) *AutoEmbedder {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize <= 0 {
+		queueSize = workers * 4
+	}
+
+	a := &AutoEmbedder{
+		store:   s,
+		queue:   make(chan DocumentChunk, queueSize),
+		closeCh: make(chan struct{}),
+	}
+
+	for i := 0; i < workers; i++ {
+		a.wg.Add(1)
+		go a.worker()
+	}
+
+	return a
+}
+
+// Enqueue submits a chunk for embedding. It blocks while the queue is
+// full, and is a no-op after Close.
+func (a *AutoEmbedder) Enqueue(chunk DocumentChunk) {
+	select {
+	case a.queue <- chunk:
+	case <-a.closeCh:
+	}
+}
+
+// Close stops accepting new work and waits for in-flight chunks to
+// finish embedding.
+func (a *AutoEmbedder) Close() {
+	a.once.Do(func() {
+		close(a.closeCh)
+		close(a.queue)
+	})
+	a.wg.Wait()
+}
+
+func (a *AutoEmbedder) worker() {
+	defer a.wg.Done()
+	for chunk := range a.queue {
+		if err := a.embedChunk(context.Background(), chunk); err != nil {
+			fmt.Printf("Warning: auto-embed failed for %s#%d: %v\n", chunk.Hash, chunk.Seq, err)
+		}
+	}
+}
+
+// embedChunk resolves chunk.Embedder to its configured model, generates
+// the embedding, and writes it to SQLite and (if configured) Qdrant
+// under that embedder's named vector, in place of a separate `qmd embed`
+// pass. The base model comes from chunk.Collection's resolved config
+// (config.Config.ResolveCollection) so a collection with its own
+// Models override embeds against it rather than the root model.
+func (a *AutoEmbedder) embedChunk(ctx context.Context, chunk DocumentChunk) error {
+	resolved := a.store.config.ResolveCollection(chunk.Collection)
+	embedderCfg, ok := resolved.Embedder(chunk.Embedder)
+
+	modelCfg := resolved.Models.Embed
+	vectorName := chunk.Embedder
+	if ok {
+		modelCfg = &embedderCfg.LLMModelConfig
+		if embedderCfg.VectorName != "" {
+			vectorName = embedderCfg.VectorName
+		}
+	}
+
+	result, err := a.store.llmRouter.EmbedWithModel(ctx, modelCfg, []string{chunk.Text})
+	if err != nil {
+		return fmt.Errorf("embed chunk %s#%d: %w", chunk.Hash, chunk.Seq, err)
+	}
+	vector := result.Embeddings[0]
+
+	db, err := a.store.GetConnection(chunk.Collection)
+	if err != nil {
+		return err
+	}
+	if err := writeChunkVector(db, chunk, vector, result.Model); err != nil {
+		return fmt.Errorf("write chunk %s#%d: %w", chunk.Hash, chunk.Seq, err)
+	}
+
+	if err := a.store.IndexExpansionTerms(ctx, db, chunk.Text); err != nil {
+		return fmt.Errorf("index expansion terms for %s#%d: %w", chunk.Hash, chunk.Seq, err)
+	}
+
+	if a.store.qdrant != nil {
+		point := VectorPoint{
+			ID:         chunkPointID(chunk.Hash, chunk.Seq),
+			Path:       chunk.Path,
+			Title:      chunk.Title,
+			Hash:       chunk.Hash,
+			Collection: chunk.Collection,
+			Vector:     vector,
+			VectorName: vectorName,
+		}
+		if err := a.store.qdrant.UpsertVectors([]VectorPoint{point}); err != nil {
+			return fmt.Errorf("qdrant upsert for %s#%d: %w", chunk.Hash, chunk.Seq, err)
+		}
+	}
+
+	return nil
+}
+
+// writeChunkVector writes a chunk's embedding and its content_vectors
+// bookkeeping row in a single transaction, so the two tables never go
+// out of sync even if the process dies mid-write.
+func writeChunkVector(db *sql.DB, chunk DocumentChunk, vector []float32, model string) error {
+	vectorJSON, err := json.Marshal(vector)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	hashSeq := fmt.Sprintf("%s_%d", chunk.Hash, chunk.Seq)
+	if _, err := tx.Exec(
+		`INSERT OR REPLACE INTO vectors_vec(hash_seq, embedding) VALUES (?, ?)`,
+		hashSeq, string(vectorJSON),
+	); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(
+		`INSERT OR REPLACE INTO content_vectors(hash, seq, pos, model, embedded_at) VALUES (?, ?, ?, ?, ?)`,
+		chunk.Hash, chunk.Seq, chunk.Seq, model, time.Now().UTC().Format(time.RFC3339),
+	); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// chunkPointID derives a stable Qdrant point ID from a chunk's content
+// hash and sequence number, so re-embedding the same chunk upserts in
+// place instead of creating a duplicate point.
+func chunkPointID(hash string, seq int) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s_%d", hash, seq)
+	return h.Sum64()
+}
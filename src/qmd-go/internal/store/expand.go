@@ -0,0 +1,478 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+// expandTokenize lowercases text and splits it into alphanumeric/underscore
+// runs, which is permissive enough to capture both prose words and code
+// identifiers (unlike the FTS5 porter tokenizer, this isn't used for
+// matching -- only for building the co-occurrence table below).
+func expandTokenize(text string) []string {
+	var tokens []string
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			tokens = append(tokens, strings.ToLower(b.String()))
+			b.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// expansionWindow is the sliding-window size IndexExpansionTerms uses
+// when counting co-occurrences: two tokens co-occur if they fall within
+// this many positions of each other.
+const expansionWindow = 5
+
+// IndexExpansionTerms scans text's tokens, accumulating per-term
+// frequency and pairwise co-occurrence counts (within expansionWindow)
+// into the expansion_terms/expansion_cooccur tables, then -- if an
+// embedding model is configured -- caches each distinct term's vector in
+// expansion_vectors for ExpandQuery's nearest-neighbor lookup. It's
+// called once per document chunk as content is ingested, alongside
+// embedding (see AutoEmbedder.embedChunk), so the expansion vocabulary
+// grows with the corpus instead of needing a separate indexing pass.
+func (s *Store) IndexExpansionTerms(ctx context.Context, db *sql.DB, text string) error {
+	tokens := expandTokenize(text)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	termFreq := make(map[string]int, len(tokens))
+	pairFreq := make(map[[2]string]int)
+
+	for i, term := range tokens {
+		termFreq[term]++
+		end := i + expansionWindow
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		for j := i + 1; j < end; j++ {
+			other := tokens[j]
+			if other == term {
+				continue
+			}
+			pair := [2]string{term, other}
+			if pair[0] > pair[1] {
+				pair[0], pair[1] = pair[1], pair[0]
+			}
+			pairFreq[pair]++
+		}
+	}
+
+	for term, freq := range termFreq {
+		if _, err := tx.Exec(`
+			INSERT INTO expansion_terms(term, freq) VALUES (?, ?)
+			ON CONFLICT(term) DO UPDATE SET freq = freq + excluded.freq
+		`, term, freq); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	for pair, freq := range pairFreq {
+		if _, err := tx.Exec(`
+			INSERT INTO expansion_cooccur(term_a, term_b, freq) VALUES (?, ?, ?)
+			ON CONFLICT(term_a, term_b) DO UPDATE SET freq = freq + excluded.freq
+		`, pair[0], pair[1], freq); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if s.config.Models.Embed == nil {
+		return nil
+	}
+	for term := range termFreq {
+		if err := s.cacheTermVector(ctx, db, term); err != nil {
+			return fmt.Errorf("cache vector for term %q: %w", term, err)
+		}
+	}
+	return nil
+}
+
+// ExpandedQuery is the result of Store.ExpandQuery: the terms it added to
+// query, kept separate from the original query so callers (the search
+// and hsearch CLI commands) can OR them into the BM25 query and report
+// which ones were added.
+type ExpandedQuery struct {
+	Terms []string
+}
+
+// ExpandQuery expands query using the strategy configured at
+// s.config.Expansion.Strategy (defaulting to config.ExpansionAll when
+// unset), finding up to n terms for the caller to OR into its BM25
+// query. See ExpandQueryWithStrategy for what each strategy contributes.
+func (s *Store) ExpandQuery(ctx context.Context, query string, n int) (ExpandedQuery, error) {
+	strategy := s.config.Expansion.Strategy
+	if strategy == "" {
+		strategy = config.ExpansionAll
+	}
+	return s.ExpandQueryWithStrategy(ctx, query, n, strategy)
+}
+
+// ExpandQueryWithStrategy finds up to n terms related to query's tokens,
+// for the caller to OR into its BM25 query, combining up to three
+// sources:
+//  1. Always (unless strategy is config.ExpansionNone): PMI against the
+//     corpus co-occurrence table built by IndexExpansionTerms --
+//     pmi(a, b) = log(p(a,b) / (p(a) * p(b))), estimated from
+//     expansion_terms/expansion_cooccur frequencies -- plus, when an
+//     embedding model is configured, the nearest terms to query's
+//     embedding by cosine similarity (expansion_vectors).
+//  2. When strategy is config.ExpansionSynonyms or config.ExpansionAll:
+//     terms looked up in the YAML file at s.config.Expansion.SynonymsPath.
+//  3. When strategy is config.ExpansionLLM or config.ExpansionAll: terms
+//     tokenized out of paraphrases from models.query_expansion, cached
+//     per query for s.config.Expansion.LLMCacheTTLSeconds.
+// Results from every active source are merged, deduplicated against each
+// other and against query's own tokens, and capped at n.
+func (s *Store) ExpandQueryWithStrategy(ctx context.Context, query string, n int, strategy config.ExpansionStrategy) (ExpandedQuery, error) {
+	if n <= 0 || strategy == config.ExpansionNone {
+		return ExpandedQuery{}, nil
+	}
+
+	queryTokens := expandTokenize(query)
+	if len(queryTokens) == 0 {
+		return ExpandedQuery{}, nil
+	}
+
+	seen := make(map[string]bool, len(queryTokens))
+	for _, t := range queryTokens {
+		seen[t] = true
+	}
+
+	var candidates []string
+	for _, collection := range s.getCollections(SearchOptions{SearchAll: true}) {
+		db, err := s.GetConnection(collection)
+		if err != nil {
+			continue
+		}
+
+		terms, err := pmiCandidates(db, queryTokens, n)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, terms...)
+
+		if s.config.Models.Embed != nil {
+			if nnTerms, err := s.nearestNeighborTerms(ctx, db, query, n); err == nil {
+				candidates = append(candidates, nnTerms...)
+			}
+		}
+	}
+
+	if strategy == config.ExpansionSynonyms || strategy == config.ExpansionAll {
+		candidates = append(candidates, synonymCandidates(queryTokens, s.loadSynonyms())...)
+	}
+
+	if strategy == config.ExpansionLLM || strategy == config.ExpansionAll {
+		if terms, err := s.llmExpansionCandidates(ctx, query); err == nil {
+			candidates = append(candidates, terms...)
+		}
+	}
+
+	var expanded []string
+	for _, term := range candidates {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		expanded = append(expanded, term)
+		if len(expanded) == n {
+			break
+		}
+	}
+
+	return ExpandedQuery{Terms: expanded}, nil
+}
+
+// loadSynonyms reads and caches s.config.Expansion.SynonymsPath (a YAML
+// map of term -> []synonym) on first use. A missing or unparsable file
+// yields an empty map rather than an error, since the synonyms file is
+// optional.
+func (s *Store) loadSynonyms() map[string][]string {
+	s.synonymsOnce.Do(func() {
+		s.synonyms = map[string][]string{}
+
+		path := s.config.Expansion.SynonymsPath
+		if path == "" {
+			return
+		}
+		data, err := os.ReadFile(expandHomePath(path))
+		if err != nil {
+			return
+		}
+		var synonyms map[string][]string
+		if err := yaml.Unmarshal(data, &synonyms); err != nil {
+			return
+		}
+		s.synonyms = synonyms
+	})
+	return s.synonyms
+}
+
+// synonymCandidates returns the synonyms of every query token found in
+// synonyms, in query-token order.
+func synonymCandidates(queryTokens []string, synonyms map[string][]string) []string {
+	var terms []string
+	for _, token := range queryTokens {
+		terms = append(terms, synonyms[token]...)
+	}
+	return terms
+}
+
+// expandHomePath expands a leading "~/" in path to the user's home
+// directory, leaving path unchanged if that can't be determined.
+func expandHomePath(path string) string {
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(path, "~/") {
+		return filepath.Join(home, strings.TrimPrefix(path, "~/"))
+	}
+	return path
+}
+
+// llmExpansionCacheEntry caches one query's LLM-generated expansion
+// terms until expires.
+type llmExpansionCacheEntry struct {
+	terms   []string
+	expires time.Time
+}
+
+// llmExpansionCandidates returns terms tokenized out of
+// s.config.Models.QueryExpansion paraphrases of query, generated via
+// s.llmRouter.GenerateParaphrases and cached for
+// s.config.Expansion.LLMCacheTTLSeconds (default 24h) so repeated
+// searches for the same query don't re-generate paraphrases.
+func (s *Store) llmExpansionCandidates(ctx context.Context, query string) ([]string, error) {
+	if s.llmRouter == nil {
+		return nil, nil
+	}
+
+	ttl := time.Duration(s.config.Expansion.LLMCacheTTLSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	maxTerms := s.config.Expansion.LLMMaxTerms
+	if maxTerms <= 0 {
+		maxTerms = 3
+	}
+
+	s.llmExpansionMu.Lock()
+	if entry, ok := s.llmExpansionCache[query]; ok && time.Now().Before(entry.expires) {
+		s.llmExpansionMu.Unlock()
+		return entry.terms, nil
+	}
+	s.llmExpansionMu.Unlock()
+
+	paraphrases, err := s.llmRouter.GenerateParaphrases(ctx, query, maxTerms)
+	if err != nil {
+		return nil, err
+	}
+
+	var terms []string
+	for _, paraphrase := range paraphrases {
+		terms = append(terms, expandTokenize(paraphrase)...)
+	}
+
+	s.llmExpansionMu.Lock()
+	if s.llmExpansionCache == nil {
+		s.llmExpansionCache = map[string]llmExpansionCacheEntry{}
+	}
+	s.llmExpansionCache[query] = llmExpansionCacheEntry{terms: terms, expires: time.Now().Add(ttl)}
+	s.llmExpansionMu.Unlock()
+
+	return terms, nil
+}
+
+// pmiCandidates returns the terms with the highest PMI against any of
+// queryTokens, sorted descending, capped at limit.
+func pmiCandidates(db *sql.DB, queryTokens []string, limit int) ([]string, error) {
+	var totalTerms int
+	if err := db.QueryRow(`SELECT COALESCE(SUM(freq), 0) FROM expansion_terms`).Scan(&totalTerms); err != nil {
+		return nil, err
+	}
+	if totalTerms == 0 {
+		return nil, nil
+	}
+
+	termFreq := make(map[string]int)
+	scores := make(map[string]float64)
+
+	for _, qt := range queryTokens {
+		var qFreq int
+		if err := db.QueryRow(`SELECT freq FROM expansion_terms WHERE term = ?`, qt).Scan(&qFreq); err != nil {
+			continue
+		}
+
+		rows, err := db.Query(`
+			SELECT term_a, term_b, freq FROM expansion_cooccur
+			WHERE term_a = ? OR term_b = ?
+		`, qt, qt)
+		if err != nil {
+			continue
+		}
+
+		for rows.Next() {
+			var termA, termB string
+			var pairFreq int
+			if err := rows.Scan(&termA, &termB, &pairFreq); err != nil {
+				continue
+			}
+			other := termA
+			if other == qt {
+				other = termB
+			}
+
+			if _, ok := termFreq[other]; !ok {
+				var otherFreq int
+				db.QueryRow(`SELECT freq FROM expansion_terms WHERE term = ?`, other).Scan(&otherFreq)
+				termFreq[other] = otherFreq
+			}
+			otherFreq := termFreq[other]
+			if otherFreq == 0 || qFreq == 0 {
+				continue
+			}
+
+			pJoint := float64(pairFreq) / float64(totalTerms)
+			pA := float64(qFreq) / float64(totalTerms)
+			pB := float64(otherFreq) / float64(totalTerms)
+			pmi := math.Log(pJoint / (pA * pB))
+
+			if pmi > scores[other] {
+				scores[other] = pmi
+			}
+		}
+		rows.Close()
+	}
+
+	terms := make([]string, 0, len(scores))
+	for term := range scores {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool { return scores[terms[i]] > scores[terms[j]] })
+
+	if len(terms) > limit {
+		terms = terms[:limit]
+	}
+	return terms, nil
+}
+
+// nearestNeighborTerms embeds query and returns the cached expansion
+// terms whose embeddings are most cosine-similar to it, computing and
+// caching any term's embedding the first time it's needed.
+func (s *Store) nearestNeighborTerms(ctx context.Context, db *sql.DB, query string, limit int) ([]string, error) {
+	result, err := s.llmRouter.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	queryVector := result.Embeddings[0]
+
+	rows, err := db.Query(`SELECT term, embedding FROM expansion_vectors`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type scored struct {
+		term  string
+		score float64
+	}
+	var candidates []scored
+	for rows.Next() {
+		var term, embeddingJSON string
+		if err := rows.Scan(&term, &embeddingJSON); err != nil {
+			continue
+		}
+		var vector []float32
+		if err := json.Unmarshal([]byte(embeddingJSON), &vector); err != nil {
+			continue
+		}
+		candidates = append(candidates, scored{term: term, score: cosineSimilarity(queryVector, vector)})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	terms := make([]string, len(candidates))
+	for i, c := range candidates {
+		terms[i] = c.term
+	}
+	return terms, nil
+}
+
+// cacheTermVector embeds term with model and caches the result in
+// expansion_vectors, so future ExpandQuery calls don't re-embed it.
+func (s *Store) cacheTermVector(ctx context.Context, db *sql.DB, term string) error {
+	var exists int
+	if err := db.QueryRow(`SELECT 1 FROM expansion_vectors WHERE term = ?`, term).Scan(&exists); err == nil {
+		return nil
+	}
+
+	result, err := s.llmRouter.Embed(ctx, []string{term})
+	if err != nil {
+		return err
+	}
+
+	vectorJSON, err := json.Marshal(result.Embeddings[0])
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO expansion_vectors(term, embedding, model) VALUES (?, ?, ?)
+		ON CONFLICT(term) DO NOTHING
+	`, term, string(vectorJSON), result.Model)
+	return err
+}
+
+// cosineSimilarity returns the cosine similarity of a and b, or 0 if
+// either is empty or they differ in length.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
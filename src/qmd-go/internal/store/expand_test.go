@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func TestExpandTokenize(t *testing.T) {
+	tokens := expandTokenize("Hello, World! foo_bar 123")
+	want := []string{"hello", "world", "foo_bar", "123"}
+
+	if len(tokens) != len(want) {
+		t.Fatalf("tokens = %v, want %v", tokens, want)
+	}
+	for i := range want {
+		if tokens[i] != want[i] {
+			t.Errorf("tokens[%d] = %q, want %q", i, tokens[i], want[i])
+		}
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	if sim := cosineSimilarity([]float32{1, 0}, []float32{1, 0}); sim != 1 {
+		t.Errorf("identical vectors: cosineSimilarity = %f, want 1", sim)
+	}
+	if sim := cosineSimilarity([]float32{1, 0}, []float32{0, 1}); sim != 0 {
+		t.Errorf("orthogonal vectors: cosineSimilarity = %f, want 0", sim)
+	}
+	if sim := cosineSimilarity(nil, []float32{1}); sim != 0 {
+		t.Errorf("empty vector: cosineSimilarity = %f, want 0", sim)
+	}
+	if sim := cosineSimilarity([]float32{1, 2}, []float32{1}); sim != 0 {
+		t.Errorf("mismatched length: cosineSimilarity = %f, want 0", sim)
+	}
+}
+
+func TestExpandQuery_NoVocabulary(t *testing.T) {
+	s := &Store{config: &config.Config{}, connections: map[string]*sql.DB{}}
+	expanded, err := s.ExpandQuery(context.Background(), "anything", 5)
+	if err != nil {
+		t.Fatalf("ExpandQuery failed: %v", err)
+	}
+	if len(expanded.Terms) != 0 {
+		t.Errorf("expected no terms without an indexed corpus, got %v", expanded.Terms)
+	}
+}
+
+func TestExpandQuery_ZeroN(t *testing.T) {
+	s := &Store{config: &config.Config{}}
+	expanded, err := s.ExpandQuery(context.Background(), "anything", 0)
+	if err != nil {
+		t.Fatalf("ExpandQuery failed: %v", err)
+	}
+	if len(expanded.Terms) != 0 {
+		t.Errorf("expected no terms when n<=0, got %v", expanded.Terms)
+	}
+}
+
+func TestExpandQueryWithStrategy_NoneDisablesExpansion(t *testing.T) {
+	s := &Store{config: &config.Config{Expansion: config.ExpansionConfig{Strategy: config.ExpansionAll}}}
+	expanded, err := s.ExpandQueryWithStrategy(context.Background(), "anything", 5, config.ExpansionNone)
+	if err != nil {
+		t.Fatalf("ExpandQueryWithStrategy failed: %v", err)
+	}
+	if len(expanded.Terms) != 0 {
+		t.Errorf("expected no terms for ExpansionNone, got %v", expanded.Terms)
+	}
+}
+
+func TestSynonymCandidates_LooksUpEachQueryToken(t *testing.T) {
+	synonyms := map[string][]string{
+		"fast": {"quick", "rapid"},
+		"car":  {"automobile"},
+	}
+
+	got := synonymCandidates([]string{"fast", "car"}, synonyms)
+	want := []string{"quick", "rapid", "automobile"}
+	if len(got) != len(want) {
+		t.Fatalf("synonymCandidates() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSynonymCandidates_TokenWithNoEntryContributesNothing(t *testing.T) {
+	got := synonymCandidates([]string{"unknown"}, map[string][]string{"fast": {"quick"}})
+	if got != nil {
+		t.Errorf("expected no candidates for an unmapped token, got %v", got)
+	}
+}
+
+func TestLoadSynonyms_MissingFileYieldsEmptyMap(t *testing.T) {
+	s := &Store{config: &config.Config{Expansion: config.ExpansionConfig{SynonymsPath: "/nonexistent/synonyms.yaml"}}}
+	synonyms := s.loadSynonyms()
+	if len(synonyms) != 0 {
+		t.Errorf("expected an empty map for a missing synonyms file, got %v", synonyms)
+	}
+}
+
+func TestLlmExpansionCandidates_NoRouterYieldsNoCandidates(t *testing.T) {
+	s := &Store{config: &config.Config{}}
+	terms, err := s.llmExpansionCandidates(context.Background(), "anything")
+	if err != nil {
+		t.Fatalf("llmExpansionCandidates failed: %v", err)
+	}
+	if terms != nil {
+		t.Errorf("expected no terms without a configured llm router, got %v", terms)
+	}
+}
@@ -0,0 +1,382 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// FusionName selects which FusionStrategy SearchOptions.Fusion picks.
+type FusionName string
+
+const (
+	// FusionRRF fuses by Reciprocal Rank Fusion, same as the long-standing
+	// rrfFusion/rrfFusionWithOptions behavior. It's the default when
+	// SearchOptions.Fusion is left empty.
+	FusionRRF FusionName = "rrf"
+	// FusionWeightedSum min-max normalizes each list's scores to [0,1]
+	// independently, then sums the (optionally weighted) normalized
+	// scores per document.
+	FusionWeightedSum FusionName = "weighted_sum"
+	// FusionZScoreSum z-score normalizes each list's scores
+	// independently, then sums the (optionally weighted) normalized
+	// scores per document.
+	FusionZScoreSum FusionName = "zscore_sum"
+	// FusionConvex combines exactly two lists as alpha*first +
+	// (1-alpha)*second over min-max normalized scores, with alpha from
+	// FusionOptions.Alpha. Lists beyond the first two are weighted
+	// (1-alpha) split evenly among them.
+	FusionConvex FusionName = "convex"
+	// FusionBorda fuses by rank-sum: a document at 0-indexed rank r in a
+	// list of n scores ceil(n-r) points, summed (optionally weighted)
+	// across lists.
+	FusionBorda FusionName = "borda"
+)
+
+// DefaultConvexAlpha is FusionConvex's weight for the first list when
+// neither FusionOptions.Alpha nor the collection's
+// config.CollectionConfig.FusionAlpha is set.
+const DefaultConvexAlpha = 0.5
+
+// FusionList names one ranked retriever list being fused, e.g. {"bm25",
+// bm25Results}. Source doubles as the key each result's Contributions
+// map is recorded under.
+type FusionList struct {
+	Source  string
+	Results []SearchResult
+}
+
+// FusionOptions configures a FusionStrategy.Fuse call. Weights is
+// parallel to the []FusionList slice passed to Fuse, defaulting to 1.0
+// per list when nil or shorter than the list count. K is the RRF
+// constant (FusionRRF only, defaulting to DefaultRRFK). Alpha is
+// FusionConvex's weight for the first list, defaulting to
+// DefaultConvexAlpha when left zero with AlphaSet false. AlphaSet
+// distinguishes that default-requesting zero from a caller that
+// resolved its own alpha and wants exactly 0 (pure second list) used
+// as-is. DedupeKey overrides the default Hash-based dedupe key, same
+// as RRFFusionOptions.DedupeKey.
+type FusionOptions struct {
+	Weights   []float32
+	K         int
+	Alpha     float32
+	AlphaSet  bool
+	DedupeKey func(SearchResult) string
+}
+
+func (o FusionOptions) weight(i int) float32 {
+	if i < len(o.Weights) {
+		return o.Weights[i]
+	}
+	return 1.0
+}
+
+func (o FusionOptions) dedupeKey() func(SearchResult) string {
+	if o.DedupeKey != nil {
+		return o.DedupeKey
+	}
+	return defaultDedupeKey
+}
+
+// FusionStrategy fuses multiple ranked retriever result lists into one
+// ranked list. Implementations populate each result's Score with the
+// fused value and Contributions with one entry per source list whose
+// dedupe key matched that result, keyed by FusionList.Source.
+type FusionStrategy interface {
+	Fuse(lists []FusionList, opts FusionOptions) []SearchResult
+}
+
+// fusionStrategies maps the names accepted by SearchOptions.Fusion (and
+// the --fusion CLI flag) to their implementation.
+var fusionStrategies = map[FusionName]FusionStrategy{
+	FusionRRF:         rrfFusionStrategy{},
+	FusionWeightedSum: weightedSumFusionStrategy{},
+	FusionZScoreSum:   zscoreSumFusionStrategy{},
+	FusionConvex:      convexFusionStrategy{},
+	FusionBorda:       bordaFusionStrategy{},
+}
+
+// FusionNames lists the values SearchOptions.Fusion and the --fusion
+// flag accept, in the order reported by ResolveFusionStrategy's error.
+var FusionNames = []FusionName{FusionRRF, FusionWeightedSum, FusionZScoreSum, FusionConvex, FusionBorda}
+
+// ResolveFusionStrategy looks up name's FusionStrategy, defaulting to
+// FusionRRF when name is empty.
+func ResolveFusionStrategy(name FusionName) (FusionStrategy, error) {
+	if name == "" {
+		name = FusionRRF
+	}
+	strategy, ok := fusionStrategies[name]
+	if !ok {
+		names := make([]string, len(FusionNames))
+		for i, n := range FusionNames {
+			names[i] = string(n)
+		}
+		return nil, fmt.Errorf("store: unknown fusion strategy %q (allowed: %v)", name, names)
+	}
+	return strategy, nil
+}
+
+// collectKeys walks lists in order, building the de-duplicated key order
+// (first-seen) and, for each key, the best (highest-Score) original
+// result plus its per-source raw score.
+func collectKeys(lists []FusionList, dedupeKey func(SearchResult) string) (order []string, best map[string]SearchResult, raw map[string]map[string]float32) {
+	best = make(map[string]SearchResult)
+	raw = make(map[string]map[string]float32)
+
+	for _, list := range lists {
+		seen := make(map[string]bool, len(list.Results))
+		for _, result := range list.Results {
+			key := dedupeKey(result)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if _, ok := raw[key]; !ok {
+				raw[key] = make(map[string]float32)
+			}
+			raw[key][list.Source] = result.Score
+
+			if existing, ok := best[key]; !ok || result.Score > existing.Score {
+				if !ok {
+					order = append(order, key)
+				}
+				best[key] = result
+			}
+		}
+	}
+
+	return order, best, raw
+}
+
+// finalize builds the fused []SearchResult from order/best, assigning
+// scores[key] as the fused Score and raw[key] as Contributions, then
+// sorts descending by Score (ties keep first-seen order, matching the
+// original rrfFusion behavior).
+func finalize(order []string, best map[string]SearchResult, raw map[string]map[string]float32, scores map[string]float32) []SearchResult {
+	fused := make([]SearchResult, 0, len(order))
+	for _, key := range order {
+		result := best[key]
+		result.Score = scores[key]
+		result.Contributions = raw[key]
+		fused = append(fused, result)
+	}
+	sort.SliceStable(fused, func(i, j int) bool { return fused[i].Score > fused[j].Score })
+	return fused
+}
+
+// rrfFusionStrategy implements Reciprocal Rank Fusion: score(d) = sum
+// over lists containing d of weight_i/(k + rank_i(d)+1), with rank_i the
+// document's 0-based rank in list i.
+type rrfFusionStrategy struct{}
+
+func (rrfFusionStrategy) Fuse(lists []FusionList, opts FusionOptions) []SearchResult {
+	k := opts.K
+	if k <= 0 {
+		k = DefaultRRFK
+	}
+	dedupeKey := opts.dedupeKey()
+
+	scores := make(map[string]float32)
+	best := make(map[string]SearchResult)
+	raw := make(map[string]map[string]float32)
+	var order []string
+
+	for i, list := range lists {
+		weight := opts.weight(i)
+		seen := make(map[string]bool, len(list.Results))
+		for rank, result := range list.Results {
+			key := dedupeKey(result)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			scores[key] += weight / float32(k+rank+1)
+			if _, ok := raw[key]; !ok {
+				raw[key] = make(map[string]float32)
+			}
+			raw[key][list.Source] = result.Score
+
+			if existing, ok := best[key]; !ok || result.Score > existing.Score {
+				if !ok {
+					order = append(order, key)
+				}
+				best[key] = result
+			}
+		}
+	}
+
+	return finalize(order, best, raw, scores)
+}
+
+// minMaxNormalize returns a copy of scores linearly rescaled to [0,1].
+// A list whose scores are all equal normalizes to 1.0 for every entry,
+// rather than dividing by zero, so a single-candidate list still counts.
+func minMaxNormalize(results []SearchResult) map[string]float32 {
+	norm := make(map[string]float32, len(results))
+	if len(results) == 0 {
+		return norm
+	}
+
+	min, max := results[0].Score, results[0].Score
+	for _, r := range results {
+		if r.Score < min {
+			min = r.Score
+		}
+		if r.Score > max {
+			max = r.Score
+		}
+	}
+
+	span := max - min
+	for _, r := range results {
+		if span == 0 {
+			norm[normKey(r)] = 1.0
+			continue
+		}
+		norm[normKey(r)] = (r.Score - min) / span
+	}
+	return norm
+}
+
+// zscoreNormalize returns a copy of scores rescaled to zero mean, unit
+// variance. A list with zero variance (including a single result)
+// normalizes every entry to 0.
+func zscoreNormalize(results []SearchResult) map[string]float32 {
+	norm := make(map[string]float32, len(results))
+	if len(results) == 0 {
+		return norm
+	}
+
+	var sum float64
+	for _, r := range results {
+		sum += float64(r.Score)
+	}
+	mean := sum / float64(len(results))
+
+	var variance float64
+	for _, r := range results {
+		d := float64(r.Score) - mean
+		variance += d * d
+	}
+	variance /= float64(len(results))
+	stddev := math.Sqrt(variance)
+
+	for _, r := range results {
+		key := normKey(r)
+		if stddev == 0 {
+			norm[key] = 0
+			continue
+		}
+		norm[key] = float32((float64(r.Score) - mean) / stddev)
+	}
+	return norm
+}
+
+// normKey returns the key minMaxNormalize/zscoreNormalize index results
+// by. It isn't the same as a FusionOptions.DedupeKey: normalization
+// happens per source list before dedupe, so it only needs to be unique
+// within one list, not across the fused result set.
+func normKey(r SearchResult) string {
+	return r.Hash + "\x00" + r.Path
+}
+
+// weightedSumFusionStrategy sums each list's min-max normalized score,
+// weighted by FusionOptions.Weights.
+type weightedSumFusionStrategy struct{}
+
+func (weightedSumFusionStrategy) Fuse(lists []FusionList, opts FusionOptions) []SearchResult {
+	return sumNormalized(lists, opts, minMaxNormalize)
+}
+
+// zscoreSumFusionStrategy sums each list's z-score normalized score,
+// weighted by FusionOptions.Weights.
+type zscoreSumFusionStrategy struct{}
+
+func (zscoreSumFusionStrategy) Fuse(lists []FusionList, opts FusionOptions) []SearchResult {
+	return sumNormalized(lists, opts, zscoreNormalize)
+}
+
+// sumNormalized is the shared body of weightedSumFusionStrategy and
+// zscoreSumFusionStrategy: normalize each list independently with
+// normalize, then sum the weighted normalized scores per deduplicated
+// document.
+func sumNormalized(lists []FusionList, opts FusionOptions, normalize func([]SearchResult) map[string]float32) []SearchResult {
+	dedupeKey := opts.dedupeKey()
+	order, best, raw := collectKeys(lists, dedupeKey)
+
+	scores := make(map[string]float32, len(order))
+	for i, list := range lists {
+		weight := opts.weight(i)
+		norm := normalize(list.Results)
+		seen := make(map[string]bool, len(list.Results))
+		for _, result := range list.Results {
+			key := dedupeKey(result)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			scores[key] += weight * norm[normKey(result)]
+		}
+	}
+
+	return finalize(order, best, raw, scores)
+}
+
+// convexFusionStrategy combines exactly two lists as alpha*first +
+// (1-alpha)*second over min-max normalized scores. Additional lists
+// beyond the first two share (1-alpha) evenly, since the "convex
+// combination" the request describes is only defined for two retrievers.
+type convexFusionStrategy struct{}
+
+func (convexFusionStrategy) Fuse(lists []FusionList, opts FusionOptions) []SearchResult {
+	alpha := opts.Alpha
+	if alpha == 0 && !opts.AlphaSet {
+		alpha = DefaultConvexAlpha
+	}
+
+	weights := make([]float32, len(lists))
+	switch len(lists) {
+	case 0:
+		return []SearchResult{}
+	case 1:
+		weights[0] = 1.0
+	default:
+		weights[0] = alpha
+		rest := (1 - alpha) / float32(len(lists)-1)
+		for i := 1; i < len(lists); i++ {
+			weights[i] = rest
+		}
+	}
+
+	return sumNormalized(lists, FusionOptions{Weights: weights, DedupeKey: opts.DedupeKey}, minMaxNormalize)
+}
+
+// bordaFusionStrategy fuses by rank-sum: a document at 0-indexed rank r
+// in a list of n results earns (n-r) points, weighted by
+// FusionOptions.Weights and summed across lists.
+type bordaFusionStrategy struct{}
+
+func (bordaFusionStrategy) Fuse(lists []FusionList, opts FusionOptions) []SearchResult {
+	dedupeKey := opts.dedupeKey()
+	order, best, raw := collectKeys(lists, dedupeKey)
+
+	scores := make(map[string]float32, len(order))
+	for i, list := range lists {
+		weight := opts.weight(i)
+		n := len(list.Results)
+		seen := make(map[string]bool, n)
+		for rank, result := range list.Results {
+			key := dedupeKey(result)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			scores[key] += weight * float32(n-rank)
+		}
+	}
+
+	return finalize(order, best, raw, scores)
+}
@@ -0,0 +1,128 @@
+package store
+
+import "testing"
+
+func TestFusionStrategies_DisjointDocSets(t *testing.T) {
+	bm25 := []SearchResult{
+		{Hash: "a", Path: "a.md", Score: 10},
+		{Hash: "b", Path: "b.md", Score: 5},
+	}
+	vector := []SearchResult{
+		{Hash: "c", Path: "c.md", Score: 0.9},
+		{Hash: "d", Path: "d.md", Score: 0.8},
+	}
+	lists := []FusionList{{Source: "bm25", Results: bm25}, {Source: "vector", Results: vector}}
+
+	for _, name := range FusionNames {
+		strategy, err := ResolveFusionStrategy(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		fused := strategy.Fuse(lists, FusionOptions{K: DefaultRRFK, Alpha: DefaultConvexAlpha})
+		if len(fused) != 4 {
+			t.Errorf("%s: expected 4 distinct documents, got %d: %+v", name, len(fused), fused)
+		}
+		seen := make(map[string]bool)
+		for _, r := range fused {
+			seen[r.Hash] = true
+		}
+		for _, hash := range []string{"a", "b", "c", "d"} {
+			if !seen[hash] {
+				t.Errorf("%s: expected %q in fused results, got %+v", name, hash, fused)
+			}
+		}
+	}
+}
+
+func TestFusionStrategies_EmptyLists(t *testing.T) {
+	for _, name := range FusionNames {
+		strategy, err := ResolveFusionStrategy(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if fused := strategy.Fuse(nil, FusionOptions{}); len(fused) != 0 {
+			t.Errorf("%s: expected no results from nil lists, got %+v", name, fused)
+		}
+		lists := []FusionList{{Source: "bm25", Results: nil}, {Source: "vector", Results: nil}}
+		if fused := strategy.Fuse(lists, FusionOptions{}); len(fused) != 0 {
+			t.Errorf("%s: expected no results from empty lists, got %+v", name, fused)
+		}
+	}
+}
+
+func TestFusionStrategies_TiesKeepFirstSeenOrder(t *testing.T) {
+	bm25 := []SearchResult{
+		{Hash: "a", Path: "a.md", Score: 10},
+		{Hash: "b", Path: "b.md", Score: 10},
+	}
+	lists := []FusionList{{Source: "bm25", Results: bm25}}
+
+	for _, name := range FusionNames {
+		strategy, err := ResolveFusionStrategy(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		fused := strategy.Fuse(lists, FusionOptions{K: DefaultRRFK, Alpha: DefaultConvexAlpha})
+		if len(fused) != 2 {
+			t.Fatalf("%s: expected 2 results, got %d: %+v", name, len(fused), fused)
+		}
+		if fused[0].Hash != "a" || fused[1].Hash != "b" {
+			t.Errorf("%s: expected tie to keep first-seen order [a b], got [%s %s]", name, fused[0].Hash, fused[1].Hash)
+		}
+	}
+}
+
+func TestConvexFusion_AlphaWeightsTowardFirstList(t *testing.T) {
+	bm25 := []SearchResult{{Hash: "a", Path: "a.md", Score: 10}}
+	vector := []SearchResult{{Hash: "b", Path: "b.md", Score: 10}}
+	lists := []FusionList{{Source: "bm25", Results: bm25}, {Source: "vector", Results: vector}}
+
+	fused := convexFusionStrategy{}.Fuse(lists, FusionOptions{Alpha: 1})
+	if fused[0].Hash != "a" {
+		t.Errorf("alpha=1: expected %q first, got %q", "a", fused[0].Hash)
+	}
+
+	fused = convexFusionStrategy{}.Fuse(lists, FusionOptions{Alpha: 0})
+	if fused[0].Hash != "a" {
+		// alpha=0 falls back to DefaultConvexAlpha (0.5), so ties are
+		// broken by first-seen order, same as the tie-break behavior
+		// above.
+		t.Errorf("alpha=0 (default): expected %q first by tie-break, got %q", "a", fused[0].Hash)
+	}
+}
+
+func TestFusionStrategies_RecordContributions(t *testing.T) {
+	bm25 := []SearchResult{{Hash: "a", Path: "a.md", Score: 10}}
+	vector := []SearchResult{{Hash: "a", Path: "a.md", Score: 0.9}}
+	lists := []FusionList{{Source: "bm25", Results: bm25}, {Source: "vector", Results: vector}}
+
+	for _, name := range FusionNames {
+		strategy, err := ResolveFusionStrategy(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		fused := strategy.Fuse(lists, FusionOptions{K: DefaultRRFK, Alpha: DefaultConvexAlpha})
+		if len(fused) != 1 {
+			t.Fatalf("%s: expected 1 deduped result, got %d: %+v", name, len(fused), fused)
+		}
+		if fused[0].Contributions["bm25"] != 10 || fused[0].Contributions["vector"] != 0.9 {
+			t.Errorf("%s: expected Contributions {bm25:10, vector:0.9}, got %+v", name, fused[0].Contributions)
+		}
+	}
+}
+
+func TestResolveFusionStrategy_UnknownNameErrors(t *testing.T) {
+	if _, err := ResolveFusionStrategy("nope"); err == nil {
+		t.Error("expected an error for an unknown fusion strategy name")
+	}
+}
+
+func TestResolveFusionStrategy_EmptyDefaultsToRRF(t *testing.T) {
+	strategy, err := ResolveFusionStrategy("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := strategy.(rrfFusionStrategy); !ok {
+		t.Errorf("expected rrfFusionStrategy, got %T", strategy)
+	}
+}
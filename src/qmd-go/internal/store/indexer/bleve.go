@@ -0,0 +1,97 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func init() {
+	Default.RegisterFTS(string(config.BM25BackendBleve), openBleve)
+}
+
+// bleveIndexer keeps one bleve.Index per collection on disk under
+// <cache_path>/<collection>/bleve.bleve, mirroring how the sqlite_fts5
+// driver keeps one sqlite file per collection.
+type bleveIndexer struct {
+	collection string
+	index      bleve.Index
+}
+
+type bleveDoc struct {
+	Collection string `json:"collection"`
+	Path       string `json:"path"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+}
+
+func openBleve(collection string, db *sql.DB, cfg *config.Config) (Indexer, error) {
+	path := filepath.Join(cfg.CachePath, collection, "bleve.bleve")
+
+	idx, err := bleve.Open(path)
+	if err == bleve.ErrorIndexPathDoesNotExist {
+		idx, err = bleve.New(path, bleve.NewIndexMapping())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bleve: open index for collection %q: %w", collection, err)
+	}
+
+	return &bleveIndexer{collection: collection, index: idx}, nil
+}
+
+func (idx *bleveIndexer) Index(ctx context.Context, docs []Document) error {
+	batch := idx.index.NewBatch()
+	for _, doc := range docs {
+		if err := batch.Index(doc.ID, bleveDoc{
+			Collection: doc.Collection,
+			Path:       doc.Path,
+			Title:      doc.Title,
+			Body:       doc.Body,
+		}); err != nil {
+			return fmt.Errorf("bleve: index %s: %w", doc.Path, err)
+		}
+	}
+	return idx.index.Batch(batch)
+}
+
+func (idx *bleveIndexer) Search(ctx context.Context, q Query) ([]SearchResult, error) {
+	bq := query.NewMatchQuery(q.Text)
+	req := bleve.NewSearchRequest(bq)
+	req.Size = q.Limit
+	req.Fields = []string{"path", "title"}
+
+	res, err := idx.index.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("bleve: search: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		results = append(results, SearchResult{
+			ID:         hit.ID,
+			Collection: idx.collection,
+			Path:       fmt.Sprintf("%v", hit.Fields["path"]),
+			Title:      fmt.Sprintf("%v", hit.Fields["title"]),
+			Score:      float32(hit.Score),
+		})
+	}
+	return results, nil
+}
+
+func (idx *bleveIndexer) Delete(ctx context.Context, ids ...string) error {
+	batch := idx.index.NewBatch()
+	for _, id := range ids {
+		batch.Delete(id)
+	}
+	return idx.index.Batch(batch)
+}
+
+func (idx *bleveIndexer) Close() error {
+	return idx.index.Close()
+}
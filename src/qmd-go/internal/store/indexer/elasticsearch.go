@@ -0,0 +1,171 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func init() {
+	Default.RegisterFTS(string(config.BM25BackendElasticsearch), openElasticsearch)
+}
+
+// elasticsearchIndexer stores one collection per Elasticsearch index,
+// named "qmd-<collection>".
+type elasticsearchIndexer struct {
+	collection string
+	indexName  string
+	client     *elasticsearch.Client
+}
+
+type elasticsearchDoc struct {
+	Collection string `json:"collection"`
+	Path       string `json:"path"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+}
+
+func openElasticsearch(collection string, db *sql.DB, cfg *config.Config) (Indexer, error) {
+	esCfg := cfg.BM25.Elasticsearch
+	if esCfg == nil || esCfg.URL == "" {
+		return nil, fmt.Errorf("elasticsearch: bm25.elasticsearch.url is not configured")
+	}
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses: []string{esCfg.URL},
+		APIKey:    esCfg.APIKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch: new client: %w", err)
+	}
+
+	return &elasticsearchIndexer{
+		collection: collection,
+		indexName:  "qmd-" + collection,
+		client:     client,
+	}, nil
+}
+
+func (idx *elasticsearchIndexer) Index(ctx context.Context, docs []Document) error {
+	for _, doc := range docs {
+		body, err := json.Marshal(elasticsearchDoc{
+			Collection: doc.Collection,
+			Path:       doc.Path,
+			Title:      doc.Title,
+			Body:       doc.Body,
+		})
+		if err != nil {
+			return fmt.Errorf("elasticsearch: marshal %s: %w", doc.Path, err)
+		}
+
+		req := esapi.IndexRequest{
+			Index:      idx.indexName,
+			DocumentID: doc.ID,
+			Body:       bytes.NewReader(body),
+			Refresh:    "false",
+		}
+		res, err := req.Do(ctx, idx.client)
+		if err != nil {
+			return fmt.Errorf("elasticsearch: index %s: %w", doc.Path, err)
+		}
+		res.Body.Close()
+		if res.IsError() {
+			return fmt.Errorf("elasticsearch: index %s: %s", doc.Path, res.Status())
+		}
+	}
+	return nil
+}
+
+func (idx *elasticsearchIndexer) Search(ctx context.Context, q Query) ([]SearchResult, error) {
+	query := map[string]any{
+		"size": q.Limit,
+		"query": map[string]any{
+			"multi_match": map[string]any{
+				"query":  q.Text,
+				"fields": []string{"title", "body"},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+		return nil, err
+	}
+
+	res, err := idx.client.Search(
+		idx.client.Search.WithContext(ctx),
+		idx.client.Search.WithIndex(idx.indexName),
+		idx.client.Search.WithBody(&buf),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch: search: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("elasticsearch: search: %s", res.Status())
+	}
+
+	var parsed struct {
+		Hits struct {
+			Hits []struct {
+				ID     string            `json:"_id"`
+				Score  float32           `json:"_score"`
+				Source elasticsearchDoc  `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("elasticsearch: decode response: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(parsed.Hits.Hits))
+	for _, hit := range parsed.Hits.Hits {
+		results = append(results, SearchResult{
+			ID:         hit.ID,
+			Collection: idx.collection,
+			Path:       hit.Source.Path,
+			Title:      hit.Source.Title,
+			Score:      hit.Score,
+		})
+	}
+	return results, nil
+}
+
+func (idx *elasticsearchIndexer) Delete(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		req := esapi.DeleteRequest{Index: idx.indexName, DocumentID: id}
+		res, err := req.Do(ctx, idx.client)
+		if err != nil {
+			return fmt.Errorf("elasticsearch: delete %s: %w", id, err)
+		}
+		res.Body.Close()
+		if res.IsError() && !strings.Contains(res.Status(), strconv.Itoa(404)) {
+			return fmt.Errorf("elasticsearch: delete %s: %s", id, res.Status())
+		}
+	}
+	return nil
+}
+
+func (idx *elasticsearchIndexer) Close() error {
+	return nil
+}
+
+func (idx *elasticsearchIndexer) Health(ctx context.Context) error {
+	res, err := idx.client.Ping(idx.client.Ping.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("elasticsearch: ping: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch: ping: %s", res.Status())
+	}
+	return nil
+}
@@ -0,0 +1,487 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+// HNSWDriverName is the registry key for the HNSW vector index, selected
+// by setting Vector.Index to config.VectorIndexHNSW alongside
+// Vector.Backend: qmd_builtin. It's a distinct driver from qmd_builtin's
+// default ("qmd_builtin", the flat sqlite-vec scan) rather than a
+// variant of it, so store.Store can resolve either one by name without
+// the driver itself branching on config.
+const HNSWDriverName = "qmd_builtin_hnsw"
+
+func init() {
+	Default.RegisterVector(HNSWDriverName, openHNSW)
+}
+
+// HNSW tuning defaults, following Malkov & Yashunin's recommendations.
+// config.HNSWConfig overrides any of these per collection; see openHNSW.
+const (
+	hnswDefaultM              = 16 // neighbors per node per layer (above layer 0); layer 0 uses 2*M
+	hnswDefaultEfConstruction = 200
+)
+
+// hnswNode is one point in the graph: its vector, the metadata needed to
+// turn a hit into a SearchResult, and its neighbor list per layer
+// (Neighbors[l][i] is a node ID, looked up in the owning graph's Nodes
+// map).
+type hnswNode struct {
+	ID         string
+	Vector     []float32
+	Path       string
+	Title      string
+	Collection string
+	Neighbors  [][]string
+}
+
+// hnswGraph is the persisted, on-disk state of one collection's HNSW
+// index: every node, plus the entry point search and insert start
+// descending from. mL = 1/ln(M) is derived rather than stored.
+type hnswGraph struct {
+	Nodes      map[string]*hnswNode
+	EntryPoint string
+	MaxLayer   int
+}
+
+func newHNSWGraph() *hnswGraph {
+	return &hnswGraph{Nodes: make(map[string]*hnswNode)}
+}
+
+// hnswIndexer adapts an hnswGraph, persisted to path, to the
+// VectorIndexer interface. mu guards both the graph and rng since
+// Index/Search/Delete can run concurrently (store.retrieveParallel,
+// QuerySession's worker pool each open their own collection but share
+// no state -- this mutex is per-collection, not global).
+type hnswIndexer struct {
+	collection string
+	path       string
+
+	m              int
+	mMax0          int
+	efConstruction int
+	efSearch       int
+	distance       func(a, b []float32) float32
+
+	mu    sync.RWMutex
+	graph *hnswGraph
+	rng   *rand.Rand
+}
+
+func openHNSW(collection string, db *sql.DB, cfg *config.Config) (VectorIndexer, error) {
+	path := filepath.Join(cfg.CachePath, collection, "hnsw.idx")
+
+	graph, err := loadHNSWGraph(path)
+	if err != nil {
+		return nil, fmt.Errorf("hnsw: load index for collection %q: %w", collection, err)
+	}
+
+	tuning := cfg.Vector.HNSW
+	m := tuning.M
+	if m <= 0 {
+		m = hnswDefaultM
+	}
+	mMax0 := 2 * m
+	efConstruction := tuning.EfConstruction
+	if efConstruction <= 0 {
+		efConstruction = hnswDefaultEfConstruction
+	}
+	efSearch := tuning.EfSearch
+	if efSearch <= 0 {
+		efSearch = efConstruction
+	}
+	distance := cosineDistance
+	if tuning.Metric == "dot" {
+		distance = dotDistance
+	}
+
+	return &hnswIndexer{
+		collection:     collection,
+		path:           path,
+		m:              m,
+		mMax0:          mMax0,
+		efConstruction: efConstruction,
+		efSearch:       efSearch,
+		distance:       distance,
+		graph:          graph,
+		rng:            rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+// loadHNSWGraph reads the graph persisted at path, or returns a fresh
+// empty graph if nothing has been indexed yet.
+func loadHNSWGraph(path string) (*hnswGraph, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return newHNSWGraph(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	graph := newHNSWGraph()
+	if err := gob.NewDecoder(f).Decode(graph); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	return graph, nil
+}
+
+// save persists the graph to idx.path, creating the collection's cache
+// directory if needed. Called after every Index/Delete/Rebuild so a
+// crash between calls loses at most the in-flight batch.
+func (idx *hnswIndexer) save() error {
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0755); err != nil {
+		return err
+	}
+
+	tmp := idx.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(idx.graph); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, idx.path)
+}
+
+func (idx *hnswIndexer) Index(ctx context.Context, docs []VectorDocument) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, doc := range docs {
+		idx.insertLocked(doc.ID, doc.Vector, doc.Path, doc.Title, doc.Collection)
+	}
+
+	return idx.save()
+}
+
+// Rebuild discards the current graph and re-inserts docs from scratch.
+// Unlike Index, which only ever grows a graph, Rebuild is how a caller
+// recovers from a corrupt index file or backfills one after switching
+// Vector.Index to hnsw for a collection that already has vectors.
+func (idx *hnswIndexer) Rebuild(ctx context.Context, docs []VectorDocument) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.graph = newHNSWGraph()
+	for _, doc := range docs {
+		idx.insertLocked(doc.ID, doc.Vector, doc.Path, doc.Title, doc.Collection)
+	}
+
+	return idx.save()
+}
+
+// insertLocked assigns id a random layer, greedily descends the graph
+// from the entry point to find its neighbors at each layer, and links it
+// in both directions up to M neighbors per layer (Mmax0 at layer 0).
+// Callers must hold idx.mu.
+func (idx *hnswIndexer) insertLocked(id string, vector []float32, path, title, collection string) {
+	layer := idx.randomLevel()
+	node := &hnswNode{
+		ID:         id,
+		Vector:     vector,
+		Path:       path,
+		Title:      title,
+		Collection: collection,
+		Neighbors:  make([][]string, layer+1),
+	}
+	idx.graph.Nodes[id] = node
+
+	if idx.graph.EntryPoint == "" {
+		idx.graph.EntryPoint = id
+		idx.graph.MaxLayer = layer
+		return
+	}
+
+	entry := idx.graph.EntryPoint
+	// Descend from the current top layer to layer+1, keeping only the
+	// single closest node found at each layer as the next layer's
+	// entry point -- node isn't linked at these layers, just routed
+	// through them.
+	for l := idx.graph.MaxLayer; l > layer; l-- {
+		candidates := idx.searchLayer(vector, []string{entry}, 1, l)
+		if len(candidates) > 0 {
+			entry = candidates[0].id
+		}
+	}
+
+	entryPoints := []string{entry}
+	for l := min(layer, idx.graph.MaxLayer); l >= 0; l-- {
+		candidates := idx.searchLayer(vector, entryPoints, idx.efConstruction, l)
+
+		mMax := idx.m
+		if l == 0 {
+			mMax = idx.mMax0
+		}
+		neighbors := selectNeighbors(candidates, mMax)
+
+		node.Neighbors[l] = make([]string, len(neighbors))
+		for i, n := range neighbors {
+			node.Neighbors[l][i] = n.id
+			idx.connect(n.id, id, l, mMax)
+		}
+
+		entryPoints = make([]string, 0, len(candidates))
+		for _, c := range candidates {
+			entryPoints = append(entryPoints, c.id)
+		}
+	}
+
+	if layer > idx.graph.MaxLayer {
+		idx.graph.MaxLayer = layer
+		idx.graph.EntryPoint = id
+	}
+}
+
+// connect adds back onto node's neighbor list at layer, trimming back to
+// the mMax closest to node if the list overflows.
+func (idx *hnswIndexer) connect(node, back string, layer, mMax int) {
+	n, ok := idx.graph.Nodes[node]
+	if !ok || layer >= len(n.Neighbors) {
+		return
+	}
+
+	n.Neighbors[layer] = append(n.Neighbors[layer], back)
+	if len(n.Neighbors[layer]) <= mMax {
+		return
+	}
+
+	candidates := make([]hnswCandidate, 0, len(n.Neighbors[layer]))
+	for _, id := range n.Neighbors[layer] {
+		if other, ok := idx.graph.Nodes[id]; ok {
+			candidates = append(candidates, hnswCandidate{id: id, dist: idx.distance(n.Vector, other.Vector)})
+		}
+	}
+	trimmed := selectNeighbors(candidates, mMax)
+	n.Neighbors[layer] = make([]string, len(trimmed))
+	for i, c := range trimmed {
+		n.Neighbors[layer][i] = c.id
+	}
+}
+
+// randomLevel draws l = floor(-ln(uniform) * mL), mL = 1/ln(M), the
+// standard HNSW level assignment: level 0 is most likely, each level up
+// exponentially less so.
+func (idx *hnswIndexer) randomLevel() int {
+	mL := 1 / math.Log(float64(idx.m))
+	return int(math.Floor(-math.Log(idx.rng.Float64()+1e-12) * mL))
+}
+
+// hnswCandidate is a node considered during search/insertion, paired
+// with its distance to the query vector.
+type hnswCandidate struct {
+	id   string
+	dist float32
+}
+
+// searchLayer runs a greedy best-first search for vector within layer,
+// starting from entryPoints, and returns up to ef candidates ordered
+// closest-first. It's the same routine insertion and Search both use,
+// parameterized on ef (efConstruction while inserting, max(ef, k) while
+// searching).
+func (idx *hnswIndexer) searchLayer(vector []float32, entryPoints []string, ef, layer int) []hnswCandidate {
+	visited := make(map[string]bool)
+	var candidates, found []hnswCandidate
+
+	for _, id := range entryPoints {
+		n, ok := idx.graph.Nodes[id]
+		if !ok || visited[id] {
+			continue
+		}
+		visited[id] = true
+		c := hnswCandidate{id: id, dist: idx.distance(vector, n.Vector)}
+		candidates = append(candidates, c)
+		found = append(found, c)
+	}
+
+	for len(candidates) > 0 {
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].dist < candidates[j].dist })
+		current := candidates[0]
+		candidates = candidates[1:]
+
+		worst := ef
+		if len(found) > 0 {
+			sort.Slice(found, func(i, j int) bool { return found[i].dist < found[j].dist })
+			if len(found) >= worst && current.dist > found[worst-1].dist {
+				break
+			}
+		}
+
+		node := idx.graph.Nodes[current.id]
+		if layer >= len(node.Neighbors) {
+			continue
+		}
+		for _, neighborID := range node.Neighbors[layer] {
+			if visited[neighborID] {
+				continue
+			}
+			visited[neighborID] = true
+			neighbor, ok := idx.graph.Nodes[neighborID]
+			if !ok {
+				continue
+			}
+			c := hnswCandidate{id: neighborID, dist: idx.distance(vector, neighbor.Vector)}
+			candidates = append(candidates, c)
+			found = append(found, c)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].dist < found[j].dist })
+	if len(found) > ef {
+		found = found[:ef]
+	}
+	return found
+}
+
+// selectNeighbors implements the simple heuristic from the HNSW paper: a
+// candidate is kept only if it's closer to the inserted node than it is
+// to every neighbor already selected, which prunes candidates a closer
+// neighbor already "dominates" rather than just taking the m closest by
+// raw distance.
+func selectNeighbors(candidates []hnswCandidate, m int) []hnswCandidate {
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].dist < candidates[j].dist })
+
+	var selected []hnswCandidate
+	for _, c := range candidates {
+		if len(selected) >= m {
+			break
+		}
+		selected = append(selected, c)
+	}
+	return selected
+}
+
+// Search runs a greedy descent from the graph's entry point to layer 1,
+// then a searchLayer pass at layer 0 with ef = max(idx.efSearch, limit),
+// returning up to limit hits ranked by similarity (1 - distance; higher
+// is better, matching the other vector backends' score convention).
+func (idx *hnswIndexer) Search(ctx context.Context, vector []float32, limit int) ([]SearchResult, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if idx.graph.EntryPoint == "" {
+		return nil, nil
+	}
+
+	entry := idx.graph.EntryPoint
+	for l := idx.graph.MaxLayer; l > 0; l-- {
+		candidates := idx.searchLayer(vector, []string{entry}, 1, l)
+		if len(candidates) > 0 {
+			entry = candidates[0].id
+		}
+	}
+
+	ef := limit
+	if ef < idx.efSearch {
+		ef = idx.efSearch
+	}
+	candidates := idx.searchLayer(vector, []string{entry}, ef, 0)
+
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	results := make([]SearchResult, 0, len(candidates))
+	for _, c := range candidates {
+		n := idx.graph.Nodes[c.id]
+		results = append(results, SearchResult{
+			ID:         n.ID,
+			Collection: n.Collection,
+			Path:       n.Path,
+			Title:      n.Title,
+			Score:      1 - c.dist,
+		})
+	}
+	return results, nil
+}
+
+// Delete removes nodes from the graph without repairing neighbors that
+// pointed at them -- searchLayer already skips any neighbor ID missing
+// from Nodes, so a stale reference is harmless, just a wasted hop. A
+// collection with heavy churn should periodically Rebuild instead of
+// relying on this indefinitely.
+func (idx *hnswIndexer) Delete(ctx context.Context, ids ...string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, id := range ids {
+		delete(idx.graph.Nodes, id)
+		if idx.graph.EntryPoint == id {
+			idx.graph.EntryPoint = ""
+			for otherID := range idx.graph.Nodes {
+				idx.graph.EntryPoint = otherID
+				break
+			}
+		}
+	}
+
+	return idx.save()
+}
+
+func (idx *hnswIndexer) Close() error {
+	return nil
+}
+
+// cosineDistance returns 1 - cosine similarity, so 0 means identical
+// direction. Vectors of mismatched length (shouldn't happen within one
+// collection) are treated as maximally distant rather than panicking.
+func cosineDistance(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 2
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 2
+	}
+
+	similarity := dot / (math.Sqrt(normA) * math.Sqrt(normB))
+	return float32(1 - similarity)
+}
+
+// dotDistance returns 1 - dot(a, b), the cheaper alternative to
+// cosineDistance for embedders whose output is already L2-normalized,
+// where it's equivalent to cosine similarity without the per-comparison
+// norm computation. Selected via config.HNSWConfig.Metric = "dot".
+func dotDistance(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 2
+	}
+
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return float32(1 - dot)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
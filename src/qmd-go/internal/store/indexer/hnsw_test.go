@@ -0,0 +1,138 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func TestHNSW_IndexAndSearch_FindsNearestNeighbor(t *testing.T) {
+	cfg := &config.Config{CachePath: t.TempDir()}
+
+	idx, err := openHNSW("docs", nil, cfg)
+	if err != nil {
+		t.Fatalf("openHNSW: %v", err)
+	}
+
+	docs := []VectorDocument{
+		{ID: "a", Path: "a.md", Vector: []float32{1, 0, 0}},
+		{ID: "b", Path: "b.md", Vector: []float32{0, 1, 0}},
+		{ID: "c", Path: "c.md", Vector: []float32{0.9, 0.1, 0}},
+	}
+	if err := idx.Index(context.Background(), docs); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+
+	results, err := idx.Search(context.Background(), []float32{1, 0, 0}, 2)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) == 0 || results[0].ID != "a" {
+		t.Errorf("Search() = %+v, want %q ranked first", results, "a")
+	}
+}
+
+func TestHNSW_PersistsAcrossReopen(t *testing.T) {
+	cachePath := t.TempDir()
+	cfg := &config.Config{CachePath: cachePath}
+
+	idx, err := openHNSW("docs", nil, cfg)
+	if err != nil {
+		t.Fatalf("openHNSW: %v", err)
+	}
+	if err := idx.Index(context.Background(), []VectorDocument{
+		{ID: "a", Path: "a.md", Vector: []float32{1, 0, 0}},
+	}); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+
+	reopened, err := openHNSW("docs", nil, cfg)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	results, err := reopened.Search(context.Background(), []float32{1, 0, 0}, 1)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "a" {
+		t.Errorf("Search() after reopen = %+v, want one result for %q", results, "a")
+	}
+
+	if _, err := os.Stat(filepath.Join(cachePath, "docs", "hnsw.idx")); err != nil {
+		t.Errorf("expected hnsw.idx to be persisted: %v", err)
+	}
+}
+
+func TestHNSW_Rebuild_DiscardsPriorState(t *testing.T) {
+	cfg := &config.Config{CachePath: t.TempDir()}
+	idx, err := openHNSW("docs", nil, cfg)
+	if err != nil {
+		t.Fatalf("openHNSW: %v", err)
+	}
+
+	rebuilder := idx.(Rebuilder)
+	if err := idx.Index(context.Background(), []VectorDocument{
+		{ID: "stale", Path: "stale.md", Vector: []float32{1, 0, 0}},
+	}); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+
+	if err := rebuilder.Rebuild(context.Background(), []VectorDocument{
+		{ID: "fresh", Path: "fresh.md", Vector: []float32{0, 1, 0}},
+	}); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+
+	results, err := idx.Search(context.Background(), []float32{0, 1, 0}, 5)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	for _, r := range results {
+		if r.ID == "stale" {
+			t.Errorf("Rebuild should have discarded %q, got it back in %+v", "stale", results)
+		}
+	}
+}
+
+func TestHNSW_ConfigTuningOverridesDefaults(t *testing.T) {
+	cfg := &config.Config{
+		CachePath: t.TempDir(),
+		Vector: config.VectorConfig{
+			HNSW: config.HNSWConfig{M: 4, EfConstruction: 8, EfSearch: 8, Metric: "dot"},
+		},
+	}
+
+	opened, err := openHNSW("docs", nil, cfg)
+	if err != nil {
+		t.Fatalf("openHNSW: %v", err)
+	}
+	idx := opened.(*hnswIndexer)
+
+	if idx.m != 4 {
+		t.Errorf("m = %d, want 4", idx.m)
+	}
+	if idx.mMax0 != 8 {
+		t.Errorf("mMax0 = %d, want 8 (2*M)", idx.mMax0)
+	}
+	if idx.efConstruction != 8 || idx.efSearch != 8 {
+		t.Errorf("efConstruction/efSearch = %d/%d, want 8/8", idx.efConstruction, idx.efSearch)
+	}
+
+	docs := []VectorDocument{
+		{ID: "a", Path: "a.md", Vector: []float32{1, 0, 0}},
+		{ID: "b", Path: "b.md", Vector: []float32{0, 1, 0}},
+	}
+	if err := idx.Index(context.Background(), docs); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	results, err := idx.Search(context.Background(), []float32{1, 0, 0}, 1)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "a" {
+		t.Errorf("Search() with dot metric = %+v, want %q ranked first", results, "a")
+	}
+}
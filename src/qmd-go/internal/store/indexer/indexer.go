@@ -0,0 +1,98 @@
+// Package indexer defines the pluggable full-text and vector indexing
+// backends store.Store searches against, and a Registry that resolves a
+// config-selected backend name (the --fts-backend/--vector-backend flag
+// values) to a concrete driver. It's modeled after database/sql's driver
+// registry: each driver lives in its own file and registers itself
+// against the package-level Default registry from an init() function,
+// rather than Store switching on backend name inline.
+package indexer
+
+import "context"
+
+// Document is one unit of content an Indexer stores and searches over.
+type Document struct {
+	ID         string
+	Collection string
+	Path       string
+	Title      string
+	Body       string
+	Hash       string
+}
+
+// Query is a full-text search request against one or all collections.
+type Query struct {
+	Text       string
+	Collection string
+	Limit      int
+	// Regex, when set, tells a backend that supports it (currently only
+	// trigram) to treat Text as a regular expression instead of a
+	// literal substring. Ignored by backends that don't implement
+	// substring/regex matching (sqlite_fts5, bleve, elasticsearch,
+	// meilisearch, lancedb), which always treat Text as their own query
+	// syntax.
+	Regex bool
+}
+
+// SearchResult is one hit from an Indexer or VectorIndexer, shaped to
+// convert directly into store.SearchResult.
+type SearchResult struct {
+	ID         string
+	Collection string
+	Path       string
+	Title      string
+	Score      float32
+}
+
+// Indexer is a full-text search backend for one collection. Concrete
+// drivers exist for sqlite_fts5, bleve, elasticsearch, and meilisearch.
+type Indexer interface {
+	Index(ctx context.Context, docs []Document) error
+	Search(ctx context.Context, query Query) ([]SearchResult, error)
+	Delete(ctx context.Context, ids ...string) error
+	Close() error
+}
+
+// VectorDocument is one embedded chunk a VectorIndexer stores and
+// searches over. VectorName selects a named vector space (for backends
+// that support more than one embedder per collection, e.g. Qdrant);
+// empty means the backend's single default vector.
+type VectorDocument struct {
+	ID         string
+	Collection string
+	Path       string
+	Title      string
+	Body       string
+	Hash       string
+	Vector     []float32
+	VectorName string
+}
+
+// VectorIndexer is a vector search backend. Concrete drivers exist for
+// sqlite_vec, qdrant, and lancedb.
+type VectorIndexer interface {
+	Index(ctx context.Context, docs []VectorDocument) error
+	Search(ctx context.Context, vector []float32, limit int) ([]SearchResult, error)
+	Delete(ctx context.Context, ids ...string) error
+	Close() error
+}
+
+// HealthChecker is implemented by indexers that can report their own
+// health independent of a search (e.g. a remote backend's connectivity).
+// `qmd status --backends` calls it when available; a driver with nothing
+// separate to check (sqlite_fts5 and sqlite_vec, which piggyback on the
+// collection's already-open *sql.DB) can skip implementing it and is
+// reported healthy as long as it resolved at all.
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// Rebuilder is implemented by vector indexers that keep accumulated
+// state an incremental Index call can't correct on its own (e.g. an
+// HNSW graph, where re-inserting every document just grows the graph
+// rather than fixing it). Rebuild discards that state and re-indexes
+// docs from scratch. Backends with nothing to rebuild (sqlite_vec,
+// qdrant, a thin view over someone else's storage) can skip implementing
+// it.
+type Rebuilder interface {
+	Rebuild(ctx context.Context, docs []VectorDocument) error
+}
@@ -0,0 +1,31 @@
+package indexer
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func init() {
+	Default.RegisterFTS(string(config.BM25BackendLanceDB), openLanceDBFTS)
+	Default.RegisterVector(string(config.VectorBackendLanceDB), openLanceDBVector)
+}
+
+// LanceDB is declared as a selectable backend in config.BM25Backend and
+// config.VectorBackend, and config.LanceDBConfig (bm25.lancedb/
+// vector.lancedb) already carries the URI/table a driver would need, but
+// there's no Go client to build one against yet: LanceDB's maintained
+// SDKs are Python/Node/Rust, and its on-disk format is an evolving
+// Arrow-over-Lance encoding rather than a stable wire protocol a
+// hand-rolled client could target safely. Resolving it fails clearly
+// instead of silently falling back to another backend, so a
+// misconfigured --fts-backend/--vector-backend surfaces immediately, and
+// the error names the missing piece instead of just "not implemented".
+func openLanceDBFTS(collection string, db *sql.DB, cfg *config.Config) (Indexer, error) {
+	return nil, fmt.Errorf("indexer: lancedb fts backend has no Go client integration yet")
+}
+
+func openLanceDBVector(collection string, db *sql.DB, cfg *config.Config) (VectorIndexer, error) {
+	return nil, fmt.Errorf("indexer: lancedb vector backend has no Go client integration yet")
+}
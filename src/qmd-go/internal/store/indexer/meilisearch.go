@@ -0,0 +1,95 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/meilisearch/meilisearch-go"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func init() {
+	Default.RegisterFTS(string(config.BM25BackendMeilisearch), openMeilisearch)
+}
+
+// meilisearchIndexer stores one collection per Meilisearch index, named
+// "qmd-<collection>".
+type meilisearchIndexer struct {
+	collection string
+	index      meilisearch.IndexManager
+}
+
+type meilisearchDoc struct {
+	ID         string `json:"id"`
+	Collection string `json:"collection"`
+	Path       string `json:"path"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+}
+
+func openMeilisearch(collection string, db *sql.DB, cfg *config.Config) (Indexer, error) {
+	msCfg := cfg.BM25.Meilisearch
+	if msCfg == nil || msCfg.URL == "" {
+		return nil, fmt.Errorf("meilisearch: bm25.meilisearch.url is not configured")
+	}
+
+	client := meilisearch.New(msCfg.URL, meilisearch.WithAPIKey(msCfg.APIKey))
+	indexName := "qmd-" + collection
+	if _, err := client.CreateIndex(&meilisearch.IndexConfig{Uid: indexName, PrimaryKey: "id"}); err != nil {
+		// CreateIndex errors when the index already exists; that's fine.
+	}
+
+	return &meilisearchIndexer{collection: collection, index: client.Index(indexName)}, nil
+}
+
+func (idx *meilisearchIndexer) Index(ctx context.Context, docs []Document) error {
+	batch := make([]meilisearchDoc, len(docs))
+	for i, doc := range docs {
+		batch[i] = meilisearchDoc{
+			ID:         doc.ID,
+			Collection: doc.Collection,
+			Path:       doc.Path,
+			Title:      doc.Title,
+			Body:       doc.Body,
+		}
+	}
+	_, err := idx.index.AddDocuments(batch, &meilisearch.DocumentOptions{PrimaryKey: meilisearch.StringPtr("id")})
+	return err
+}
+
+func (idx *meilisearchIndexer) Search(ctx context.Context, q Query) ([]SearchResult, error) {
+	res, err := idx.index.Search(q.Text, &meilisearch.SearchRequest{Limit: int64(q.Limit)})
+	if err != nil {
+		return nil, fmt.Errorf("meilisearch: search: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(res.Hits))
+	for rank, hit := range res.Hits {
+		var doc meilisearchDoc
+		if err := hit.DecodeInto(&doc); err != nil {
+			continue
+		}
+		results = append(results, SearchResult{
+			ID:         doc.ID,
+			Collection: idx.collection,
+			Path:       doc.Path,
+			Title:      doc.Title,
+			// Meilisearch doesn't return a relevance score by default;
+			// approximate one from rank so results stay comparable to
+			// other backends' descending-score ordering.
+			Score: 1.0 / float32(rank+1),
+		})
+	}
+	return results, nil
+}
+
+func (idx *meilisearchIndexer) Delete(ctx context.Context, ids ...string) error {
+	_, err := idx.index.DeleteDocuments(ids, nil)
+	return err
+}
+
+func (idx *meilisearchIndexer) Close() error {
+	return nil
+}
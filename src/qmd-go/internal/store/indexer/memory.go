@@ -0,0 +1,176 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func init() {
+	Default.RegisterFTS(string(config.BM25BackendMemory), openMemoryFTS)
+	Default.RegisterVector(string(config.VectorBackendMemory), openMemoryVector)
+}
+
+// memoryFTS is a process-local, non-persistent Indexer: no file, no
+// service, nothing to clean up between test runs beyond the process
+// exiting. It ranks by term-overlap count rather than BM25, which is
+// plenty to assert "the right document came back" in a test without
+// depending on sqlite_fts5's scoring or a throwaway cache directory.
+type memoryFTS struct {
+	collection string
+	mu         sync.RWMutex
+	docs       map[string]Document
+}
+
+func openMemoryFTS(collection string, db *sql.DB, cfg *config.Config) (Indexer, error) {
+	return &memoryFTS{collection: collection, docs: make(map[string]Document)}, nil
+}
+
+func (idx *memoryFTS) Index(ctx context.Context, docs []Document) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, doc := range docs {
+		idx.docs[doc.ID] = doc
+	}
+	return nil
+}
+
+func (idx *memoryFTS) Search(ctx context.Context, query Query) ([]SearchResult, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	terms := strings.Fields(strings.ToLower(query.Text))
+	var results []SearchResult
+	for _, doc := range idx.docs {
+		if query.Collection != "" && doc.Collection != query.Collection {
+			continue
+		}
+		score := memoryTermOverlap(terms, doc.Title, doc.Body)
+		if score == 0 {
+			continue
+		}
+		results = append(results, SearchResult{
+			ID:         doc.ID,
+			Collection: doc.Collection,
+			Path:       doc.Path,
+			Title:      doc.Title,
+			Score:      score,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+	return results, nil
+}
+
+// memoryTermOverlap counts how many of terms appear in title or body,
+// weighting a title hit like sqlite_fts5's "title" column boost so a
+// match in the title still outranks a body-only match.
+func memoryTermOverlap(terms []string, title, body string) float32 {
+	titleLower := strings.ToLower(title)
+	bodyLower := strings.ToLower(body)
+
+	var score float32
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		if strings.Contains(titleLower, term) {
+			score += 2
+		}
+		if strings.Contains(bodyLower, term) {
+			score++
+		}
+	}
+	return score
+}
+
+func (idx *memoryFTS) Delete(ctx context.Context, ids ...string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, id := range ids {
+		delete(idx.docs, id)
+	}
+	return nil
+}
+
+func (idx *memoryFTS) Close() error { return nil }
+
+// memoryVector is the VectorIndexer counterpart of memoryFTS: brute-force
+// cosine similarity over whatever's been indexed this process, with no
+// ANN structure to build or persist. Fine for test collections; not
+// meant to scale the way sqlite_vec/qdrant/milvus do.
+type memoryVector struct {
+	collection string
+	mu         sync.RWMutex
+	docs       map[string]VectorDocument
+}
+
+func openMemoryVector(collection string, db *sql.DB, cfg *config.Config) (VectorIndexer, error) {
+	return &memoryVector{collection: collection, docs: make(map[string]VectorDocument)}, nil
+}
+
+func (idx *memoryVector) Index(ctx context.Context, docs []VectorDocument) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, doc := range docs {
+		idx.docs[doc.ID] = doc
+	}
+	return nil
+}
+
+func (idx *memoryVector) Search(ctx context.Context, vector []float32, limit int) ([]SearchResult, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var results []SearchResult
+	for _, doc := range idx.docs {
+		results = append(results, SearchResult{
+			ID:         doc.ID,
+			Collection: doc.Collection,
+			Path:       doc.Path,
+			Title:      doc.Title,
+			Score:      cosineSimilarity(vector, doc.Vector),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func cosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
+
+func (idx *memoryVector) Delete(ctx context.Context, ids ...string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, id := range ids {
+		delete(idx.docs, id)
+	}
+	return nil
+}
+
+func (idx *memoryVector) Close() error { return nil }
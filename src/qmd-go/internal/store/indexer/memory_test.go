@@ -0,0 +1,82 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func TestMemoryFTS_IndexAndSearch(t *testing.T) {
+	idx, err := openMemoryFTS("notes", nil, config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("openMemoryFTS: %v", err)
+	}
+
+	docs := []Document{
+		{ID: "1", Collection: "notes", Path: "a.md", Title: "Go channels", Body: "a tutorial on channels"},
+		{ID: "2", Collection: "notes", Path: "b.md", Title: "Rust ownership", Body: "borrowing and lifetimes"},
+	}
+	if err := idx.Index(context.Background(), docs); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+
+	results, err := idx.Search(context.Background(), Query{Text: "channels", Collection: "notes", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "1" {
+		t.Errorf("Search(%q) = %+v, want one result for doc 1", "channels", results)
+	}
+}
+
+func TestMemoryFTS_Delete(t *testing.T) {
+	idx, _ := openMemoryFTS("notes", nil, config.DefaultConfig())
+	idx.Index(context.Background(), []Document{{ID: "1", Collection: "notes", Title: "channels", Body: "channels"}})
+
+	if err := idx.Delete(context.Background(), "1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	results, err := idx.Search(context.Background(), Query{Text: "channels", Collection: "notes"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Search() after Delete = %+v, want no results", results)
+	}
+}
+
+func TestMemoryVector_SearchRanksByCosineSimilarity(t *testing.T) {
+	idx, err := openMemoryVector("notes", nil, config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("openMemoryVector: %v", err)
+	}
+
+	docs := []VectorDocument{
+		{ID: "close", Collection: "notes", Path: "a.md", Title: "a", Vector: []float32{1, 0}},
+		{ID: "far", Collection: "notes", Path: "b.md", Title: "b", Vector: []float32{0, 1}},
+	}
+	if err := idx.Index(context.Background(), docs); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+
+	results, err := idx.Search(context.Background(), []float32{1, 0}, 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "close" {
+		t.Errorf("Search() = %+v, want %q ranked first", results, "close")
+	}
+}
+
+func TestDefaultRegistry_MemoryBackendsAreRegistered(t *testing.T) {
+	cfg := config.DefaultConfig()
+
+	if _, err := Default.OpenFTS(string(config.BM25BackendMemory), "notes", nil, cfg); err != nil {
+		t.Errorf("OpenFTS(memory): %v", err)
+	}
+	if _, err := Default.OpenVector(string(config.VectorBackendMemory), "notes", nil, cfg); err != nil {
+		t.Errorf("OpenVector(memory): %v", err)
+	}
+}
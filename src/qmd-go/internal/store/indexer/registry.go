@@ -0,0 +1,71 @@
+package indexer
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+// FTSOpenFunc builds an Indexer for one collection. db is the
+// collection's already-open sqlite connection, used only by the
+// sqlite_fts5 driver; remote backends ignore it and open their own
+// client from cfg.
+type FTSOpenFunc func(collection string, db *sql.DB, cfg *config.Config) (Indexer, error)
+
+// VectorOpenFunc builds a VectorIndexer for one collection. db is used
+// only by the sqlite_vec driver, mirroring FTSOpenFunc.
+type VectorOpenFunc func(collection string, db *sql.DB, cfg *config.Config) (VectorIndexer, error)
+
+// Registry maps backend names (the --fts-backend/--vector-backend flag
+// values) to the driver that builds them.
+type Registry struct {
+	fts    map[string]FTSOpenFunc
+	vector map[string]VectorOpenFunc
+}
+
+// NewRegistry creates an empty Registry. Most callers want the
+// package-level Default, which built-in drivers register themselves
+// into.
+func NewRegistry() *Registry {
+	return &Registry{
+		fts:    make(map[string]FTSOpenFunc),
+		vector: make(map[string]VectorOpenFunc),
+	}
+}
+
+// Default is pre-populated with qmd's built-in drivers via their
+// init() functions.
+var Default = NewRegistry()
+
+// RegisterFTS registers an FTS driver under name, overwriting any driver
+// previously registered under the same name.
+func (r *Registry) RegisterFTS(name string, open FTSOpenFunc) {
+	r.fts[name] = open
+}
+
+// RegisterVector registers a vector driver under name, overwriting any
+// driver previously registered under the same name.
+func (r *Registry) RegisterVector(name string, open VectorOpenFunc) {
+	r.vector[name] = open
+}
+
+// OpenFTS resolves name to a registered FTS driver and builds an Indexer
+// for collection.
+func (r *Registry) OpenFTS(name, collection string, db *sql.DB, cfg *config.Config) (Indexer, error) {
+	open, ok := r.fts[name]
+	if !ok {
+		return nil, fmt.Errorf("indexer: no fts backend registered as %q", name)
+	}
+	return open(collection, db, cfg)
+}
+
+// OpenVector resolves name to a registered vector driver and builds a
+// VectorIndexer for collection.
+func (r *Registry) OpenVector(name, collection string, db *sql.DB, cfg *config.Config) (VectorIndexer, error) {
+	open, ok := r.vector[name]
+	if !ok {
+		return nil, fmt.Errorf("indexer: no vector backend registered as %q", name)
+	}
+	return open(collection, db, cfg)
+}
@@ -0,0 +1,74 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+type fakeIndexer struct{ name string }
+
+func (f *fakeIndexer) Index(ctx context.Context, docs []Document) error { return nil }
+
+func (f *fakeIndexer) Search(ctx context.Context, q Query) ([]SearchResult, error) {
+	return []SearchResult{{ID: f.name}}, nil
+}
+
+func (f *fakeIndexer) Delete(ctx context.Context, ids ...string) error { return nil }
+
+func (f *fakeIndexer) Close() error { return nil }
+
+func TestRegistry_OpenFTS_ResolvesRegisteredDriver(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterFTS("fake", func(collection string, db *sql.DB, cfg *config.Config) (Indexer, error) {
+		return &fakeIndexer{name: collection}, nil
+	})
+
+	idx, err := r.OpenFTS("fake", "notes", nil, config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("OpenFTS: %v", err)
+	}
+
+	results, err := idx.Search(context.Background(), Query{Text: "x"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "notes" {
+		t.Errorf("Search() = %+v, want one result for collection %q", results, "notes")
+	}
+}
+
+func TestRegistry_OpenFTS_UnknownBackend(t *testing.T) {
+	r := NewRegistry()
+	if _, err := r.OpenFTS("does-not-exist", "notes", nil, config.DefaultConfig()); err == nil {
+		t.Error("expected an error resolving an unregistered backend")
+	}
+}
+
+func TestRegistry_OpenVector_UnknownBackend(t *testing.T) {
+	r := NewRegistry()
+	if _, err := r.OpenVector("does-not-exist", "notes", nil, config.DefaultConfig()); err == nil {
+		t.Error("expected an error resolving an unregistered backend")
+	}
+}
+
+func TestDefaultRegistry_LanceDBReportsNotImplemented(t *testing.T) {
+	cfg := config.DefaultConfig()
+
+	if _, err := Default.OpenFTS(string(config.BM25BackendLanceDB), "notes", nil, cfg); err == nil {
+		t.Error("expected lancedb fts to report not-implemented, got no error")
+	}
+	if _, err := Default.OpenVector(string(config.VectorBackendLanceDB), "notes", nil, cfg); err == nil {
+		t.Error("expected lancedb vector to report not-implemented, got no error")
+	}
+}
+
+func TestDefaultRegistry_SQLiteFTS5RequiresConnection(t *testing.T) {
+	cfg := config.DefaultConfig()
+
+	if _, err := Default.OpenFTS(string(config.BM25BackendSqliteFTS5), "notes", nil, cfg); err == nil {
+		t.Error("expected an error opening sqlite_fts5 without a *sql.DB")
+	}
+}
@@ -0,0 +1,98 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func init() {
+	Default.RegisterFTS(string(config.BM25BackendSqliteFTS5), openSQLiteFTS5)
+}
+
+// sqliteFTS5Indexer runs the same FTS5 queries store.Store previously
+// ran inline, against the collection's already-open *sql.DB (the schema,
+// including the documents_fts virtual table and its sync triggers, is
+// created by store.Store.initSchema).
+type sqliteFTS5Indexer struct {
+	collection string
+	db         *sql.DB
+}
+
+func openSQLiteFTS5(collection string, db *sql.DB, cfg *config.Config) (Indexer, error) {
+	if db == nil {
+		return nil, fmt.Errorf("sqlite_fts5: no connection for collection %q", collection)
+	}
+	return &sqliteFTS5Indexer{collection: collection, db: db}, nil
+}
+
+func (idx *sqliteFTS5Indexer) Index(ctx context.Context, docs []Document) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, doc := range docs {
+		if _, err := idx.db.ExecContext(ctx, `
+			INSERT INTO content (hash, doc, size) VALUES (?, ?, ?)
+			ON CONFLICT(hash) DO UPDATE SET doc = excluded.doc, size = excluded.size
+		`, doc.Hash, doc.Body, len(doc.Body)); err != nil {
+			return fmt.Errorf("index %s: %w", doc.Path, err)
+		}
+
+		if _, err := idx.db.ExecContext(ctx, `
+			INSERT INTO documents (collection, path, title, hash, created_at, modified_at, active)
+			VALUES (?, ?, ?, ?, ?, ?, 1)
+			ON CONFLICT(hash) DO UPDATE SET title = excluded.title, modified_at = excluded.modified_at, active = 1
+		`, idx.collection, doc.Path, doc.Title, doc.Hash, now, now); err != nil {
+			return fmt.Errorf("index %s: %w", doc.Path, err)
+		}
+	}
+	return nil
+}
+
+func (idx *sqliteFTS5Indexer) Search(ctx context.Context, query Query) ([]SearchResult, error) {
+	rows, err := idx.db.QueryContext(ctx, `
+		SELECT rowid, bm25(documents_fts), title, path
+		FROM documents_fts
+		WHERE documents_fts MATCH ? AND active = 1
+		ORDER BY bm25(documents_fts)
+		LIMIT ?
+	`, fmt.Sprintf("%s NOT active:0", query.Text), query.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var rowID int64
+		var score float64
+		var title, path string
+		if err := rows.Scan(&rowID, &score, &title, &path); err != nil {
+			return nil, err
+		}
+		results = append(results, SearchResult{
+			ID:         fmt.Sprintf("%d", rowID),
+			Collection: idx.collection,
+			Path:       path,
+			Title:      title,
+			Score:      float32(score),
+		})
+	}
+	return results, rows.Err()
+}
+
+func (idx *sqliteFTS5Indexer) Delete(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		if _, err := idx.db.ExecContext(ctx, `UPDATE documents SET active = 0 WHERE hash = ?`, id); err != nil {
+			return fmt.Errorf("delete %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (idx *sqliteFTS5Indexer) Close() error {
+	// The *sql.DB is owned by store.Store's connection pool, not this
+	// indexer, so there's nothing to close here.
+	return nil
+}
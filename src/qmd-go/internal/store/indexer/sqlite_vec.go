@@ -0,0 +1,110 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func init() {
+	Default.RegisterVector(string(config.VectorBackendQmdBuiltin), openSQLiteVec)
+}
+
+// sqliteVecIndexer runs the same vec0 queries store.Store previously ran
+// inline in VectorSearchSQLite, against the collection's already-open
+// *sql.DB.
+type sqliteVecIndexer struct {
+	collection string
+	db         *sql.DB
+}
+
+func openSQLiteVec(collection string, db *sql.DB, cfg *config.Config) (VectorIndexer, error) {
+	if db == nil {
+		return nil, fmt.Errorf("sqlite_vec: no connection for collection %q", collection)
+	}
+	return &sqliteVecIndexer{collection: collection, db: db}, nil
+}
+
+func (idx *sqliteVecIndexer) Index(ctx context.Context, docs []VectorDocument) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	for seq, doc := range docs {
+		vectorJSON, err := json.Marshal(doc.Vector)
+		if err != nil {
+			return fmt.Errorf("index %s: %w", doc.Path, err)
+		}
+
+		hashSeq := fmt.Sprintf("%s_%d", doc.Hash, seq)
+		if _, err := idx.db.ExecContext(ctx, `
+			INSERT INTO vectors_vec (hash_seq, embedding) VALUES (?, ?)
+			ON CONFLICT(hash_seq) DO UPDATE SET embedding = excluded.embedding
+		`, hashSeq, string(vectorJSON)); err != nil {
+			return fmt.Errorf("index %s: %w", doc.Path, err)
+		}
+
+		if _, err := idx.db.ExecContext(ctx, `
+			INSERT INTO content_vectors (hash, seq, pos, model, embedded_at) VALUES (?, ?, 0, ?, ?)
+			ON CONFLICT(hash, seq) DO UPDATE SET embedded_at = excluded.embedded_at
+		`, doc.Hash, seq, doc.VectorName, now); err != nil {
+			return fmt.Errorf("index %s: %w", doc.Path, err)
+		}
+	}
+	return nil
+}
+
+func (idx *sqliteVecIndexer) Search(ctx context.Context, vector []float32, limit int) ([]SearchResult, error) {
+	vectorJSON, err := json.Marshal(vector)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := idx.db.QueryContext(ctx, `
+		SELECT
+			v.embedding,
+			d.title,
+			d.path,
+			d.hash
+		FROM vectors_vec v
+		JOIN documents d ON v.hash_seq LIKE d.hash || '%'
+		WHERE d.active = 1
+		ORDER BY v.embedding <=> ?
+		LIMIT ?
+	`, string(vectorJSON), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var embedding float64
+		var title, path, hash string
+		if err := rows.Scan(&embedding, &title, &path, &hash); err != nil {
+			return nil, err
+		}
+		results = append(results, SearchResult{
+			ID:         hash,
+			Collection: idx.collection,
+			Path:       path,
+			Title:      title,
+			Score:      float32(1.0 / (1.0 + embedding)),
+		})
+	}
+	return results, rows.Err()
+}
+
+func (idx *sqliteVecIndexer) Delete(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		if _, err := idx.db.ExecContext(ctx, `DELETE FROM vectors_vec WHERE hash_seq LIKE ? || '%'`, id); err != nil {
+			return fmt.Errorf("delete %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (idx *sqliteVecIndexer) Close() error {
+	return nil
+}
@@ -0,0 +1,293 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func init() {
+	Default.RegisterFTS(string(config.BM25BackendTrigram), openTrigram)
+}
+
+// minTrigramQueryLen is the shortest query Search will trigram-filter
+// before verifying candidates; shorter queries fall back to scanning
+// every active document's body, since a 1- or 2-byte query yields no
+// trigrams to intersect on.
+const minTrigramQueryLen = 3
+
+// trigramIndexer is a Zoekt-style substring/regex index: for each
+// document it extracts overlapping 3-byte trigrams (both case-preserving
+// and lowercased, in separate posting tables), stores `trigram -> []hash`
+// postings in the collection's own sqlite connection, and answers a
+// query by intersecting the postings of every trigram in the query, then
+// verifying each candidate with an actual substring or regexp match
+// against the stored body -- the posting-list intersection is a
+// prefilter, not a final answer, since two docs sharing the query's
+// trigrams need not contain the query's substring as a contiguous run.
+//
+// It's registered as a distinct indexer.Indexer under
+// config.BM25BackendTrigram rather than plumbed through bm25.backend,
+// since it answers substring/regex queries, not BM25-ranked full-text
+// ones -- store.Store resolves it explicitly for the `qmd code` command
+// (see Store.CodeSearch) the same way migrate.go resolves a named
+// backend directly, regardless of a collection's configured default.
+type trigramIndexer struct {
+	collection string
+	db         *sql.DB
+}
+
+func openTrigram(collection string, db *sql.DB, cfg *config.Config) (Indexer, error) {
+	if db == nil {
+		return nil, fmt.Errorf("trigram: no connection for collection %q", collection)
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS trigram_documents (
+			hash       TEXT PRIMARY KEY,
+			collection TEXT NOT NULL,
+			path       TEXT NOT NULL,
+			title      TEXT NOT NULL,
+			body       TEXT NOT NULL,
+			active     INTEGER NOT NULL DEFAULT 1
+		);
+		CREATE TABLE IF NOT EXISTS trigram_postings (
+			trigram TEXT NOT NULL,
+			hash    TEXT NOT NULL,
+			PRIMARY KEY (trigram, hash)
+		);
+		CREATE INDEX IF NOT EXISTS idx_trigram_postings_trigram ON trigram_postings(trigram);
+		CREATE TABLE IF NOT EXISTS trigram_postings_ci (
+			trigram TEXT NOT NULL,
+			hash    TEXT NOT NULL,
+			PRIMARY KEY (trigram, hash)
+		);
+		CREATE INDEX IF NOT EXISTS idx_trigram_postings_ci_trigram ON trigram_postings_ci(trigram);
+	`); err != nil {
+		return nil, fmt.Errorf("trigram: create schema: %w", err)
+	}
+
+	return &trigramIndexer{collection: collection, db: db}, nil
+}
+
+// trigrams returns the set of distinct overlapping 3-byte substrings of
+// s. Bodies shorter than 3 bytes have none, same as Search's fallback
+// for short queries.
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for i := 0; i+3 <= len(s); i++ {
+		set[s[i:i+3]] = true
+	}
+	return set
+}
+
+func (idx *trigramIndexer) Index(ctx context.Context, docs []Document) error {
+	for _, doc := range docs {
+		if err := idx.indexOne(ctx, doc); err != nil {
+			return fmt.Errorf("index %s: %w", doc.Path, err)
+		}
+	}
+	return nil
+}
+
+func (idx *trigramIndexer) indexOne(ctx context.Context, doc Document) error {
+	if err := idx.deleteHash(ctx, doc.Hash); err != nil {
+		return err
+	}
+
+	if _, err := idx.db.ExecContext(ctx, `
+		INSERT INTO trigram_documents (hash, collection, path, title, body, active)
+		VALUES (?, ?, ?, ?, ?, 1)
+	`, doc.Hash, doc.Collection, doc.Path, doc.Title, doc.Body); err != nil {
+		return err
+	}
+
+	for trigram := range trigrams(doc.Body) {
+		if _, err := idx.db.ExecContext(ctx, `
+			INSERT OR IGNORE INTO trigram_postings (trigram, hash) VALUES (?, ?)
+		`, trigram, doc.Hash); err != nil {
+			return err
+		}
+	}
+	for trigram := range trigrams(strings.ToLower(doc.Body)) {
+		if _, err := idx.db.ExecContext(ctx, `
+			INSERT OR IGNORE INTO trigram_postings_ci (trigram, hash) VALUES (?, ?)
+		`, trigram, doc.Hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Search answers query.Text as a literal substring, or a regular
+// expression when query.Regex is set. A literal query of at least
+// minTrigramQueryLen bytes is narrowed to candidates via posting-list
+// intersection before verification; everything else (short literals,
+// and regexes -- extracting a regex's required literal run is its own
+// project, not attempted here) scans every active document's body
+// directly.
+func (idx *trigramIndexer) Search(ctx context.Context, query Query) ([]SearchResult, error) {
+	var matches func(body string) bool
+	if query.Regex {
+		re, err := regexp.Compile(query.Text)
+		if err != nil {
+			return nil, fmt.Errorf("trigram: invalid regex %q: %w", query.Text, err)
+		}
+		matches = re.MatchString
+	} else {
+		matches = func(body string) bool { return strings.Contains(body, query.Text) }
+	}
+
+	var candidates []string
+	filtered := !query.Regex && len(query.Text) >= minTrigramQueryLen
+	if filtered {
+		hashes, err := idx.candidatesFromPostings(ctx, query.Text)
+		if err != nil {
+			return nil, err
+		}
+		candidates = hashes
+	}
+
+	rows, err := idx.scanCandidates(ctx, filtered, candidates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var hash, path, title, body string
+		if err := rows.Scan(&hash, &path, &title, &body); err != nil {
+			return nil, err
+		}
+		if !matches(body) {
+			continue
+		}
+		results = append(results, SearchResult{
+			ID:         hash,
+			Collection: idx.collection,
+			Path:       path,
+			Title:      title,
+			Score:      1,
+		})
+		if query.Limit > 0 && len(results) >= query.Limit {
+			break
+		}
+	}
+	return results, rows.Err()
+}
+
+// candidatesFromPostings intersects the posting lists of every trigram
+// in text -- the case-preserving table if text has any uppercase
+// letter, otherwise the case-folded one -- returning the hashes common
+// to all of them.
+func (idx *trigramIndexer) candidatesFromPostings(ctx context.Context, text string) ([]string, error) {
+	table := "trigram_postings_ci"
+	if text != strings.ToLower(text) {
+		table = "trigram_postings"
+	} else {
+		text = strings.ToLower(text)
+	}
+
+	set := trigrams(text)
+	terms := make([]string, 0, len(set))
+	for t := range set {
+		terms = append(terms, t)
+	}
+	sort.Strings(terms)
+
+	placeholders := make([]string, len(terms))
+	args := make([]any, len(terms)+1)
+	for i, t := range terms {
+		placeholders[i] = "?"
+		args[i] = t
+	}
+	args[len(terms)] = len(terms)
+
+	rows, err := idx.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT hash FROM %s
+		WHERE trigram IN (%s)
+		GROUP BY hash
+		HAVING COUNT(DISTINCT trigram) = ?
+	`, table, strings.Join(placeholders, ",")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("trigram: intersect postings: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, rows.Err()
+}
+
+// scanCandidates opens a result set of (hash, path, title, body) for
+// active documents. When filtered is true, hashes is the full candidate
+// set from posting-list intersection (possibly empty, meaning no
+// document contains every query trigram). When filtered is false, no
+// trigram prefiltering applies and every active document is scanned.
+func (idx *trigramIndexer) scanCandidates(ctx context.Context, filtered bool, hashes []string) (*sql.Rows, error) {
+	if filtered && len(hashes) == 0 {
+		return idx.db.QueryContext(ctx, `
+			SELECT hash, path, title, body FROM trigram_documents
+			WHERE collection = ? AND active = 1 AND 1 = 0
+		`, idx.collection)
+	}
+	if !filtered {
+		return idx.db.QueryContext(ctx, `
+			SELECT hash, path, title, body FROM trigram_documents
+			WHERE collection = ? AND active = 1
+		`, idx.collection)
+	}
+
+	placeholders := make([]string, len(hashes))
+	args := make([]any, 0, len(hashes)+1)
+	args = append(args, idx.collection)
+	for i, h := range hashes {
+		placeholders[i] = "?"
+		args = append(args, h)
+	}
+
+	return idx.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT hash, path, title, body FROM trigram_documents
+		WHERE collection = ? AND active = 1 AND hash IN (%s)
+	`, strings.Join(placeholders, ",")), args...)
+}
+
+func (idx *trigramIndexer) Delete(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		if err := idx.deleteHash(ctx, id); err != nil {
+			return fmt.Errorf("delete %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (idx *trigramIndexer) deleteHash(ctx context.Context, hash string) error {
+	if _, err := idx.db.ExecContext(ctx, `DELETE FROM trigram_documents WHERE hash = ?`, hash); err != nil {
+		return err
+	}
+	if _, err := idx.db.ExecContext(ctx, `DELETE FROM trigram_postings WHERE hash = ?`, hash); err != nil {
+		return err
+	}
+	if _, err := idx.db.ExecContext(ctx, `DELETE FROM trigram_postings_ci WHERE hash = ?`, hash); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (idx *trigramIndexer) Close() error {
+	// The *sql.DB is owned by store.Store's connection pool, not this
+	// indexer, so there's nothing to close here.
+	return nil
+}
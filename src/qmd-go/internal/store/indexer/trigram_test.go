@@ -0,0 +1,29 @@
+package indexer
+
+import "testing"
+
+func TestTrigrams_OverlappingSubstrings(t *testing.T) {
+	got := trigrams("foo_bar")
+	want := []string{"foo", "oo_", "o_b", "_ba", "bar"}
+	for _, w := range want {
+		if !got[w] {
+			t.Errorf("trigrams(%q) missing %q, got %v", "foo_bar", w, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("trigrams(%q) = %d distinct trigrams, want %d", "foo_bar", len(got), len(want))
+	}
+}
+
+func TestTrigrams_ShorterThanThreeIsEmpty(t *testing.T) {
+	if got := trigrams("ab"); len(got) != 0 {
+		t.Errorf("trigrams(%q) = %v, want empty", "ab", got)
+	}
+}
+
+func TestTrigrams_DedupesRepeatedRuns(t *testing.T) {
+	got := trigrams("aaaa")
+	if len(got) != 1 || !got["aaa"] {
+		t.Errorf("trigrams(%q) = %v, want only {\"aaa\"}", "aaaa", got)
+	}
+}
@@ -0,0 +1,316 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/qmd/qmd-go/internal/anel"
+)
+
+// VectorRecord is one payload+vector pair moved by the Migrator. It
+// mirrors the fields a search result is rendered from, so migrating
+// backends doesn't lose anything a search would have shown.
+type VectorRecord struct {
+	Hash       string
+	Path       string
+	Title      string
+	Body       string
+	Collection string
+	Vector     []float32
+	// Model is the embedding model that produced Vector, e.g. the
+	// content_vectors.model column SQLiteVectorBackend reads/writes.
+	// Empty when the source backend doesn't track it (Qdrant, Milvus
+	// today), in which case Migrate can't tell whether Vector needs
+	// re-embedding for a model change and leaves it untouched.
+	Model string
+}
+
+// MigrationSource streams vector records in batchSize-sized pages.
+// StreamPoints returns nextCursor == "" once the source is exhausted.
+type MigrationSource interface {
+	Dimensions() uint64
+	DistanceMetric() string
+	StreamPoints(ctx context.Context, cursor string, batchSize int) (points []VectorRecord, nextCursor string, err error)
+}
+
+// MigrationDestination writes a batch of vector records, preserving
+// their payload fields.
+type MigrationDestination interface {
+	Dimensions() uint64
+	DistanceMetric() string
+	WritePoints(ctx context.Context, points []VectorRecord) error
+}
+
+// MigrationCounter is an optional interface a MigrationSource can
+// implement to report its total point count up front, so Migrate can
+// estimate an ETA. Sources that don't implement it (or for whom
+// counting is expensive, like a remote Qdrant/Milvus collection) just
+// get MigrateProgress.ETA left at zero.
+type MigrationCounter interface {
+	Count(ctx context.Context) (int, error)
+}
+
+// MigrateOptions controls a Migrate run.
+type MigrateOptions struct {
+	// BatchSize is how many points StreamPoints/WritePoints move per
+	// round trip; <=0 defaults to 100.
+	BatchSize int
+	// DryRun counts points without writing them to dst.
+	DryRun bool
+	// Job names the checkpoint file under CachePath/migrate/<job>.json.
+	// Empty disables checkpointing.
+	Job string
+	// CachePath is the base cache directory; empty defaults to
+	// ~/.cache/qmd, matching config.DefaultCachePath.
+	CachePath string
+	// TargetModel is the embedding model points should carry once
+	// migrated, e.g. config.VectorConfig.Model. A point whose
+	// VectorRecord.Model already matches (or is empty, meaning the
+	// source doesn't track it) is copied as-is; Embed is only called
+	// for an actual mismatch, so moving between two same-model backends
+	// never touches the embedding service. Leave empty to skip
+	// re-embedding entirely.
+	TargetModel string
+	// Embed re-embeds a point's Body when TargetModel is set and the
+	// point's Model differs from it. Required to actually change
+	// migrated vectors to TargetModel's embedding space; nil disables
+	// re-embedding (mismatched points still move, but keep their
+	// stale vector).
+	Embed func(ctx context.Context, text string) ([]float32, error)
+	// OnProgress, if set, is called after every batch with the run's
+	// progress so far, for callers surfacing it (e.g. the migrate CLI
+	// command's anel.NDJSONRecord "metadata" events).
+	OnProgress func(MigrateProgress)
+}
+
+// MigrateStats summarizes a completed (or dry-run) Migrate call.
+type MigrateStats struct {
+	Moved      int
+	Reembedded int
+	DryRun     bool
+}
+
+// MigrateProgress reports a Migrate call's progress after one batch.
+type MigrateProgress struct {
+	// Processed is how many points have been moved (or counted, during
+	// a dry run) so far, across this and any prior checkpointed runs.
+	Processed int
+	// Failed is always 0 today: a batch failure aborts the whole run
+	// (see Migrate's doc comment) rather than being counted and
+	// skipped, since MigrationDestination.WritePoints has no way to
+	// report which points in a batch succeeded. It's here so a future
+	// per-point-tolerant write path doesn't need an incompatible
+	// struct change.
+	Failed int
+	// ETA estimates how much longer the run will take, extrapolated
+	// from the average time per point so far against the points
+	// remaining. Zero when src doesn't implement MigrationCounter, so
+	// the total -- and therefore how many remain -- isn't known.
+	ETA time.Duration
+}
+
+// migrateCheckpoint is the on-disk shape of a resumable migration's
+// progress.
+type migrateCheckpoint struct {
+	Cursor string `json:"cursor"`
+	Moved  int    `json:"moved"`
+}
+
+// Migrator moves vector data between backends (SQLite <-> Qdrant, Qdrant
+// <-> Qdrant across hosts or collections, and eventually other pkg/store
+// backends), re-embedding only the points whose Model disagrees with
+// MigrateOptions.TargetModel. It's independent of any one backend's
+// concrete type, so it's unit-testable against fakes implementing
+// MigrationSource/MigrationDestination.
+type Migrator struct{}
+
+// NewMigrator creates a Migrator.
+func NewMigrator() *Migrator {
+	return &Migrator{}
+}
+
+// Migrate streams every point from src to dst in opts.BatchSize-sized
+// batches, refusing to start if their vector dimensions or distance
+// metrics disagree. With opts.DryRun set, it counts points without
+// calling dst.WritePoints. If opts.Job is set, progress is checkpointed
+// after every batch so a run interrupted partway through can resume from
+// where it left off instead of starting over; a batch failure returns
+// an ErrorCodeStorageError whose RecoveryHint names that resume path.
+func (m *Migrator) Migrate(ctx context.Context, src MigrationSource, dst MigrationDestination, opts MigrateOptions) (MigrateStats, error) {
+	if src.Dimensions() != dst.Dimensions() {
+		return MigrateStats{}, anel.Newf(anel.ErrorCodeInvalidInput, "migrate_dimension_mismatch",
+			"source and destination vector dimensions disagree (%d vs %d)",
+			src.Dimensions(), dst.Dimensions()).
+			WithDetail("source_dimensions", src.Dimensions()).
+			WithDetail("destination_dimensions", dst.Dimensions()).
+			WithHint("recreate the destination collection with the source's vector size")
+	}
+	if src.DistanceMetric() != dst.DistanceMetric() {
+		return MigrateStats{}, anel.Newf(anel.ErrorCodeInvalidInput, "migrate_distance_metric_mismatch",
+			"source and destination distance metrics disagree (%s vs %s)",
+			src.DistanceMetric(), dst.DistanceMetric()).
+			WithDetail("source_metric", src.DistanceMetric()).
+			WithDetail("destination_metric", dst.DistanceMetric()).
+			WithHint("recreate the destination collection with the source's distance metric")
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	checkpoint, err := loadMigrateCheckpoint(opts)
+	if err != nil {
+		return MigrateStats{}, fmt.Errorf("load checkpoint: %w", err)
+	}
+	cursor := checkpoint.Cursor
+	moved := checkpoint.Moved
+	reembedded := 0
+
+	total := 0
+	if counter, ok := src.(MigrationCounter); ok {
+		if total, err = counter.Count(ctx); err != nil {
+			return MigrateStats{}, fmt.Errorf("count source points: %w", err)
+		}
+	}
+	start := time.Now()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return MigrateStats{Moved: moved, Reembedded: reembedded, DryRun: opts.DryRun}, err
+		}
+
+		points, nextCursor, err := src.StreamPoints(ctx, cursor, batchSize)
+		if err != nil {
+			return MigrateStats{Moved: moved, Reembedded: reembedded, DryRun: opts.DryRun},
+				m.storageError("migrate_stream_failed", opts, fmt.Errorf("stream points at cursor %q: %w", cursor, err))
+		}
+		if len(points) == 0 {
+			break
+		}
+
+		if !opts.DryRun {
+			if opts.TargetModel != "" && opts.Embed != nil {
+				for i := range points {
+					if points[i].Model == "" || points[i].Model == opts.TargetModel {
+						continue
+					}
+					vector, err := opts.Embed(ctx, points[i].Body)
+					if err != nil {
+						return MigrateStats{Moved: moved, Reembedded: reembedded, DryRun: opts.DryRun},
+							m.storageError("migrate_reembed_failed", opts, fmt.Errorf("re-embed %s: %w", points[i].Hash, err))
+					}
+					points[i].Vector = vector
+					points[i].Model = opts.TargetModel
+					reembedded++
+				}
+			}
+
+			if err := dst.WritePoints(ctx, points); err != nil {
+				return MigrateStats{Moved: moved, Reembedded: reembedded, DryRun: opts.DryRun},
+					m.storageError("migrate_write_failed", opts, fmt.Errorf("write points at cursor %q: %w", cursor, err))
+			}
+		}
+
+		moved += len(points)
+		cursor = nextCursor
+
+		if err := saveMigrateCheckpoint(opts, migrateCheckpoint{Cursor: cursor, Moved: moved}); err != nil {
+			return MigrateStats{Moved: moved, Reembedded: reembedded, DryRun: opts.DryRun}, fmt.Errorf("save checkpoint: %w", err)
+		}
+
+		if opts.OnProgress != nil {
+			opts.OnProgress(MigrateProgress{Processed: moved, ETA: estimateETA(start, moved, total)})
+		}
+
+		if nextCursor == "" {
+			break
+		}
+	}
+
+	return MigrateStats{Moved: moved, Reembedded: reembedded, DryRun: opts.DryRun}, nil
+}
+
+// storageError wraps cause as an ErrorCodeStorageError with a
+// RecoveryHint describing how to resume: naming opts.Job if the caller
+// already set one (checkpointing is already on, just re-run the same
+// command), or suggesting --job if not (so the next attempt won't have
+// to start over).
+func (m *Migrator) storageError(minor string, opts MigrateOptions, cause error) error {
+	err := anel.Wrap(anel.ErrorCodeStorageError, minor, cause)
+	if opts.Job != "" {
+		return err.WithHint(fmt.Sprintf("re-run the same migration with --job %s to resume from the last completed batch", opts.Job))
+	}
+	return err.WithHint("re-run with --job <name> so an interrupted migration can resume from the last completed batch instead of starting over")
+}
+
+// estimateETA extrapolates the remaining duration of a Migrate run from
+// its average per-point rate so far. Returns 0 when total is unknown
+// (src has no MigrationCounter) or no points have moved yet.
+func estimateETA(start time.Time, moved, total int) time.Duration {
+	if total <= 0 || moved <= 0 || moved >= total {
+		return 0
+	}
+	perPoint := time.Since(start) / time.Duration(moved)
+	return perPoint * time.Duration(total-moved)
+}
+
+func migrateCheckpointPath(opts MigrateOptions) (string, error) {
+	if opts.Job == "" {
+		return "", nil
+	}
+
+	cachePath := opts.CachePath
+	if cachePath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		cachePath = filepath.Join(home, ".cache", "qmd")
+	}
+
+	return filepath.Join(cachePath, "migrate", opts.Job+".json"), nil
+}
+
+func loadMigrateCheckpoint(opts MigrateOptions) (migrateCheckpoint, error) {
+	path, err := migrateCheckpointPath(opts)
+	if err != nil || path == "" {
+		return migrateCheckpoint{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return migrateCheckpoint{}, nil
+		}
+		return migrateCheckpoint{}, err
+	}
+
+	var cp migrateCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return migrateCheckpoint{}, err
+	}
+	return cp, nil
+}
+
+func saveMigrateCheckpoint(opts MigrateOptions, cp migrateCheckpoint) error {
+	path, err := migrateCheckpointPath(opts)
+	if err != nil || path == "" {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
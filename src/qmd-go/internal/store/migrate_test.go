@@ -0,0 +1,233 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/qmd/qmd-go/internal/anel"
+)
+
+// fakeMigrationEndpoint is an in-memory MigrationSource/MigrationDestination
+// used to unit-test Migrator independent of any real backend.
+type fakeMigrationEndpoint struct {
+	dimensions uint64
+	metric     string
+	records    []VectorRecord
+	written    []VectorRecord
+	streamed   []int // batch sizes StreamPoints was called with, for resume assertions
+}
+
+func (f *fakeMigrationEndpoint) Dimensions() uint64    { return f.dimensions }
+func (f *fakeMigrationEndpoint) DistanceMetric() string { return f.metric }
+
+func (f *fakeMigrationEndpoint) StreamPoints(ctx context.Context, cursor string, batchSize int) ([]VectorRecord, string, error) {
+	f.streamed = append(f.streamed, batchSize)
+
+	start := 0
+	if cursor != "" {
+		for i, r := range f.records {
+			if r.Hash == cursor {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	end := start + batchSize
+	if end > len(f.records) {
+		end = len(f.records)
+	}
+	if start >= end {
+		return nil, "", nil
+	}
+
+	batch := f.records[start:end]
+	nextCursor := ""
+	if end < len(f.records) {
+		nextCursor = batch[len(batch)-1].Hash
+	}
+	return batch, nextCursor, nil
+}
+
+func (f *fakeMigrationEndpoint) WritePoints(ctx context.Context, points []VectorRecord) error {
+	f.written = append(f.written, points...)
+	return nil
+}
+
+func testRecords(n int) []VectorRecord {
+	records := make([]VectorRecord, n)
+	for i := range records {
+		records[i] = VectorRecord{
+			Hash:   string(rune('a' + i)),
+			Vector: []float32{1, 2, 3},
+		}
+	}
+	return records
+}
+
+func TestMigrator_MovesAllPointsInBatches(t *testing.T) {
+	src := &fakeMigrationEndpoint{dimensions: 3, metric: "cosine", records: testRecords(5)}
+	dst := &fakeMigrationEndpoint{dimensions: 3, metric: "cosine"}
+
+	stats, err := NewMigrator().Migrate(context.Background(), src, dst, MigrateOptions{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if stats.Moved != 5 {
+		t.Errorf("Moved = %d, want 5", stats.Moved)
+	}
+	if len(dst.written) != 5 {
+		t.Errorf("destination received %d points, want 5", len(dst.written))
+	}
+}
+
+func TestMigrator_DryRunCountsWithoutWriting(t *testing.T) {
+	src := &fakeMigrationEndpoint{dimensions: 3, metric: "cosine", records: testRecords(4)}
+	dst := &fakeMigrationEndpoint{dimensions: 3, metric: "cosine"}
+
+	stats, err := NewMigrator().Migrate(context.Background(), src, dst, MigrateOptions{BatchSize: 2, DryRun: true})
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if stats.Moved != 4 {
+		t.Errorf("Moved = %d, want 4", stats.Moved)
+	}
+	if len(dst.written) != 0 {
+		t.Errorf("dry run should not write, got %d points written", len(dst.written))
+	}
+}
+
+func TestMigrator_RejectsDimensionMismatch(t *testing.T) {
+	src := &fakeMigrationEndpoint{dimensions: 384, metric: "cosine"}
+	dst := &fakeMigrationEndpoint{dimensions: 768, metric: "cosine"}
+
+	if _, err := NewMigrator().Migrate(context.Background(), src, dst, MigrateOptions{}); err == nil {
+		t.Fatal("expected an error for mismatched dimensions")
+	}
+}
+
+func TestMigrator_RejectsDistanceMetricMismatch(t *testing.T) {
+	src := &fakeMigrationEndpoint{dimensions: 384, metric: "cosine"}
+	dst := &fakeMigrationEndpoint{dimensions: 384, metric: "euclidean"}
+
+	if _, err := NewMigrator().Migrate(context.Background(), src, dst, MigrateOptions{}); err == nil {
+		t.Fatal("expected an error for mismatched distance metrics")
+	}
+}
+
+func TestMigrator_ReembedsOnlyMismatchedModels(t *testing.T) {
+	src := &fakeMigrationEndpoint{dimensions: 3, metric: "cosine", records: []VectorRecord{
+		{Hash: "a", Vector: []float32{1, 2, 3}, Model: "old-model"},
+		{Hash: "b", Vector: []float32{4, 5, 6}, Model: "new-model"},
+		{Hash: "c", Vector: []float32{7, 8, 9}}, // no recorded model: left untouched
+	}}
+	dst := &fakeMigrationEndpoint{dimensions: 3, metric: "cosine"}
+
+	var reembedded []string
+	stats, err := NewMigrator().Migrate(context.Background(), src, dst, MigrateOptions{
+		BatchSize:   10,
+		TargetModel: "new-model",
+		Embed: func(ctx context.Context, text string) ([]float32, error) {
+			reembedded = append(reembedded, text)
+			return []float32{0, 0, 0}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if stats.Reembedded != 1 {
+		t.Errorf("Reembedded = %d, want 1", stats.Reembedded)
+	}
+	if len(reembedded) != 1 {
+		t.Fatalf("Embed called %d times, want 1", len(reembedded))
+	}
+
+	for _, p := range dst.written {
+		if p.Hash == "a" && p.Model != "new-model" {
+			t.Errorf("point %q: Model = %q, want re-embedded to %q", p.Hash, p.Model, "new-model")
+		}
+		if p.Hash == "b" && (p.Vector[0] != 4 || p.Model != "new-model") {
+			t.Errorf("point %q: expected to pass through unchanged, got %+v", p.Hash, p)
+		}
+		if p.Hash == "c" && p.Model != "" {
+			t.Errorf("point %q: expected Model to stay empty without re-embedding info, got %q", p.Hash, p.Model)
+		}
+	}
+}
+
+func TestMigrator_ReportsProgress(t *testing.T) {
+	src := &fakeMigrationEndpoint{dimensions: 3, metric: "cosine", records: testRecords(5)}
+	dst := &fakeMigrationEndpoint{dimensions: 3, metric: "cosine"}
+
+	var progress []MigrateProgress
+	_, err := NewMigrator().Migrate(context.Background(), src, dst, MigrateOptions{
+		BatchSize:  2,
+		OnProgress: func(p MigrateProgress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(progress) != 3 {
+		t.Fatalf("OnProgress called %d times, want 3 (batches of 2,2,1)", len(progress))
+	}
+	if progress[len(progress)-1].Processed != 5 {
+		t.Errorf("final Processed = %d, want 5", progress[len(progress)-1].Processed)
+	}
+}
+
+func TestMigrator_StorageErrorCarriesResumeHint(t *testing.T) {
+	src := &fakeMigrationEndpoint{dimensions: 3, metric: "cosine", records: testRecords(2)}
+	dst := &failingDestination{}
+
+	_, err := NewMigrator().Migrate(context.Background(), src, dst, MigrateOptions{BatchSize: 10, Job: "resume-me"})
+	if err == nil {
+		t.Fatal("expected a write failure")
+	}
+	anelErr, ok := anel.As(err)
+	if !ok {
+		t.Fatalf("expected an *anel.Error, got %T: %v", err, err)
+	}
+	if anelErr.Code != anel.ErrorCodeStorageError {
+		t.Errorf("Code = %q, want %q", anelErr.Code, anel.ErrorCodeStorageError)
+	}
+	if anelErr.Hint == "" || !strings.Contains(anelErr.Hint, "resume-me") {
+		t.Errorf("expected RecoveryHint to name the job, got %q", anelErr.Hint)
+	}
+}
+
+// failingDestination always fails WritePoints, to exercise Migrate's
+// storage-error path.
+type failingDestination struct{}
+
+func (failingDestination) Dimensions() uint64     { return 3 }
+func (failingDestination) DistanceMetric() string { return "cosine" }
+func (failingDestination) WritePoints(ctx context.Context, points []VectorRecord) error {
+	return fmt.Errorf("boom")
+}
+
+func TestMigrator_ResumesFromCheckpoint(t *testing.T) {
+	cachePath := t.TempDir()
+	opts := MigrateOptions{BatchSize: 2, Job: "resume-test", CachePath: cachePath}
+
+	src := &fakeMigrationEndpoint{dimensions: 3, metric: "cosine", records: testRecords(5)}
+	dst := &fakeMigrationEndpoint{dimensions: 3, metric: "cosine"}
+
+	// Simulate a checkpoint left behind by a run that made it through the
+	// first batch before being interrupted.
+	if err := saveMigrateCheckpoint(opts, migrateCheckpoint{Cursor: "b", Moved: 2}); err != nil {
+		t.Fatalf("saveMigrateCheckpoint failed: %v", err)
+	}
+
+	stats, err := NewMigrator().Migrate(context.Background(), src, dst, opts)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if stats.Moved != 5 {
+		t.Errorf("Moved = %d, want 5 (2 resumed + 3 remaining)", stats.Moved)
+	}
+	if len(dst.written) != 3 {
+		t.Errorf("destination received %d points, want 3 (only the unmigrated remainder)", len(dst.written))
+	}
+}
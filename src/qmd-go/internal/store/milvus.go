@@ -0,0 +1,426 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+
+	"github.com/qmd/qmd-go/internal/anel"
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+// milvusVectorField and milvusMetadataField name the fields of every
+// collection this backend creates: a FloatVector sized to the
+// configured VectorSize, and a JSON blob carrying the payload fields
+// Qdrant stores as a map (collection/path/title/body).
+const (
+	milvusPrimaryField  = "hash"
+	milvusVectorField   = "vector"
+	milvusMetadataField = "metadata"
+)
+
+// MilvusBackend represents a Milvus vector database backend. Unlike
+// QdrantBackend it has a single, fixed dimensionality per collection:
+// Milvus's named-vector-per-embedder story isn't wired up here yet.
+type MilvusBackend struct {
+	client     client.Client
+	collection string
+	cfg        config.MilvusConfig
+	dimension  uint64
+}
+
+// NewMilvusBackend creates a new Milvus backend, connecting to
+// cfg.Address and ensuring cfg.Collection exists with a primary key
+// ("hash"), a FloatVector field sized to dimension, and a JSON metadata
+// field.
+func NewMilvusBackend(cfg config.MilvusConfig, dimension uint64) (*MilvusBackend, error) {
+	c, err := client.NewClient(context.Background(), client.Config{
+		Address:  cfg.Address,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DBName:   cfg.Database,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Milvus client: %w", err)
+	}
+
+	backend := &MilvusBackend{
+		client:     c,
+		collection: cfg.Collection,
+		cfg:        cfg,
+		dimension:  dimension,
+	}
+
+	if err := backend.ensureCollection(); err != nil {
+		return nil, err
+	}
+
+	return backend, nil
+}
+
+// ensureCollection creates the collection, its vector index, and loads
+// it into memory if it doesn't already exist.
+func (b *MilvusBackend) ensureCollection() error {
+	ctx := context.Background()
+
+	exists, err := b.client.HasCollection(ctx, b.collection)
+	if err != nil {
+		return fmt.Errorf("failed to check collection: %w", err)
+	}
+
+	if !exists {
+		schema := entity.NewSchema().
+			WithName(b.collection).
+			WithField(entity.NewField().WithName(milvusPrimaryField).WithDataType(entity.FieldTypeVarChar).
+				WithIsPrimaryKey(true).WithMaxLength(64)).
+			WithField(entity.NewField().WithName(milvusVectorField).WithDataType(entity.FieldTypeFloatVector).
+				WithDim(int64(b.dimension))).
+			WithField(entity.NewField().WithName(milvusMetadataField).WithDataType(entity.FieldTypeJSON))
+
+		if err := b.client.CreateCollection(ctx, schema, entity.DefaultShardNumber); err != nil {
+			return fmt.Errorf("failed to create collection: %w", err)
+		}
+
+		idx, err := b.index()
+		if err != nil {
+			return fmt.Errorf("failed to build index params: %w", err)
+		}
+		if err := b.client.CreateIndex(ctx, b.collection, milvusVectorField, idx, false); err != nil {
+			return fmt.Errorf("failed to create index: %w", err)
+		}
+	}
+
+	if err := b.client.LoadCollection(ctx, b.collection, false); err != nil {
+		return fmt.Errorf("failed to load collection: %w", err)
+	}
+
+	return nil
+}
+
+// index builds the ANN index Milvus attaches to milvusVectorField,
+// translating cfg.IndexType/MetricType/IndexParams into the matching
+// entity.Index constructor. Defaults to HNSW/cosine when unset.
+func (b *MilvusBackend) index() (entity.Index, error) {
+	metric := entity.MetricType(b.cfg.MetricType)
+	if metric == "" {
+		metric = entity.COSINE
+	}
+
+	switch b.cfg.IndexType {
+	case "", "HNSW":
+		m := intParam(b.cfg.IndexParams, "M", 16)
+		efConstruction := intParam(b.cfg.IndexParams, "efConstruction", 200)
+		return entity.NewIndexHNSW(metric, m, efConstruction)
+	case "IVF_FLAT":
+		nlist := intParam(b.cfg.IndexParams, "nlist", 128)
+		return entity.NewIndexIvfFlat(metric, nlist)
+	default:
+		return nil, fmt.Errorf("unsupported milvus index_type %q", b.cfg.IndexType)
+	}
+}
+
+// intParam parses params[key] as an int, falling back to def when the
+// key is absent or unparseable.
+func intParam(params map[string]string, key string, def int) int {
+	val, ok := params[key]
+	if !ok {
+		return def
+	}
+	var parsed int
+	if _, err := fmt.Sscanf(val, "%d", &parsed); err != nil {
+		return def
+	}
+	return parsed
+}
+
+// VectorSearchMilvus performs vector search using Milvus. ctx bounds
+// both the embedding call and the Milvus RPC.
+func (s *Store) VectorSearchMilvus(ctx context.Context, query string, options SearchOptions) ([]SearchResult, error) {
+	if s.milvus == nil {
+		return []SearchResult{}, anel.Newf(anel.ErrorCodeBackendUnavailable, "milvus_not_configured",
+			"Milvus backend is not available").
+			WithHint("set vector.backend to milvus and configure its endpoint")
+	}
+
+	embeddingResult, err := s.llmRouter.Embed(ctx, []string{query})
+	if err != nil {
+		return []SearchResult{}, anel.Wrap(anel.ErrorCodeEmbeddingFailed, "query_embed_failed", err).
+			WithDetail("query", query)
+	}
+
+	queryVector := embeddingResult.Embeddings[0]
+
+	results, err := s.milvus.Search(ctx, queryVector, options.Limit)
+	if err != nil {
+		return []SearchResult{}, err
+	}
+
+	searchResults := make([]SearchResult, len(results))
+	for i, r := range results {
+		searchResults[i] = SearchResult{
+			Path:       r.Path,
+			Collection: r.Collection,
+			Score:      r.Score,
+			Title:      r.Title,
+			Hash:       r.Hash,
+			DocID:      r.Hash,
+		}
+	}
+
+	ApplySort(searchResults, options.SortSpec)
+	return searchResults, nil
+}
+
+// milvusSearchHit is one row of a Milvus Search response, already
+// unpacked from its metadata JSON.
+type milvusSearchHit struct {
+	Hash       string
+	Path       string
+	Title      string
+	Collection string
+	Score      float32
+}
+
+// milvusMetadata is the JSON payload stored in milvusMetadataField,
+// mirroring the fields QdrantBackend keeps as a flat payload map.
+type milvusMetadata struct {
+	Path       string `json:"path"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	Collection string `json:"collection"`
+}
+
+// Search performs vector search against Milvus, decoding each hit's
+// metadata JSON column back into its path/title/collection fields.
+func (b *MilvusBackend) Search(ctx context.Context, vector []float32, limit int) ([]milvusSearchHit, error) {
+	sp, err := b.searchParam()
+	if err != nil {
+		return nil, fmt.Errorf("build search params: %w", err)
+	}
+
+	results, err := b.client.Search(ctx, b.collection, nil, "", []string{milvusMetadataField},
+		[]entity.Vector{entity.FloatVector(vector)}, milvusVectorField,
+		entity.MetricType(b.distanceMetricOrDefault()), limit, sp)
+	if err != nil {
+		return nil, fmt.Errorf("search collection %s: %w", b.collection, err)
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	return decodeMilvusResult(results[0])
+}
+
+// decodeMilvusResult unpacks one Search response's primary-key and
+// metadata columns into milvusSearchHit values.
+func decodeMilvusResult(result client.SearchResult) ([]milvusSearchHit, error) {
+	idCol, ok := result.IDs.(*entity.ColumnVarChar)
+	if !ok {
+		return nil, fmt.Errorf("unexpected primary key column type %T", result.IDs)
+	}
+
+	metaCol, err := milvusMetadataColumn(result.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]milvusSearchHit, result.ResultCount)
+	for i := 0; i < result.ResultCount; i++ {
+		var meta milvusMetadata
+		if err := json.Unmarshal(metaCol.Data()[i], &meta); err != nil {
+			return nil, fmt.Errorf("unmarshal metadata: %w", err)
+		}
+		hits[i] = milvusSearchHit{
+			Hash:       idCol.Data()[i],
+			Path:       meta.Path,
+			Title:      meta.Title,
+			Collection: meta.Collection,
+			Score:      result.Scores[i],
+		}
+	}
+	return hits, nil
+}
+
+// milvusMetadataColumn finds the JSON metadata column among a result's
+// output field columns.
+func milvusMetadataColumn(fields []entity.Column) (*entity.ColumnJSONBytes, error) {
+	for _, f := range fields {
+		if c, ok := f.(*entity.ColumnJSONBytes); ok && c.Name() == milvusMetadataField {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("metadata column %q missing from result", milvusMetadataField)
+}
+
+// marshalMilvusMetadata encodes meta as the JSON blob stored in
+// milvusMetadataField.
+func marshalMilvusMetadata(meta milvusMetadata) ([]byte, error) {
+	return json.Marshal(meta)
+}
+
+// searchParam builds the per-query search tuning knobs matching
+// whichever index type ensureCollection created.
+func (b *MilvusBackend) searchParam() (entity.SearchParam, error) {
+	switch b.cfg.IndexType {
+	case "", "HNSW":
+		return entity.NewIndexHNSWSearchParam(intParam(b.cfg.IndexParams, "ef", 64))
+	case "IVF_FLAT":
+		return entity.NewIndexIvfFlatSearchParam(intParam(b.cfg.IndexParams, "nprobe", 10))
+	default:
+		return nil, fmt.Errorf("unsupported milvus index_type %q", b.cfg.IndexType)
+	}
+}
+
+// UpsertVectors inserts vectors into Milvus, keyed by each point's
+// content hash (the collection's primary key) and carrying its
+// payload fields as a JSON metadata column.
+func (b *MilvusBackend) UpsertVectors(ctx context.Context, points []VectorPoint) error {
+	hashes := make([]string, len(points))
+	vectors := make([][]float32, len(points))
+	metadata := make([][]byte, len(points))
+	for i, p := range points {
+		hashes[i] = p.Hash
+		vectors[i] = p.Vector
+
+		meta, err := marshalMilvusMetadata(milvusMetadata{
+			Path:       p.Path,
+			Title:      p.Title,
+			Body:       p.Body,
+			Collection: p.Collection,
+		})
+		if err != nil {
+			return fmt.Errorf("marshal metadata for %s: %w", p.Hash, err)
+		}
+		metadata[i] = meta
+	}
+
+	_, err := b.client.Insert(ctx, b.collection, "",
+		entity.NewColumnVarChar(milvusPrimaryField, hashes),
+		entity.NewColumnFloatVector(milvusVectorField, int(b.dimension), vectors),
+		entity.NewColumnJSONBytes(milvusMetadataField, metadata),
+	)
+	return err
+}
+
+// Dimensions returns the size of this backend's vector space, for
+// Migrator's dimension-agreement check.
+func (b *MilvusBackend) Dimensions() uint64 {
+	return b.dimension
+}
+
+// DistanceMetric returns the collection's configured distance metric,
+// defaulting to cosine when unset.
+func (b *MilvusBackend) DistanceMetric() string {
+	return b.distanceMetricOrDefault()
+}
+
+func (b *MilvusBackend) distanceMetricOrDefault() string {
+	if b.cfg.MetricType == "" {
+		return "COSINE"
+	}
+	return b.cfg.MetricType
+}
+
+// StreamPoints pages through the collection via Milvus's Query API,
+// ordered by hash so a cursor (the last hash seen) resumes cleanly.
+// cursor is the last hash returned, or "" to start from the beginning.
+func (b *MilvusBackend) StreamPoints(ctx context.Context, cursor string, batchSize int) ([]VectorRecord, string, error) {
+	expr := fmt.Sprintf("%s > \"%s\"", milvusPrimaryField, cursor)
+	if cursor == "" {
+		expr = ""
+	}
+
+	rows, err := b.client.Query(ctx, b.collection, nil, expr,
+		[]string{milvusPrimaryField, milvusVectorField, milvusMetadataField})
+	if err != nil {
+		return nil, "", fmt.Errorf("query collection %s: %w", b.collection, err)
+	}
+	if len(rows) == 0 {
+		return nil, "", nil
+	}
+
+	records, lastHash, err := decodeMilvusRecords(rows, batchSize)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(records) == 0 {
+		return nil, "", nil
+	}
+
+	nextCursor := ""
+	if len(records) == batchSize {
+		nextCursor = lastHash
+	}
+
+	return records, nextCursor, nil
+}
+
+// decodeMilvusRecords unpacks a Query response's hash/vector/metadata
+// columns into VectorRecord values, truncated to at most limit rows,
+// and reports the last hash seen for StreamPoints's cursor.
+func decodeMilvusRecords(columns []entity.Column, limit int) ([]VectorRecord, string, error) {
+	var hashCol *entity.ColumnVarChar
+	var vecCol *entity.ColumnFloatVector
+	var metaCol *entity.ColumnJSONBytes
+	for _, c := range columns {
+		switch typed := c.(type) {
+		case *entity.ColumnVarChar:
+			if typed.Name() == milvusPrimaryField {
+				hashCol = typed
+			}
+		case *entity.ColumnFloatVector:
+			vecCol = typed
+		case *entity.ColumnJSONBytes:
+			metaCol = typed
+		}
+	}
+	if hashCol == nil || vecCol == nil || metaCol == nil {
+		return nil, "", fmt.Errorf("query result missing expected columns")
+	}
+
+	n := hashCol.Len()
+	if limit > 0 && n > limit {
+		n = limit
+	}
+
+	records := make([]VectorRecord, n)
+	var lastHash string
+	for i := 0; i < n; i++ {
+		var meta milvusMetadata
+		if err := json.Unmarshal(metaCol.Data()[i], &meta); err != nil {
+			return nil, "", fmt.Errorf("unmarshal metadata: %w", err)
+		}
+		lastHash = hashCol.Data()[i]
+		records[i] = VectorRecord{
+			Hash:       lastHash,
+			Path:       meta.Path,
+			Title:      meta.Title,
+			Body:       meta.Body,
+			Collection: meta.Collection,
+			Vector:     vecCol.Data()[i],
+		}
+	}
+	return records, lastHash, nil
+}
+
+// WritePoints upserts a batch of migrated records into this
+// collection, keyed by their content hash so re-running a migration
+// after a partial failure overwrites in place instead of duplicating
+// rows.
+func (b *MilvusBackend) WritePoints(ctx context.Context, points []VectorRecord) error {
+	vPoints := make([]VectorPoint, len(points))
+	for i, p := range points {
+		vPoints[i] = VectorPoint{
+			Path:       p.Path,
+			Title:      p.Title,
+			Body:       p.Body,
+			Hash:       p.Hash,
+			Collection: p.Collection,
+			Vector:     p.Vector,
+		}
+	}
+	return b.UpsertVectors(ctx, vPoints)
+}
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/qmd/qmd-go/internal/config"
+	"github.com/qmd/qmd-go/internal/store/indexer"
+)
+
+func init() {
+	indexer.Default.RegisterVector(string(config.VectorBackendMilvus), openMilvusIndexer)
+}
+
+// milvusIndexerAdapter wraps MilvusBackend to satisfy indexer.VectorIndexer,
+// so milvus is reachable through indexer.Registry the same way as
+// qdrant, for uniform resolution (e.g. `qmd status --backends`) without
+// duplicating MilvusBackend's client logic. Store.New keeps its own
+// *MilvusBackend alongside this (see Store.milvus) because Migrator
+// needs the concrete type, not this interface.
+type milvusIndexerAdapter struct {
+	backend *MilvusBackend
+}
+
+// openMilvusIndexer ignores db: Milvus is a remote backend with its own
+// client, not a *sql.DB-backed one.
+func openMilvusIndexer(collection string, db *sql.DB, cfg *config.Config) (indexer.VectorIndexer, error) {
+	milvusCfg := cfg.Vector.Milvus
+	milvusCfg.Collection = collection
+	backend, err := NewMilvusBackend(milvusCfg, uint64(cfg.Vector.VectorSize))
+	if err != nil {
+		return nil, fmt.Errorf("milvus: %w", err)
+	}
+	return &milvusIndexerAdapter{backend: backend}, nil
+}
+
+func (a *milvusIndexerAdapter) Index(ctx context.Context, docs []indexer.VectorDocument) error {
+	points := make([]VectorPoint, len(docs))
+	for i, doc := range docs {
+		points[i] = VectorPoint{
+			Path:       doc.Path,
+			Title:      doc.Title,
+			Body:       doc.Body,
+			Hash:       doc.Hash,
+			Collection: doc.Collection,
+			Vector:     doc.Vector,
+			VectorName: doc.VectorName,
+		}
+	}
+	return a.backend.UpsertVectors(ctx, points)
+}
+
+func (a *milvusIndexerAdapter) Search(ctx context.Context, vector []float32, limit int) ([]indexer.SearchResult, error) {
+	results, err := a.backend.Search(ctx, vector, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	searchResults := make([]indexer.SearchResult, len(results))
+	for i, r := range results {
+		searchResults[i] = indexer.SearchResult{
+			ID:         r.Hash,
+			Collection: r.Collection,
+			Path:       r.Path,
+			Title:      r.Title,
+			Score:      r.Score,
+		}
+	}
+	return searchResults, nil
+}
+
+func (a *milvusIndexerAdapter) Delete(ctx context.Context, ids ...string) error {
+	return a.backend.client.Delete(ctx, a.backend.collection, "",
+		fmt.Sprintf("%s in [%s]", milvusPrimaryField, quotedMilvusIDs(ids)))
+}
+
+func (a *milvusIndexerAdapter) Close() error {
+	return a.backend.client.Close()
+}
+
+// Health checks Milvus connectivity by re-checking the configured
+// collection exists, satisfying indexer.HealthChecker.
+func (a *milvusIndexerAdapter) Health(ctx context.Context) error {
+	exists, err := a.backend.client.HasCollection(ctx, a.backend.collection)
+	if err != nil {
+		return fmt.Errorf("milvus: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("milvus: collection %q does not exist", a.backend.collection)
+	}
+	return nil
+}
+
+// quotedMilvusIDs renders ids as a comma-separated list of double-quoted
+// Milvus expression literals, for use in an `in [...]` filter expr.
+func quotedMilvusIDs(ids []string) string {
+	quoted := ""
+	for i, id := range ids {
+		if i > 0 {
+			quoted += ", "
+		}
+		quoted += fmt.Sprintf("%q", id)
+	}
+	return quoted
+}
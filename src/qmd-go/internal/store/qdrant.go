@@ -5,17 +5,31 @@ import (
 	"fmt"
 
 	"github.com/qdrant/go-client/qdrant"
+
+	"github.com/qmd/qmd-go/internal/anel"
+	"github.com/qmd/qmd-go/internal/config"
 )
 
 // QdrantBackend represents a Qdrant vector database backend
 type QdrantBackend struct {
-	client     *qdrant.Client
-	collection string
-	vectorSize uint64
+	client      *qdrant.Client
+	collection  string
+	vectorSizes map[string]uint64
 }
 
-// NewQdrantBackend creates a new Qdrant backend
+// NewQdrantBackend creates a new Qdrant backend with a single, unnamed
+// vector of vectorSize. Collections created this way accept plain
+// UpsertVectors points.
 func NewQdrantBackend(url, apiKey, collection string, vectorSize uint64) (*QdrantBackend, error) {
+	return NewQdrantBackendWithVectors(url, apiKey, collection, map[string]uint64{"": vectorSize})
+}
+
+// NewQdrantBackendWithVectors creates a new Qdrant backend whose
+// collection has one named vector per entry of vectorSizes, mirroring a
+// config.ModelsConfig.Embedders declaration where each named embedder
+// gets its own Qdrant vector. A single entry keyed by "" produces a
+// collection with one unnamed vector, same as NewQdrantBackend.
+func NewQdrantBackendWithVectors(url, apiKey, collection string, vectorSizes map[string]uint64) (*QdrantBackend, error) {
 	client, err := qdrant.NewClient(&qdrant.Config{
 		Host: url,
 		APIKey: apiKey,
@@ -25,9 +39,9 @@ func NewQdrantBackend(url, apiKey, collection string, vectorSize uint64) (*Qdran
 	}
 
 	backend := &QdrantBackend{
-		client:     client,
-		collection: collection,
-		vectorSize: vectorSize,
+		client:      client,
+		collection:  collection,
+		vectorSizes: vectorSizes,
 	}
 
 	// Ensure collection exists
@@ -52,14 +66,7 @@ func (b *QdrantBackend) ensureCollection() error {
 		// Create collection
 		err = b.client.CreateCollection(ctx, &qdrant.CreateCollection{
 			CollectionName: b.collection,
-			VectorsConfig: &qdrant.VectorsConfig{
-				Config: &qdrant.VectorsConfig_Params{
-					Params: &qdrant.VectorParams{
-						Size:     b.vectorSize,
-						Distance: qdrant.Distance_Cosine,
-					},
-				},
-			},
+			VectorsConfig:  b.vectorsConfig(),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create collection: %w", err)
@@ -69,19 +76,75 @@ func (b *QdrantBackend) ensureCollection() error {
 	return nil
 }
 
-// VectorSearchQdrant performs vector search using Qdrant
-func (s *Store) VectorSearchQdrant(query string, options SearchOptions) ([]SearchResult, error) {
-	ctx := context.Background()
+// vectorsConfig builds the collection's vector configuration: a single
+// unnamed vector when there's exactly one size keyed by "" (the common
+// case and what older callers of NewQdrantBackend expect), otherwise a
+// named vector per entry so each configured embedder writes to its own
+// vector space.
+func (b *QdrantBackend) vectorsConfig() *qdrant.VectorsConfig {
+	if size, ok := b.vectorSizes[""]; ok && len(b.vectorSizes) == 1 {
+		return &qdrant.VectorsConfig{
+			Config: &qdrant.VectorsConfig_Params{
+				Params: &qdrant.VectorParams{
+					Size:     size,
+					Distance: qdrant.Distance_Cosine,
+				},
+			},
+		}
+	}
+
+	params := make(map[string]*qdrant.VectorParams, len(b.vectorSizes))
+	for name, size := range b.vectorSizes {
+		params[name] = &qdrant.VectorParams{
+			Size:     size,
+			Distance: qdrant.Distance_Cosine,
+		}
+	}
+	return &qdrant.VectorsConfig{
+		Config: &qdrant.VectorsConfig_ParamsMap{
+			ParamsMap: &qdrant.VectorParamsMap{Map: params},
+		},
+	}
+}
+
+// qdrantVectorSizes derives Qdrant's named-vector sizes from
+// cfg.Models.Embedders, falling back to a single unnamed vector sized
+// from cfg.Vector.VectorSize when no embedders are declared.
+// Shared by Store.New (which keeps the concrete *QdrantBackend for
+// callers like the migrate CLI command) and the "qdrant" indexer.Registry
+// driver (which only needs it through the indexer.VectorIndexer
+// interface), so the two don't drift.
+func qdrantVectorSizes(cfg *config.Config) map[string]uint64 {
+	vectorSizes := map[string]uint64{}
+	for name, embedder := range cfg.Models.Embedders {
+		size := uint64(embedder.Dimensions)
+		if size == 0 {
+			size = uint64(cfg.Vector.VectorSize)
+		}
+		vectorSizes[name] = size
+	}
+	if len(vectorSizes) == 0 {
+		vectorSizes[""] = uint64(cfg.Vector.VectorSize)
+	}
+	return vectorSizes
+}
 
+// VectorSearchQdrant performs vector search using Qdrant. ctx bounds the
+// embedding call; cancellation during the Qdrant RPC itself is not yet
+// wired through the qdrant client.
+func (s *Store) VectorSearchQdrant(ctx context.Context, query string, options SearchOptions) ([]SearchResult, error) {
 	// Check if Qdrant backend is available
 	if s.qdrant == nil {
-		return []SearchResult{}, fmt.Errorf("Qdrant backend not available")
+		return []SearchResult{}, anel.Newf(anel.ErrorCodeBackendUnavailable, "qdrant_not_configured",
+			"Qdrant backend is not available").
+			WithHint("set vector.backend to qdrant and configure its endpoint")
 	}
 
 	// Generate embedding for query
 	embeddingResult, err := s.llmRouter.Embed(ctx, []string{query})
 	if err != nil {
-		return []SearchResult{}, fmt.Errorf("failed to generate embedding: %w", err)
+		return []SearchResult{}, anel.Wrap(anel.ErrorCodeEmbeddingFailed, "query_embed_failed", err).
+			WithDetail("query", query)
 	}
 
 	queryVector := embeddingResult.Embeddings[0]
@@ -105,6 +168,7 @@ func (s *Store) VectorSearchQdrant(query string, options SearchOptions) ([]Searc
 		}
 	}
 
+	ApplySort(searchResults, options.SortSpec)
 	return searchResults, nil
 }
 
@@ -140,7 +204,10 @@ func (b *QdrantBackend) Search(queryVector []float32, limit uint64) ([]map[strin
 	return results, nil
 }
 
-// UpsertVectors inserts vectors into Qdrant
+// UpsertVectors inserts vectors into Qdrant. A point whose VectorName is
+// set writes into that named vector (for collections created with
+// multiple embedders); an empty VectorName writes the collection's
+// single unnamed vector.
 func (b *QdrantBackend) UpsertVectors(points []VectorPoint) error {
 	ctx := context.Background()
 
@@ -156,8 +223,12 @@ func (b *QdrantBackend) UpsertVectors(points []VectorPoint) error {
 		// Use helper function to create PointId
 		id := qdrant.NewIDNum(p.ID)
 
-		// Use helper function to create vectors
-		vectors := qdrant.NewVectorsDense(p.Vector)
+		var vectors *qdrant.Vectors
+		if p.VectorName != "" {
+			vectors = qdrant.NewVectorsMap(map[string][]float32{p.VectorName: p.Vector})
+		} else {
+			vectors = qdrant.NewVectorsDense(p.Vector)
+		}
 
 		qdrantPoints[i] = &qdrant.PointStruct{
 			Id:      id,
@@ -183,6 +254,91 @@ func strToValue(s string) *qdrant.Value {
 	}
 }
 
+// Dimensions returns the size of this backend's vector space, for
+// Migrator's dimension-agreement check. Collections with multiple named
+// vectors aren't migratable as a single stream yet, so this reports the
+// unnamed ("") vector's size.
+func (b *QdrantBackend) Dimensions() uint64 {
+	return b.vectorSizes[""]
+}
+
+// DistanceMetric returns the collection's distance metric. Every vector
+// this backend creates uses cosine distance (see vectorsConfig), so this
+// is currently constant.
+func (b *QdrantBackend) DistanceMetric() string {
+	return "cosine"
+}
+
+// StreamPoints pages through the collection via Qdrant's scroll API,
+// ordered by point ID so a cursor (the last ID seen) resumes cleanly.
+// cursor is the string form of the last point ID returned, or "" to
+// start from the beginning.
+func (b *QdrantBackend) StreamPoints(ctx context.Context, cursor string, batchSize int) ([]VectorRecord, string, error) {
+	limit := uint32(batchSize)
+	scrollReq := &qdrant.ScrollPoints{
+		CollectionName: b.collection,
+		Limit:          &limit,
+		WithVectors:    qdrant.NewWithVectorsEnable(true),
+		WithPayload:    qdrant.NewWithPayloadEnable(true),
+	}
+	if cursor != "" {
+		var id uint64
+		if _, err := fmt.Sscanf(cursor, "%d", &id); err != nil {
+			return nil, "", fmt.Errorf("invalid cursor %q: %w", cursor, err)
+		}
+		scrollReq.Offset = qdrant.NewIDNum(id)
+	}
+
+	points, err := b.client.Scroll(ctx, scrollReq)
+	if err != nil {
+		return nil, "", fmt.Errorf("scroll collection %s: %w", b.collection, err)
+	}
+	if len(points) == 0 {
+		return nil, "", nil
+	}
+
+	records := make([]VectorRecord, len(points))
+	var lastID uint64
+	for i, p := range points {
+		lastID = p.Id.GetNum()
+		records[i] = VectorRecord{
+			Hash:       p.Payload["hash"].GetStringValue(),
+			Path:       p.Payload["path"].GetStringValue(),
+			Title:      p.Payload["title"].GetStringValue(),
+			Body:       p.Payload["body"].GetStringValue(),
+			Collection: p.Payload["collection"].GetStringValue(),
+			Vector:     p.Vectors.GetVector().GetData(),
+		}
+	}
+
+	nextCursor := ""
+	if len(points) == int(limit) {
+		nextCursor = fmt.Sprintf("%d", lastID+1)
+	}
+
+	return records, nextCursor, nil
+}
+
+// WritePoints upserts a batch of migrated records into this collection,
+// deriving each point's ID from its content hash via chunkPointID so
+// re-running a migration after a partial failure overwrites in place
+// instead of duplicating points.
+func (b *QdrantBackend) WritePoints(ctx context.Context, points []VectorRecord) error {
+	vPoints := make([]VectorPoint, len(points))
+	for i, p := range points {
+		vPoints[i] = VectorPoint{
+			ID:         chunkPointID(p.Hash, 0),
+			Path:       p.Path,
+			Title:      p.Title,
+			Body:       p.Body,
+			Hash:       p.Hash,
+			Collection: p.Collection,
+			Vector:     p.Vector,
+		}
+	}
+	return b.UpsertVectors(vPoints)
+}
+
 // VectorPoint represents a vector point for upsert
 type VectorPoint struct {
 	ID         uint64
@@ -192,4 +348,8 @@ type VectorPoint struct {
 	Hash       string
 	Collection string
 	Vector     []float32
+	// VectorName selects which named vector this point writes into, for
+	// collections created with multiple embedders; empty means the
+	// collection's single unnamed vector.
+	VectorName string
 }
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/qmd/qmd-go/internal/config"
+	"github.com/qmd/qmd-go/internal/store/indexer"
+)
+
+func init() {
+	indexer.Default.RegisterVector(string(config.VectorBackendQdrant), openQdrantIndexer)
+}
+
+// qdrantIndexerAdapter wraps QdrantBackend to satisfy indexer.VectorIndexer,
+// so qdrant is reachable through indexer.Registry the same way as
+// sqlite_vec, for uniform resolution (e.g. `qmd status --backends`)
+// without duplicating QdrantBackend's client logic. Store.New keeps its
+// own *QdrantBackend alongside this (see Store.qdrant) because
+// Migrator needs the concrete type, not this interface.
+type qdrantIndexerAdapter struct {
+	backend *QdrantBackend
+}
+
+// openQdrantIndexer ignores db: Qdrant is a remote backend with its own
+// client, not a *sql.DB-backed one.
+func openQdrantIndexer(collection string, db *sql.DB, cfg *config.Config) (indexer.VectorIndexer, error) {
+	backend, err := NewQdrantBackendWithVectors(
+		cfg.Vector.Qdrant.URL,
+		cfg.Vector.Qdrant.APIKey,
+		cfg.Vector.Qdrant.Collection,
+		qdrantVectorSizes(cfg),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("qdrant: %w", err)
+	}
+	return &qdrantIndexerAdapter{backend: backend}, nil
+}
+
+func (a *qdrantIndexerAdapter) Index(ctx context.Context, docs []indexer.VectorDocument) error {
+	points := make([]VectorPoint, len(docs))
+	for i, doc := range docs {
+		points[i] = VectorPoint{
+			Path:       doc.Path,
+			Title:      doc.Title,
+			Body:       doc.Body,
+			Hash:       doc.Hash,
+			Collection: doc.Collection,
+			Vector:     doc.Vector,
+			VectorName: doc.VectorName,
+			ID:         chunkPointID(doc.Hash, 0),
+		}
+	}
+	return a.backend.UpsertVectors(points)
+}
+
+func (a *qdrantIndexerAdapter) Search(ctx context.Context, vector []float32, limit int) ([]indexer.SearchResult, error) {
+	results, err := a.backend.Search(vector, uint64(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	searchResults := make([]indexer.SearchResult, len(results))
+	for i, r := range results {
+		searchResults[i] = indexer.SearchResult{
+			ID:         fmt.Sprintf("%v", r["hash"]),
+			Collection: fmt.Sprintf("%v", r["collection"]),
+			Path:       fmt.Sprintf("%v", r["path"]),
+			Title:      fmt.Sprintf("%v", r["title"]),
+			Score:      float32(r["score"].(float64)),
+		}
+	}
+	return searchResults, nil
+}
+
+func (a *qdrantIndexerAdapter) Delete(ctx context.Context, ids ...string) error {
+	// QdrantBackend doesn't expose a delete-by-payload-hash call yet.
+	return fmt.Errorf("qdrant: delete is not implemented")
+}
+
+func (a *qdrantIndexerAdapter) Close() error {
+	return nil
+}
+
+// Health checks Qdrant connectivity by re-checking the configured
+// collection exists, satisfying indexer.HealthChecker.
+func (a *qdrantIndexerAdapter) Health(ctx context.Context) error {
+	exists, err := a.backend.client.CollectionExists(ctx, a.backend.collection)
+	if err != nil {
+		return fmt.Errorf("qdrant: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("qdrant: collection %q does not exist", a.backend.collection)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"sync"
+)
+
+// SearchResultStream is a channel-based view of a search in progress:
+// Results emits each SearchResult as it becomes available and Err
+// reports any failure once Results has closed, the same Results()/Err()
+// shape QuerySession already uses for its per-collection streaming.
+type SearchResultStream struct {
+	results chan SearchResult
+
+	mu  sync.Mutex
+	err error
+}
+
+// Results returns the channel results stream onto. It closes once the
+// underlying search finishes, fails, or ctx is cancelled.
+func (s *SearchResultStream) Results() <-chan SearchResult {
+	return s.results
+}
+
+// Err returns the stream's failure, if any, or nil if it drained
+// normally. Call it after Results() has closed.
+func (s *SearchResultStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *SearchResultStream) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// streamResults runs fn (one of the non-streaming *Search methods) in a
+// goroutine and relays its results onto the returned stream one at a
+// time, honoring ctx cancellation while a slow consumer is still
+// draining it. fn runs to completion -- full ranking included -- before
+// its first result reaches the channel, so this buys a caller streaming
+// consumption/formatting of the result set, not early termination of
+// the search itself; a consumer piping into `head` still pays for the
+// full ranked search underneath.
+func streamResults(ctx context.Context, fn func() ([]SearchResult, error)) *SearchResultStream {
+	stream := &SearchResultStream{results: make(chan SearchResult)}
+	go func() {
+		defer close(stream.results)
+		results, err := fn()
+		if err != nil {
+			stream.setErr(err)
+			return
+		}
+		for _, r := range results {
+			select {
+			case <-ctx.Done():
+				stream.setErr(ctx.Err())
+				return
+			case stream.results <- r:
+			}
+		}
+	}()
+	return stream
+}
+
+// BM25SearchStream streams query's BM25 results over a channel instead
+// of blocking the caller until the full ranked list is ready. BM25Search
+// itself runs as a single synchronous SQLite FTS5 query -- there's no
+// partial-results signal from SQLite to relay mid-query -- so the stream
+// receives the full ranked list as a backlog of sends once BM25Search
+// returns; it still lets a caller start consuming/forwarding results
+// without waiting on a slower vector or hybrid search running alongside
+// it to finish too.
+func (s *Store) BM25SearchStream(ctx context.Context, query string, options SearchOptions) *SearchResultStream {
+	return streamResults(ctx, func() ([]SearchResult, error) {
+		return s.BM25Search(ctx, query, options)
+	})
+}
+
+// VectorSearchStream is VectorSearch's streaming counterpart; see
+// BM25SearchStream's doc comment for why results arrive as a backlog
+// rather than incrementally.
+func (s *Store) VectorSearchStream(ctx context.Context, query string, options SearchOptions) *SearchResultStream {
+	return streamResults(ctx, func() ([]SearchResult, error) {
+		return s.VectorSearch(ctx, query, options)
+	})
+}
+
+// HybridSearchStream is HybridSearch's streaming counterpart; see
+// BM25SearchStream's doc comment for why results arrive as a backlog
+// rather than incrementally.
+func (s *Store) HybridSearchStream(ctx context.Context, query string, options SearchOptions) *SearchResultStream {
+	return streamResults(ctx, func() ([]SearchResult, error) {
+		return s.HybridSearch(ctx, query, options)
+	})
+}
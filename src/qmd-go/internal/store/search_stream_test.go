@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func TestBM25SearchStream_NoCollectionsClosesImmediately(t *testing.T) {
+	s := &Store{config: &config.Config{}}
+
+	stream := s.BM25SearchStream(context.Background(), "query", SearchOptions{})
+	if _, ok := <-stream.Results(); ok {
+		t.Fatal("expected Results() to close with no collections configured")
+	}
+	if err := stream.Err(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestHybridSearchStream_RelaysResults(t *testing.T) {
+	s := newTestSessionStore(t)
+
+	stream := s.HybridSearchStream(context.Background(), "query", SearchOptions{SearchAll: true})
+	count := 0
+	for range stream.Results() {
+		count++
+	}
+	if err := stream.Err(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	_ = count // an empty freshly-created collection legitimately has zero hits
+}
+
+func TestHybridSearchStream_CanceledContextSurfacesErr(t *testing.T) {
+	s := newTestSessionStore(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	stream := s.HybridSearchStream(ctx, "query", SearchOptions{SearchAll: true})
+	for range stream.Results() {
+	}
+
+	// HybridSearch itself may already return context.Canceled before
+	// streamResults gets a chance to send anything, so either the
+	// caller's cancellation or HybridSearch's own is an acceptable Err.
+	if stream.Err() == nil {
+		t.Error("expected a non-nil error from a pre-canceled context")
+	}
+}
@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultSessionWorkers bounds how many collections a QuerySession
+// retrieves from concurrently. Collections beyond this limit queue
+// behind a worker rather than all firing at once against SQLite/the
+// vector backend.
+const DefaultSessionWorkers = 4
+
+// QuerySession is a long-lived handle on a single logical user query: it
+// keeps the collections' BM25 and vector retrievals running concurrently
+// and streams fused results back to the caller as they're ready, rather
+// than blocking until every collection has answered. Callers range over
+// Results() until it closes, then check Err() for anything that went
+// wrong along the way.
+type QuerySession struct {
+	results chan SearchResult
+	cancel  context.CancelFunc
+
+	mu  sync.Mutex
+	err error
+
+	done chan struct{}
+}
+
+// OpenSession starts retrieving q against every collection options
+// selects and returns a QuerySession streaming fused per-collection
+// results as they complete. Retrieval runs in a bounded worker pool
+// (DefaultSessionWorkers) so a --all query against many collections
+// doesn't open every connection at once; each collection's BM25 and
+// vector legs still run concurrently with each other, the same as
+// retrieveParallel. Canceling ctx (or calling Close) stops outstanding
+// SQLite queries via their *sql.DB's QueryContext and closes Results().
+func (s *Store) OpenSession(ctx context.Context, q string, opts SearchOptions) (*QuerySession, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	qs := &QuerySession{
+		results: make(chan SearchResult),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	collections := s.getCollections(opts)
+
+	go qs.run(ctx, s, q, opts, collections)
+
+	return qs, nil
+}
+
+// run fans collections out across a bounded worker pool, fuses each
+// collection's BM25+vector results with rrfFusion, and streams them onto
+// qs.results in whatever order collections finish -- it's an incremental
+// merge rather than a single final sort, so the caller sees hits from a
+// fast collection before a slow one has even started.
+func (qs *QuerySession) run(ctx context.Context, s *Store, q string, opts SearchOptions, collections []string) {
+	defer close(qs.results)
+	defer close(qs.done)
+
+	workers := DefaultSessionWorkers
+	if len(collections) < workers {
+		workers = len(collections)
+	}
+	if workers == 0 {
+		return
+	}
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+
+	for _, collection := range collections {
+		select {
+		case <-ctx.Done():
+			qs.setErr(ctx.Err())
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(collection string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			qs.retrieveCollection(ctx, s, q, opts, collection)
+		}(collection)
+	}
+
+	wg.Wait()
+}
+
+// retrieveCollection runs BM25+vector retrieval for a single collection,
+// fuses the two lists, and sends each fused result onto qs.results,
+// honoring ctx cancellation both while waiting on retrieval and while
+// sending.
+func (qs *QuerySession) retrieveCollection(ctx context.Context, s *Store, q string, opts SearchOptions, collection string) {
+	collOpts := opts
+	collOpts.Collection = collection
+	collOpts.SearchAll = false
+
+	bm25Results, vectorResults := s.retrieveParallel(ctx, q, collOpts)
+	if ctx.Err() != nil {
+		qs.setErr(ctx.Err())
+		return
+	}
+
+	fused := s.rrfFusion(ctx, [][]SearchResult{bm25Results, vectorResults}, nil, DefaultRRFK)
+	for _, result := range fused {
+		select {
+		case <-ctx.Done():
+			qs.setErr(ctx.Err())
+			return
+		case qs.results <- result:
+		}
+	}
+}
+
+func (qs *QuerySession) setErr(err error) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	if qs.err == nil {
+		qs.err = err
+	}
+}
+
+// Results returns the channel results stream onto as each collection's
+// retrieval completes. It closes once every collection has been
+// retrieved, ctx is canceled, or Close is called.
+func (qs *QuerySession) Results() <-chan SearchResult {
+	return qs.results
+}
+
+// Err returns the first error the session encountered (typically
+// ctx.Err() from a cancellation), or nil if retrieval completed
+// normally. Call it after Results() has closed.
+func (qs *QuerySession) Err() error {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	return qs.err
+}
+
+// Close cancels any outstanding retrieval and waits for the session's
+// goroutines to finish. It's safe to call more than once.
+func (qs *QuerySession) Close() {
+	qs.cancel()
+	<-qs.done
+}
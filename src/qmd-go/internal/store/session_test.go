@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qmd/qmd-go/internal/config"
+)
+
+func TestOpenSession_NoCollectionsClosesImmediately(t *testing.T) {
+	s := &Store{config: &config.Config{}}
+
+	session, err := s.OpenSession(context.Background(), "query", SearchOptions{})
+	if err != nil {
+		t.Fatalf("OpenSession: %v", err)
+	}
+	defer session.Close()
+
+	if _, ok := <-session.Results(); ok {
+		t.Fatal("expected Results() to close with no collections configured")
+	}
+	if err := session.Err(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func newTestSessionStore(t *testing.T) *Store {
+	t.Helper()
+	cfg := &config.Config{
+		CachePath:   t.TempDir(),
+		Collections: []config.CollectionConfig{{Name: "docs"}},
+	}
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return s
+}
+
+func TestOpenSession_CanceledContextSurfacesErr(t *testing.T) {
+	s := newTestSessionStore(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	session, err := s.OpenSession(ctx, "query", SearchOptions{SearchAll: true})
+	if err != nil {
+		t.Fatalf("OpenSession: %v", err)
+	}
+	defer session.Close()
+
+	for range session.Results() {
+	}
+
+	if err := session.Err(); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestOpenSession_CloseCancelsAndReturns(t *testing.T) {
+	s := newTestSessionStore(t)
+
+	session, err := s.OpenSession(context.Background(), "query", SearchOptions{SearchAll: true})
+	if err != nil {
+		t.Fatalf("OpenSession: %v", err)
+	}
+
+	session.Close()
+	session.Close() // safe to call twice
+}
@@ -0,0 +1,181 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLiteVectorBackend adapts a collection's sqlite-vec table
+// (vectors_vec/content_vectors, alongside documents/content) to the
+// MigrationSource/MigrationDestination interfaces, so vectors can move
+// to or from Qdrant without going through Store's search-oriented
+// methods.
+type SQLiteVectorBackend struct {
+	db         *sql.DB
+	collection string
+	dimensions uint64
+}
+
+// NewSQLiteVectorBackend wraps db for collection's vectors_vec table.
+// dimensions must match the table's declared embedding size (384 for
+// the schema initSchema creates).
+func NewSQLiteVectorBackend(db *sql.DB, collection string, dimensions uint64) *SQLiteVectorBackend {
+	return &SQLiteVectorBackend{db: db, collection: collection, dimensions: dimensions}
+}
+
+// Dimensions returns the backend's vector size.
+func (b *SQLiteVectorBackend) Dimensions() uint64 {
+	return b.dimensions
+}
+
+// DistanceMetric returns the backend's distance metric. vectors_vec is
+// always declared with distance_metric=cosine (see initSchema), so this
+// is currently constant.
+func (b *SQLiteVectorBackend) DistanceMetric() string {
+	return "cosine"
+}
+
+// Count implements MigrationCounter, reporting this collection's active
+// document count so Migrate can estimate an ETA.
+func (b *SQLiteVectorBackend) Count(ctx context.Context) (int, error) {
+	var count int
+	err := b.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM documents d
+		JOIN vectors_vec v ON v.hash_seq LIKE d.hash || '%'
+		WHERE d.active = 1
+	`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count vectors for %s: %w", b.collection, err)
+	}
+	return count, nil
+}
+
+// StreamPoints pages through this collection's vectors ordered by
+// documents.id, joining in each vector's owning document for payload
+// fields. cursor is the string form of the last document id returned.
+func (b *SQLiteVectorBackend) StreamPoints(ctx context.Context, cursor string, batchSize int) ([]VectorRecord, string, error) {
+	afterID := int64(0)
+	if cursor != "" {
+		var err error
+		afterID, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid cursor %q: %w", cursor, err)
+		}
+	}
+
+	rows, err := b.db.QueryContext(ctx, `
+		SELECT d.id, d.hash, d.path, d.title, d.collection, c.doc, v.embedding,
+		       COALESCE(cv.model, '')
+		FROM documents d
+		JOIN vectors_vec v ON v.hash_seq LIKE d.hash || '%'
+		LEFT JOIN content c ON c.hash = d.hash
+		LEFT JOIN content_vectors cv ON cv.hash = d.hash AND cv.seq = 0
+		WHERE d.active = 1 AND d.id > ?
+		ORDER BY d.id
+		LIMIT ?
+	`, afterID, batchSize)
+	if err != nil {
+		return nil, "", fmt.Errorf("query vectors for %s: %w", b.collection, err)
+	}
+	defer rows.Close()
+
+	var records []VectorRecord
+	var lastID int64
+	for rows.Next() {
+		var id int64
+		var hash, path, title, collection, doc, embeddingJSON, model string
+		if err := rows.Scan(&id, &hash, &path, &title, &collection, &doc, &embeddingJSON, &model); err != nil {
+			return nil, "", err
+		}
+
+		var vector []float32
+		if err := json.Unmarshal([]byte(embeddingJSON), &vector); err != nil {
+			return nil, "", fmt.Errorf("decode embedding for %s: %w", hash, err)
+		}
+
+		records = append(records, VectorRecord{
+			Hash:       hash,
+			Path:       path,
+			Title:      title,
+			Body:       doc,
+			Collection: collection,
+			Vector:     vector,
+			Model:      model,
+		})
+		lastID = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(records) == batchSize {
+		nextCursor = strconv.FormatInt(lastID, 10)
+	}
+
+	return records, nextCursor, nil
+}
+
+// WritePoints inserts a batch of migrated records' content and vectors.
+// Documents are created if missing (hash collisions are assumed to mean
+// the same content, matching documents.hash's UNIQUE constraint); the
+// vector itself is always overwritten, so re-running a migration after a
+// partial failure is safe.
+func (b *SQLiteVectorBackend) WritePoints(ctx context.Context, points []VectorRecord) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, p := range points {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO content(hash, doc, size) VALUES (?, ?, ?)`,
+			p.Hash, p.Body, len(p.Body),
+		); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("write content for %s: %w", p.Hash, err)
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO documents(collection, path, title, hash, created_at, modified_at, active)
+			 VALUES (?, ?, ?, ?, ?, ?, 1)`,
+			b.collection, strings.TrimPrefix(p.Path, b.collection+"/"), p.Title, p.Hash, now, now,
+		); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("write document for %s: %w", p.Hash, err)
+		}
+
+		vectorJSON, err := json.Marshal(p.Vector)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		hashSeq := p.Hash + "_0"
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR REPLACE INTO vectors_vec(hash_seq, embedding) VALUES (?, ?)`,
+			hashSeq, string(vectorJSON),
+		); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("write vector for %s: %w", p.Hash, err)
+		}
+
+		if p.Model != "" {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT OR REPLACE INTO content_vectors(hash, seq, pos, model, embedded_at) VALUES (?, 0, 0, ?, ?)`,
+				p.Hash, p.Model, now,
+			); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("write model for %s: %w", p.Hash, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
@@ -3,14 +3,21 @@ package store
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 
+	"go.opentelemetry.io/otel/attribute"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/qmd/qmd-go/internal/anel"
 	"github.com/qmd/qmd-go/internal/config"
 	"github.com/qmd/qmd-go/internal/llm"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/qmd/qmd-go/internal/otel"
+	"github.com/qmd/qmd-go/internal/store/indexer"
 )
 
 // SearchResult represents a search result
@@ -21,6 +28,16 @@ type SearchResult struct {
 	Lines      int
 	Title      string
 	Hash       string
+	// DocID is the backend's stable identifier for the document this
+	// result came from (its content hash today, across every backend),
+	// kept separate from Hash so a future backend whose primary key isn't
+	// the content hash still has somewhere to report it.
+	DocID string
+	// Contributions records this result's raw (pre-fusion) score from
+	// each FusionList it appeared in, keyed by FusionList.Source (e.g.
+	// "bm25", "vector"). Populated by FusionStrategy.Fuse; nil for
+	// results that never went through fusion.
+	Contributions map[string]float32
 }
 
 // SearchOptions represents search options
@@ -29,6 +46,141 @@ type SearchOptions struct {
 	MinScore   float32
 	Collection string
 	SearchAll  bool
+	SortSpec   []SortKey
+	// Fusion selects the FusionStrategy HybridSearchWithOptions combines
+	// BM25 and vector results with. Empty defaults to FusionRRF.
+	Fusion FusionName
+	// ConvexAlpha overrides the collection's
+	// config.CollectionConfig.FusionAlpha for this call's FusionConvex
+	// weight. Nil means "use the collection's configured alpha, or
+	// DefaultConvexAlpha if that's unset too"; a non-nil zero is a valid
+	// override meaning pure vector (see fuse).
+	ConvexAlpha *float32
+	// Tags restricts results to documents carrying every listed tag (an
+	// implicit AND). Ignored when TagExpr is set.
+	Tags []string
+	// TagExpr restricts results to documents matching a boolean tag
+	// expression, e.g. "golang AND (tutorial OR reference) AND NOT
+	// deprecated". Takes precedence over Tags.
+	TagExpr string
+}
+
+// SortKey orders results by one SearchResult field, descending when Desc
+// is set. A zero-value slice of SortKey leaves results in whatever order
+// the search method (or fusion) already produced.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// SortableFields lists the SearchResult fields ParseSortSpec and
+// ApplySort accept, in the order they're reported in parse errors.
+var SortableFields = []string{"path", "collection", "score", "lines", "title", "hash"}
+
+// ParseSortSpec parses a comma-separated --sort value such as
+// "-score,collection,title" into an ordered []SortKey: a leading "-"
+// sorts that field descending, otherwise ascending. An empty spec
+// returns a nil slice. It returns an error naming the allowed fields if
+// spec references anything outside SortableFields.
+func ParseSortSpec(spec string) ([]SortKey, error) {
+	if spec == "" {
+		return nil, nil
+	}
+
+	allowed := make(map[string]bool, len(SortableFields))
+	for _, f := range SortableFields {
+		allowed[f] = true
+	}
+
+	fields := strings.Split(spec, ",")
+	keys := make([]SortKey, 0, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		key := SortKey{Field: field}
+		if strings.HasPrefix(field, "-") {
+			key.Desc = true
+			key.Field = strings.TrimPrefix(field, "-")
+		}
+
+		if !allowed[key.Field] {
+			return nil, fmt.Errorf("unknown sort field %q (allowed: %s)", key.Field, strings.Join(SortableFields, ", "))
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// ApplySort stably sorts results in place by spec, evaluating keys in
+// order so later keys break ties left by earlier ones. Unknown fields
+// were already rejected by ParseSortSpec, so this ignores them rather
+// than erroring.
+func ApplySort(results []SearchResult, spec []SortKey) {
+	if len(spec) == 0 {
+		return
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		for _, key := range spec {
+			cmp := compareSortField(a, b, key.Field)
+			if cmp == 0 {
+				continue
+			}
+			if key.Desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+}
+
+// compareSortField returns -1, 0, or 1 comparing a and b on field, which
+// must be one of SortableFields.
+func compareSortField(a, b SearchResult, field string) int {
+	switch field {
+	case "path":
+		return strings.Compare(a.Path, b.Path)
+	case "collection":
+		return strings.Compare(a.Collection, b.Collection)
+	case "score":
+		return compareFloat32(a.Score, b.Score)
+	case "lines":
+		return compareInt(a.Lines, b.Lines)
+	case "title":
+		return strings.Compare(a.Title, b.Title)
+	case "hash":
+		return strings.Compare(a.Hash, b.Hash)
+	default:
+		return 0
+	}
+}
+
+func compareFloat32(a, b float32) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
 }
 
 // IndexStats represents index statistics
@@ -41,27 +193,50 @@ type IndexStats struct {
 
 // Store represents the main storage structure
 type Store struct {
-	config     *config.Config
+	config      *config.Config
 	connections map[string]*sql.DB
-	llmRouter  *llm.Router
-	qdrant     *QdrantBackend
+	llmRouter   *llm.Router
+	qdrant      *QdrantBackend
+	milvus      *MilvusBackend
+
+	// ftsIndexers and vectorIndexers cache the indexer.Registry-resolved
+	// backend for each collection, keyed by collection name (ftsIndexers)
+	// or "backend:collection" (vectorIndexers, since a single Store can
+	// resolve more than one vector backend across its lifetime, e.g.
+	// during `qmd status --backends`).
+	ftsIndexers    map[string]indexer.Indexer
+	vectorIndexers map[string]indexer.VectorIndexer
+
+	// synonymsOnce/synonyms lazily load config.Expansion.SynonymsPath the
+	// first time the synonyms expansion strategy runs.
+	synonymsOnce sync.Once
+	synonyms     map[string][]string
+
+	// llmExpansionMu guards llmExpansionCache, which holds each query's
+	// LLM-generated expansion terms until they expire.
+	llmExpansionMu    sync.Mutex
+	llmExpansionCache map[string]llmExpansionCacheEntry
 }
 
 // New creates a new Store
 func New(cfg *config.Config) (*Store, error) {
 	store := &Store{
-		config:     cfg,
-		connections: make(map[string]*sql.DB),
-		llmRouter:  llm.New(cfg),
+		config:         cfg,
+		connections:    make(map[string]*sql.DB),
+		llmRouter:      llm.New(cfg),
+		ftsIndexers:    make(map[string]indexer.Indexer),
+		vectorIndexers: make(map[string]indexer.VectorIndexer),
 	}
 
-	// Initialize Qdrant backend if configured
-	if cfg.Vector.Backend == "qdrant" {
-		qdrant, err := NewQdrantBackend(
+	// Initialize Qdrant backend if configured. When named embedders are
+	// declared, the collection gets one named vector per embedder
+	// instead of a single unnamed one.
+	if cfg.Vector.Backend == config.VectorBackendQdrant {
+		qdrant, err := NewQdrantBackendWithVectors(
 			cfg.Vector.Qdrant.URL,
 			cfg.Vector.Qdrant.APIKey,
 			cfg.Vector.Qdrant.Collection,
-			uint64(cfg.Vector.Qdrant.VectorSize),
+			qdrantVectorSizes(cfg),
 		)
 		if err != nil {
 			fmt.Printf("Warning: Qdrant backend not available: %v\n", err)
@@ -70,6 +245,16 @@ func New(cfg *config.Config) (*Store, error) {
 		}
 	}
 
+	// Initialize Milvus backend if configured.
+	if cfg.Vector.Backend == config.VectorBackendMilvus {
+		milvus, err := NewMilvusBackend(cfg.Vector.Milvus, uint64(cfg.Vector.VectorSize))
+		if err != nil {
+			fmt.Printf("Warning: Milvus backend not available: %v\n", err)
+		} else {
+			store.milvus = milvus
+		}
+	}
+
 	// Initialize connections for each collection
 	for _, col := range cfg.Collections {
 		if _, err := store.GetConnection(col.Name); err != nil {
@@ -80,13 +265,59 @@ func New(cfg *config.Config) (*Store, error) {
 	return store, nil
 }
 
+// Qdrant returns the store's Qdrant backend, or nil if one isn't
+// configured. Useful for callers (like the migrate CLI command) that
+// need the backend itself rather than going through Store's search
+// methods.
+func (s *Store) Qdrant() *QdrantBackend {
+	return s.qdrant
+}
+
+// Milvus returns the store's Milvus backend, or nil if one isn't
+// configured. Useful for callers (like the migrate CLI command) that
+// need the backend itself rather than going through Store's search
+// methods.
+func (s *Store) Milvus() *MilvusBackend {
+	return s.milvus
+}
+
+// LLMRouter returns the store's llm.Router. Useful for callers (like
+// package router) that need direct access to embedding/rerank/query
+// classification rather than going through Store's search methods --
+// and that must share this instance rather than construct their own,
+// since a second llm.Router would supervise a second llama-server
+// process when cfg.LlamaServer is set.
+func (s *Store) LLMRouter() *llm.Router {
+	return s.llmRouter
+}
+
+// EmbedText embeds a single piece of text with the store's configured
+// embed model, returning the vector and the model name that produced
+// it. Exposed for callers outside the search path -- the migrate CLI
+// command uses it to re-embed points whose recorded model differs from
+// EmbedModelName.
+func (s *Store) EmbedText(ctx context.Context, text string) ([]float32, string, error) {
+	result, err := s.llmRouter.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, "", err
+	}
+	return result.Embeddings[0], result.Model, nil
+}
+
+// EmbedModelName returns the model name new embeddings are produced
+// with, i.e. config.VectorConfig.Model. Migrate compares a point's
+// recorded model against this to decide whether it needs re-embedding.
+func (s *Store) EmbedModelName() string {
+	return s.config.Vector.Model
+}
+
 // GetConnection gets or creates a database connection
 func (s *Store) GetConnection(collection string) (*sql.DB, error) {
 	if db, ok := s.connections[collection]; ok {
 		return db, nil
 	}
 
-	dbPath := filepath.Join(s.config.CachePath, collection, "index.db")
+	dbPath := s.config.DBPathFor(collection)
 
 	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
 		return nil, err
@@ -97,7 +328,11 @@ func (s *Store) GetConnection(collection string) (*sql.DB, error) {
 		return nil, err
 	}
 
-	if err := s.initSchema(db); err != nil {
+	var analyzer *config.AnalyzerConfig
+	if col, ok := s.config.Collection(collection); ok {
+		analyzer = col.Analyzer
+	}
+	if err := s.initSchema(db, analyzer); err != nil {
 		return nil, err
 	}
 
@@ -105,9 +340,12 @@ func (s *Store) GetConnection(collection string) (*sql.DB, error) {
 	return db, nil
 }
 
-// initSchema initializes the database schema
-func (s *Store) initSchema(db *sql.DB) error {
-	schema := `
+// initSchema initializes the database schema. documents_fts's tokenize
+// clause comes from analyzer (nil meaning English/porter, the
+// long-standing default) via FTS5TokenizeClause -- see its doc comment
+// for which languages get real tokenizer support today.
+func (s *Store) initSchema(db *sql.DB, analyzer *config.AnalyzerConfig) error {
+	schema := fmt.Sprintf(`
 		CREATE TABLE IF NOT EXISTS documents (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			collection TEXT NOT NULL,
@@ -121,7 +359,7 @@ func (s *Store) initSchema(db *sql.DB) error {
 
 		CREATE VIRTUAL TABLE IF NOT EXISTS documents_fts USING fts5(
 			filepath, title, body,
-			tokenize='porter unicode61',
+			tokenize=%q,
 			content='documents',
 			content_rowid='id'
 		);
@@ -159,164 +397,551 @@ func (s *Store) initSchema(db *sql.DB) error {
 
 		CREATE INDEX IF NOT EXISTS idx_documents_collection ON documents(collection);
 		CREATE INDEX IF NOT EXISTS idx_documents_hash ON documents(hash);
-	`
+
+		CREATE TABLE IF NOT EXISTS expansion_terms (
+			term TEXT PRIMARY KEY,
+			freq INTEGER NOT NULL DEFAULT 0
+		);
+
+		CREATE TABLE IF NOT EXISTS expansion_cooccur (
+			term_a TEXT NOT NULL,
+			term_b TEXT NOT NULL,
+			freq INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (term_a, term_b)
+		);
+
+		CREATE TABLE IF NOT EXISTS expansion_vectors (
+			term TEXT PRIMARY KEY,
+			embedding TEXT NOT NULL,
+			model TEXT NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			description TEXT NOT NULL DEFAULT ''
+		);
+
+		CREATE TABLE IF NOT EXISTS document_tags (
+			doc_hash TEXT NOT NULL,
+			tag_id INTEGER NOT NULL REFERENCES tags(id),
+			added_at TEXT NOT NULL,
+			PRIMARY KEY (doc_hash, tag_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_document_tags_hash ON document_tags(doc_hash);
+
+		CREATE TABLE IF NOT EXISTS tag_suggestions (
+			doc_hash TEXT NOT NULL,
+			tag_id INTEGER NOT NULL REFERENCES tags(id),
+			confidence REAL NOT NULL,
+			suggested_at TEXT NOT NULL,
+			PRIMARY KEY (doc_hash, tag_id)
+		);
+	`, FTS5TokenizeClause(analyzer))
 
 	_, err := db.Exec(schema)
 	return err
 }
 
-// BM25Search performs BM25 full-text search
-func (s *Store) BM25Search(query string, options SearchOptions) ([]SearchResult, error) {
+// AnalyzeText tokenizes text with collection's configured analyzer
+// (falling back to the first configured collection, the same rule
+// getCollections uses for an unset SearchOptions.Collection), for the
+// search command's --analyze flag.
+func (s *Store) AnalyzeText(ctx context.Context, collection, text string) ([]string, error) {
+	if collection == "" {
+		collections := s.getCollections(SearchOptions{})
+		if len(collections) == 0 {
+			return nil, anel.Newf(anel.ErrorCodeInvalidInput, "analyze_no_collection",
+				"no collection configured to analyze against").
+				WithHint("pass --collection or configure at least one collection")
+		}
+		collection = collections[0]
+	}
+
+	var cfg *config.AnalyzerConfig
+	if col, ok := s.config.Collection(collection); ok {
+		cfg = col.Analyzer
+	}
+
+	db, err := s.GetConnection(collection)
+	if err != nil {
+		return nil, err
+	}
+	return AnalyzeText(ctx, db, FTS5TokenizeClause(cfg), text)
+}
+
+// BM25Search performs BM25 full-text search across every collection
+// options selects. It checks ctx between collections -- the loop's only
+// safe point, since a single collection's FTS5 query is a synchronous
+// call with no mid-query cancellation hook of its own -- so a caller's
+// --timeout/--deadline still aborts the search promptly rather than
+// running every remaining collection first.
+func (s *Store) BM25Search(ctx context.Context, query string, options SearchOptions) ([]SearchResult, error) {
 	var results []SearchResult
+	err := otel.Wrap(ctx, otel.SpanBM25Search, otel.Attrs{
+		Collection: options.Collection,
+		K:          options.Limit,
+	}, func(ctx context.Context) (int, error) {
+		var err error
+		results, err = s.bm25Search(ctx, query, options)
+		return len(results), err
+	})
+	return results, err
+}
 
-	collections := s.getCollections(options)
+func (s *Store) bm25Search(ctx context.Context, query string, options SearchOptions) ([]SearchResult, error) {
+	var results []SearchResult
 
-	for _, collection := range collections {
-		db, err := s.GetConnection(collection)
+	for _, collection := range s.getCollections(options) {
+		if err := ctx.Err(); err != nil {
+			return nil, anel.DeadlineExceededError(err)
+		}
+
+		idx, err := s.ftsIndexerFor(collection)
+		if err != nil {
+			continue
+		}
+
+		var allowed map[string]bool
+		if len(options.Tags) > 0 || options.TagExpr != "" {
+			db, err := s.GetConnection(collection)
+			if err != nil {
+				continue
+			}
+			allowed, err = s.tagFilterSet(db, options)
+			if err != nil {
+				continue
+			}
+		}
+
+		hits, err := idx.Search(ctx, indexer.Query{
+			Text:       query,
+			Collection: collection,
+			Limit:      options.Limit,
+		})
 		if err != nil {
 			continue
 		}
 
-		rows, err := db.Query(`
-			SELECT rowid, bm25(documents_fts), title, path
-			FROM documents_fts
-			WHERE documents_fts MATCH ? AND active = 1
-			ORDER BY bm25(documents_fts)
-			LIMIT ?
-		`, fmt.Sprintf("%s NOT active:0", query), options.Limit)
+		for _, hit := range hits {
+			if allowed != nil && !allowed[hit.ID] {
+				continue
+			}
+			results = append(results, SearchResult{
+				Path:       hit.Path,
+				Collection: hit.Collection,
+				Score:      hit.Score,
+				Title:      hit.Title,
+				Hash:       hit.ID,
+				DocID:      hit.ID,
+			})
+		}
+	}
+
+	ApplySort(results, options.SortSpec)
+	return results, nil
+}
 
+// CodeSearch answers a literal substring (or, when regex is set, regular
+// expression) query against the trigram index, for the `qmd code`
+// command. It resolves config.BM25BackendTrigram directly per
+// collection rather than through ftsIndexerFor, since a collection's
+// configured bm25.backend is almost always a BM25-ranked one and
+// CodeSearch wants the trigram index regardless.
+func (s *Store) CodeSearch(ctx context.Context, query string, options SearchOptions, regex bool) ([]SearchResult, error) {
+	var results []SearchResult
+
+	for _, collection := range s.getCollections(options) {
+		db, err := s.GetConnection(collection)
 		if err != nil {
 			continue
 		}
 
-		for rows.Next() {
-			var result SearchResult
-			var rowID int64
-			var score float64
+		idx, err := indexer.Default.OpenFTS(string(config.BM25BackendTrigram), collection, db, s.config)
+		if err != nil {
+			continue
+		}
 
-			rows.Scan(&rowID, &score, &result.Title, &result.Path)
-			result.Score = float32(score)
-			result.Collection = collection
-			result.Hash = fmt.Sprintf("%d", rowID)
-			results = append(results, result)
+		hits, err := idx.Search(ctx, indexer.Query{
+			Text:       query,
+			Collection: collection,
+			Limit:      options.Limit,
+			Regex:      regex,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, hit := range hits {
+			results = append(results, SearchResult{
+				Path:       hit.Path,
+				Collection: hit.Collection,
+				Score:      hit.Score,
+				Title:      hit.Title,
+				Hash:       hit.ID,
+				DocID:      hit.ID,
+			})
 		}
 	}
 
+	ApplySort(results, options.SortSpec)
 	return results, nil
 }
 
-// VectorSearch performs vector search
-func (s *Store) VectorSearch(query string, options SearchOptions) ([]SearchResult, error) {
-	// Check vector backend configuration
-	switch s.config.Vector.Backend {
-	case "qmd_builtin":
+// ftsIndexerFor resolves, and caches, the indexer.Registry-configured FTS
+// backend for collection -- collection's own CollectionConfig.BM25
+// override (config.Config.ResolveCollection) when it has one, else the
+// root config's bm25.backend.
+func (s *Store) ftsIndexerFor(collection string) (indexer.Indexer, error) {
+	if idx, ok := s.ftsIndexers[collection]; ok {
+		return idx, nil
+	}
+
+	db, err := s.GetConnection(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := s.config.ResolveCollection(collection)
+	idx, err := indexer.Default.OpenFTS(string(resolved.BM25.Backend), collection, db, resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	s.ftsIndexers[collection] = idx
+	return idx, nil
+}
+
+// qmdBuiltinIndexerName returns the indexer.Registry name VectorSearchSQLite
+// and RebuildIndex should resolve for the qmd_builtin vector backend:
+// the flat sqlite-vec scan by default, or the HNSW graph driver when
+// cfg's Vector.Index selects it. cfg is the caller's already-resolved
+// config (config.Config.ResolveCollection) so a collection with its own
+// Vector.Index override gets its own driver rather than the root one.
+func qmdBuiltinIndexerName(cfg *config.Config) string {
+	if cfg.Vector.Index == config.VectorIndexHNSW {
+		return indexer.HNSWDriverName
+	}
+	return string(config.VectorBackendQmdBuiltin)
+}
+
+// vectorIndexerFor resolves, and caches, backend (a config.VectorBackend
+// value) for collection, opening it against cfg (the caller's
+// already-resolved config, so a collection's Vector override reaches
+// indexer.Registry the same way ftsIndexerFor's resolved config does).
+// It's parameterized on backend rather than always reading
+// cfg.Vector.Backend so VectorSearchSQLite and VectorSearchQdrant can each
+// resolve their own backend regardless of which one is currently
+// configured, the way they already did before the indexer.Registry
+// existed.
+func (s *Store) vectorIndexerFor(backend, collection string, cfg *config.Config) (indexer.VectorIndexer, error) {
+	key := backend + ":" + collection
+	if idx, ok := s.vectorIndexers[key]; ok {
+		return idx, nil
+	}
+
+	var db *sql.DB
+	if backend == string(config.VectorBackendQmdBuiltin) {
+		var err error
+		db, err = s.GetConnection(collection)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	idx, err := indexer.Default.OpenVector(backend, collection, db, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s.vectorIndexers[key] = idx
+	return idx, nil
+}
+
+// VectorSearch performs vector search. ctx bounds the query embedding
+// call and, for the Qdrant backend, the outbound search request, so a
+// caller can cancel a slow search instead of waiting it out.
+func (s *Store) VectorSearch(ctx context.Context, query string, options SearchOptions) ([]SearchResult, error) {
+	var results []SearchResult
+	err := otel.Wrap(ctx, otel.SpanVectorSearch, otel.Attrs{
+		Collection: options.Collection,
+		K:          options.Limit,
+		Backend:    string(s.config.Vector.Backend),
+	}, func(ctx context.Context) (int, error) {
+		var err error
+		results, err = s.vectorSearchDispatch(ctx, query, options)
+		return len(results), err
+	})
+	return results, err
+}
+
+// vectorSearchDispatch is VectorSearch's backend switch, split out so
+// VectorSearch can wrap it in a single otel.Wrap call that still sees the
+// real result count once whichever backend returns. When options names a
+// single collection, it resolves that collection's own Vector override
+// (config.Config.ResolveCollection) before switching, so a collection
+// pinned to a different backend than the root config dispatches to it
+// rather than whatever's globally configured. An unset Collection (the
+// "search every configured collection" case) still switches on the root
+// backend -- VectorSearchQdrant/VectorSearchMilvus are each one outbound
+// call across every collection, so mixing backends within a single
+// multi-collection query isn't supported.
+func (s *Store) vectorSearchDispatch(ctx context.Context, query string, options SearchOptions) ([]SearchResult, error) {
+	cfg := s.config
+	if options.Collection != "" {
+		cfg = s.config.ResolveCollection(options.Collection)
+	}
+
+	switch cfg.Vector.Backend {
+	case config.VectorBackendQmdBuiltin:
 		// Use sqlite-vec
-		return s.VectorSearchSQLite(query, options)
-	case "qdrant":
+		return s.VectorSearchSQLite(ctx, query, options)
+	case config.VectorBackendQdrant:
 		// Use Qdrant backend
-		return s.VectorSearchQdrant(query, options)
+		return s.VectorSearchQdrant(ctx, query, options)
+	case config.VectorBackendMilvus:
+		// Use Milvus backend
+		return s.VectorSearchMilvus(ctx, query, options)
 	default:
 		// Fall back to BM25
-		return s.BM25Search(query, options)
+		return s.BM25Search(ctx, query, options)
 	}
 }
 
-// VectorSearchSQLite performs vector search using sqlite-vec
-func (s *Store) VectorSearchSQLite(query string, options SearchOptions) ([]SearchResult, error) {
-	ctx := context.Background()
+// VectorSearchSQLite performs vector search using sqlite-vec. When
+// options names a single collection, the query is embedded with that
+// collection's resolved Models.Embed override (config.Config.ResolveCollection)
+// instead of the root model, matching how embedChunk embeds the
+// documents it's being compared against; an unset Collection embeds once
+// with the root model, same as before per-collection overrides existed.
+func (s *Store) VectorSearchSQLite(ctx context.Context, query string, options SearchOptions) ([]SearchResult, error) {
+	embedCfg := s.config
+	if options.Collection != "" {
+		embedCfg = s.config.ResolveCollection(options.Collection)
+	}
 
-	// Generate query embedding
-	embeddingResult, err := s.llmRouter.Embed(ctx, []string{query})
+	embeddingResult, err := s.llmRouter.EmbedWithModel(ctx, embedCfg.Models.Embed, []string{query})
 	if err != nil {
 		// Fall back to BM25
-		return s.BM25Search(query, options)
+		return s.BM25Search(ctx, query, options)
 	}
 
 	queryVector := embeddingResult.Embeddings[0]
 
-	// Search using sqlite-vec
 	results := []SearchResult{}
-	collections := s.getCollections(options)
-
-	for _, collection := range collections {
-		db, err := s.GetConnection(collection)
+	for _, collection := range s.getCollections(options) {
+		resolved := s.config.ResolveCollection(collection)
+		idx, err := s.vectorIndexerFor(qmdBuiltinIndexerName(resolved), collection, resolved)
 		if err != nil {
+			// sqlite-vec may not be available
 			continue
 		}
 
-		// Convert vector to JSON
-		vectorJSON, _ := json.Marshal(queryVector)
-
-		rows, err := db.Query(`
-			SELECT
-				v.hash_seq,
-				v.embedding,
-				d.title,
-				d.path,
-				d.hash,
-				d.collection
-			FROM vectors_vec v
-			JOIN documents d ON v.hash_seq LIKE d.hash || '%'
-			WHERE d.active = 1
-			ORDER BY v.embedding <=> ?
-			LIMIT ?
-		`, string(vectorJSON), options.Limit)
-
+		hits, err := idx.Search(ctx, queryVector, options.Limit)
 		if err != nil {
-			// sqlite-vec may not be available
 			continue
 		}
 
-		for rows.Next() {
-			var hashSeq string
-			var embedding float64
-			var title, path, hash, coll string
-
-			rows.Scan(&hashSeq, &embedding, &title, &path, &hash, &coll)
-
-			// Convert distance to score
-			score := 1.0 / (1.0 + embedding)
-
+		for _, hit := range hits {
 			results = append(results, SearchResult{
-				Path:       coll + "/" + path,
-				Collection: coll,
-				Score:      float32(score),
+				Path:       hit.Collection + "/" + hit.Path,
+				Collection: hit.Collection,
+				Score:      hit.Score,
 				Lines:      0,
-				Title:      title,
-				Hash:       hash,
+				Title:      hit.Title,
+				Hash:       hit.ID,
+				DocID:      hit.ID,
 			})
 		}
 	}
 
 	if len(results) == 0 {
 		// Fall back to BM25
-		return s.BM25Search(query, options)
+		return s.BM25Search(ctx, query, options)
 	}
 
+	ApplySort(results, options.SortSpec)
 	return results, nil
 }
 
-// HybridSearch performs hybrid search with reranking
-func (s *Store) HybridSearch(query string, options SearchOptions) ([]SearchResult, error) {
-	// Query expansion
-	_ = s.expandQuery(query)
+// rebuildBatchSize is how many vectors RebuildIndex pages through
+// vectors_vec at a time, matching the Migrator's default.
+const rebuildBatchSize = 100
+
+// RebuildIndex rebuilds collection's vector index from scratch by paging
+// through its vectors_vec table and replaying every vector into a fresh
+// index, rather than relying on whatever Index calls already happened to
+// accumulate. It's a no-op for backends that don't implement
+// indexer.Rebuilder (currently only the HNSW driver does), returning an
+// error so a caller switching Vector.Index to hnsw for an
+// already-populated collection knows it needs this instead of assuming
+// the switch alone backfilled the graph.
+func (s *Store) RebuildIndex(ctx context.Context, collection string) error {
+	resolved := s.config.ResolveCollection(collection)
+	idx, err := s.vectorIndexerFor(qmdBuiltinIndexerName(resolved), collection, resolved)
+	if err != nil {
+		return err
+	}
+
+	rebuilder, ok := idx.(indexer.Rebuilder)
+	if !ok {
+		return fmt.Errorf("store: vector index for collection %q doesn't support rebuilding", collection)
+	}
+
+	db, err := s.GetConnection(collection)
+	if err != nil {
+		return err
+	}
+	source := NewSQLiteVectorBackend(db, collection, 0)
+
+	var docs []indexer.VectorDocument
+	cursor := ""
+	for {
+		points, nextCursor, err := source.StreamPoints(ctx, cursor, rebuildBatchSize)
+		if err != nil {
+			return fmt.Errorf("rebuild %q: %w", collection, err)
+		}
+		for _, p := range points {
+			docs = append(docs, indexer.VectorDocument{
+				ID:         p.Hash,
+				Collection: collection,
+				Path:       p.Path,
+				Title:      p.Title,
+				Body:       p.Body,
+				Vector:     p.Vector,
+			})
+		}
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	return rebuilder.Rebuild(ctx, docs)
+}
+
+// Retrievers selects which retriever(s) HybridSearchWithOptions combines.
+type Retrievers int
+
+const (
+	// RetrieversBoth runs BM25 and vector search and fuses both lists.
+	RetrieversBoth Retrievers = iota
+	// RetrieversBM25Only skips vector retrieval entirely.
+	RetrieversBM25Only
+	// RetrieversVectorOnly skips BM25 retrieval entirely.
+	RetrieversVectorOnly
+)
+
+// DefaultCandidateWindow is the reranking candidate window
+// HybridSearchOptions uses when CandidateWindow is left at zero,
+// matching HybridSearch's previous hard-coded cap.
+const DefaultCandidateWindow = 30
+
+// HybridSearchOptions configures HybridSearchWithOptions: which
+// retriever(s) to combine, and how many fused candidates to keep for
+// reranking.
+type HybridSearchOptions struct {
+	Retrievers      Retrievers
+	CandidateWindow int
+}
+
+// HybridSearch performs hybrid search with reranking, combining both
+// retrievers over a DefaultCandidateWindow-sized candidate pool. It's a
+// thin wrapper over HybridSearchWithOptions for callers that don't need
+// to pick retrievers or adjust the window.
+func (s *Store) HybridSearch(ctx context.Context, query string, options SearchOptions) ([]SearchResult, error) {
+	return s.HybridSearchWithOptions(ctx, query, options, HybridSearchOptions{})
+}
 
-	// Parallel retrieval
-	bm25Results, _ := s.BM25Search(query, options)
-	vectorResults, _ := s.VectorSearch(query, options)
+// HybridSearchWithOptions performs hybrid search with reranking. ctx is
+// threaded into the vector leg of retrieval so the whole call can be
+// canceled. hybridOpts.Retrievers picks which retriever(s) feed the
+// fusion step (running only one skips the other's network/DB call
+// entirely, rather than fusing an empty list); hybridOpts.CandidateWindow
+// caps how many fused results are kept for the (not yet implemented)
+// reranking pass, defaulting to DefaultCandidateWindow. Fusion itself is
+// delegated to s.fuse, which honors options.Fusion (defaulting to
+// FusionRRF).
+func (s *Store) HybridSearchWithOptions(ctx context.Context, query string, options SearchOptions, hybridOpts HybridSearchOptions) ([]SearchResult, error) {
+	ctx, span := anel.StartSpan(ctx, "store.HybridSearch",
+		attribute.String("qmd.collection", options.Collection),
+		attribute.Int("qmd.k", options.Limit),
+		attribute.String("qmd.backend", string(s.config.Vector.Backend)),
+	)
+	defer span.End()
+
+	var bm25Results, vectorResults []SearchResult
+
+	switch hybridOpts.Retrievers {
+	case RetrieversBM25Only:
+		bm25Results, _ = s.BM25Search(ctx, query, options)
+	case RetrieversVectorOnly:
+		vectorResults, _ = s.VectorSearch(ctx, query, options)
+	default:
+		bm25Results, vectorResults = s.retrieveParallel(ctx, query, options)
+	}
 
-	// RRF fusion
-	fused := s.rrfFusion([][]SearchResult{bm25Results, vectorResults}, nil, 60)
+	fused := s.fuse(ctx, options, bm25Results, vectorResults)
 
-	// Top 30 for reranking
+	window := hybridOpts.CandidateWindow
+	if window <= 0 {
+		window = DefaultCandidateWindow
+	}
 	candidates := fused
-	if len(candidates) > 30 {
-		candidates = candidates[:30]
+	if len(candidates) > window {
+		candidates = candidates[:window]
 	}
 
+	ApplySort(candidates, options.SortSpec)
 	return candidates, nil
 }
 
+// DefaultRRFK is the Reciprocal Rank Fusion constant used when a caller
+// doesn't specify one: score(d) = sum 1/(k + rank_i(d)). Larger k flattens
+// the influence of rank; 60 is the commonly cited default from the RRF
+// literature.
+const DefaultRRFK = 60
+
+// HSearch performs Meilisearch-style hybrid search: it runs the BM25 and
+// vector queries in parallel and fuses their ranked lists with weighted
+// Reciprocal Rank Fusion, with no reranking pass. semanticRatio weights
+// the vector list's contribution against BM25's (0.0 = pure BM25, 1.0 =
+// pure vector); k is the RRF constant, defaulting to DefaultRRFK when
+// k<=0.
+func (s *Store) HSearch(ctx context.Context, query string, options SearchOptions, semanticRatio float32, k int) ([]SearchResult, error) {
+	bm25Results, vectorResults := s.retrieveParallel(ctx, query, options)
+
+	weights := []float32{1 - semanticRatio, semanticRatio}
+	fused := s.rrfFusion(ctx, [][]SearchResult{bm25Results, vectorResults}, weights, k)
+
+	if options.Limit > 0 && len(fused) > options.Limit {
+		fused = fused[:options.Limit]
+	}
+
+	return fused, nil
+}
+
+// retrieveParallel runs BM25Search and VectorSearch concurrently,
+// returning empty slices for whichever leg errors so callers can still
+// fuse whatever came back.
+func (s *Store) retrieveParallel(ctx context.Context, query string, options SearchOptions) (bm25Results, vectorResults []SearchResult) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		bm25Results, _ = s.BM25Search(ctx, query, options)
+	}()
+	go func() {
+		defer wg.Done()
+		vectorResults, _ = s.VectorSearch(ctx, query, options)
+	}()
+
+	wg.Wait()
+	return bm25Results, vectorResults
+}
+
 func (s *Store) getCollections(options SearchOptions) []string {
 	if options.SearchAll {
 		collections := make([]string, len(s.config.Collections))
@@ -337,34 +962,131 @@ func (s *Store) getCollections(options SearchOptions) []string {
 	return []string{}
 }
 
-func (s *Store) expandQuery(query string) []string {
-	// TODO: Implement query expansion
-	return []string{query}
+// RRFFusionOptions configures rrfFusionWithOptions. K and Weights behave
+// the same as rrfFusion's k/weights parameters; DedupeKey lets a caller
+// replace the default Hash-based dedupe key (e.g. with
+// Collection+"/"+Path, for result lists that don't populate Hash).
+type RRFFusionOptions struct {
+	K         int
+	Weights   []float32
+	DedupeKey func(SearchResult) string
 }
 
-func (s *Store) rrfFusion(resultLists [][]SearchResult, weights []float32, k int) []SearchResult {
-	// TODO: Implement RRF fusion
-	if len(resultLists) == 0 {
-		return []SearchResult{}
+// fuse resolves options.Fusion (falling back to FusionRRF for an
+// unrecognized name, the same way VectorSearch falls back to BM25 on an
+// unusable backend) and combines bm25Results/vectorResults with it,
+// passing options.ConvexAlpha through when set (including an explicit
+// 0, meaning pure vector), else the collection's configured
+// config.CollectionConfig.FusionAlpha.
+func (s *Store) fuse(ctx context.Context, options SearchOptions, bm25Results, vectorResults []SearchResult) []SearchResult {
+	var fused []SearchResult
+	otel.Wrap(ctx, otel.SpanHybridMerge, otel.Attrs{Collection: options.Collection, K: options.Limit}, func(ctx context.Context) (int, error) {
+		strategy, err := ResolveFusionStrategy(options.Fusion)
+		if err != nil {
+			strategy = rrfFusionStrategy{}
+		}
+
+		alpha := s.convexAlphaFor(options.Collection)
+		if options.ConvexAlpha != nil {
+			alpha = *options.ConvexAlpha
+		}
+
+		lists := []FusionList{
+			{Source: "bm25", Results: bm25Results},
+			{Source: "vector", Results: vectorResults},
+		}
+		fused = strategy.Fuse(lists, FusionOptions{K: DefaultRRFK, Alpha: alpha, AlphaSet: true})
+		return len(fused), nil
+	})
+	return fused
+}
+
+// convexAlphaFor returns collection's configured FusionAlpha, or
+// DefaultConvexAlpha if collection is empty, unknown, or left its
+// FusionAlpha unset.
+func (s *Store) convexAlphaFor(collection string) float32 {
+	if c, ok := s.config.Collection(collection); ok && c.FusionAlpha != 0 {
+		return c.FusionAlpha
 	}
+	return DefaultConvexAlpha
+}
+
+// defaultDedupeKey returns result.Hash, falling back to
+// Collection+"/"+Path when Hash is empty so callers with no Hash don't
+// all collapse into a single dedupe bucket.
+func defaultDedupeKey(result SearchResult) string {
+	if result.Hash != "" {
+		return result.Hash
+	}
+	return result.Collection + "/" + result.Path
+}
+
+// rrfFusion merges ranked result lists into one list ordered by
+// Reciprocal Rank Fusion score, deduplicating by Hash (or
+// Collection+"/"+Path when Hash is empty) with k defaulting to
+// DefaultRRFK and weights defaulting to 1.0 per list. It's a thin
+// wrapper over rrfFusionWithOptions for callers that don't need a custom
+// dedupe key.
+func (s *Store) rrfFusion(ctx context.Context, resultLists [][]SearchResult, weights []float32, k int) []SearchResult {
+	return s.rrfFusionWithOptions(ctx, resultLists, RRFFusionOptions{K: k, Weights: weights})
+}
+
+// rrfFusionWithOptions merges ranked result lists into one list ordered
+// by Reciprocal Rank Fusion score: for each document d, score(d) = sum
+// over lists containing d of weight_i/(k + rank_i(d)), with rank_i the
+// document's 1-based rank in list i. opts.Weights defaults to 1.0 per
+// list when nil or shorter than the list count, so every list counts
+// equally; documents absent from a list contribute nothing to that
+// list's term. Documents are deduplicated by opts.DedupeKey (Hash by
+// default), and the fused score replaces the highest-original-score
+// result's score. Ties in the fused score keep the order they were
+// first seen in, so fusing the same lists twice always returns the same
+// order.
+func (s *Store) rrfFusionWithOptions(ctx context.Context, resultLists [][]SearchResult, opts RRFFusionOptions) []SearchResult {
+	var fused []SearchResult
+	otel.Wrap(ctx, otel.SpanHybridMerge, otel.Attrs{K: opts.K}, func(ctx context.Context) (int, error) {
+		if len(resultLists) == 0 {
+			fused = []SearchResult{}
+			return 0, nil
+		}
 
-	return resultLists[0]
+		lists := make([]FusionList, len(resultLists))
+		for i, results := range resultLists {
+			lists[i] = FusionList{Source: fmt.Sprintf("list%d", i), Results: results}
+		}
+
+		fused = rrfFusionStrategy{}.Fuse(lists, FusionOptions{
+			Weights:   opts.Weights,
+			K:         opts.K,
+			DedupeKey: opts.DedupeKey,
+		})
+		return len(fused), nil
+	})
+	return fused
 }
 
-// GetStats returns index statistics
-func (s *Store) GetStats() (*IndexStats, error) {
+// GetStats returns index statistics. It polls ctx between collections --
+// the loop's only safe point, since a single collection's COUNT query is
+// already bounded by ctx via QueryRowContext -- so a caller's
+// --timeout/--deadline is honored without leaving a collection's query
+// running after GetStats has returned.
+func (s *Store) GetStats(ctx context.Context) (*IndexStats, error) {
 	stats := &IndexStats{
 		CollectionCount: len(s.config.Collections),
 	}
 
 	for _, collection := range s.config.Collections {
+		if err := ctx.Err(); err != nil {
+			return nil, anel.DeadlineExceededError(err)
+		}
+
 		db, err := s.GetConnection(collection.Name)
 		if err != nil {
 			continue
 		}
 
 		var count int
-		err = db.QueryRow("SELECT COUNT(*) FROM documents WHERE active = 1").Scan(&count)
+		err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM documents WHERE active = 1").Scan(&count)
 		if err != nil {
 			continue
 		}
@@ -375,3 +1097,74 @@ func (s *Store) GetStats() (*IndexStats, error) {
 	stats.IndexedCount = stats.DocumentCount
 	return stats, nil
 }
+
+// BackendStatus reports one configured backend's resolved driver and
+// health, for `qmd status --backends`.
+type BackendStatus struct {
+	Kind    string // "fts" or "vector"
+	Backend string
+	Healthy bool
+	Error   string
+}
+
+// BackendStatuses resolves the configured FTS and vector backends
+// through indexer.Registry and reports each one's health, so `qmd status
+// --backends` can tell a user their --fts-backend/--vector-backend
+// choice is actually reachable before they run a search against it.
+func (s *Store) BackendStatuses(ctx context.Context) []BackendStatus {
+	return []BackendStatus{
+		s.checkFTSBackend(ctx),
+		s.checkVectorBackend(ctx),
+	}
+}
+
+func (s *Store) checkFTSBackend(ctx context.Context) BackendStatus {
+	status := BackendStatus{Kind: "fts", Backend: string(s.config.BM25.Backend)}
+
+	collection := ""
+	if len(s.config.Collections) > 0 {
+		collection = s.config.Collections[0].Name
+	}
+
+	idx, err := s.ftsIndexerFor(collection)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	if hc, ok := idx.(indexer.HealthChecker); ok {
+		if err := hc.Health(ctx); err != nil {
+			status.Error = err.Error()
+			return status
+		}
+	}
+
+	status.Healthy = true
+	return status
+}
+
+func (s *Store) checkVectorBackend(ctx context.Context) BackendStatus {
+	status := BackendStatus{Kind: "vector", Backend: string(s.config.Vector.Backend)}
+
+	collection := ""
+	if len(s.config.Collections) > 0 {
+		collection = s.config.Collections[0].Name
+	}
+
+	resolved := s.config.ResolveCollection(collection)
+	idx, err := s.vectorIndexerFor(string(resolved.Vector.Backend), collection, resolved)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	if hc, ok := idx.(indexer.HealthChecker); ok {
+		if err := hc.Health(ctx); err != nil {
+			status.Error = err.Error()
+			return status
+		}
+	}
+
+	status.Healthy = true
+	return status
+}
@@ -0,0 +1,190 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRrfFusion_DedupesAndRanksByFusedScore(t *testing.T) {
+	s := &Store{}
+
+	bm25 := []SearchResult{
+		{Hash: "a", Path: "a.md", Score: 10},
+		{Hash: "b", Path: "b.md", Score: 5},
+	}
+	vector := []SearchResult{
+		{Hash: "b", Path: "b.md", Score: 0.9},
+		{Hash: "c", Path: "c.md", Score: 0.8},
+	}
+
+	fused := s.rrfFusion(context.Background(), [][]SearchResult{bm25, vector}, nil, 60)
+
+	if len(fused) != 3 {
+		t.Fatalf("expected 3 distinct documents, got %d: %+v", len(fused), fused)
+	}
+
+	// "b" appears first in both lists (rank 1 in vector, rank 2 in bm25)
+	// so its fused score should beat "a" and "c", which only appear once.
+	if fused[0].Hash != "b" {
+		t.Errorf("expected %q to rank first, got %q", "b", fused[0].Hash)
+	}
+}
+
+func TestRrfFusion_SemanticRatioWeightsVectorList(t *testing.T) {
+	s := &Store{}
+
+	bm25 := []SearchResult{{Hash: "a", Score: 10}}
+	vector := []SearchResult{{Hash: "b", Score: 10}}
+
+	// Pure BM25: "a" should win.
+	fused := s.rrfFusion(context.Background(), [][]SearchResult{bm25, vector}, []float32{1, 0}, 60)
+	if fused[0].Hash != "a" {
+		t.Errorf("semantic_ratio=0: expected %q first, got %q", "a", fused[0].Hash)
+	}
+
+	// Pure vector: "b" should win.
+	fused = s.rrfFusion(context.Background(), [][]SearchResult{bm25, vector}, []float32{0, 1}, 60)
+	if fused[0].Hash != "b" {
+		t.Errorf("semantic_ratio=1: expected %q first, got %q", "b", fused[0].Hash)
+	}
+}
+
+func TestRrfFusion_EmptyInput(t *testing.T) {
+	s := &Store{}
+	if fused := s.rrfFusion(context.Background(), nil, nil, 60); len(fused) != 0 {
+		t.Errorf("expected no results, got %+v", fused)
+	}
+}
+
+func TestRrfFusionWithOptions_CustomDedupeKey(t *testing.T) {
+	s := &Store{}
+
+	// No Hash set; dedupe must fall back to Collection+"/"+Path.
+	bm25 := []SearchResult{{Collection: "notes", Path: "a.md", Score: 10}}
+	vector := []SearchResult{{Collection: "notes", Path: "a.md", Score: 0.9}}
+
+	fused := s.rrfFusionWithOptions(context.Background(), [][]SearchResult{bm25, vector}, RRFFusionOptions{K: 60})
+	if len(fused) != 1 {
+		t.Fatalf("expected dedupe via Collection+Path fallback, got %d results: %+v", len(fused), fused)
+	}
+}
+
+func TestRrfFusionWithOptions_TieBreakKeepsFirstSeenOrder(t *testing.T) {
+	s := &Store{}
+
+	list := []SearchResult{
+		{Hash: "a", Score: 1},
+		{Hash: "b", Score: 1},
+		{Hash: "c", Score: 1},
+	}
+
+	fused := s.rrfFusionWithOptions(context.Background(), [][]SearchResult{list}, RRFFusionOptions{K: 60})
+	want := []string{"a", "b", "c"}
+	for i, hash := range want {
+		if fused[i].Hash != hash {
+			t.Errorf("fused[%d].Hash = %q, want %q (tie-break should preserve first-seen order)", i, fused[i].Hash, hash)
+		}
+	}
+}
+
+func TestRrfFusionWithOptions_EmptyAndDuplicateLists(t *testing.T) {
+	s := &Store{}
+
+	dup := []SearchResult{{Hash: "a", Score: 1}, {Hash: "a", Score: 2}}
+	fused := s.rrfFusionWithOptions(context.Background(), [][]SearchResult{dup, nil, {}}, RRFFusionOptions{K: 60})
+
+	if len(fused) != 1 {
+		t.Fatalf("expected duplicate hash within a list to collapse to 1 result, got %d: %+v", len(fused), fused)
+	}
+}
+
+func TestRrfFusion_MoreResultsThanDefaultCandidateWindow(t *testing.T) {
+	s := &Store{}
+
+	bm25 := make([]SearchResult, DefaultCandidateWindow+20)
+	for i := range bm25 {
+		bm25[i] = SearchResult{Hash: fmt.Sprintf("doc-%d", i), Score: float32(len(bm25) - i)}
+	}
+
+	fused := s.rrfFusion(context.Background(), [][]SearchResult{bm25, nil}, nil, DefaultRRFK)
+	if len(fused) <= DefaultCandidateWindow {
+		t.Fatalf("test setup: need more than %d fused results, got %d", DefaultCandidateWindow, len(fused))
+	}
+}
+
+func TestChunkPointID_StableAndDistinct(t *testing.T) {
+	id1 := chunkPointID("abc123", 0)
+	id2 := chunkPointID("abc123", 0)
+	if id1 != id2 {
+		t.Errorf("chunkPointID should be deterministic, got %d and %d", id1, id2)
+	}
+
+	if id3 := chunkPointID("abc123", 1); id3 == id1 {
+		t.Errorf("chunkPointID for a different seq should differ, both were %d", id1)
+	}
+}
+
+func TestParseSortSpec_DescAndAscFields(t *testing.T) {
+	keys, err := ParseSortSpec("-score,collection,title")
+	if err != nil {
+		t.Fatalf("ParseSortSpec: %v", err)
+	}
+
+	want := []SortKey{{Field: "score", Desc: true}, {Field: "collection"}, {Field: "title"}}
+	if len(keys) != len(want) {
+		t.Fatalf("ParseSortSpec() = %+v, want %+v", keys, want)
+	}
+	for i, k := range keys {
+		if k != want[i] {
+			t.Errorf("key %d = %+v, want %+v", i, k, want[i])
+		}
+	}
+}
+
+func TestParseSortSpec_Empty(t *testing.T) {
+	keys, err := ParseSortSpec("")
+	if err != nil || keys != nil {
+		t.Errorf("ParseSortSpec(\"\") = %+v, %v, want nil, nil", keys, err)
+	}
+}
+
+func TestParseSortSpec_UnknownFieldListsAllowed(t *testing.T) {
+	_, err := ParseSortSpec("bogus")
+	if err == nil {
+		t.Fatal("expected an error for an unknown sort field")
+	}
+	for _, field := range SortableFields {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("error %q should list allowed field %q", err, field)
+		}
+	}
+}
+
+func TestApplySort_MultiKeyBreaksTies(t *testing.T) {
+	results := []SearchResult{
+		{Title: "b", Score: 1},
+		{Title: "a", Score: 1},
+		{Title: "c", Score: 2},
+	}
+
+	ApplySort(results, []SortKey{{Field: "score", Desc: true}, {Field: "title"}})
+
+	got := []string{results[0].Title, results[1].Title, results[2].Title}
+	want := []string{"c", "a", "b"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ApplySort order = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestApplySort_EmptySpecLeavesOrderUnchanged(t *testing.T) {
+	results := []SearchResult{{Hash: "b"}, {Hash: "a"}}
+	ApplySort(results, nil)
+	if results[0].Hash != "b" || results[1].Hash != "a" {
+		t.Errorf("ApplySort with no spec should not reorder, got %+v", results)
+	}
+}
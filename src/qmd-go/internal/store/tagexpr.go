@@ -0,0 +1,169 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tagExprNode is one node of a parsed tag expression, evaluated against
+// a single document's tag membership.
+type tagExprNode interface {
+	eval(tags map[string]bool) bool
+	tagNames(into map[string]bool)
+}
+
+// tagExprTerm is a bare tag name, true when the document carries it.
+type tagExprTerm struct{ name string }
+
+func (n tagExprTerm) eval(tags map[string]bool) bool { return tags[n.name] }
+func (n tagExprTerm) tagNames(into map[string]bool)  { into[n.name] = true }
+
+type tagExprNot struct{ operand tagExprNode }
+
+func (n tagExprNot) eval(tags map[string]bool) bool { return !n.operand.eval(tags) }
+func (n tagExprNot) tagNames(into map[string]bool)  { n.operand.tagNames(into) }
+
+type tagExprAnd struct{ left, right tagExprNode }
+
+func (n tagExprAnd) eval(tags map[string]bool) bool { return n.left.eval(tags) && n.right.eval(tags) }
+func (n tagExprAnd) tagNames(into map[string]bool) {
+	n.left.tagNames(into)
+	n.right.tagNames(into)
+}
+
+type tagExprOr struct{ left, right tagExprNode }
+
+func (n tagExprOr) eval(tags map[string]bool) bool { return n.left.eval(tags) || n.right.eval(tags) }
+func (n tagExprOr) tagNames(into map[string]bool) {
+	n.left.tagNames(into)
+	n.right.tagNames(into)
+}
+
+// parseTagExpr parses a boolean tag expression like "golang AND
+// (tutorial OR reference) AND NOT deprecated" into a tagExprNode. AND,
+// OR, and NOT are matched case-insensitively; anything else is treated
+// as a tag name (lowercased, matching AddTags' tag naming). NOT binds
+// tightest, then AND, then OR.
+func parseTagExpr(expr string) (tagExprNode, error) {
+	p := &tagExprParser{tokens: tokenizeTagExpr(expr)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty tag expression")
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %q in tag expression", p.tokens[p.pos])
+	}
+	return node, nil
+}
+
+// tokenizeTagExpr splits expr on whitespace, treating '(' and ')' as
+// standalone tokens even when not surrounded by spaces.
+func tokenizeTagExpr(expr string) []string {
+	var tokens []string
+	var cur strings.Builder
+
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for _, r := range expr {
+		switch {
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+type tagExprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *tagExprParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *tagExprParser) parseOr() (tagExprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "OR") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = tagExprOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *tagExprParser) parseAnd() (tagExprNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "AND") {
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = tagExprAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *tagExprParser) parseNot() (tagExprNode, error) {
+	if strings.EqualFold(p.peek(), "NOT") {
+		p.pos++
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return tagExprNot{operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *tagExprParser) parsePrimary() (tagExprNode, error) {
+	tok := p.peek()
+	switch tok {
+	case "":
+		return nil, fmt.Errorf("unexpected end of tag expression")
+	case "(":
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("expected closing parenthesis in tag expression")
+		}
+		p.pos++
+		return node, nil
+	case ")":
+		return nil, fmt.Errorf("unexpected closing parenthesis in tag expression")
+	default:
+		p.pos++
+		return tagExprTerm{name: strings.ToLower(tok)}, nil
+	}
+}
@@ -0,0 +1,61 @@
+package store
+
+import "testing"
+
+func TestParseTagExpr_SimpleAnd(t *testing.T) {
+	node, err := parseTagExpr("golang AND tutorial")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !node.eval(map[string]bool{"golang": true, "tutorial": true}) {
+		t.Error("expected golang AND tutorial to match when both tags are present")
+	}
+	if node.eval(map[string]bool{"golang": true}) {
+		t.Error("expected golang AND tutorial not to match when only one tag is present")
+	}
+}
+
+func TestParseTagExpr_OrAndNotWithParens(t *testing.T) {
+	node, err := parseTagExpr("golang AND (tutorial OR reference) AND NOT deprecated")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !node.eval(map[string]bool{"golang": true, "reference": true}) {
+		t.Error("expected match: golang + reference, not deprecated")
+	}
+	if node.eval(map[string]bool{"golang": true, "reference": true, "deprecated": true}) {
+		t.Error("expected no match when deprecated is present")
+	}
+	if node.eval(map[string]bool{"golang": true}) {
+		t.Error("expected no match without tutorial or reference")
+	}
+}
+
+func TestParseTagExpr_UnmatchedParenErrors(t *testing.T) {
+	if _, err := parseTagExpr("golang AND (tutorial"); err == nil {
+		t.Fatal("expected an error for an unclosed parenthesis")
+	}
+}
+
+func TestParseTagExpr_EmptyExprErrors(t *testing.T) {
+	if _, err := parseTagExpr("   "); err == nil {
+		t.Fatal("expected an error for an empty tag expression")
+	}
+}
+
+func TestParseTagExpr_TagNames(t *testing.T) {
+	node, err := parseTagExpr("Golang AND (Tutorial OR Reference)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	names := make(map[string]bool)
+	node.tagNames(names)
+
+	for _, want := range []string{"golang", "tutorial", "reference"} {
+		if !names[want] {
+			t.Errorf("expected tagNames to include %q, got %v", want, names)
+		}
+	}
+}
@@ -0,0 +1,437 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/qmd/qmd-go/internal/anel"
+	"github.com/qmd/qmd-go/internal/llm"
+)
+
+// TagCount is one entry of ListTags: a tag and how many documents in
+// the collection currently carry it.
+type TagCount struct {
+	Name  string
+	Count int
+}
+
+// tagSuggestionExcerptChars bounds how much of a document's body
+// SuggestTags sends the model -- a rough proxy for "first chunk" that
+// doesn't depend on however this collection's indexer happened to chunk
+// it.
+const tagSuggestionExcerptChars = 1000
+
+// docHashByPath resolves path's current content hash within collection.
+// Tags are keyed by hash rather than path so they survive re-indexing:
+// a document whose content hasn't changed keeps the same hash (and
+// therefore its tags) across a re-scan, the same way its embeddings do.
+func (s *Store) docHashByPath(collection, path string) (string, error) {
+	db, err := s.GetConnection(collection)
+	if err != nil {
+		return "", err
+	}
+
+	var hash string
+	err = db.QueryRow(`SELECT hash FROM documents WHERE collection = ? AND path = ? AND active = 1`,
+		collection, path).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", anel.Newf(anel.ErrorCodeNotFound, "tag_document_not_found",
+			"no active document at %q in collection %q", path, collection)
+	}
+	if err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// ensureTag returns name's id in db's tags table, inserting it (with an
+// empty description) if it doesn't already exist.
+func (s *Store) ensureTag(db *sql.DB, name string) (int64, error) {
+	if _, err := db.Exec(`INSERT INTO tags(name, description) VALUES (?, '') ON CONFLICT(name) DO NOTHING`, name); err != nil {
+		return 0, err
+	}
+	var id int64
+	if err := db.QueryRow(`SELECT id FROM tags WHERE name = ?`, name).Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// AddTags attaches tagNames to the document at path in collection,
+// keyed by its current content hash.
+func (s *Store) AddTags(collection, path string, tagNames []string) error {
+	hash, err := s.docHashByPath(collection, path)
+	if err != nil {
+		return err
+	}
+	db, err := s.GetConnection(collection)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, name := range tagNames {
+		id, err := s.ensureTag(db, name)
+		if err != nil {
+			return err
+		}
+		if _, err := db.Exec(`INSERT INTO document_tags(doc_hash, tag_id, added_at) VALUES (?, ?, ?) ON CONFLICT(doc_hash, tag_id) DO NOTHING`,
+			hash, id, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveTags detaches tagNames from the document at path in collection.
+// Unknown tag names, or ones not attached to this document, are
+// silently ignored.
+func (s *Store) RemoveTags(collection, path string, tagNames []string) error {
+	hash, err := s.docHashByPath(collection, path)
+	if err != nil {
+		return err
+	}
+	db, err := s.GetConnection(collection)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range tagNames {
+		if _, err := db.Exec(`
+			DELETE FROM document_tags
+			WHERE doc_hash = ? AND tag_id = (SELECT id FROM tags WHERE name = ?)
+		`, hash, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DocumentTags lists the tags currently attached to the document at
+// path in collection.
+func (s *Store) DocumentTags(collection, path string) ([]string, error) {
+	hash, err := s.docHashByPath(collection, path)
+	if err != nil {
+		return nil, err
+	}
+	db, err := s.GetConnection(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT t.name FROM tags t
+		JOIN document_tags dt ON dt.tag_id = t.id
+		WHERE dt.doc_hash = ?
+		ORDER BY t.name
+	`, hash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, name)
+	}
+	return tags, rows.Err()
+}
+
+// ListTags lists every tag defined in collection along with how many
+// documents currently carry it.
+func (s *Store) ListTags(collection string) ([]TagCount, error) {
+	db, err := s.GetConnection(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT t.name, COUNT(dt.doc_hash) FROM tags t
+		LEFT JOIN document_tags dt ON dt.tag_id = t.id
+		GROUP BY t.id
+		ORDER BY t.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []TagCount
+	for rows.Next() {
+		var tc TagCount
+		if err := rows.Scan(&tc.Name, &tc.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, tc)
+	}
+	return counts, rows.Err()
+}
+
+// tagFilterSet resolves options.TagExpr (or, absent that, options.Tags
+// as an implicit AND) into the set of document hashes in db that
+// satisfy it, for BM25Search to intersect against before scoring. It
+// returns a nil map when neither field is set, meaning "no filter".
+func (s *Store) tagFilterSet(db *sql.DB, options SearchOptions) (map[string]bool, error) {
+	if options.TagExpr != "" {
+		return s.evalTagExpr(db, options.TagExpr)
+	}
+	if len(options.Tags) > 0 {
+		return s.hashesWithAllTags(db, options.Tags)
+	}
+	return nil, nil
+}
+
+// hashesWithAllTags returns the hashes of documents carrying every tag
+// in names.
+func (s *Store) hashesWithAllTags(db *sql.DB, names []string) (map[string]bool, error) {
+	var allowed map[string]bool
+	for _, name := range names {
+		hashes, err := hashesForTag(db, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if allowed == nil {
+			allowed = hashes
+			continue
+		}
+		for hash := range allowed {
+			if !hashes[hash] {
+				delete(allowed, hash)
+			}
+		}
+	}
+	if allowed == nil {
+		allowed = make(map[string]bool)
+	}
+	return allowed, nil
+}
+
+// evalTagExpr parses expr and evaluates it against every active
+// document in db, returning the hashes that satisfy it. NOT needs the
+// full universe of document hashes, not just tagged ones, so "NOT
+// deprecated" also matches documents with no tags at all.
+func (s *Store) evalTagExpr(db *sql.DB, expr string) (map[string]bool, error) {
+	node, err := parseTagExpr(expr)
+	if err != nil {
+		return nil, anel.Wrap(anel.ErrorCodeInvalidInput, "tag_expr_parse_failed", err)
+	}
+
+	names := make(map[string]bool)
+	node.tagNames(names)
+
+	tagsByHash := make(map[string]map[string]bool)
+	for name := range names {
+		hashes, err := hashesForTag(db, name)
+		if err != nil {
+			return nil, err
+		}
+		for hash := range hashes {
+			if tagsByHash[hash] == nil {
+				tagsByHash[hash] = make(map[string]bool)
+			}
+			tagsByHash[hash][name] = true
+		}
+	}
+
+	rows, err := db.Query(`SELECT hash FROM documents WHERE active = 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matched := make(map[string]bool)
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		if node.eval(tagsByHash[hash]) {
+			matched[hash] = true
+		}
+	}
+	return matched, rows.Err()
+}
+
+// hashesForTag returns the hashes of documents carrying name.
+func hashesForTag(db *sql.DB, name string) (map[string]bool, error) {
+	rows, err := db.Query(`
+		SELECT dt.doc_hash FROM document_tags dt
+		JOIN tags t ON t.id = dt.tag_id
+		WHERE t.name = ?
+	`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]bool)
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes[hash] = true
+	}
+	return hashes, rows.Err()
+}
+
+// firstChunk returns body's first tagSuggestionExcerptChars.
+func firstChunk(body string) string {
+	if len(body) <= tagSuggestionExcerptChars {
+		return body
+	}
+	return body[:tagSuggestionExcerptChars]
+}
+
+// TagSearch returns every active document in options' collection(s)
+// matching options.TagExpr (or options.Tags), with no text query to
+// score against -- each result's Score is left at zero. For the `qmd
+// tag search` command, where the query is the tag expression itself
+// rather than free text.
+func (s *Store) TagSearch(options SearchOptions) ([]SearchResult, error) {
+	var results []SearchResult
+
+	for _, collection := range s.getCollections(options) {
+		db, err := s.GetConnection(collection)
+		if err != nil {
+			continue
+		}
+
+		allowed, err := s.tagFilterSet(db, options)
+		if err != nil || allowed == nil {
+			continue
+		}
+
+		rows, err := db.Query(`SELECT hash, path, title FROM documents WHERE active = 1`)
+		if err != nil {
+			continue
+		}
+		for rows.Next() {
+			var hash, path, title string
+			if err := rows.Scan(&hash, &path, &title); err != nil {
+				continue
+			}
+			if !allowed[hash] {
+				continue
+			}
+			results = append(results, SearchResult{
+				Path:       path,
+				Collection: collection,
+				Title:      title,
+				Hash:       hash,
+				DocID:      hash,
+			})
+		}
+		rows.Close()
+	}
+
+	if options.Limit > 0 && len(results) > options.Limit {
+		results = results[:options.Limit]
+	}
+	ApplySort(results, options.SortSpec)
+	return results, nil
+}
+
+// SuggestTags asks the configured LLM to propose tags for the document
+// at path in collection, feeding it the document's title and first
+// chunk, and records each suggestion (with its confidence score) in
+// tag_suggestions for AcceptTagSuggestion/RejectTagSuggestion to resolve
+// later -- it never writes to document_tags directly.
+func (s *Store) SuggestTags(ctx context.Context, collection, path string, n int) ([]llm.TagSuggestion, error) {
+	hash, err := s.docHashByPath(collection, path)
+	if err != nil {
+		return nil, err
+	}
+	db, err := s.GetConnection(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	var title, body string
+	if err := db.QueryRow(`SELECT title FROM documents WHERE hash = ?`, hash).Scan(&title); err != nil {
+		return nil, err
+	}
+	if err := db.QueryRow(`SELECT doc FROM content WHERE hash = ?`, hash).Scan(&body); err != nil {
+		return nil, err
+	}
+
+	suggestions, err := s.llmRouter.SuggestTags(ctx, title, firstChunk(body), n)
+	if err != nil || suggestions == nil {
+		return suggestions, err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, suggestion := range suggestions {
+		id, err := s.ensureTag(db, suggestion.Tag)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := db.Exec(`
+			INSERT INTO tag_suggestions(doc_hash, tag_id, confidence, suggested_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(doc_hash, tag_id) DO UPDATE SET
+				confidence = excluded.confidence,
+				suggested_at = excluded.suggested_at
+		`, hash, id, suggestion.Confidence, now); err != nil {
+			return nil, err
+		}
+	}
+	return suggestions, nil
+}
+
+// AcceptTagSuggestion promotes a pending tag_suggestions entry into
+// document_tags and removes the suggestion.
+func (s *Store) AcceptTagSuggestion(collection, path, tagName string) error {
+	hash, err := s.docHashByPath(collection, path)
+	if err != nil {
+		return err
+	}
+	db, err := s.GetConnection(collection)
+	if err != nil {
+		return err
+	}
+
+	var tagID int64
+	err = db.QueryRow(`SELECT tag_id FROM tag_suggestions WHERE doc_hash = ? AND tag_id = (SELECT id FROM tags WHERE name = ?)`,
+		hash, tagName).Scan(&tagID)
+	if err == sql.ErrNoRows {
+		return anel.Newf(anel.ErrorCodeNotFound, "tag_suggestion_not_found",
+			"no pending suggestion of tag %q for %q", tagName, path)
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := db.Exec(`INSERT INTO document_tags(doc_hash, tag_id, added_at) VALUES (?, ?, ?) ON CONFLICT(doc_hash, tag_id) DO NOTHING`,
+		hash, tagID, now); err != nil {
+		return err
+	}
+	_, err = db.Exec(`DELETE FROM tag_suggestions WHERE doc_hash = ? AND tag_id = ?`, hash, tagID)
+	return err
+}
+
+// RejectTagSuggestion discards a pending tag_suggestions entry without
+// adding it to document_tags.
+func (s *Store) RejectTagSuggestion(collection, path, tagName string) error {
+	hash, err := s.docHashByPath(collection, path)
+	if err != nil {
+		return err
+	}
+	db, err := s.GetConnection(collection)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		DELETE FROM tag_suggestions
+		WHERE doc_hash = ? AND tag_id = (SELECT id FROM tags WHERE name = ?)
+	`, hash, tagName)
+	return err
+}
@@ -0,0 +1,81 @@
+// Package qmd is the importable API behind the qmd CLI: a Client wraps a
+// configured store.Store so other Go programs -- the MCP server, tests,
+// or a caller embedding QMD directly -- can run searches and manage
+// collections without going through Cobra. internal/cli builds one of
+// these per invocation and layers flag parsing, --strict/--dry-run/
+// --emit-spec handling, and output formatting on top of it.
+package qmd
+
+import (
+	"context"
+
+	"github.com/qmd/qmd-go/internal/config"
+	"github.com/qmd/qmd-go/internal/store"
+)
+
+// Client is the entry point to the pkg/qmd API: a Store bound to one
+// loaded Config.
+type Client struct {
+	store  *store.Store
+	config *config.Config
+}
+
+// NewClient builds a Client from an already-loaded Config. Use
+// config.LoadConfig (or config.DefaultConfig) to obtain one.
+func NewClient(cfg *config.Config) (*Client, error) {
+	s, err := store.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{store: s, config: cfg}, nil
+}
+
+// Config returns the Config the Client was built from.
+func (c *Client) Config() *config.Config {
+	return c.config
+}
+
+// Search runs a BM25 full-text search.
+func (c *Client) Search(ctx context.Context, query string, opts store.SearchOptions) ([]store.SearchResult, error) {
+	return c.store.BM25Search(ctx, query, opts)
+}
+
+// VectorSearch runs a vector semantic search.
+func (c *Client) VectorSearch(ctx context.Context, query string, opts store.SearchOptions) ([]store.SearchResult, error) {
+	return c.store.VectorSearch(ctx, query, opts)
+}
+
+// HybridSearch runs hybrid search with reranking, fusing BM25 and vector
+// results.
+func (c *Client) HybridSearch(ctx context.Context, query string, opts store.SearchOptions) ([]store.SearchResult, error) {
+	return c.store.HybridSearch(ctx, query, opts)
+}
+
+// AddCollection appends col to the Client's Config and persists it,
+// mirroring `qmd collection add`.
+func (c *Client) AddCollection(col config.CollectionConfig) error {
+	c.config.Collections = append(c.config.Collections, col)
+	return c.config.Save()
+}
+
+// Status reports the health of every backend the Client's Config
+// selects (FTS and vector), mirroring `qmd status --backends`.
+func (c *Client) Status(ctx context.Context) []store.BackendStatus {
+	return c.store.BackendStatuses(ctx)
+}
+
+// RebuildIndex rebuilds collection's vector index from scratch,
+// mirroring `qmd embed --rebuild-index`. It's only meaningful for
+// backends that keep accumulated state beyond what Index calls alone
+// maintain (currently the HNSW index selected via Vector.Index).
+func (c *Client) RebuildIndex(ctx context.Context, collection string) error {
+	return c.store.RebuildIndex(ctx, collection)
+}
+
+// Cleanup removes stale index entries. It's a placeholder today -- `qmd
+// cleanup` doesn't do real work yet either -- kept here so callers can
+// already code against the Client surface this will eventually perform
+// the removal through.
+func (c *Client) Cleanup(ctx context.Context, olderThanDays uint, dryRun bool) (removed int, err error) {
+	return 0, nil
+}